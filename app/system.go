@@ -6,6 +6,7 @@ import (
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/audit"
 	"github.com/jakevoytko/crbot/config"
 	"github.com/jakevoytko/crbot/feature"
 	"github.com/jakevoytko/crbot/feature/factsphere"
@@ -15,6 +16,8 @@ import (
 	"github.com/jakevoytko/crbot/feature/learn"
 	"github.com/jakevoytko/crbot/feature/list"
 	"github.com/jakevoytko/crbot/feature/moderation"
+	"github.com/jakevoytko/crbot/feature/permissions"
+	"github.com/jakevoytko/crbot/feature/toggle"
 	"github.com/jakevoytko/crbot/feature/vote"
 	"github.com/jakevoytko/crbot/log"
 	"github.com/jakevoytko/crbot/model"
@@ -26,7 +29,11 @@ import (
 func InitializeRegistry(
 	commandMap stringmap.StringMap,
 	karmaMap stringmap.StringMap,
+	karmaHistoryMap stringmap.StringMap,
+	karmaMergeMap stringmap.StringMap,
 	voteMap stringmap.StringMap,
+	moderationMap stringmap.StringMap,
+	toggleMap stringmap.StringMap,
 	gist api.Gist,
 	config *config.Config,
 	clock model.UTCClock,
@@ -37,15 +44,18 @@ func InitializeRegistry(
 	// TODO(jvoytko): investigate the circularity that emerged to see if there's
 	// a better pattern here.
 	featureRegistry := feature.NewRegistry()
+	auditBus, auditLog := audit.NewBusFromConfig(config, clock)
 	allFeatures := []feature.Feature{
 		factsphere.NewFeature(featureRegistry),
 		help.NewFeature(featureRegistry),
-		karma.NewFeature(featureRegistry, karmaMap),
-		karmalist.NewFeature(featureRegistry, karmaMap, gist),
-		learn.NewFeature(featureRegistry, commandMap),
+		karma.NewFeature(featureRegistry, karmaMap, karmaHistoryMap, karmaMergeMap, clock, config),
+		karmalist.NewFeature(featureRegistry, karmaMap, karmaHistoryMap, gist, clock, config),
+		learn.NewFeature(featureRegistry, commandMap, auditBus),
 		list.NewFeature(featureRegistry, commandMap, gist),
-		moderation.NewFeature(featureRegistry, config),
-		vote.NewFeature(featureRegistry, voteMap, clock, timer, commandChannel),
+		moderation.NewFeature(featureRegistry, moderationMap, commandMap, auditBus, auditLog, clock, timer, commandChannel, config),
+		permissions.NewFeature(featureRegistry, auditBus, config),
+		toggle.NewFeature(featureRegistry, toggleMap),
+		vote.NewFeature(featureRegistry, voteMap, karmaMap, karmaHistoryMap, karmaMergeMap, gist, auditBus, clock, timer, commandChannel, config),
 	}
 
 	for _, f := range allFeatures {
@@ -64,10 +74,14 @@ func InitializeRegistry(
 const (
 	// MsgPublicOnly is a user-visible string for commands that cannot be executed in a private channels.
 	MsgPublicOnly = "Cannot execute `%s` in a private channel"
+	// MsgModeratorOnly is a user-visible string for commands that only moderators can execute.
+	MsgModeratorOnly = "Only moderators can execute `%s`"
+	// MsgFeatureDisabled is a user-visible string for commands whose feature is disabled in the channel.
+	MsgFeatureDisabled = "`%s` is disabled in this channel"
 )
 
 // HandleCommands pops commands off the command channel and attempts to dispatch them to a command executor.
-func HandleCommands(featureRegistry *feature.Registry, s api.DiscordSession, commandChannel <-chan *model.Command) {
+func HandleCommands(featureRegistry *feature.Registry, config *config.Config, toggles *toggle.ModelHelper, s api.DiscordSession, commandChannel <-chan *model.Command) {
 	for command := range commandChannel {
 		var err error // so I don't have to use := in the intercept() call
 		for _, interceptor := range featureRegistry.CommandInterceptors() {
@@ -87,15 +101,68 @@ func HandleCommands(featureRegistry *feature.Registry, s api.DiscordSession, com
 				log.Info("Error retrieving channel from discord for command executor", err)
 				continue
 			}
+			if !isFeatureEnabled(featureRegistry, toggles, discordChannel, command) {
+				// Only commands that were typed get a reply. Reactions are ignored.
+				if len(command.OriginalName) > 0 {
+					s.ChannelMessageSend(command.ChannelID.Format(), fmt.Sprintf(MsgFeatureDisabled, command.OriginalName))
+				}
+				continue
+			}
 			if (discordChannel.Type == discordgo.ChannelTypeDM || discordChannel.Type == discordgo.ChannelTypeGroupDM) && executor.PublicOnly() {
 				s.ChannelMessageSend(command.ChannelID.Format(), fmt.Sprintf(MsgPublicOnly, command.OriginalName))
 				continue
 			}
+			if executor.ModeratorOnly() && !isModerator(config, command) {
+				s.ChannelMessageSend(command.ChannelID.Format(), fmt.Sprintf(MsgModeratorOnly, command.OriginalName))
+				continue
+			}
 			executor.Execute(s, command.ChannelID, command)
 		}
 	}
 }
 
+// isFeatureEnabled returns whether the features that own the command's parser
+// and executor are enabled in the channel. Commands generated by the bot
+// itself have no author, and always run, so votes still conclude after their
+// feature is disabled.
+func isFeatureEnabled(featureRegistry *feature.Registry, toggles *toggle.ModelHelper, discordChannel *discordgo.Channel, command *model.Command) bool {
+	if command.Author == nil {
+		return true
+	}
+	names := []string{featureRegistry.GetFeatureNameByType(command.Type)}
+	if len(command.OriginalName) > 0 {
+		names = append(names, featureRegistry.GetFeatureNameByParserName(command.OriginalName))
+	}
+	for _, name := range names {
+		if len(name) == 0 {
+			continue
+		}
+		enabled, err := toggles.IsEnabledIn(discordChannel, name)
+		if err != nil {
+			log.Info("Error reading feature toggles", err)
+			continue
+		}
+		if !enabled {
+			return false
+		}
+	}
+	return true
+}
+
+// isModerator returns whether the author of the command is a moderator.
+// Commands generated by the bot itself have no author, and are trusted.
+func isModerator(config *config.Config, command *model.Command) bool {
+	if command.Author == nil {
+		return true
+	}
+	userID, err := model.ParseSnowflake(command.Author.ID)
+	if err != nil {
+		log.Info("Error parsing command user ID", err)
+		return false
+	}
+	return config.IsModerator(userID)
+}
+
 // GetHandleMessage returns the main handler for incoming messages.
 func GetHandleMessage(commandMap stringmap.StringMap, featureRegistry *feature.Registry, commandChannel chan<- *model.Command) func(api.DiscordSession, *discordgo.MessageCreate) {
 	return func(s api.DiscordSession, m *discordgo.MessageCreate) {
@@ -110,12 +177,77 @@ func GetHandleMessage(commandMap stringmap.StringMap, featureRegistry *feature.R
 			return
 		}
 		command.Author = m.Author
+		command.Content = m.Content
 		channelID, err := model.ParseSnowflake(m.ChannelID)
 		if err != nil {
 			log.Info("Error parsing channel ID", err)
 			return
 		}
 		command.ChannelID = channelID
+		// Direct messages have no guild.
+		if len(m.GuildID) > 0 {
+			guildID, err := model.ParseSnowflake(m.GuildID)
+			if err != nil {
+				log.Info("Error parsing guild ID", err)
+				return
+			}
+			command.GuildID = guildID
+		}
+
+		commandChannel <- command
+	}
+}
+
+// GetHandleReaction returns the handler for emoji reactions that users add to,
+// or remove from, messages. Only ballot reactions are handled, and the bot's
+// own reactions must be dropped before they reach the handler.
+func GetHandleReaction(commandChannel chan<- *model.Command) func(api.DiscordSession, *discordgo.MessageReaction, *discordgo.Member, bool) {
+	return func(s api.DiscordSession, r *discordgo.MessageReaction, member *discordgo.Member, added bool) {
+		// Most reactions aren't ballots, so they are dropped before any lookups.
+		if r.Emoji.Name != vote.ReactionInFavor && r.Emoji.Name != vote.ReactionAgainst {
+			return
+		}
+
+		// Discord only sends the member for reactions that are added in a guild.
+		// Otherwise, the user's ID is all that the ballot needs.
+		user := &discordgo.User{ID: r.UserID}
+		if member != nil && member.User != nil {
+			user = member.User
+		}
+		// Never respond to a bot.
+		if user.Bot {
+			return
+		}
+
+		channelID, err := model.ParseSnowflake(r.ChannelID)
+		if err != nil {
+			log.Info("Error parsing channel ID", err)
+			return
+		}
+		messageID, err := model.ParseSnowflake(r.MessageID)
+		if err != nil {
+			log.Info("Error parsing message ID", err)
+			return
+		}
+		command := &model.Command{
+			Type:      model.CommandTypeReaction,
+			Author:    user,
+			ChannelID: channelID,
+			Reaction: &model.ReactionData{
+				MessageID: messageID,
+				Emoji:     r.Emoji.Name,
+				Added:     added,
+			},
+		}
+		// Direct messages have no guild.
+		if len(r.GuildID) > 0 {
+			guildID, err := model.ParseSnowflake(r.GuildID)
+			if err != nil {
+				log.Info("Error parsing guild ID", err)
+				return
+			}
+			command.GuildID = guildID
+		}
 
 		commandChannel <- command
 	}
@@ -145,7 +277,11 @@ func parseCommand(commandMap stringmap.StringMap, registry *feature.Registry, m
 		return nil, err
 	}
 	if has {
-		return registry.FallbackParser.Parse(splitContent, m)
+		command, err := registry.FallbackParser.Parse(splitContent, m)
+		if command != nil {
+			command.OriginalName = splitContent[0]
+		}
+		return command, err
 	}
 
 	// No such command!
@@ -0,0 +1,56 @@
+package audit
+
+import (
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/config"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// Sink records audit events somewhere.
+type Sink interface {
+	// Write records the event. The session can be used to post it to Discord.
+	Write(s api.DiscordSession, event *Event) error
+}
+
+// Bus timestamps the events that features publish, and hands them to every
+// sink.
+type Bus struct {
+	utcClock model.UTCClock
+	sinks    []Sink
+}
+
+// NewBus works as advertised.
+func NewBus(utcClock model.UTCClock, sinks ...Sink) *Bus {
+	return &Bus{
+		utcClock: utcClock,
+		sinks:    sinks,
+	}
+}
+
+// NewBusFromConfig returns a bus with the sinks that the config asks for. The
+// file sink is also returned so that it can be queried, or nil if the config
+// doesn't name a file.
+func NewBusFromConfig(config *config.Config, utcClock model.UTCClock) (*Bus, *FileSink) {
+	sinks := []Sink{}
+	if config.AuditChannelID > 0 {
+		sinks = append(sinks, NewChannelSink(config.AuditChannelID))
+	}
+	var fileSink *FileSink
+	if len(config.AuditLogFile) > 0 {
+		fileSink = NewFileSink(config.AuditLogFile)
+		sinks = append(sinks, fileSink)
+	}
+	return NewBus(utcClock, sinks...), fileSink
+}
+
+// Publish records the event in every sink. Sinks that fail are logged, and
+// don't stop the others.
+func (b *Bus) Publish(s api.DiscordSession, event *Event) {
+	event.Timestamp = b.utcClock.Now()
+	for _, sink := range b.sinks {
+		if err := sink.Write(s, event); err != nil {
+			log.Info("Error writing audit event", err)
+		}
+	}
+}
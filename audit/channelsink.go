@@ -0,0 +1,24 @@
+package audit
+
+import (
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// ChannelSink posts audit events in a mod-log channel.
+type ChannelSink struct {
+	channelID model.Snowflake
+}
+
+// NewChannelSink works as advertised.
+func NewChannelSink(channelID model.Snowflake) *ChannelSink {
+	return &ChannelSink{
+		channelID: channelID,
+	}
+}
+
+// Write posts the formatted event.
+func (c *ChannelSink) Write(s api.DiscordSession, event *Event) error {
+	_, err := s.ChannelMessageSend(c.channelID.Format(), event.Format())
+	return err
+}
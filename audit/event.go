@@ -0,0 +1,74 @@
+package audit
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jakevoytko/crbot/model"
+)
+
+// Types of audit events.
+const (
+	EventMute             = "mute"
+	EventMuteExpire       = "mute-expire"
+	EventPermissionDenied = "permission-denied"
+	EventRickListAdd      = "ricklist-add"
+	EventRickListExpire   = "ricklist-expire"
+	EventRickListHit      = "ricklist-hit"
+	EventRickListRemove   = "ricklist-remove"
+	EventSpamRickList     = "spam-ricklist"
+	EventSpamWarning      = "spam-warning"
+	EventUnlearn          = "unlearn"
+	EventVoteCancel       = "vote-cancel"
+)
+
+// Event is a moderation action, or a command that moderation stopped. IDs are
+// stored as strings, since JSON readers may lose precision on large numbers.
+type Event struct {
+	Timestamp time.Time `json:"timestamp"`
+	Type      string    `json:"type"`
+	// The user who acted, or 0 for crbot itself.
+	UserID model.Snowflake `json:"user_id,string"`
+	// The user who was acted on, if any.
+	TargetID  model.Snowflake `json:"target_id,string,omitempty"`
+	ChannelID model.Snowflake `json:"channel_id,string,omitempty"`
+	// The command involved, including the leading ?.
+	Command string `json:"command,omitempty"`
+	Details string `json:"details,omitempty"`
+}
+
+// Format returns a one-line, user-visible description of the event, like
+// "[2017-01-01 01:01 UTC] unlearn by <@1> in <#2>: `?rick`".
+func (e *Event) Format() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "[%s] %s", e.Timestamp.UTC().Format("2006-01-02 15:04 MST"), e.Type)
+	if e.UserID > 0 {
+		fmt.Fprintf(&builder, " by <@%v>", e.UserID)
+	}
+	if e.TargetID > 0 {
+		fmt.Fprintf(&builder, " on <@%v>", e.TargetID)
+	}
+	if e.ChannelID > 0 {
+		fmt.Fprintf(&builder, " in <#%v>", e.ChannelID)
+	}
+	if len(e.Command) > 0 {
+		fmt.Fprintf(&builder, ": `%s`", e.Command)
+	}
+	if len(e.Details) > 0 {
+		fmt.Fprintf(&builder, " (%s)", e.Details)
+	}
+	return builder.String()
+}
+
+// Involves returns whether the user acted, or was acted on.
+func (e *Event) Involves(userID model.Snowflake) bool {
+	return e.UserID == userID || e.TargetID == userID
+}
+
+// IsAbout returns whether the event involved the command, or has the given
+// type. The leading ? is optional.
+func (e *Event) IsAbout(name string) bool {
+	name = strings.TrimPrefix(name, "?")
+	return strings.TrimPrefix(e.Command, "?") == name || e.Type == name
+}
@@ -0,0 +1,83 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/log"
+)
+
+// FileSink appends audit events to a local file, one JSON object per line.
+type FileSink struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewFileSink works as advertised.
+func NewFileSink(path string) *FileSink {
+	return &FileSink{
+		path: path,
+	}
+}
+
+// Write appends the event to the file, creating it if needed.
+func (f *FileSink) Write(s api.DiscordSession, event *Event) error {
+	serializedEvent, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(serializedEvent, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Query returns the last events in the file that match the filter, oldest
+// first. A limit of 0 returns all of them. A missing file has no events, and
+// lines that aren't events, like one that was cut off by a crash, are skipped.
+func (f *FileSink) Query(filter func(*Event) bool, limit int) ([]*Event, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	file, err := os.Open(f.path)
+	if os.IsNotExist(err) {
+		return []*Event{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	events := []*Event{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			log.Info("Skipping unreadable audit log line", err)
+			continue
+		}
+		if filter(&event) {
+			events = append(events, &event)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(events) > limit {
+		events = events[len(events)-limit:]
+	}
+	return events, nil
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"encoding/json"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/jakevoytko/crbot/model"
 )
@@ -15,13 +17,117 @@ import (
 type Config struct {
 	BotToken      string            `json:"bot_token"`
 	RickList      []model.Snowflake `json:"ricklist"`
+	Moderators    []model.Snowflake `json:"moderators"`
 	RedisHost     string            `json:"redis_host"`
 	RedisPort     int               `json:"redis_port"`
 	RedisUsername string            `json:"redis_username"`
 	RedisPassword string            `json:"redis_password"`
 	RedisDatabase int               `json:"redis_database"`
+	// KarmaDecayHalfLifeDays is how many days it takes for karma to lose half
+	// of its weight in decayed scores. 0 disables decayed scores.
+	KarmaDecayHalfLifeDays int `json:"karma_decay_half_life_days"`
+	// DefaultGuildID is the guild that receives karma that was stored before
+	// karma was scoped by guild. Karma is only moved on the first startup with
+	// guild-scoped karma, and 0 leaves it where it is for good.
+	DefaultGuildID model.Snowflake `json:"default_guild_id"`
+	// Vote defaults and limits. Durations are in minutes, and thresholds are
+	// fractions like "2/3". 0 or "" uses the built-in value.
+	VoteDefaultDurationMinutes int    `json:"vote_default_duration_minutes"`
+	VoteMinDurationMinutes     int    `json:"vote_min_duration_minutes"`
+	VoteMaxDurationMinutes     int    `json:"vote_max_duration_minutes"`
+	VoteDefaultQuorum          int    `json:"vote_default_quorum"`
+	VoteMaxQuorum              int    `json:"vote_max_quorum"`
+	VoteDefaultThreshold       string `json:"vote_default_threshold"`
+	VoteMaxOpen                int    `json:"vote_max_open"`
+	// VoteActionMinQuorum is the smallest quorum that a vote on an action can
+	// have, since passing votes are carried out. 0 uses the default quorum.
+	VoteActionMinQuorum int `json:"vote_action_min_quorum"`
+	// VoteMaxScheduleHours is how far ahead a vote can be scheduled to open.
+	VoteMaxScheduleHours int `json:"vote_max_schedule_hours"`
+	// VoteReminderMinutes are how many minutes before a vote closes to remind
+	// the channel about it, like [10, 1]. Empty disables reminders.
+	VoteReminderMinutes []int `json:"vote_reminder_minutes"`
+	// VoteRoleWeights are the ballot weights of Discord roles in votes weighted
+	// by role. Voters count as their heaviest role, or 1.
+	VoteRoleWeights []VoteRoleWeight `json:"vote_role_weights"`
+	// VoteBallotSecret keys the hashes that identify voters in secret votes. It
+	// must never be stored with the votes. Secret votes can't be started without
+	// it.
+	VoteBallotSecret string `json:"vote_ballot_secret"`
+	// VoteKarmaTiers are the ballot weights of karma tiers in votes weighted by
+	// karma. Voters count as the heaviest tier they reached, or 1.
+	VoteKarmaTiers []VoteKarmaTier `json:"vote_karma_tiers"`
+	// CommandPolicies restrict who can run commands, and where. Commands
+	// without a policy can be run by everyone, everywhere.
+	CommandPolicies []CommandPolicy `json:"command_policies"`
+	// AuditChannelID is the mod-log channel that moderation actions are posted
+	// in. 0 doesn't post them.
+	AuditChannelID model.Snowflake `json:"audit_channel_id"`
+	// AuditLogFile is the file that moderation actions are appended to, as JSON
+	// lines. It can be searched with ?audit. "" doesn't record them.
+	AuditLogFile string `json:"audit_log_file"`
+	// Spam protection. A command is spam when one user sends more than
+	// SpamUserLimit near-identical copies of it, or a channel receives more
+	// than SpamChannelLimit, within SpamWindowSeconds. Spam is ignored. Users
+	// who go past SpamUserLimit SpamWarnStrikes times within
+	// SpamStrikeWindowMinutes are warned, and SpamRickListStrikes times are
+	// ricklisted and muted for SpamRickListMinutes. A 0 limit disables its
+	// check, and other 0s use the built-in values.
+	SpamUserLimit           int `json:"spam_user_limit"`
+	SpamChannelLimit        int `json:"spam_channel_limit"`
+	SpamWindowSeconds       int `json:"spam_window_seconds"`
+	SpamWarnStrikes         int `json:"spam_warn_strikes"`
+	SpamRickListStrikes     int `json:"spam_ricklist_strikes"`
+	SpamStrikeWindowMinutes int `json:"spam_strike_window_minutes"`
+	SpamRickListMinutes     int `json:"spam_ricklist_minutes"`
 }
 
+// CommandPolicy allows or denies a command by user, role, and channel. A
+// caller matching any deny list is refused. Each allow list that is set must
+// be matched by the caller, so allowing a role in a channel only lets members
+// of the role use the command in that channel.
+type CommandPolicy struct {
+	// Command is the name of the command, like "?unlearn".
+	Command       string            `json:"command"`
+	AllowUsers    []model.Snowflake `json:"allow_users"`
+	AllowRoles    []model.Snowflake `json:"allow_roles"`
+	AllowChannels []model.Snowflake `json:"allow_channels"`
+	DenyUsers     []model.Snowflake `json:"deny_users"`
+	DenyRoles     []model.Snowflake `json:"deny_roles"`
+	DenyChannels  []model.Snowflake `json:"deny_channels"`
+}
+
+// VoteRoleWeight is the ballot weight of the members of a role.
+type VoteRoleWeight struct {
+	RoleID model.Snowflake `json:"role_id"`
+	Weight int             `json:"weight"`
+}
+
+// VoteKarmaTier is the ballot weight of voters with at least MinKarma karma.
+type VoteKarmaTier struct {
+	MinKarma int `json:"min_karma"`
+	Weight   int `json:"weight"`
+}
+
+// Built-in vote defaults and limits.
+const (
+	DefaultVoteDurationMinutes    = 30
+	DefaultVoteMinDurationMinutes = 1
+	DefaultVoteMaxDurationMinutes = 24 * 60
+	DefaultVoteMaxQuorum          = 100
+	DefaultVoteMaxOpen            = 5
+	DefaultVoteMaxScheduleHours   = 7 * 24
+)
+
+// Built-in spam protection values.
+const (
+	DefaultSpamWindowSeconds       = 10
+	DefaultSpamWarnStrikes         = 2
+	DefaultSpamRickListStrikes     = 5
+	DefaultSpamStrikeWindowMinutes = 10
+	DefaultSpamRickListMinutes     = 10
+)
+
 // NewConfig builds a new config and sets default values for config params that have them.
 func NewConfig() Config {
 	return Config{
@@ -32,6 +138,196 @@ func NewConfig() Config {
 	}
 }
 
+// IsModerator returns whether the given user is a moderator.
+func (c *Config) IsModerator(userID model.Snowflake) bool {
+	for _, moderator := range c.Moderators {
+		if moderator == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// KarmaDecayHalfLife returns the configured karma half-life, or 0 if decay is
+// disabled.
+func (c *Config) KarmaDecayHalfLife() time.Duration {
+	return time.Duration(c.KarmaDecayHalfLifeDays*24) * time.Hour
+}
+
+// VoteDefaultDuration returns how long votes last when no duration is given.
+func (c *Config) VoteDefaultDuration() time.Duration {
+	return minutesOrDefault(c.VoteDefaultDurationMinutes, DefaultVoteDurationMinutes)
+}
+
+// VoteMinDuration returns the shortest allowed vote.
+func (c *Config) VoteMinDuration() time.Duration {
+	return minutesOrDefault(c.VoteMinDurationMinutes, DefaultVoteMinDurationMinutes)
+}
+
+// VoteMaxDuration returns the longest allowed vote.
+func (c *Config) VoteMaxDuration() time.Duration {
+	return minutesOrDefault(c.VoteMaxDurationMinutes, DefaultVoteMaxDurationMinutes)
+}
+
+// VoteQuorum returns the number of ballots needed when no quorum is given.
+func (c *Config) VoteQuorum() int {
+	if c.VoteDefaultQuorum <= 0 {
+		return model.DefaultVoteQuorum
+	}
+	return c.VoteDefaultQuorum
+}
+
+// VoteQuorumLimit returns the largest allowed quorum.
+func (c *Config) VoteQuorumLimit() int {
+	if c.VoteMaxQuorum <= 0 {
+		return DefaultVoteMaxQuorum
+	}
+	return c.VoteMaxQuorum
+}
+
+// VoteActionQuorum returns the smallest quorum that a vote on an action can
+// have.
+func (c *Config) VoteActionQuorum() int {
+	if c.VoteActionMinQuorum <= 0 {
+		return c.VoteQuorum()
+	}
+	return c.VoteActionMinQuorum
+}
+
+// VoteOpenLimit returns how many votes and polls may be active in a channel at
+// once.
+func (c *Config) VoteOpenLimit() int {
+	if c.VoteMaxOpen <= 0 {
+		return DefaultVoteMaxOpen
+	}
+	return c.VoteMaxOpen
+}
+
+// VoteScheduleLimit returns how far ahead a vote can be scheduled to open.
+func (c *Config) VoteScheduleLimit() time.Duration {
+	hours := c.VoteMaxScheduleHours
+	if hours <= 0 {
+		hours = DefaultVoteMaxScheduleHours
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// VoteThreshold returns the threshold used when no threshold is given. Returns
+// an error if the configured threshold is malformed.
+func (c *Config) VoteThreshold() (model.VoteThreshold, error) {
+	if len(c.VoteDefaultThreshold) == 0 {
+		return model.VoteThreshold{}, nil
+	}
+	return model.ParseVoteThreshold(c.VoteDefaultThreshold)
+}
+
+// VoteReminders returns how long before a vote closes to remind the channel
+// about it. Non-positive offsets are ignored.
+func (c *Config) VoteReminders() []time.Duration {
+	reminders := []time.Duration{}
+	for _, minutes := range c.VoteReminderMinutes {
+		if minutes > 0 {
+			reminders = append(reminders, time.Duration(minutes)*time.Minute)
+		}
+	}
+	return reminders
+}
+
+// SpamProtected returns whether any spam check is enabled.
+func (c *Config) SpamProtected() bool {
+	return c.SpamUserLimit > 0 || c.SpamChannelLimit > 0
+}
+
+// SpamWindow returns how far back near-identical commands are counted.
+func (c *Config) SpamWindow() time.Duration {
+	seconds := c.SpamWindowSeconds
+	if seconds <= 0 {
+		seconds = DefaultSpamWindowSeconds
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// SpamWarnLimit returns how many ignored commands get a user warned.
+func (c *Config) SpamWarnLimit() int {
+	if c.SpamWarnStrikes <= 0 {
+		return DefaultSpamWarnStrikes
+	}
+	return c.SpamWarnStrikes
+}
+
+// SpamRickListLimit returns how many ignored commands get a user ricklisted.
+func (c *Config) SpamRickListLimit() int {
+	if c.SpamRickListStrikes <= 0 {
+		return DefaultSpamRickListStrikes
+	}
+	return c.SpamRickListStrikes
+}
+
+// SpamStrikeWindow returns how far back ignored commands are counted.
+func (c *Config) SpamStrikeWindow() time.Duration {
+	return minutesOrDefault(c.SpamStrikeWindowMinutes, DefaultSpamStrikeWindowMinutes)
+}
+
+// SpamRickListDuration returns how long spammers stay ricklisted and muted.
+func (c *Config) SpamRickListDuration() time.Duration {
+	return minutesOrDefault(c.SpamRickListMinutes, DefaultSpamRickListMinutes)
+}
+
+// RoleBallotWeight returns the weight of a ballot cast by a member of the given
+// roles.
+func (c *Config) RoleBallotWeight(roleIDs []model.Snowflake) int {
+	weight := 1
+	for _, roleWeight := range c.VoteRoleWeights {
+		for _, roleID := range roleIDs {
+			if roleWeight.RoleID == roleID && roleWeight.Weight > weight {
+				weight = roleWeight.Weight
+			}
+		}
+	}
+	return weight
+}
+
+// KarmaBallotWeight returns the weight of a ballot cast by a voter with the
+// given karma.
+func (c *Config) KarmaBallotWeight(karma int) int {
+	weight := 1
+	for _, tier := range c.VoteKarmaTiers {
+		if karma >= tier.MinKarma && tier.Weight > weight {
+			weight = tier.Weight
+		}
+	}
+	return weight
+}
+
+// CommandPolicy returns the policy of the named command, or nil if everyone
+// can run it everywhere. The leading ? is optional in both the name and the
+// config. Aliases like ?f1 share the policy of the command they alias.
+func (c *Config) CommandPolicy(name string) *CommandPolicy {
+	name = canonicalPolicyName(name)
+	if name == "?" {
+		return nil
+	}
+	for i := range c.CommandPolicies {
+		if canonicalPolicyName(c.CommandPolicies[i].Command) == name {
+			return &c.CommandPolicies[i]
+		}
+	}
+	return nil
+}
+
+// canonicalPolicyName returns the canonical name of the command, with the
+// leading ?.
+func canonicalPolicyName(name string) string {
+	return model.CanonicalCommandName("?" + strings.TrimPrefix(name, "?"))
+}
+
+func minutesOrDefault(minutes, defaultMinutes int) time.Duration {
+	if minutes <= 0 {
+		minutes = defaultMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
 // ParseConfig reads the config from the given filename.
 func ParseConfig(filename string) (*Config, error) {
 	f, e := os.ReadFile(filename)
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"strconv"
@@ -11,6 +12,8 @@ import (
 	"github.com/jakevoytko/crbot/api"
 	"github.com/jakevoytko/crbot/app"
 	"github.com/jakevoytko/crbot/config"
+	"github.com/jakevoytko/crbot/feature/toggle"
+	"github.com/jakevoytko/crbot/feature/vote"
 	"github.com/jakevoytko/crbot/log"
 	"github.com/jakevoytko/crbot/model"
 	stringmap "github.com/jakevoytko/go-stringmap"
@@ -49,7 +52,23 @@ func main() {
 
 	commandMap := stringmap.NewRedisStringMap(ctx, redisClient, RedisCommandHash)
 	karmaMap := stringmap.NewRedisStringMap(ctx, redisClient, RedisKarmaHash)
+	karmaHistoryMap := stringmap.NewRedisStringMap(ctx, redisClient, RedisKarmaHistoryHash)
+	karmaMergeMap := stringmap.NewRedisStringMap(ctx, redisClient, RedisKarmaMergeHash)
 	voteMap := stringmap.NewRedisStringMap(ctx, redisClient, RedisVoteHash)
+	moderationMap := stringmap.NewRedisStringMap(ctx, redisClient, RedisModerationHash)
+	toggleMap := stringmap.NewRedisStringMap(ctx, redisClient, RedisToggleHash)
+
+	// Subcommands work offline, and exit without connecting to Discord.
+	switch flag.Arg(0) {
+	case "":
+	case SubcommandExportVotes:
+		if err := exportVotes(voteMap, flag.Args()[1:]); err != nil {
+			log.Fatal("Exporting votes failed", err)
+		}
+		return
+	default:
+		log.Fatal("Unknown subcommand", errors.New(flag.Arg(0)))
+	}
 
 	gist := api.NewRemoteHastebin()
 
@@ -67,7 +86,7 @@ func main() {
 	commandChannel := make(chan *model.Command, 10)
 
 	featureRegistry := app.InitializeRegistry(
-		commandMap, karmaMap, voteMap, gist, config, clock, timer, commandChannel)
+		commandMap, karmaMap, karmaHistoryMap, karmaMergeMap, voteMap, moderationMap, toggleMap, gist, config, clock, timer, commandChannel)
 
 	// Run any initial load handlers up front.
 	for _, fn := range featureRegistry.GetInitialLoadFns() {
@@ -77,7 +96,7 @@ func main() {
 		}
 	}
 
-	go app.HandleCommands(featureRegistry, discord, commandChannel)
+	go app.HandleCommands(featureRegistry, config, toggle.NewModelHelper(toggleMap), discord, commandChannel)
 
 	// Open communications with Discord.
 	handler := app.GetHandleMessage(commandMap, featureRegistry, commandChannel)
@@ -87,6 +106,22 @@ func main() {
 		handler(s, c)
 	}
 	discord.AddHandler(wrappedHandler)
+
+	// Reactions are handled the same way, whether they are added or removed. The
+	// bot's own reactions are recognized from the state cache.
+	reactionHandler := app.GetHandleReaction(commandChannel)
+	discord.AddHandler(func(s *discordgo.Session, r *discordgo.MessageReactionAdd) {
+		if s.State.User != nil && r.UserID == s.State.User.ID {
+			return
+		}
+		reactionHandler(s, r.MessageReaction, r.Member, true /* added */)
+	})
+	discord.AddHandler(func(s *discordgo.Session, r *discordgo.MessageReactionRemove) {
+		if s.State.User != nil && r.UserID == s.State.User.ID {
+			return
+		}
+		reactionHandler(s, r.MessageReaction, nil /* member */, false /* added */)
+	})
 	if err := discord.Open(); err != nil {
 		log.Fatal("Error opening Discord session", err)
 	}
@@ -96,13 +131,77 @@ func main() {
 	<-make(chan interface{})
 }
 
+// exportVotes writes votes from the vote hash to stdout, for offline reporting.
+func exportVotes(voteMap stringmap.StringMap, args []string) error {
+	flags := flag.NewFlagSet(SubcommandExportVotes, flag.ExitOnError)
+	channel := flags.String("channel", "", "ID of the channel to export. Exports every channel if empty")
+	voteID := flags.Int("vote", 0, "ID of the vote to export. Requires -channel. Exports every vote if 0")
+	format := flags.String("format", vote.ExportFormatCSV, "Format of the export: csv or json")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	modelHelper := vote.NewModelHelper(voteMap, model.NewSystemUTCClock(), "" /* ballotSecret */)
+	var votes []*model.Vote
+	switch {
+	case len(*channel) == 0 && *voteID > 0:
+		return errors.New("-vote requires -channel")
+	case len(*channel) == 0:
+		allVotes, err := modelHelper.AllVotes()
+		if err != nil {
+			return err
+		}
+		votes = allVotes
+	default:
+		channelID, err := model.ParseSnowflake(*channel)
+		if err != nil {
+			return err
+		}
+		if *voteID > 0 {
+			singleVote, err := modelHelper.Vote(channelID, *voteID)
+			if err != nil {
+				return err
+			}
+			if singleVote == nil {
+				return fmt.Errorf("no vote #%d in channel %s", *voteID, *channel)
+			}
+			votes = []*model.Vote{singleVote}
+		} else {
+			channelVotes, err := modelHelper.ChannelVotes(channelID)
+			if err != nil {
+				return err
+			}
+			votes = channelVotes
+		}
+	}
+
+	// Usernames need Discord, so offline exports only have user IDs.
+	exported := []*vote.ExportedVote{}
+	for _, v := range votes {
+		exported = append(exported, vote.NewExportedVote(v, "" /* initiator */))
+	}
+	export, err := vote.ExportVotes(exported, *format)
+	if err != nil {
+		return err
+	}
+	fmt.Println(export)
+	return nil
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 // Constants
 ///////////////////////////////////////////////////////////////////////////////
 
 // NOTE: These cannot change without a migration, since they are mapped to storage.
 const (
-	RedisCommandHash = "crbot-custom-commands"
-	RedisKarmaHash   = "crbot-feature-karma"
-	RedisVoteHash    = "crbot-feature-vote"
+	RedisCommandHash      = "crbot-custom-commands"
+	RedisKarmaHash        = "crbot-feature-karma"
+	RedisKarmaHistoryHash = "crbot-feature-karma-history"
+	RedisKarmaMergeHash   = "crbot-feature-karma-merges"
+	RedisVoteHash         = "crbot-feature-vote"
+	RedisModerationHash   = "crbot-feature-moderation"
+	RedisToggleHash       = "crbot-feature-toggles"
 )
+
+// SubcommandExportVotes exports votes to stdout instead of running the bot.
+const SubcommandExportVotes = "export-votes"
@@ -0,0 +1,21 @@
+package feature
+
+import (
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// Action is an operation that a vote can carry out automatically when it
+// passes. Features only provide actions that are safe to perform without a
+// moderator present.
+type Action interface {
+	// The name used to call a vote on the action, like `unlearn`.
+	GetName() string
+	// Describe validates the arguments and returns a user-visible description
+	// of what the action will do. Returns a user-visible error if the action
+	// can't be performed with these arguments.
+	Describe(args string) (string, error)
+	// Execute performs the action in the given channel, and returns a
+	// user-visible description of the result.
+	Execute(s api.DiscordSession, channelID model.Snowflake, args string) (string, error)
+}
@@ -13,4 +13,6 @@ type Executor interface {
 	Execute(api.DiscordSession, model.Snowflake, *model.Command)
 	// Whether the command cannot be executed in private channels.
 	PublicOnly() bool
+	// Whether the command can only be executed by moderators.
+	ModeratorOnly() bool
 }
@@ -27,6 +27,11 @@ func (e *Executor) PublicOnly() bool {
 	return false
 }
 
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *Executor) ModeratorOnly() bool {
+	return false
+}
+
 // Execute returns a random factsphere fact
 func (e *Executor) Execute(s api.DiscordSession, channelID model.Snowflake, command *model.Command) {
 	_, err := s.ChannelMessageSend(channelID.Format(), factSphereFacts[rand.Intn(len(factSphereFacts))])
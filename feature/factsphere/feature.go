@@ -3,6 +3,7 @@ package factsphere
 import (
 	"github.com/jakevoytko/crbot/api"
 	"github.com/jakevoytko/crbot/feature"
+	"github.com/jakevoytko/crbot/model"
 )
 
 // Feature is a Feature that prints a random fact to the user.
@@ -17,6 +18,11 @@ func NewFeature(featureRegistry *feature.Registry) *Feature {
 	}
 }
 
+// GetName returns the name that channels enable and disable the feature by.
+func (f *Feature) GetName() string {
+	return model.FeatureNameFactSphere
+}
+
 // Parsers returns the parsers.
 func (f *Feature) Parsers() []feature.Parser {
 	return []feature.Parser{NewFactSphereParser()}
@@ -37,5 +43,10 @@ func (f *Feature) Executors() []feature.Executor {
 	return []feature.Executor{NewFactSphereExecutor()}
 }
 
+// Actions returns nothing.
+func (f *Feature) Actions() []feature.Action {
+	return []feature.Action{}
+}
+
 // OnInitialLoad does nothing.
 func (f *Feature) OnInitialLoad(s api.DiscordSession) error { return nil }
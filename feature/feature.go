@@ -5,6 +5,9 @@ import "github.com/jakevoytko/crbot/api"
 // Feature encapsulates all of the behavior necessary for a built-in
 // feature.
 type Feature interface {
+	// GetName returns the name of the feature, which channels enable and
+	// disable it by.
+	GetName() string
 	// Returns all parsers associated with this feature.
 	Parsers() []Parser
 	// FallbackParser returns the parser to execute if no other parser is
@@ -14,6 +17,9 @@ type Feature interface {
 	CommandInterceptors() []CommandInterceptor
 	// Returns all executors associated with this feature.
 	Executors() []Executor
+	// Actions returns the operations that votes may carry out on this
+	// feature's behalf.
+	Actions() []Action
 	// A callback that allows the feature to perform work before the normal
 	// command flow begins.
 	OnInitialLoad(s api.DiscordSession) error
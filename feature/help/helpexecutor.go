@@ -36,6 +36,11 @@ func (e *Executor) PublicOnly() bool {
 	return false
 }
 
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *Executor) ModeratorOnly() bool {
+	return false
+}
+
 // Execute replies over the given channel with a help message.
 func (e *Executor) Execute(s api.DiscordSession, channel model.Snowflake, command *model.Command) {
 	if command.Help == nil {
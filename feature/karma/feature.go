@@ -1,7 +1,10 @@
 package karma
 
 import (
+	"log"
+
 	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/config"
 	"github.com/jakevoytko/crbot/feature"
 	"github.com/jakevoytko/crbot/model"
 	stringmap "github.com/jakevoytko/go-stringmap"
@@ -11,21 +14,32 @@ import (
 type Feature struct {
 	featureRegistry *feature.Registry
 	modelHelper     *ModelHelper
+	config          *config.Config
 }
 
 // NewFeature returns a new Feature.
-func NewFeature(featureRegistry *feature.Registry, karmaMap stringmap.StringMap) *Feature {
+func NewFeature(featureRegistry *feature.Registry, karmaMap, karmaHistoryMap, karmaMergeMap stringmap.StringMap, clock model.UTCClock, config *config.Config) *Feature {
 	return &Feature{
 		featureRegistry: featureRegistry,
-		modelHelper:     NewModelHelper(karmaMap),
+		modelHelper:     NewModelHelper(karmaMap, karmaHistoryMap, karmaMergeMap, clock),
+		config:          config,
 	}
 }
 
+// GetName returns the name that channels enable and disable the feature by.
+func (f *Feature) GetName() string {
+	return model.FeatureNameKarma
+}
+
 // Parsers gets the learn feature parsers.
 func (f *Feature) Parsers() []feature.Parser {
 	return []feature.Parser{
 		NewParser(model.CommandNameKarmaIncrement, true /* increment */),
 		NewParser(model.CommandNameKarmaDecrement, false /* increment */),
+		NewInfoParser(),
+		NewMergeParser(model.CommandNameKarmaMerge, false /* alias */),
+		NewMergeParser(model.CommandNameKarmaAlias, true /* alias */),
+		NewUnmergeParser(),
 	}
 }
 
@@ -41,8 +55,26 @@ func (f *Feature) FallbackParser() feature.Parser {
 
 // Executors gets the executors.
 func (f *Feature) Executors() []feature.Executor {
-	return []feature.Executor{NewExecutor(f.modelHelper)}
+	return []feature.Executor{
+		NewExecutor(f.modelHelper),
+		NewInfoExecutor(f.modelHelper, f.config.KarmaDecayHalfLife()),
+		NewMergeExecutor(f.modelHelper, false /* alias */),
+		NewMergeExecutor(f.modelHelper, true /* alias */),
+		NewUnmergeExecutor(f.modelHelper),
+	}
 }
 
-// OnInitialLoad does nothing.
-func (f *Feature) OnInitialLoad(s api.DiscordSession) error { return nil }
+// Actions returns nothing.
+func (f *Feature) Actions() []feature.Action {
+	return []feature.Action{}
+}
+
+// OnInitialLoad moves karma that was stored before karma was scoped by guild
+// into the configured default guild, the first time that crbot starts.
+func (f *Feature) OnInitialLoad(s api.DiscordSession) error {
+	migrated, err := f.modelHelper.MigrateToGuild(f.config.DefaultGuildID)
+	if migrated > 0 {
+		log.Printf("Migrated %v karma keys to guild %v", migrated, f.config.DefaultGuildID)
+	}
+	return err
+}
@@ -0,0 +1,119 @@
+package karma
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/jakevoytko/crbot/model"
+	stringmap "github.com/jakevoytko/go-stringmap"
+)
+
+// KeyGuildScope prefixes every karma key with the guild that it belongs to.
+// Direct messages have no guild, and are scoped to guild 0.
+const KeyGuildScope = "%v:"
+
+// GuildMap is a StringMap that only sees the keys of a single guild. It
+// stores every key in the underlying map with the guild's prefix.
+type GuildMap struct {
+	stringMap stringmap.StringMap
+	prefix    string
+}
+
+// NewGuildMap works as advertised.
+func NewGuildMap(stringMap stringmap.StringMap, guildID model.Snowflake) *GuildMap {
+	return &GuildMap{
+		stringMap: stringMap,
+		prefix:    fmt.Sprintf(KeyGuildScope, guildID),
+	}
+}
+
+// Has returns whether or not key is present in the guild.
+func (m *GuildMap) Has(key string) (bool, error) {
+	return m.stringMap.Has(m.prefix + key)
+}
+
+// Get returns the given key in the guild.
+func (m *GuildMap) Get(key string) (string, error) {
+	return m.stringMap.Get(m.prefix + key)
+}
+
+// Set sets the given key in the guild.
+func (m *GuildMap) Set(key, value string) error {
+	return m.stringMap.Set(m.prefix+key, value)
+}
+
+// Delete deletes the given key from the guild.
+func (m *GuildMap) Delete(key string) error {
+	return m.stringMap.Delete(m.prefix + key)
+}
+
+// GetAll returns every entry in the guild, without the guild prefix.
+func (m *GuildMap) GetAll() (map[string]string, error) {
+	all, err := m.stringMap.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	guildEntries := map[string]string{}
+	for key, value := range all {
+		if strings.HasPrefix(key, m.prefix) {
+			guildEntries[strings.TrimPrefix(key, m.prefix)] = value
+		}
+	}
+	return guildEntries, nil
+}
+
+// ScanKeys finds all keys in the guild that match the given pattern, without
+// the guild prefix.
+func (m *GuildMap) ScanKeys(pattern string) ([]string, error) {
+	keys, err := m.stringMap.ScanKeys(m.prefix + pattern)
+	if err != nil {
+		return nil, err
+	}
+	guildKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		// Some stringmaps match the pattern anywhere in the key, so guild 1234
+		// would otherwise see the keys of guild 51234.
+		if strings.HasPrefix(key, m.prefix) {
+			guildKeys = append(guildKeys, strings.TrimPrefix(key, m.prefix))
+		}
+	}
+	return guildKeys, nil
+}
+
+// MigrateToGuild moves every key that isn't in the given guild into it, and
+// returns the number of keys that were moved. It must only run while every
+// other key is from before karma was scoped by guild, since other guilds' keys
+// look the same as legacy keys. A legacy key is never moved over a key that
+// the guild already has.
+func MigrateToGuild(stringMap stringmap.StringMap, guildID model.Snowflake) (int, error) {
+	all, err := stringMap.GetAll()
+	if err != nil {
+		return 0, err
+	}
+	guildMap := NewGuildMap(stringMap, guildID)
+	migrated := 0
+	for key, value := range all {
+		if strings.HasPrefix(key, guildMap.prefix) {
+			continue
+		}
+		has, err := guildMap.Has(key)
+		if err != nil {
+			return migrated, err
+		}
+		if has {
+			log.Printf("Not migrating karma key %v, since guild %v already has it", key, guildID)
+			continue
+		}
+		// Write the scoped key first, so that an interrupted migration never
+		// loses karma.
+		if err := guildMap.Set(key, value); err != nil {
+			return migrated, err
+		}
+		if err := stringMap.Delete(key); err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+	return migrated, nil
+}
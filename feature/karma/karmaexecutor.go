@@ -29,11 +29,22 @@ func (e *Executor) PublicOnly() bool {
 	return true
 }
 
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *Executor) ModeratorOnly() bool {
+	return false
+}
+
 const (
 	// MsgIncrementKarma prints the results of ?++ thing
 	MsgIncrementKarma = "%v has been upvoted. %v now has %d karma."
 	// MsgDecrementKarma prints the results of ?-- thing
 	MsgDecrementKarma = "%v has been downvoted. %v now has %d karma."
+	// MsgIncrementKarmaReason prints the results of ?++ thing for reason
+	MsgIncrementKarmaReason = "%v has been upvoted for %v. %v now has %d karma."
+	// MsgDecrementKarmaReason prints the results of ?-- thing for reason
+	MsgDecrementKarmaReason = "%v has been downvoted for %v. %v now has %d karma."
+	// MsgKarmaFailed prints that karma couldn't be updated
+	MsgKarmaFailed = "Couldn't update karma. Try again later."
 )
 
 // Execute attempts to add karma to the total already in memory, or creates a
@@ -43,24 +54,45 @@ func (e *Executor) Execute(s api.DiscordSession, channelID model.Snowflake, comm
 		log.Fatal("Incorrectly generated karma command", errors.New("wat"))
 	}
 
+	modelHelper := e.modelHelper.ForGuild(command.GuildID)
+
+	// Karma for an alias goes to its canonical target.
+	target, err := modelHelper.Resolve(command.Karma.Target)
+	if err != nil {
+		log.Info("Error reading karma aliases", err)
+		s.ChannelMessageSend(channelID.Format(), MsgKarmaFailed)
+		return
+	}
+
 	var newKarma int
-	var err error
 	if command.Karma.Increment {
-		newKarma, err = e.modelHelper.Increment(command.Karma.Target)
+		newKarma, err = modelHelper.Increment(target, command.Karma.Reason)
 	} else {
-		newKarma, err = e.modelHelper.Decrement(command.Karma.Target)
+		newKarma, err = modelHelper.Decrement(target, command.Karma.Reason)
 	}
 
+	// A corrupt karma value only breaks its own target.
 	if err != nil {
-		log.Fatal("Error writing karma storage", err)
+		log.Info("Error updating karma", err)
+		return
 	}
 
 	// Send ack.
-	karmaAckMessage := MsgDecrementKarma
-	if command.Karma.Increment {
-		karmaAckMessage = MsgIncrementKarma
+	var karmaAckMessage string
+	if len(command.Karma.Reason) > 0 {
+		karmaAckMessage = MsgDecrementKarmaReason
+		if command.Karma.Increment {
+			karmaAckMessage = MsgIncrementKarmaReason
+		}
+		karmaAckMessage = fmt.Sprintf(karmaAckMessage, target, command.Karma.Reason, target, newKarma)
+	} else {
+		karmaAckMessage = MsgDecrementKarma
+		if command.Karma.Increment {
+			karmaAckMessage = MsgIncrementKarma
+		}
+		karmaAckMessage = fmt.Sprintf(karmaAckMessage, target, target, newKarma)
 	}
-	_, err = s.ChannelMessageSend(channelID.Format(), fmt.Sprintf(karmaAckMessage, command.Karma.Target, command.Karma.Target, newKarma))
+	_, err = s.ChannelMessageSend(channelID.Format(), karmaAckMessage)
 	if err != nil {
 		log.Info("Error sending karma message", err)
 	}
@@ -0,0 +1,101 @@
+package karma
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// InfoExecutor prints a target's karma and the most common reasons for it.
+type InfoExecutor struct {
+	modelHelper   *ModelHelper
+	decayHalfLife time.Duration
+}
+
+// NewInfoExecutor works as advertised. A decayHalfLife of 0 disables decayed
+// scores.
+func NewInfoExecutor(modelHelper *ModelHelper, decayHalfLife time.Duration) *InfoExecutor {
+	return &InfoExecutor{
+		modelHelper:   modelHelper,
+		decayHalfLife: decayHalfLife,
+	}
+}
+
+// GetType returns the type of this feature.
+func (e *InfoExecutor) GetType() int {
+	return model.CommandTypeKarmaInfo
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private channel.
+func (e *InfoExecutor) PublicOnly() bool {
+	return false
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *InfoExecutor) ModeratorOnly() bool {
+	return false
+}
+
+const (
+	// MsgKarmaInfo prints the karma of a target
+	MsgKarmaInfo = "%v has %d karma."
+	// MsgKarmaInfoDecayed prints the raw and decayed karma of a target
+	MsgKarmaInfoDecayed = "%v has %d karma (%.1f after decay)."
+	// MsgKarmaReasons is the header for the most common reasons
+	MsgKarmaReasons = "Most common reasons: "
+	// MsgKarmaReason prints a single reason and how often it was given
+	MsgKarmaReason = "%v (%d)"
+	// NumTopReasons is the number of reasons shown by ?karma
+	NumTopReasons = 3
+)
+
+// Execute prints the karma of the target, along with its most common reasons.
+func (e *InfoExecutor) Execute(s api.DiscordSession, channelID model.Snowflake, command *model.Command) {
+	if command.KarmaInfo == nil {
+		log.Fatal("Incorrectly generated karma info command", errors.New("wat"))
+	}
+
+	modelHelper := e.modelHelper.ForGuild(command.GuildID)
+
+	target, err := modelHelper.Resolve(command.KarmaInfo.Target)
+	if err != nil {
+		log.Info("Error reading karma aliases", err)
+		return
+	}
+	karma, err := modelHelper.Karma(target)
+	if err != nil {
+		log.Info("Error reading karma", err)
+		return
+	}
+	reasons, err := modelHelper.TopReasons(target, NumTopReasons)
+	if err != nil {
+		log.Info("Error reading karma history", err)
+		return
+	}
+
+	message := fmt.Sprintf(MsgKarmaInfo, target, karma)
+	if e.decayHalfLife > 0 {
+		decayed, err := modelHelper.DecayedKarma(target, e.decayHalfLife)
+		if err != nil {
+			log.Info("Error reading karma history", err)
+			return
+		}
+		message = fmt.Sprintf(MsgKarmaInfoDecayed, target, karma, decayed)
+	}
+	if len(reasons) > 0 {
+		formattedReasons := make([]string, 0, len(reasons))
+		for _, reason := range reasons {
+			formattedReasons = append(formattedReasons, fmt.Sprintf(MsgKarmaReason, reason.Reason, reason.Count))
+		}
+		message = message + "\n" + MsgKarmaReasons + strings.Join(formattedReasons, ", ")
+	}
+
+	if _, err := s.ChannelMessageSend(channelID.Format(), message); err != nil {
+		log.Info("Error sending karma info message", err)
+	}
+}
@@ -0,0 +1,64 @@
+package karma
+
+import (
+	"errors"
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jakevoytko/crbot/model"
+	"github.com/jakevoytko/crbot/util"
+)
+
+// InfoParser parses ?karma commands
+type InfoParser struct{}
+
+// NewInfoParser works as advertised.
+func NewInfoParser() *InfoParser {
+	return &InfoParser{}
+}
+
+// GetName returns the named type.
+func (p *InfoParser) GetName() string {
+	return model.CommandNameKarmaInfo
+}
+
+const (
+	// MsgHelpKarmaInfo is help text for ?karma
+	MsgHelpKarmaInfo = "Type ?karma <target> to see a target's karma score and the most common reasons it was given"
+)
+
+// HelpText returns the help text.
+func (p *InfoParser) HelpText(command string) (string, error) {
+	return MsgHelpKarmaInfo, nil
+}
+
+// Parse parses the given karma info command.
+func (p *InfoParser) Parse(splitContent []string, m *discordgo.MessageCreate) (*model.Command, error) {
+	if splitContent[0] != p.GetName() {
+		log.Fatal("KarmaInfoParser.Parse called with non-karma command", errors.New("wat"))
+	}
+
+	splitContent = util.CollapseWhitespace(splitContent, 1)
+
+	var target string
+	if len(splitContent) > 1 {
+		target = parseTarget(splitContent[1], m)
+	}
+
+	// Show help when not enough data is present, or malicious data is present.
+	if len(target) == 0 {
+		return &model.Command{
+			Type: model.CommandTypeHelp,
+			Help: &model.HelpData{
+				Command: model.CommandNameKarmaInfo,
+			},
+		}, nil
+	}
+
+	return &model.Command{
+		Type: model.CommandTypeKarmaInfo,
+		KarmaInfo: &model.KarmaInfoData{
+			Target: target,
+		},
+	}, nil
+}
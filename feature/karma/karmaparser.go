@@ -34,9 +34,9 @@ func (p *Parser) GetName() string {
 
 const (
 	// MsgHelpKarmaIncrement is help text for ?++
-	MsgHelpKarmaIncrement = "Type ?++ <target> to add a single unit of karma to a target's karma score"
+	MsgHelpKarmaIncrement = "Type ?++ <target> [for <reason>] to add a single unit of karma to a target's karma score"
 	// MsgHelpKarmaDecrement is help text for ?--
-	MsgHelpKarmaDecrement = "Type ?-- <target> to deduct a single unit of karma from a target's karma score"
+	MsgHelpKarmaDecrement = "Type ?-- <target> [for <reason>] to deduct a single unit of karma from a target's karma score"
 )
 
 // HelpText returns the help text.
@@ -62,27 +62,8 @@ func (p *Parser) Parse(splitContent []string, m *discordgo.MessageCreate) (*mode
 	splitContent = util.CollapseWhitespace(splitContent, 1)
 
 	var target string
-
 	if len(splitContent) > 1 {
-		// First, test to see if there is an embedded entity that can be looked up.
-		entityMatch := entityRegexp.FindStringSubmatch(splitContent[1])
-		if len(entityMatch) == 2 {
-			// Look up the ID in mentions in the original message.
-			id := entityMatch[1]
-			for _, mention := range m.Mentions {
-				if mention.ID == id {
-					target = mention.Username
-				}
-			}
-		}
-
-		// If not, try to trim and match what's left.
-		if len(target) == 0 {
-			trimmedTarget := strings.Split(strings.TrimPrefix(splitContent[1], "@"), "#")[0]
-			if directMentionRegexp.MatchString(trimmedTarget) {
-				target = trimmedTarget
-			}
-		}
+		target = parseTarget(splitContent[1], m)
 	}
 
 	// Show help when not enough data is present, or malicious data is present.
@@ -99,11 +80,43 @@ func (p *Parser) Parse(splitContent []string, m *discordgo.MessageCreate) (*mode
 		}, nil
 	}
 
+	// An optional reason can follow the target, as in `?++ alice for fixing CI`.
+	reason := ""
+	splitContent = util.CollapseWhitespace(splitContent, 2)
+	splitContent = util.CollapseWhitespace(splitContent, 3)
+	if len(splitContent) > 3 && splitContent[2] == "for" {
+		reason = strings.TrimSpace(strings.Join(splitContent[3:], " "))
+	}
+
 	return &model.Command{
 		Type: model.CommandTypeKarma,
 		Karma: &model.KarmaData{
 			Increment: p.Increment,
 			Target:    target,
+			Reason:    reason,
 		},
 	}, nil
 }
+
+// parseTarget returns the karma target named by the given token, or the empty
+// string if the token does not name a valid target.
+func parseTarget(token string, m *discordgo.MessageCreate) string {
+	// First, test to see if there is an embedded entity that can be looked up.
+	entityMatch := entityRegexp.FindStringSubmatch(token)
+	if len(entityMatch) == 2 {
+		// Look up the ID in mentions in the original message.
+		id := entityMatch[1]
+		for _, mention := range m.Mentions {
+			if mention.ID == id {
+				return mention.Username
+			}
+		}
+	}
+
+	// If not, try to trim and match what's left.
+	trimmedTarget := strings.Split(strings.TrimPrefix(token, "@"), "#")[0]
+	if directMentionRegexp.MatchString(trimmedTarget) {
+		return trimmedTarget
+	}
+	return ""
+}
@@ -0,0 +1,105 @@
+package karma
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// MergeExecutor merges or aliases karma targets and prints the results to the
+// user.
+type MergeExecutor struct {
+	modelHelper *ModelHelper
+	alias       bool
+}
+
+// NewMergeExecutor works as advertised.
+func NewMergeExecutor(modelHelper *ModelHelper, alias bool) *MergeExecutor {
+	return &MergeExecutor{
+		modelHelper: modelHelper,
+		alias:       alias,
+	}
+}
+
+// GetType returns the type of this feature.
+func (e *MergeExecutor) GetType() int {
+	if e.alias {
+		return model.CommandTypeKarmaAlias
+	}
+	return model.CommandTypeKarmaMerge
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private channel.
+func (e *MergeExecutor) PublicOnly() bool {
+	return true
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *MergeExecutor) ModeratorOnly() bool {
+	return true
+}
+
+const (
+	// MsgKarmaMerged prints the results of ?karmamerge from into
+	MsgKarmaMerged = "Merged %v into %v. %v now has %d karma. Type `?karmaunmerge %d` to undo."
+	// MsgKarmaAliased prints the results of ?karmaalias alias canonical
+	MsgKarmaAliased = "%v is now an alias of %v. %v now has %d karma. Type `?karmaunmerge %d` to undo."
+	// MsgKarmaMergeSame prints that a target can't be merged into itself
+	MsgKarmaMergeSame = "%v is already %v"
+	// MsgKarmaNothingToMerge prints that the target has no karma to merge
+	MsgKarmaNothingToMerge = "%v has no karma to merge"
+	// MsgKarmaMergeFailed prints that the targets couldn't be merged
+	MsgKarmaMergeFailed = "Couldn't merge %v into %v. Try again later."
+)
+
+// Execute merges the targets, and optionally aliases them.
+func (e *MergeExecutor) Execute(s api.DiscordSession, channelID model.Snowflake, command *model.Command) {
+	if command.KarmaMerge == nil {
+		log.Fatal("Incorrectly generated karma merge command", errors.New("wat"))
+	}
+
+	modelHelper := e.modelHelper.ForGuild(command.GuildID)
+
+	userID, err := model.ParseSnowflake(command.Author.ID)
+	if err != nil {
+		log.Info("Error parsing command user ID", err)
+		return
+	}
+
+	from := command.KarmaMerge.From
+	var merge *model.KarmaMerge
+	if e.alias {
+		merge, err = modelHelper.SetAlias(from, command.KarmaMerge.Into, userID)
+	} else {
+		merge, err = modelHelper.Merge(from, command.KarmaMerge.Into, userID)
+	}
+
+	var message string
+	switch err {
+	case nil:
+		karma, err := modelHelper.Karma(merge.Into)
+		if err != nil {
+			log.Info("Error reading karma", err)
+			return
+		}
+		messageFormat := MsgKarmaMerged
+		if e.alias {
+			messageFormat = MsgKarmaAliased
+		}
+		message = fmt.Sprintf(messageFormat, merge.From, merge.Into, merge.Into, karma, merge.MergeID)
+	case ErrorSameTarget:
+		message = fmt.Sprintf(MsgKarmaMergeSame, from, command.KarmaMerge.Into)
+	case ErrorNothingToMerge:
+		message = fmt.Sprintf(MsgKarmaNothingToMerge, from)
+	default:
+		log.Info("Error merging karma", err)
+		message = fmt.Sprintf(MsgKarmaMergeFailed, from, command.KarmaMerge.Into)
+	}
+
+	if _, err := s.ChannelMessageSend(channelID.Format(), message); err != nil {
+		log.Info("Error sending karma merge message", err)
+	}
+}
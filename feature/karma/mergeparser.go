@@ -0,0 +1,85 @@
+package karma
+
+import (
+	"errors"
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jakevoytko/crbot/model"
+	"github.com/jakevoytko/crbot/util"
+)
+
+// MergeParser parses ?karmamerge and ?karmaalias commands
+type MergeParser struct {
+	// The message that the parser looks for.
+	Message string
+	// Whether the merged target becomes an alias of the canonical target
+	Alias bool
+}
+
+// NewMergeParser works as advertised.
+func NewMergeParser(message string, alias bool) *MergeParser {
+	return &MergeParser{
+		Message: message,
+		Alias:   alias,
+	}
+}
+
+// GetName returns the named type.
+func (p *MergeParser) GetName() string {
+	return p.Message
+}
+
+const (
+	// MsgHelpKarmaMerge is help text for ?karmamerge
+	MsgHelpKarmaMerge = "Type ?karmamerge <from> <into> to move all of a target's karma and history into another target. Moderators only."
+	// MsgHelpKarmaAlias is help text for ?karmaalias
+	MsgHelpKarmaAlias = "Type ?karmaalias <alias> <canonical> to merge a target into another, and give all of its future karma to the other target. Moderators only."
+)
+
+// HelpText returns the help text.
+func (p *MergeParser) HelpText(command string) (string, error) {
+	if p.Alias {
+		return MsgHelpKarmaAlias, nil
+	}
+	return MsgHelpKarmaMerge, nil
+}
+
+// Parse parses the given merge command.
+func (p *MergeParser) Parse(splitContent []string, m *discordgo.MessageCreate) (*model.Command, error) {
+	if splitContent[0] != p.GetName() {
+		log.Fatal("KarmaMergeParser.Parse called with non-merge command", errors.New("wat"))
+	}
+
+	splitContent = util.CollapseWhitespace(splitContent, 1)
+	splitContent = util.CollapseWhitespace(splitContent, 2)
+	splitContent = util.CollapseWhitespace(splitContent, 3)
+
+	var from, into string
+	if len(splitContent) == 3 {
+		from = parseTarget(splitContent[1], m)
+		into = parseTarget(splitContent[2], m)
+	}
+
+	// Show help when not enough data is present, or malicious data is present.
+	if len(from) == 0 || len(into) == 0 {
+		return &model.Command{
+			Type: model.CommandTypeHelp,
+			Help: &model.HelpData{
+				Command: p.GetName(),
+			},
+		}, nil
+	}
+
+	commandType := model.CommandTypeKarmaMerge
+	if p.Alias {
+		commandType = model.CommandTypeKarmaAlias
+	}
+	return &model.Command{
+		Type: commandType,
+		KarmaMerge: &model.KarmaMergeData{
+			From: from,
+			Into: into,
+		},
+	}, nil
+}
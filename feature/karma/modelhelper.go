@@ -1,64 +1,557 @@
 package karma
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"sort"
 	"strconv"
+	"time"
 
+	"github.com/jakevoytko/crbot/model"
 	stringmap "github.com/jakevoytko/go-stringmap"
 )
 
 // ModelHelper provides helpers for working with karma storage.
 type ModelHelper struct {
-	karmaMap stringmap.StringMap
+	karmaMap        stringmap.StringMap
+	karmaHistoryMap stringmap.StringMap
+	karmaMergeMap   stringmap.StringMap
+	utcClock        model.UTCClock
 }
 
 // NewModelHelper works as advertised.
-func NewModelHelper(karmaMap stringmap.StringMap) *ModelHelper {
-	return &ModelHelper{karmaMap: karmaMap}
+func NewModelHelper(karmaMap, karmaHistoryMap, karmaMergeMap stringmap.StringMap, utcClock model.UTCClock) *ModelHelper {
+	return &ModelHelper{
+		karmaMap:        karmaMap,
+		karmaHistoryMap: karmaHistoryMap,
+		karmaMergeMap:   karmaMergeMap,
+		utcClock:        utcClock,
+	}
+}
+
+// ForGuild returns a helper for the karma of a single guild.
+func (h *ModelHelper) ForGuild(guildID model.Snowflake) *ModelHelper {
+	return NewModelHelper(
+		NewGuildMap(h.karmaMap, guildID),
+		NewGuildMap(h.karmaHistoryMap, guildID),
+		NewGuildMap(h.karmaMergeMap, guildID),
+		h.utcClock)
 }
 
-// Increment either adds 1 to storage in the key, or increments the key in storage if it exists.
-func (h *ModelHelper) Increment(target string) (int, error) {
-	return h.process(target, true /* increment */)
+// MigrateToGuild moves all karma that was stored before karma was scoped by
+// guild into the given guild. It only runs on the first startup, even when
+// there is no guild to move karma into, since other guilds' karma can't be told
+// apart from legacy karma afterwards.
+func (h *ModelHelper) MigrateToGuild(guildID model.Snowflake) (int, error) {
+	done, err := h.karmaMergeMap.Has(KeyGuildMigration)
+	if err != nil || done {
+		return 0, err
+	}
+	if guildID == 0 {
+		return 0, h.karmaMergeMap.Set(KeyGuildMigration, guildID.Format())
+	}
+	migratedKarma, err := MigrateToGuild(h.karmaMap, guildID)
+	if err != nil {
+		return migratedKarma, err
+	}
+	migratedHistory, err := MigrateToGuild(h.karmaHistoryMap, guildID)
+	if err != nil {
+		return migratedKarma + migratedHistory, err
+	}
+	migratedMerges, err := MigrateToGuild(h.karmaMergeMap, guildID)
+	if err != nil {
+		return migratedKarma + migratedHistory + migratedMerges, err
+	}
+	return migratedKarma + migratedHistory + migratedMerges, h.karmaMergeMap.Set(KeyGuildMigration, guildID.Format())
+}
+
+const (
+	// KeyKarmaHistory is the key/value store key for a target's karma history
+	KeyKarmaHistory = "history-%v"
+	// RedisKarmaHistory matches every karma history key
+	RedisKarmaHistory = "history-*"
+	// KeyKarmaAlias is the key/value store key for the target an alias points to
+	KeyKarmaAlias = "alias-%v"
+	// KeyKarmaMerge is the key/value store key for a merge record
+	KeyKarmaMerge = "merge-%v"
+	// KeyMostRecentMergeID is the key/value store key for the most recent merge ID
+	KeyMostRecentMergeID = "most-recent-merge-id"
+	// KeyGuildMigration is the key/value store key for the guild that legacy
+	// karma was moved into, or 0 if it was left where it is
+	KeyGuildMigration = "guild-migration"
+	// MaxAliasDepth is the longest chain of aliases that is followed
+	MaxAliasDepth = 10
+)
+
+// ErrorSameTarget indicates that a target can't be merged into itself
+var ErrorSameTarget = errors.New("cannot merge a target into itself")
+
+// ErrorNothingToMerge indicates that the merged target has no karma or history
+var ErrorNothingToMerge = errors.New("target has no karma to merge")
+
+// ErrorNoSuchMerge indicates that the merge ID was never recorded
+var ErrorNoSuchMerge = errors.New("no merge with the given ID")
+
+// ErrorMergeUndone indicates that the merge was already undone
+var ErrorMergeUndone = errors.New("merge was already undone")
+
+// ErrorMergeTargetMerged indicates that the merge's target was merged into
+// another target since, and that merge has to be undone first
+var ErrorMergeTargetMerged = errors.New("merge target was merged away since")
+
+// ReasonCount is the number of times a single reason was given for a target's
+// karma.
+type ReasonCount struct {
+	Reason string
+	Count  int
+}
+
+// Increment either adds 1 to storage in the key, or increments the key in
+// storage if it exists. The reason is recorded in the target's history, and
+// may be empty.
+func (h *ModelHelper) Increment(target, reason string) (int, error) {
+	return h.process(target, reason, true /* increment */)
 }
 
 // Decrement either subtracts 1 from storage in the key, or decrements the key in
-// storage if it exists.
-func (h *ModelHelper) Decrement(target string) (int, error) {
-	return h.process(target, false /* increment */)
+// storage if it exists. The reason is recorded in the target's history, and
+// may be empty.
+func (h *ModelHelper) Decrement(target, reason string) (int, error) {
+	return h.process(target, reason, false /* increment */)
 }
 
-// Process handles both Increment and Decrement.
-func (h *ModelHelper) process(target string, increment bool) (int, error) {
-	// Get the current value of karma (if it exists) and increment/decrement it
-	currentKarma := 0
+// Karma returns the current karma of the target, or 0 if it has never received
+// karma.
+func (h *ModelHelper) Karma(target string) (int, error) {
 	has, err := h.karmaMap.Has(target)
 	if err != nil {
-		log.Fatal("Couldn't check if target has karma", err)
+		return 0, err
 	}
-	if has {
-		currentKarmaStr, err := h.karmaMap.Get(target)
-		if err != nil {
-			return 0, fmt.Errorf("%s: %f", "Couldn't get target's current karma", err)
+	if !has {
+		return 0, nil
+	}
+	currentKarmaStr, err := h.karmaMap.Get(target)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", "Couldn't get target's current karma", err)
+	}
+	currentKarma, err := strconv.Atoi(currentKarmaStr)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", "Invalid karma value", err)
+	}
+	return currentKarma, nil
+}
+
+// History returns every recorded karma change for the target. Karma that was
+// given before history was recorded does not appear.
+func (h *ModelHelper) History(target string) (*model.KarmaHistory, error) {
+	key := fmt.Sprintf(KeyKarmaHistory, target)
+	has, err := h.karmaHistoryMap.Has(key)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return model.NewKarmaHistory(target), nil
+	}
+
+	serializedHistory, err := h.karmaHistoryMap.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var history model.KarmaHistory
+	if err := json.Unmarshal([]byte(serializedHistory), &history); err != nil {
+		return nil, err
+	}
+	return &history, nil
+}
+
+// DecayedKarma returns the target's karma, where each change loses half of its
+// weight every halfLife. Karma that was given before history was recorded is
+// not counted.
+func (h *ModelHelper) DecayedKarma(target string, halfLife time.Duration) (float64, error) {
+	history, err := h.History(target)
+	if err != nil {
+		return 0, err
+	}
+	return history.Decayed(h.utcClock.Now(), halfLife), nil
+}
+
+// TopReasons returns up to `limit` of the most commonly given reasons for the
+// target's karma, most common first. Ties are broken alphabetically.
+func (h *ModelHelper) TopReasons(target string, limit int) ([]ReasonCount, error) {
+	history, err := h.History(target)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{}
+	for _, event := range history.Events {
+		if len(event.Reason) > 0 {
+			counts[event.Reason]++
 		}
-		currentKarma, err = strconv.Atoi(currentKarmaStr)
-		if err != nil {
-			return 0, fmt.Errorf("%s: %f", "Invalid karma value", err)
+	}
+
+	reasons := make([]ReasonCount, 0, len(counts))
+	for reason, count := range counts {
+		reasons = append(reasons, ReasonCount{Reason: reason, Count: count})
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		if reasons[i].Count != reasons[j].Count {
+			return reasons[i].Count > reasons[j].Count
 		}
+		return reasons[i].Reason < reasons[j].Reason
+	})
+
+	if len(reasons) > limit {
+		reasons = reasons[:limit]
 	}
+	return reasons, nil
+}
 
-	var newKarma int
+// Process handles both Increment and Decrement.
+func (h *ModelHelper) process(target, reason string, increment bool) (int, error) {
+	// Get the current value of karma (if it exists) and increment/decrement it
+	currentKarma, err := h.Karma(target)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", "Couldn't get target's current karma", err)
+	}
+
+	delta := -1
 	if increment {
-		newKarma = currentKarma + 1
-	} else {
-		newKarma = currentKarma - 1
+		delta = 1
 	}
+	newKarma := currentKarma + delta
 
 	err = h.karmaMap.Set(target, strconv.Itoa(newKarma))
 	if err != nil {
-		return 0, fmt.Errorf("%s: %f", "Error storing new karma value", err)
+		return 0, fmt.Errorf("%s: %v", "Error storing new karma value", err)
+	}
+
+	// Record the change after the total, so that a history write failure never
+	// loses the user's karma.
+	history, err := h.History(target)
+	if err != nil {
+		return 0, err
+	}
+	history.Events = append(history.Events, model.KarmaEvent{
+		Timestamp: h.utcClock.Now(),
+		Delta:     delta,
+		Reason:    reason,
+	})
+	if err := h.writeHistory(history); err != nil {
+		return 0, fmt.Errorf("%s: %v", "Error storing karma history", err)
 	}
 
 	return newKarma, nil
 }
+
+// Resolve follows aliases from the given target, and returns the target that
+// its karma is stored under.
+func (h *ModelHelper) Resolve(target string) (string, error) {
+	for i := 0; i < MaxAliasDepth; i++ {
+		key := fmt.Sprintf(KeyKarmaAlias, target)
+		has, err := h.karmaMergeMap.Has(key)
+		if err != nil {
+			return "", err
+		}
+		if !has {
+			return target, nil
+		}
+		if target, err = h.karmaMergeMap.Get(key); err != nil {
+			return "", err
+		}
+	}
+	return target, nil
+}
+
+// Merge moves the karma total and history of `from` into the target that
+// `into` resolves to, and returns the record of the merge. Returns
+// ErrorNothingToMerge if `from` has neither.
+func (h *ModelHelper) Merge(from, into string, userID model.Snowflake) (*model.KarmaMerge, error) {
+	return h.merge(from, into, userID, false /* alias */)
+}
+
+// SetAlias makes `alias` point at the target that `canonical` resolves to, so
+// that future karma for `alias` is given to the canonical target. Any karma
+// already stored for `alias` is merged into the canonical target.
+func (h *ModelHelper) SetAlias(alias, canonical string, userID model.Snowflake) (*model.KarmaMerge, error) {
+	return h.merge(alias, canonical, userID, true /* alias */)
+}
+
+// Unmerge undoes the merge with the given ID, and returns the record of the
+// merge. Karma given to either target since the merge is kept. Merges are
+// unwound in reverse order: if the target was merged away since, this returns
+// that later merge with ErrorMergeTargetMerged.
+func (h *ModelHelper) Unmerge(mergeID int) (*model.KarmaMerge, error) {
+	merge, err := h.mergeRecord(mergeID)
+	if err != nil {
+		return nil, err
+	}
+	if merge.Undone {
+		return nil, ErrorMergeUndone
+	}
+	laterMerge, err := h.laterMergeFrom(mergeID, merge.Into)
+	if err != nil {
+		return nil, err
+	}
+	if laterMerge != nil {
+		return laterMerge, ErrorMergeTargetMerged
+	}
+
+	// Move the total back.
+	if merge.FromKarma != 0 {
+		intoKarma, err := h.Karma(merge.Into)
+		if err != nil {
+			return nil, err
+		}
+		fromKarma, err := h.Karma(merge.From)
+		if err != nil {
+			return nil, err
+		}
+		if err := h.karmaMap.Set(merge.Into, strconv.Itoa(intoKarma-merge.FromKarma)); err != nil {
+			return nil, err
+		}
+		if err := h.karmaMap.Set(merge.From, strconv.Itoa(fromKarma+merge.FromKarma)); err != nil {
+			return nil, err
+		}
+	}
+
+	// Move the history back, removing one matching event for every event that
+	// was moved.
+	if len(merge.FromEvents) > 0 {
+		intoHistory, err := h.History(merge.Into)
+		if err != nil {
+			return nil, err
+		}
+		fromHistory, err := h.History(merge.From)
+		if err != nil {
+			return nil, err
+		}
+		for _, moved := range merge.FromEvents {
+			for i, event := range intoHistory.Events {
+				if event.Timestamp.Equal(moved.Timestamp) && event.Delta == moved.Delta && event.Reason == moved.Reason {
+					intoHistory.Events = append(intoHistory.Events[:i], intoHistory.Events[i+1:]...)
+					break
+				}
+			}
+		}
+		fromHistory.Events = mergeEvents(fromHistory.Events, merge.FromEvents)
+		if err := h.writeHistory(intoHistory); err != nil {
+			return nil, err
+		}
+		if err := h.writeHistory(fromHistory); err != nil {
+			return nil, err
+		}
+	}
+
+	// Restore the previous alias.
+	if merge.Alias {
+		aliasKey := fmt.Sprintf(KeyKarmaAlias, merge.From)
+		var err error
+		if len(merge.PreviousAlias) > 0 {
+			err = h.karmaMergeMap.Set(aliasKey, merge.PreviousAlias)
+		} else {
+			err = h.karmaMergeMap.Delete(aliasKey)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	merge.Undone = true
+	if err := h.writeMergeRecord(merge); err != nil {
+		return nil, err
+	}
+	return merge, nil
+}
+
+func (h *ModelHelper) merge(from, into string, userID model.Snowflake, alias bool) (*model.KarmaMerge, error) {
+	into, err := h.Resolve(into)
+	if err != nil {
+		return nil, err
+	}
+	if from == into {
+		return nil, ErrorSameTarget
+	}
+
+	hasKarma, err := h.karmaMap.Has(from)
+	if err != nil {
+		return nil, err
+	}
+	fromKarma, err := h.Karma(from)
+	if err != nil {
+		return nil, err
+	}
+	fromHistory, err := h.History(from)
+	if err != nil {
+		return nil, err
+	}
+	if !alias && !hasKarma && len(fromHistory.Events) == 0 {
+		return nil, ErrorNothingToMerge
+	}
+
+	mergeID, err := h.nextMergeID()
+	if err != nil {
+		return nil, err
+	}
+	merge := &model.KarmaMerge{
+		MergeID:    mergeID,
+		From:       from,
+		Into:       into,
+		UserID:     userID,
+		Timestamp:  h.utcClock.Now(),
+		FromKarma:  fromKarma,
+		FromEvents: fromHistory.Events,
+		Alias:      alias,
+	}
+
+	// Record the merge up front, so that a failure partway through can still
+	// be undone.
+	if alias {
+		aliasKey := fmt.Sprintf(KeyKarmaAlias, from)
+		hasAlias, err := h.karmaMergeMap.Has(aliasKey)
+		if err != nil {
+			return nil, err
+		}
+		if hasAlias {
+			if merge.PreviousAlias, err = h.karmaMergeMap.Get(aliasKey); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := h.writeMergeRecord(merge); err != nil {
+		return nil, err
+	}
+	if err := h.karmaMergeMap.Set(KeyMostRecentMergeID, strconv.Itoa(mergeID)); err != nil {
+		return nil, err
+	}
+
+	// Move the total.
+	intoKarma, err := h.Karma(into)
+	if err != nil {
+		return nil, err
+	}
+	if hasKarma || fromKarma != 0 {
+		if err := h.karmaMap.Set(into, strconv.Itoa(intoKarma+fromKarma)); err != nil {
+			return nil, err
+		}
+	}
+	if hasKarma {
+		if err := h.karmaMap.Delete(from); err != nil {
+			return nil, err
+		}
+	}
+
+	// Move the history.
+	if len(fromHistory.Events) > 0 {
+		intoHistory, err := h.History(into)
+		if err != nil {
+			return nil, err
+		}
+		intoHistory.Events = mergeEvents(intoHistory.Events, fromHistory.Events)
+		if err := h.writeHistory(intoHistory); err != nil {
+			return nil, err
+		}
+		if err := h.karmaHistoryMap.Delete(fmt.Sprintf(KeyKarmaHistory, from)); err != nil {
+			return nil, err
+		}
+	}
+
+	if alias {
+		if err := h.karmaMergeMap.Set(fmt.Sprintf(KeyKarmaAlias, from), into); err != nil {
+			return nil, err
+		}
+	}
+
+	return merge, nil
+}
+
+// nextMergeID returns the ID of the next merge. Merge IDs start at 1.
+func (h *ModelHelper) nextMergeID() (int, error) {
+	has, err := h.karmaMergeMap.Has(KeyMostRecentMergeID)
+	if err != nil {
+		return 0, err
+	}
+	if !has {
+		return 1, nil
+	}
+	mostRecentMergeIDStr, err := h.karmaMergeMap.Get(KeyMostRecentMergeID)
+	if err != nil {
+		return 0, err
+	}
+	mostRecentMergeID, err := strconv.Atoi(mostRecentMergeIDStr)
+	if err != nil {
+		return 0, err
+	}
+	return mostRecentMergeID + 1, nil
+}
+
+// laterMergeFrom returns the first merge after the given one that moved the
+// target's karma elsewhere and wasn't undone, or nil.
+func (h *ModelHelper) laterMergeFrom(mergeID int, target string) (*model.KarmaMerge, error) {
+	nextMergeID, err := h.nextMergeID()
+	if err != nil {
+		return nil, err
+	}
+	for laterMergeID := mergeID + 1; laterMergeID < nextMergeID; laterMergeID++ {
+		merge, err := h.mergeRecord(laterMergeID)
+		if err == ErrorNoSuchMerge {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !merge.Undone && merge.From == target {
+			return merge, nil
+		}
+	}
+	return nil, nil
+}
+
+func (h *ModelHelper) mergeRecord(mergeID int) (*model.KarmaMerge, error) {
+	key := fmt.Sprintf(KeyKarmaMerge, mergeID)
+	has, err := h.karmaMergeMap.Has(key)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrorNoSuchMerge
+	}
+	serializedMerge, err := h.karmaMergeMap.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	var merge model.KarmaMerge
+	if err := json.Unmarshal([]byte(serializedMerge), &merge); err != nil {
+		return nil, err
+	}
+	return &merge, nil
+}
+
+func (h *ModelHelper) writeMergeRecord(merge *model.KarmaMerge) error {
+	serializedMerge, err := json.Marshal(merge)
+	if err != nil {
+		return err
+	}
+	return h.karmaMergeMap.Set(fmt.Sprintf(KeyKarmaMerge, merge.MergeID), string(serializedMerge))
+}
+
+// mergeEvents returns both lists of events, ordered by time.
+func mergeEvents(a, b []model.KarmaEvent) []model.KarmaEvent {
+	merged := make([]model.KarmaEvent, 0, len(a)+len(b))
+	merged = append(merged, a...)
+	merged = append(merged, b...)
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Timestamp.Before(merged[j].Timestamp)
+	})
+	return merged
+}
+
+func (h *ModelHelper) writeHistory(history *model.KarmaHistory) error {
+	serializedHistory, err := json.Marshal(history)
+	if err != nil {
+		return err
+	}
+	return h.karmaHistoryMap.Set(fmt.Sprintf(KeyKarmaHistory, history.Target), string(serializedHistory))
+}
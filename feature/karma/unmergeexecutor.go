@@ -0,0 +1,91 @@
+package karma
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// UnmergeExecutor undoes a recorded karma merge and prints the results to the
+// user.
+type UnmergeExecutor struct {
+	modelHelper *ModelHelper
+}
+
+// NewUnmergeExecutor works as advertised.
+func NewUnmergeExecutor(modelHelper *ModelHelper) *UnmergeExecutor {
+	return &UnmergeExecutor{modelHelper: modelHelper}
+}
+
+// GetType returns the type of this feature.
+func (e *UnmergeExecutor) GetType() int {
+	return model.CommandTypeKarmaUnmerge
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private channel.
+func (e *UnmergeExecutor) PublicOnly() bool {
+	return true
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *UnmergeExecutor) ModeratorOnly() bool {
+	return true
+}
+
+const (
+	// MsgKarmaUnmerged prints the results of ?karmaunmerge id
+	MsgKarmaUnmerged = "Undid merge %d. %v has %d karma, and %v has %d karma."
+	// MsgKarmaNoSuchMerge prints that the merge doesn't exist
+	MsgKarmaNoSuchMerge = "There is no merge %d"
+	// MsgKarmaMergeUndone prints that the merge was already undone
+	MsgKarmaMergeUndone = "Merge %d was already undone"
+	// MsgKarmaMergeTargetMerged prints that the merge's target was merged away
+	// since, so that merge has to be undone first
+	MsgKarmaMergeTargetMerged = "%v was merged into %v by merge %d since. Undo merge %d first."
+	// MsgKarmaUnmergeFailed prints that the merge couldn't be undone
+	MsgKarmaUnmergeFailed = "Couldn't undo merge %d. Try again later."
+)
+
+// Execute undoes the merge.
+func (e *UnmergeExecutor) Execute(s api.DiscordSession, channelID model.Snowflake, command *model.Command) {
+	if command.KarmaUnmerge == nil {
+		log.Fatal("Incorrectly generated karma unmerge command", errors.New("wat"))
+	}
+
+	modelHelper := e.modelHelper.ForGuild(command.GuildID)
+
+	mergeID := command.KarmaUnmerge.MergeID
+	merge, err := modelHelper.Unmerge(mergeID)
+
+	var message string
+	switch err {
+	case nil:
+		fromKarma, err := modelHelper.Karma(merge.From)
+		if err != nil {
+			log.Info("Error reading karma", err)
+			return
+		}
+		intoKarma, err := modelHelper.Karma(merge.Into)
+		if err != nil {
+			log.Info("Error reading karma", err)
+			return
+		}
+		message = fmt.Sprintf(MsgKarmaUnmerged, mergeID, merge.From, fromKarma, merge.Into, intoKarma)
+	case ErrorNoSuchMerge:
+		message = fmt.Sprintf(MsgKarmaNoSuchMerge, mergeID)
+	case ErrorMergeUndone:
+		message = fmt.Sprintf(MsgKarmaMergeUndone, mergeID)
+	case ErrorMergeTargetMerged:
+		message = fmt.Sprintf(MsgKarmaMergeTargetMerged, merge.From, merge.Into, merge.MergeID, merge.MergeID)
+	default:
+		log.Info("Error undoing karma merge", err)
+		message = fmt.Sprintf(MsgKarmaUnmergeFailed, mergeID)
+	}
+
+	if _, err := s.ChannelMessageSend(channelID.Format(), message); err != nil {
+		log.Info("Error sending karma unmerge message", err)
+	}
+}
@@ -0,0 +1,66 @@
+package karma
+
+import (
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jakevoytko/crbot/model"
+	"github.com/jakevoytko/crbot/util"
+)
+
+// UnmergeParser parses ?karmaunmerge commands
+type UnmergeParser struct{}
+
+// NewUnmergeParser works as advertised.
+func NewUnmergeParser() *UnmergeParser {
+	return &UnmergeParser{}
+}
+
+// GetName returns the named type.
+func (p *UnmergeParser) GetName() string {
+	return model.CommandNameKarmaUnmerge
+}
+
+const (
+	// MsgHelpKarmaUnmerge is help text for ?karmaunmerge
+	MsgHelpKarmaUnmerge = "Type ?karmaunmerge <merge ID> to undo a ?karmamerge or ?karmaalias. Moderators only."
+)
+
+// HelpText returns the help text.
+func (p *UnmergeParser) HelpText(command string) (string, error) {
+	return MsgHelpKarmaUnmerge, nil
+}
+
+// Parse parses the given unmerge command.
+func (p *UnmergeParser) Parse(splitContent []string, m *discordgo.MessageCreate) (*model.Command, error) {
+	if splitContent[0] != p.GetName() {
+		log.Fatal("KarmaUnmergeParser.Parse called with non-unmerge command", errors.New("wat"))
+	}
+
+	splitContent = util.CollapseWhitespace(splitContent, 1)
+	splitContent = util.CollapseWhitespace(splitContent, 2)
+
+	mergeID := 0
+	if len(splitContent) == 2 {
+		mergeID, _ = strconv.Atoi(splitContent[1])
+	}
+
+	// Show help when not enough data is present, or malicious data is present.
+	if mergeID <= 0 {
+		return &model.Command{
+			Type: model.CommandTypeHelp,
+			Help: &model.HelpData{
+				Command: model.CommandNameKarmaUnmerge,
+			},
+		}, nil
+	}
+
+	return &model.Command{
+		Type: model.CommandTypeKarmaUnmerge,
+		KarmaUnmerge: &model.KarmaUnmergeData{
+			MergeID: mergeID,
+		},
+	}, nil
+}
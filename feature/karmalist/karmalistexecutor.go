@@ -13,6 +13,13 @@ const (
 	MsgGistAddress = "The list of karma is here"
 	// MsgListKarma is a user-visible header for the list of Karma'd things
 	MsgListKarma = "Karma targets listed by Magnitude:"
+	// MsgGistLeaderboardAddress is a user-visible string announcing the url of a long leaderboard
+	MsgGistLeaderboardAddress = "The karma leaderboard is here"
+
+	// MaxInlineLength is the longest leaderboard that is posted directly to the
+	// channel. Longer leaderboards are uploaded to the gist API. Discord rejects
+	// messages longer than 2000 characters.
+	MaxInlineLength = 2000
 )
 
 // Executor uploads sorted karma list to hastebin and returns the url to the user
@@ -43,14 +50,48 @@ func (e *Executor) PublicOnly() bool {
 	return false
 }
 
-// Execute uploads the sorted karma list to the gist API and pings the gist link in chat.
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *Executor) ModeratorOnly() bool {
+	return false
+}
+
+// Execute uploads the sorted karma list to the gist API and pings the gist link
+// in chat. Leaderboards are posted directly in chat, unless they are too long.
 func (e *Executor) Execute(s api.DiscordSession, channel model.Snowflake, command *model.Command) {
-	sortedKarma := e.modelHelper.GenerateList()
+	modelHelper := e.modelHelper.ForGuild(command.GuildID)
+	if command.KarmaList != nil {
+		e.executeLeaderboard(s, channel, modelHelper, command.KarmaList)
+		return
+	}
+
+	sortedKarma := modelHelper.GenerateList()
 	if url, err := e.gist.Upload(sortedKarma); err != nil {
 		s.ChannelMessageSend(channel.Format(), err.Error())
 		log.Info("Gist API failed", err)
 	} else {
 		s.ChannelMessageSend(channel.Format(), MsgGistAddress+": "+url)
 	}
+}
 
+func (e *Executor) executeLeaderboard(s api.DiscordSession, channel model.Snowflake, modelHelper *ModelHelper, data *model.KarmaListData) {
+	leaderboard, err := modelHelper.GenerateLeaderboard(data)
+	if err != nil {
+		log.Info(MsgKarmaMapFailed, err)
+		s.ChannelMessageSend(channel.Format(), MsgLeaderboardFailed)
+		return
+	}
+
+	if len(leaderboard) <= MaxInlineLength {
+		if _, err := s.ChannelMessageSend(channel.Format(), leaderboard); err != nil {
+			log.Info("Error sending karma leaderboard", err)
+		}
+		return
+	}
+
+	if url, err := e.gist.Upload(leaderboard); err != nil {
+		s.ChannelMessageSend(channel.Format(), err.Error())
+		log.Info("Gist API failed", err)
+	} else {
+		s.ChannelMessageSend(channel.Format(), MsgGistLeaderboardAddress+": "+url)
+	}
 }
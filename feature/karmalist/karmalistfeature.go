@@ -2,7 +2,9 @@ package karmalist
 
 import (
 	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/config"
 	"github.com/jakevoytko/crbot/feature"
+	"github.com/jakevoytko/crbot/model"
 	stringmap "github.com/jakevoytko/go-stringmap"
 )
 
@@ -15,15 +17,20 @@ type Feature struct {
 }
 
 // NewFeature returns a new Feature.
-func NewFeature(featureRegistry *feature.Registry, karmaMap stringmap.StringMap, gist api.Gist) *Feature {
+func NewFeature(featureRegistry *feature.Registry, karmaMap, karmaHistoryMap stringmap.StringMap, gist api.Gist, clock model.UTCClock, config *config.Config) *Feature {
 	return &Feature{
 		featureRegistry: featureRegistry,
 		karmaMap:        karmaMap,
-		modelHelper:     NewModelHelper(karmaMap),
+		modelHelper:     NewModelHelper(karmaMap, karmaHistoryMap, clock, config.KarmaDecayHalfLife()),
 		gist:            gist,
 	}
 }
 
+// GetName returns the name that channels enable and disable the feature by.
+func (f *Feature) GetName() string {
+	return model.FeatureNameKarmaList
+}
+
 // Parsers returns the parsers.
 func (f *Feature) Parsers() []feature.Parser {
 	return []feature.Parser{NewParser()}
@@ -44,5 +51,10 @@ func (f *Feature) Executors() []feature.Executor {
 	return []feature.Executor{NewExecutor(f.featureRegistry, f.karmaMap, f.modelHelper, f.gist)}
 }
 
+// Actions returns nothing.
+func (f *Feature) Actions() []feature.Action {
+	return []feature.Action{}
+}
+
 // OnInitialLoad does nothing.
 func (f *Feature) OnInitialLoad(s api.DiscordSession) error { return nil }
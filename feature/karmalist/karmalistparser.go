@@ -3,9 +3,12 @@ package karmalist
 import (
 	"errors"
 	"log"
+	"strconv"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/jakevoytko/crbot/model"
+	"github.com/jakevoytko/crbot/util"
 )
 
 // Parser parses ?karmalist commands.
@@ -23,7 +26,14 @@ func (p *Parser) GetName() string {
 
 const (
 	// MsgHelpKarmaList is the help text for ?karmalist
-	MsgHelpKarmaList = "Type `?karmalist` to get the URL of a hastebin with all them karma"
+	MsgHelpKarmaList = "Type `?karmalist` to get the URL of a hastebin with all them karma, `?karmalist week` or `?karmalist month` for recent leaderboards, or `?karmalist top|bottom [n]` for the highest or lowest scores"
+
+	// DefaultLimit is the number of targets shown by ?karmalist top|bottom
+	DefaultLimit = 10
+	// Week is the window of ?karmalist week
+	Week = time.Duration(7*24) * time.Hour
+	// Month is the window of ?karmalist month
+	Month = time.Duration(30*24) * time.Hour
 )
 
 // HelpText explains how to use ?list.
@@ -36,7 +46,57 @@ func (p *Parser) Parse(splitContent []string, m *discordgo.MessageCreate) (*mode
 	if splitContent[0] != p.GetName() {
 		log.Fatal("parseList called with non-list command", errors.New("wat"))
 	}
-	return &model.Command{
-		Type: model.CommandTypeKarmaList,
-	}, nil
+	splitContent = util.CollapseWhitespace(splitContent, 1)
+	splitContent = util.CollapseWhitespace(splitContent, 2)
+	splitContent = util.CollapseWhitespace(splitContent, 3)
+
+	// The full list is uploaded as before.
+	if len(splitContent) < 2 || len(splitContent[1]) == 0 {
+		return &model.Command{
+			Type: model.CommandTypeKarmaList,
+		}, nil
+	}
+
+	help := &model.Command{
+		Type: model.CommandTypeHelp,
+		Help: &model.HelpData{
+			Command: model.CommandNameKarmaList,
+		},
+	}
+
+	switch splitContent[1] {
+	case "week", "month":
+		if len(splitContent) > 2 && len(splitContent[2]) > 0 {
+			return help, nil
+		}
+		window := Week
+		if splitContent[1] == "month" {
+			window = Month
+		}
+		return &model.Command{
+			Type: model.CommandTypeKarmaList,
+			KarmaList: &model.KarmaListData{
+				Window: window,
+			},
+		}, nil
+
+	case "top", "bottom":
+		limit := DefaultLimit
+		if len(splitContent) > 2 && len(splitContent[2]) > 0 {
+			parsedLimit, err := strconv.Atoi(splitContent[2])
+			if err != nil || parsedLimit <= 0 || len(splitContent) > 3 {
+				return help, nil
+			}
+			limit = parsedLimit
+		}
+		return &model.Command{
+			Type: model.CommandTypeKarmaList,
+			KarmaList: &model.KarmaListData{
+				Bottom: splitContent[1] == "bottom",
+				Limit:  limit,
+			},
+		}, nil
+	}
+
+	return help, nil
 }
@@ -2,11 +2,16 @@ package karmalist
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
 	"math"
 	"sort"
 	"strconv"
+	"time"
 
+	"github.com/jakevoytko/crbot/feature/karma"
 	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
 	stringmap "github.com/jakevoytko/go-stringmap"
 )
 
@@ -16,23 +21,59 @@ import (
 // loved things. Karma would need an overhaul to list users vs other since users
 // are stored by plain text Discord username with no differentiation
 type ModelHelper struct {
-	KarmaMap stringmap.StringMap
+	KarmaMap        stringmap.StringMap
+	KarmaHistoryMap stringmap.StringMap
+	UTCClock        model.UTCClock
+	// DecayHalfLife is the half-life of decayed scores. Decayed scores are only
+	// listed when it is positive.
+	DecayHalfLife time.Duration
 }
 
 // NewModelHelper works as advertised.
-func NewModelHelper(stringMap stringmap.StringMap) *ModelHelper {
+func NewModelHelper(stringMap, karmaHistoryMap stringmap.StringMap, utcClock model.UTCClock, decayHalfLife time.Duration) *ModelHelper {
 	return &ModelHelper{
-		KarmaMap: stringMap,
+		KarmaMap:        stringMap,
+		KarmaHistoryMap: karmaHistoryMap,
+		UTCClock:        utcClock,
+		DecayHalfLife:   decayHalfLife,
 	}
 }
 
+// ForGuild returns a helper for the karma of a single guild.
+func (h *ModelHelper) ForGuild(guildID model.Snowflake) *ModelHelper {
+	return NewModelHelper(karma.NewGuildMap(h.KarmaMap, guildID), karma.NewGuildMap(h.KarmaHistoryMap, guildID), h.UTCClock, h.DecayHalfLife)
+}
+
 const (
 	// MsgKarmaMapFailed is an error string for when the Karma map fails
 	MsgKarmaMapFailed = "error reading karma map"
+	// MsgLeaderboardFailed is a user-visible string for when the leaderboard
+	// can't be read
+	MsgLeaderboardFailed = "Couldn't read the karma leaderboard. Try again later."
 	// MsgNoKarma is an error string for when karma hasn't been stored yet.
 	MsgNoKarma = "nothing has accumulated karma"
+	// MsgNoKarmaInWindow is a user-visible string for when no karma was given recently.
+	MsgNoKarmaInWindow = "No karma has been given in the past %v days"
+	// MsgLeaderboardWindow is a user-visible header for a recent karma leaderboard
+	MsgLeaderboardWindow = "Karma leaderboard for the past %v days:"
+	// MsgLeaderboardTop is a user-visible header for the highest karma scores
+	MsgLeaderboardTop = "Top %v karma targets:"
+	// MsgLeaderboardBottom is a user-visible header for the lowest karma scores
+	MsgLeaderboardBottom = "Bottom %v karma targets:"
+	// MsgLeaderboardEntry is a single line of a leaderboard
+	MsgLeaderboardEntry = "%v. %v: %v"
+	// MsgDecayedSuffix follows a raw score when decayed scores are enabled
+	MsgDecayedSuffix = " (%.1f after decay)"
 )
 
+// Score is a single target's karma for a leaderboard. Decayed only counts
+// karma with recorded history.
+type Score struct {
+	Target  string
+	Karma   int
+	Decayed float64
+}
+
 // GenerateList returns a string of all the user:karma pairs in the map sorted by
 // magnitude. If there is no karma, it returns an error string.
 func (h *ModelHelper) GenerateList() string {
@@ -44,6 +85,14 @@ func (h *ModelHelper) GenerateList() string {
 		return MsgNoKarma
 	}
 
+	var decayed map[string]float64
+	if h.DecayHalfLife > 0 {
+		decayed, err = h.decayedScores()
+		if err != nil {
+			log.Fatal(MsgKarmaMapFailed, err)
+		}
+	}
+
 	type sortableKarma struct {
 		displayKarma string
 		absKarma     int
@@ -53,6 +102,9 @@ func (h *ModelHelper) GenerateList() string {
 	// Sort karma by absolute value so that stronger feelings are at the top of the list
 	for k, v := range all {
 		displayKarma := k + ": " + v
+		if decayed != nil {
+			displayKarma = displayKarma + fmt.Sprintf(MsgDecayedSuffix, decayed[k])
+		}
 		floatKarma, _ := strconv.ParseFloat(v, 32)
 		absKarma := int(math.Abs(floatKarma))
 		karmaStore = append(karmaStore, sortableKarma{displayKarma, absKarma})
@@ -80,3 +132,151 @@ func (h *ModelHelper) GenerateList() string {
 
 	return buffer.String()
 }
+
+// GenerateLeaderboard returns a ranked leaderboard described by the given
+// data. Windowed leaderboards are built from karma history, so karma given
+// before history was recorded only counts towards all-time leaderboards.
+func (h *ModelHelper) GenerateLeaderboard(data *model.KarmaListData) (string, error) {
+	var scores []Score
+	var err error
+	if data.Window > 0 {
+		scores, err = h.ScoresSince(h.UTCClock.Now().Add(-data.Window))
+	} else {
+		scores, err = h.AllTimeScores()
+	}
+	if err != nil {
+		return "", err
+	}
+
+	days := int(data.Window / (time.Duration(24) * time.Hour))
+	if len(scores) == 0 {
+		if data.Window > 0 {
+			return fmt.Sprintf(MsgNoKarmaInWindow, days), nil
+		}
+		return MsgNoKarma, nil
+	}
+
+	// Rank highest first and then alphabetically, so that the same ordering is
+	// returned regardless of the ordering returned from redis.
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Karma != scores[j].Karma {
+			if data.Bottom {
+				return scores[i].Karma < scores[j].Karma
+			}
+			return scores[i].Karma > scores[j].Karma
+		}
+		return scores[i].Target < scores[j].Target
+	})
+	if data.Limit > 0 && len(scores) > data.Limit {
+		scores = scores[:data.Limit]
+	}
+
+	header := fmt.Sprintf(MsgLeaderboardTop, len(scores))
+	if data.Window > 0 {
+		header = fmt.Sprintf(MsgLeaderboardWindow, days)
+	} else if data.Bottom {
+		header = fmt.Sprintf(MsgLeaderboardBottom, len(scores))
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString(header)
+	for i, score := range scores {
+		buffer.WriteString("\n")
+		buffer.WriteString(fmt.Sprintf(MsgLeaderboardEntry, i+1, score.Target, score.Karma))
+		if h.DecayHalfLife > 0 {
+			buffer.WriteString(fmt.Sprintf(MsgDecayedSuffix, score.Decayed))
+		}
+	}
+	return buffer.String(), nil
+}
+
+// AllTimeScores returns the stored karma total of every target. Totals that
+// aren't numbers are skipped.
+func (h *ModelHelper) AllTimeScores() ([]Score, error) {
+	all, err := h.KarmaMap.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	decayed, err := h.decayedScores()
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make([]Score, 0, len(all))
+	for target, value := range all {
+		karma, err := strconv.Atoi(value)
+		if err != nil {
+			log.Info("Skipping unparsable karma for "+target, err)
+			continue
+		}
+		scores = append(scores, Score{Target: target, Karma: karma, Decayed: decayed[target]})
+	}
+	return scores, nil
+}
+
+// ScoresSince returns the net karma of every target that received karma after
+// the given time.
+func (h *ModelHelper) ScoresSince(since time.Time) ([]Score, error) {
+	histories, err := h.histories()
+	if err != nil {
+		return nil, err
+	}
+
+	now := h.UTCClock.Now()
+	scores := []Score{}
+	for _, history := range histories {
+		found := false
+		score := Score{Target: history.Target}
+		for i := range history.Events {
+			event := &history.Events[i]
+			if event.Timestamp.After(since) {
+				found = true
+				score.Karma += event.Delta
+				score.Decayed += event.Decayed(now, h.DecayHalfLife)
+			}
+		}
+		if found {
+			scores = append(scores, score)
+		}
+	}
+	return scores, nil
+}
+
+// decayedScores returns the decayed karma of every target with recorded
+// history.
+func (h *ModelHelper) decayedScores() (map[string]float64, error) {
+	histories, err := h.histories()
+	if err != nil {
+		return nil, err
+	}
+
+	now := h.UTCClock.Now()
+	decayed := make(map[string]float64, len(histories))
+	for _, history := range histories {
+		decayed[history.Target] = history.Decayed(now, h.DecayHalfLife)
+	}
+	return decayed, nil
+}
+
+// histories returns every stored karma history.
+func (h *ModelHelper) histories() ([]*model.KarmaHistory, error) {
+	keys, err := h.KarmaHistoryMap.ScanKeys(karma.RedisKarmaHistory)
+	if err != nil {
+		return nil, err
+	}
+
+	histories := make([]*model.KarmaHistory, 0, len(keys))
+	for _, key := range keys {
+		serializedHistory, err := h.KarmaHistoryMap.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		var history model.KarmaHistory
+		if err := json.Unmarshal([]byte(serializedHistory), &history); err != nil {
+			return nil, err
+		}
+		histories = append(histories, &history)
+	}
+	return histories, nil
+}
@@ -32,6 +32,11 @@ func (e *CustomExecutor) PublicOnly() bool {
 	return false
 }
 
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *CustomExecutor) ModeratorOnly() bool {
+	return false
+}
+
 // Detect a single-image giphy album so that it can be rewritten. Giphy albums
 // do not get unfurled properly in Discord mobile.
 var giphyRegexp = regexp.MustCompile(`^https://([[:alnum:]]+.)*giphy.com/media/([[:alnum:]]+)/giphy.gif$`)
@@ -60,17 +65,21 @@ func (e *CustomExecutor) Execute(s api.DiscordSession, channel model.Snowflake,
 		log.Fatal("Error reading custom response", err)
 	}
 
-	// Perform command substitutions.
+	s.ChannelMessageSend(channel.Format(), FormatResponse(response, command.Custom.Args))
+}
+
+// FormatResponse substitutes the args into a learned response, and rewrites
+// giphy links so that they unfurl.
+func FormatResponse(response, args string) string {
 	if strings.Contains(response, "$1") {
-		if command.Custom.Args == "" {
-			response = MsgCustomNeedsArgs
-		} else {
-			response = strings.Replace(response, "$1", command.Custom.Args, 4)
+		if args == "" {
+			return MsgCustomNeedsArgs
 		}
-	} else if matches := giphyRegexp.FindStringSubmatch(response); len(matches) > 2 {
+		return strings.Replace(response, "$1", args, 4)
+	}
+	if matches := giphyRegexp.FindStringSubmatch(response); len(matches) > 2 {
 		url := matches[2]
-		response = fmt.Sprintf(MsgGiphyLink, url)
+		return fmt.Sprintf(MsgGiphyLink, url)
 	}
-
-	s.ChannelMessageSend(channel.Format(), response)
+	return response
 }
@@ -30,6 +30,11 @@ func (f *CustomLearnExecutor) PublicOnly() bool {
 	return false
 }
 
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (f *CustomLearnExecutor) ModeratorOnly() bool {
+	return false
+}
+
 // Execute replies over the given channel with a help message.
 func (f *CustomLearnExecutor) Execute(s api.DiscordSession, channel model.Snowflake, command *model.Command) {
 	if command.Learn == nil {
@@ -2,7 +2,9 @@ package learn
 
 import (
 	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/audit"
 	"github.com/jakevoytko/crbot/feature"
+	"github.com/jakevoytko/crbot/model"
 	stringmap "github.com/jakevoytko/go-stringmap"
 )
 
@@ -10,16 +12,23 @@ import (
 type Feature struct {
 	featureRegistry *feature.Registry
 	commandMap      stringmap.StringMap
+	auditBus        *audit.Bus
 }
 
 // NewFeature returns a new Feature.
-func NewFeature(featureRegistry *feature.Registry, commandMap stringmap.StringMap) *Feature {
+func NewFeature(featureRegistry *feature.Registry, commandMap stringmap.StringMap, auditBus *audit.Bus) *Feature {
 	return &Feature{
 		featureRegistry: featureRegistry,
 		commandMap:      commandMap,
+		auditBus:        auditBus,
 	}
 }
 
+// GetName returns the name that channels enable and disable the feature by.
+func (f *Feature) GetName() string {
+	return model.FeatureNameLearn
+}
+
 // Parsers gets the learn feature parsers.
 func (f *Feature) Parsers() []feature.Parser {
 	return []feature.Parser{
@@ -43,11 +52,16 @@ func (f *Feature) FallbackParser() feature.Parser {
 func (f *Feature) Executors() []feature.Executor {
 	return []feature.Executor{
 		NewCustomLearnExecutor(f.commandMap),
-		NewUnlearnExecutor(f.commandMap),
+		NewUnlearnExecutor(f.commandMap, f.auditBus),
 		NewCustomExecutor(f.commandMap),
 	}
 }
 
+// Actions returns the operations that votes may carry out.
+func (f *Feature) Actions() []feature.Action {
+	return []feature.Action{NewUnlearnAction(f.featureRegistry, f.commandMap, f.auditBus)}
+}
+
 // OnInitialLoad does nothing.
 func (f *Feature) OnInitialLoad(s api.DiscordSession) error { return nil }
 
@@ -66,6 +80,10 @@ const (
 	MsgLearnFail = "I already know ?%s"
 	// MsgLearnSuccess indicates that the bot learned the command
 	MsgLearnSuccess = "Learned about %s"
+	// MsgHelpUnlearnAction is the help text for votes on the unlearn action
+	MsgHelpUnlearnAction = "Type `?vote action unlearn <call>` to vote on forgetting a user-defined command."
+	// MsgUnlearnActionDescription describes a vote on the unlearn action
+	MsgUnlearnActionDescription = "Unlearn `?%s`"
 	// MsgUnlearnFail indicates that the user attempted to unlearn an unlearnable command
 	MsgUnlearnFail = "I can't unlearn `?%s`"
 	// MsgUnlearnMustBePublic indicates that the user tried to unlearn in a private channel
@@ -0,0 +1,83 @@
+package learn
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/audit"
+	"github.com/jakevoytko/crbot/feature"
+	"github.com/jakevoytko/crbot/model"
+	stringmap "github.com/jakevoytko/go-stringmap"
+)
+
+// UnlearnAction lets a vote unlearn a custom command.
+type UnlearnAction struct {
+	featureRegistry *feature.Registry
+	commandMap      stringmap.StringMap
+	auditBus        *audit.Bus
+}
+
+// NewUnlearnAction works as advertised.
+func NewUnlearnAction(featureRegistry *feature.Registry, commandMap stringmap.StringMap, auditBus *audit.Bus) *UnlearnAction {
+	return &UnlearnAction{
+		featureRegistry: featureRegistry,
+		commandMap:      commandMap,
+		auditBus:        auditBus,
+	}
+}
+
+// ActionNameUnlearn is the name used to call a vote on unlearning a command.
+const ActionNameUnlearn = "unlearn"
+
+// GetName returns the name of the action.
+func (a *UnlearnAction) GetName() string {
+	return ActionNameUnlearn
+}
+
+// Describe checks that the call can be unlearned.
+func (a *UnlearnAction) Describe(args string) (string, error) {
+	call, err := a.learnedCall(args)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(MsgUnlearnActionDescription, call), nil
+}
+
+// Execute unlearns the call. The call is checked again, since it may have been
+// unlearned while the vote was running.
+func (a *UnlearnAction) Execute(s api.DiscordSession, channelID model.Snowflake, args string) (string, error) {
+	call, err := a.learnedCall(args)
+	if err != nil {
+		return "", err
+	}
+	if err := a.commandMap.Delete(call); err != nil {
+		return "", err
+	}
+	a.auditBus.Publish(s, &audit.Event{
+		Type:      audit.EventUnlearn,
+		ChannelID: channelID,
+		Command:   "?" + call,
+		Details:   "by vote",
+	})
+	return fmt.Sprintf(MsgUnlearnSuccess, call), nil
+}
+
+// learnedCall returns the call named by the args, or a user-visible error if it
+// isn't a learned command.
+func (a *UnlearnAction) learnedCall(args string) (string, error) {
+	call := strings.TrimPrefix(strings.TrimSpace(args), "?")
+	if !regexp.MustCompile("^[[:alnum:]][^[:space:]]*$").MatchString(call) {
+		return "", errors.New(MsgHelpUnlearnAction)
+	}
+	has, err := a.commandMap.Has(call)
+	if err != nil {
+		return "", err
+	}
+	if !has || a.featureRegistry.IsInvokable(call) {
+		return "", fmt.Errorf(MsgUnlearnFail, call)
+	}
+	return call, nil
+}
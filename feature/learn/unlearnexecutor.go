@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/audit"
 	"github.com/jakevoytko/crbot/log"
 	"github.com/jakevoytko/crbot/model"
 	stringmap "github.com/jakevoytko/go-stringmap"
@@ -13,11 +14,15 @@ import (
 // UnlearnExecutor attempts to unlearn a custom command and returns the result to the user.
 type UnlearnExecutor struct {
 	commandMap stringmap.StringMap
+	auditBus   *audit.Bus
 }
 
 // NewUnlearnExecutor works as advertised.
-func NewUnlearnExecutor(commandMap stringmap.StringMap) *UnlearnExecutor {
-	return &UnlearnExecutor{commandMap: commandMap}
+func NewUnlearnExecutor(commandMap stringmap.StringMap, auditBus *audit.Bus) *UnlearnExecutor {
+	return &UnlearnExecutor{
+		commandMap: commandMap,
+		auditBus:   auditBus,
+	}
 }
 
 // GetType returns the type of this feature.
@@ -30,6 +35,11 @@ func (e *UnlearnExecutor) PublicOnly() bool {
 	return true
 }
 
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *UnlearnExecutor) ModeratorOnly() bool {
+	return false
+}
+
 // Execute replies over the given channel indicating successful unlearning, or
 // failure to unlearn.
 func (e *UnlearnExecutor) Execute(s api.DiscordSession, channel model.Snowflake, command *model.Command) {
@@ -53,6 +63,18 @@ func (e *UnlearnExecutor) Execute(s api.DiscordSession, channel model.Snowflake,
 		log.Fatal("Unsuccessful unlearning a key; Dying since it might work with a restart", err)
 	}
 
+	event := &audit.Event{
+		Type:      audit.EventUnlearn,
+		ChannelID: channel,
+		Command:   "?" + command.Unlearn.Call,
+	}
+	if command.Author != nil {
+		if userID, err := model.ParseSnowflake(command.Author.ID); err == nil {
+			event.UserID = userID
+		}
+	}
+	e.auditBus.Publish(s, event)
+
 	// Send ack.
 	s.ChannelMessageSend(channel.Format(), fmt.Sprintf(MsgUnlearnSuccess, command.Unlearn.Call))
 }
@@ -47,6 +47,11 @@ func (e *Executor) PublicOnly() bool {
 	return false
 }
 
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *Executor) ModeratorOnly() bool {
+	return false
+}
+
 // Execute uploads the command list to github and pings the gist link in chat.
 func (e *Executor) Execute(s api.DiscordSession, channel model.Snowflake, command *model.Command) {
 	builtins := e.featureRegistry.GetInvokableFeatureNames()
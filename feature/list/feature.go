@@ -3,6 +3,7 @@ package list
 import (
 	"github.com/jakevoytko/crbot/api"
 	"github.com/jakevoytko/crbot/feature"
+	"github.com/jakevoytko/crbot/model"
 	stringmap "github.com/jakevoytko/go-stringmap"
 )
 
@@ -26,6 +27,11 @@ func NewFeature(
 	}
 }
 
+// GetName returns the name that channels enable and disable the feature by.
+func (f *Feature) GetName() string {
+	return model.FeatureNameList
+}
+
 // Parsers returns the parsers.
 func (f *Feature) Parsers() []feature.Parser {
 	return []feature.Parser{NewParser()}
@@ -46,5 +52,10 @@ func (f *Feature) Executors() []feature.Executor {
 	return []feature.Executor{NewExecutor(f.featureRegistry, f.commandMap, f.gist)}
 }
 
+// Actions returns nothing.
+func (f *Feature) Actions() []feature.Action {
+	return []feature.Action{}
+}
+
 // OnInitialLoad does nothing.
 func (f *Feature) OnInitialLoad(s api.DiscordSession) error { return nil }
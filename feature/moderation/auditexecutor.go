@@ -0,0 +1,90 @@
+package moderation
+
+import (
+	"strings"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/audit"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// AuditExecutor searches the audit log file.
+type AuditExecutor struct {
+	auditLog *audit.FileSink
+}
+
+// NewAuditExecutor works as advertised. The audit log may be nil if the config
+// doesn't name a file.
+func NewAuditExecutor(auditLog *audit.FileSink) *AuditExecutor {
+	return &AuditExecutor{
+		auditLog: auditLog,
+	}
+}
+
+// GetType returns the type.
+func (e *AuditExecutor) GetType() int {
+	return model.CommandTypeAudit
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private channel.
+func (e *AuditExecutor) PublicOnly() bool {
+	return false
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *AuditExecutor) ModeratorOnly() bool {
+	return true
+}
+
+const (
+	// MsgAuditNotConfigured is a user-visible string for searching the audit log without a file
+	MsgAuditNotConfigured = "The audit log isn't being recorded to a file, so it can't be searched."
+	// MsgAuditEmpty is a user-visible string for an audit search without results
+	MsgAuditEmpty = "No audit entries found."
+	// MsgAuditFailed is a user-visible string for an audit search that failed
+	MsgAuditFailed = "Couldn't search the audit log. Try again later."
+)
+
+// AuditQueryLimit is how many entries ?audit shows, most recent last.
+const AuditQueryLimit = 10
+
+// Execute replies over the given channel with the matching audit entries.
+func (e *AuditExecutor) Execute(s api.DiscordSession, channel model.Snowflake, command *model.Command) {
+	if e.auditLog == nil {
+		if _, err := s.ChannelMessageSend(channel.Format(), MsgAuditNotConfigured); err != nil {
+			log.Info("Failed to send audit message", err)
+		}
+		return
+	}
+
+	filter := &model.AuditData{}
+	if command.Audit != nil {
+		filter = command.Audit
+	}
+	events, err := e.auditLog.Query(func(event *audit.Event) bool {
+		if filter.UserID > 0 && !event.Involves(filter.UserID) {
+			return false
+		}
+		return len(filter.Command) == 0 || event.IsAbout(filter.Command)
+	}, AuditQueryLimit)
+	if err != nil {
+		log.Info("Error searching the audit log", err)
+		if _, err := s.ChannelMessageSend(channel.Format(), MsgAuditFailed); err != nil {
+			log.Info("Failed to send audit message", err)
+		}
+		return
+	}
+
+	message := MsgAuditEmpty
+	if len(events) > 0 {
+		lines := make([]string, 0, len(events))
+		for _, event := range events {
+			lines = append(lines, event.Format())
+		}
+		message = strings.Join(lines, "\n")
+	}
+	if _, err := s.ChannelMessageSend(channel.Format(), message); err != nil {
+		log.Info("Failed to send audit message", err)
+	}
+}
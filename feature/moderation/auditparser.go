@@ -0,0 +1,64 @@
+package moderation
+
+import (
+	"errors"
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jakevoytko/crbot/model"
+	"github.com/jakevoytko/crbot/util"
+)
+
+// AuditParser parses ?audit commands.
+type AuditParser struct{}
+
+// NewAuditParser works as advertised.
+func NewAuditParser() *AuditParser {
+	return &AuditParser{}
+}
+
+// GetName returns the named type of this feature.
+func (p *AuditParser) GetName() string {
+	return model.CommandNameAudit
+}
+
+const (
+	// MsgHelpAudit is the help text for ?audit
+	MsgHelpAudit = "Moderators can type `?audit` to see the latest moderation actions, `?audit @user` to see the ones involving a user, or `?audit <command>` to see the ones involving a command, like `?audit unlearn`."
+)
+
+// HelpText explains how to use ?audit.
+func (p *AuditParser) HelpText(command string) (string, error) {
+	return MsgHelpAudit, nil
+}
+
+// Parse parses the given audit command.
+func (p *AuditParser) Parse(splitContent []string, m *discordgo.MessageCreate) (*model.Command, error) {
+	if splitContent[0] != p.GetName() {
+		log.Fatal("AuditParser.Parse called with non-audit command", errors.New("wat"))
+	}
+
+	splitContent = util.CollapseWhitespace(splitContent, 1)
+	splitContent = util.CollapseWhitespace(splitContent, 2)
+	if len(splitContent) > 2 {
+		return &model.Command{
+			Type: model.CommandTypeHelp,
+			Help: &model.HelpData{
+				Command: p.GetName(),
+			},
+		}, nil
+	}
+
+	data := &model.AuditData{}
+	if len(splitContent) == 2 {
+		if userID := parseUserID(splitContent[1]); userID > 0 {
+			data.UserID = userID
+		} else {
+			data.Command = splitContent[1]
+		}
+	}
+	return &model.Command{
+		Type:  model.CommandTypeAudit,
+		Audit: data,
+	}, nil
+}
@@ -2,27 +2,52 @@ package moderation
 
 import (
 	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/audit"
 	"github.com/jakevoytko/crbot/config"
 	"github.com/jakevoytko/crbot/feature"
+	"github.com/jakevoytko/crbot/model"
+	stringmap "github.com/jakevoytko/go-stringmap"
 )
 
 // Feature registers feature-specific things for moderation.
 type Feature struct {
 	featureRegistry *feature.Registry
+	modelHelper     *ModelHelper
+	commandMap      stringmap.StringMap
+	auditBus        *audit.Bus
+	auditLog        *audit.FileSink
+	commandChannel  chan<- *model.Command
+	utcTimer        model.UTCTimer
+	utcClock        model.UTCClock
 	config          *config.Config
 }
 
 // NewFeature returns a new Feature.
-func NewFeature(featureRegistry *feature.Registry, config *config.Config) *Feature {
+func NewFeature(featureRegistry *feature.Registry, moderationMap, commandMap stringmap.StringMap, auditBus *audit.Bus, auditLog *audit.FileSink, clock model.UTCClock, timer model.UTCTimer, commandChannel chan<- *model.Command, config *config.Config) *Feature {
 	return &Feature{
 		featureRegistry: featureRegistry,
+		modelHelper:     NewModelHelper(moderationMap, clock),
+		commandMap:      commandMap,
+		auditBus:        auditBus,
+		auditLog:        auditLog,
+		commandChannel:  commandChannel,
+		utcTimer:        timer,
+		utcClock:        clock,
 		config:          config,
 	}
 }
 
+// GetName returns the name that channels enable and disable the feature by.
+func (f *Feature) GetName() string {
+	return model.FeatureNameModeration
+}
+
 // Parsers returns the parsers.
 func (f *Feature) Parsers() []feature.Parser {
 	return []feature.Parser{
+		NewAuditParser(),
+		NewMuteParser(),
+		NewMutesParser(),
 		NewRickListInfoParser(),
 	}
 }
@@ -30,7 +55,9 @@ func (f *Feature) Parsers() []feature.Parser {
 // CommandInterceptors returns command interceptors.
 func (f *Feature) CommandInterceptors() []feature.CommandInterceptor {
 	return []feature.CommandInterceptor{
-		NewRickListCommandInterceptor(f.config),
+		NewMuteCommandInterceptor(f.modelHelper, f.config),
+		NewSpamCommandInterceptor(f.utcClock, f.config),
+		NewRickListCommandInterceptor(f.modelHelper, f.auditBus),
 	}
 }
 
@@ -42,10 +69,49 @@ func (f *Feature) FallbackParser() feature.Parser {
 // Executors gets the executors.
 func (f *Feature) Executors() []feature.Executor {
 	return []feature.Executor{
-		NewRickListExecutor(),
-		NewRickListInfoExecutor(f.config),
+		NewAuditExecutor(f.auditLog),
+		NewMuteExecutor(f.modelHelper, f.auditBus, f.utcTimer, f.commandChannel, f.config),
+		NewMuteExpireExecutor(f.modelHelper, f.auditBus),
+		NewMutesExecutor(f.modelHelper, f.utcClock),
+		NewRickListExecutor(f.modelHelper, f.commandMap),
+		NewRickListInfoExecutor(f.modelHelper, f.utcClock),
+		NewRickListAddExecutor(f.modelHelper, f.auditBus, f.utcTimer, f.commandChannel),
+		NewRickListRemoveExecutor(f.modelHelper, f.auditBus),
+		NewRickListExpireExecutor(f.modelHelper, f.auditBus),
+		NewRickListResponsesExecutor(f.modelHelper),
+		NewRickListResponseAddExecutor(f.modelHelper, f.commandMap),
+		NewRickListResponseRemoveExecutor(f.modelHelper),
+		NewShadowCustomExecutor(f.modelHelper),
+		NewShadowLearnExecutor(f.modelHelper),
+		NewSpamRickListExecutor(f.modelHelper, f.auditBus, f.utcTimer, f.commandChannel, f.config),
+		NewSpamWarningExecutor(f.auditBus),
 	}
 }
 
-// OnInitialLoad does nothing.
-func (f *Feature) OnInitialLoad(s api.DiscordSession) error { return nil }
+// Actions returns the actions that votes can carry out.
+func (f *Feature) Actions() []feature.Action {
+	return []feature.Action{NewRickListAction(f.modelHelper, f.auditBus, f.utcTimer, f.commandChannel, f.config)}
+}
+
+// OnInitialLoad seeds the ricklist from the config, and restarts the timers of
+// timed entries and mutes.
+func (f *Feature) OnInitialLoad(s api.DiscordSession) error {
+	if _, err := f.modelHelper.SeedRickList(f.config.RickList); err != nil {
+		return err
+	}
+	entries, err := f.modelHelper.TimedRickListEntries()
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		scheduleRickListExpiry(f.utcClock, f.utcTimer, f.commandChannel, entry)
+	}
+	mutes, err := f.modelHelper.AllMutes()
+	if err != nil {
+		return err
+	}
+	for _, mute := range mutes {
+		scheduleMuteExpiry(f.utcClock, f.utcTimer, f.commandChannel, mute)
+	}
+	return nil
+}
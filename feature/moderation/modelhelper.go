@@ -0,0 +1,472 @@
+package moderation
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jakevoytko/crbot/model"
+	stringmap "github.com/jakevoytko/go-stringmap"
+)
+
+// ModelHelper provides helpers for working with moderation storage.
+type ModelHelper struct {
+	stringMap stringmap.StringMap
+	utcClock  model.UTCClock
+}
+
+// NewModelHelper works as advertised.
+func NewModelHelper(stringMap stringmap.StringMap, utcClock model.UTCClock) *ModelHelper {
+	return &ModelHelper{
+		stringMap: stringMap,
+		utcClock:  utcClock,
+	}
+}
+
+const (
+	// KeyRickListEntry is the key/value store key for a user's ricklist entry
+	KeyRickListEntry = "ricklist-user-%v"
+	// RedisRickListEntry matches every ricklist entry
+	RedisRickListEntry = "ricklist-user-*"
+	// KeyRickListSeeded is the key/value store key for the users that were
+	// seeded from the config
+	KeyRickListSeeded = "ricklist-seeded"
+	// KeyRickListResponses is the key/value store key for the responses that
+	// every ricklisted user gets
+	KeyRickListResponses = "ricklist-responses"
+	// KeyRickListUserResponses is the key/value store key for the responses
+	// that one ricklisted user gets
+	KeyRickListUserResponses = "ricklist-responses-%v"
+	// KeyShadowLearn is the key/value store key for a call that a shadowbanned
+	// user learned
+	KeyShadowLearn = "shadow-learn-%v-%v"
+	// RedisShadowLearn matches every call that a shadowbanned user learned
+	RedisShadowLearn = "shadow-learn-%v-*"
+	// KeyMuteUser is the key/value store key for a muted user
+	KeyMuteUser = "mute-user-%v"
+	// KeyMuteChannel is the key/value store key for a muted channel
+	KeyMuteChannel = "mute-channel-%v"
+	// RedisMute matches every mute
+	RedisMute = "mute-*"
+)
+
+// SeedRickList adds the config's ricklisted users to storage. Each user is
+// only seeded once, so users that a moderator removed stay removed. Returns
+// the number of users that were added.
+func (h *ModelHelper) SeedRickList(userIDs []model.Snowflake) (int, error) {
+	seeded := []model.Snowflake{}
+	ok, err := h.stringMap.Has(KeyRickListSeeded)
+	if err != nil {
+		return 0, err
+	}
+	if ok {
+		serializedSeeded, err := h.stringMap.Get(KeyRickListSeeded)
+		if err != nil {
+			return 0, err
+		}
+		if err := json.Unmarshal([]byte(serializedSeeded), &seeded); err != nil {
+			return 0, err
+		}
+	}
+
+	added := 0
+	for _, userID := range userIDs {
+		if containsSnowflake(seeded, userID) {
+			continue
+		}
+		entry, err := h.rickListEntry(userID)
+		if err != nil {
+			return added, err
+		}
+		if entry == nil {
+			entry = &model.RickListEntry{
+				UserID:         userID,
+				TimestampAdded: h.utcClock.Now(),
+			}
+			if err := h.writeRickListEntry(entry); err != nil {
+				return added, err
+			}
+			added++
+		}
+		seeded = append(seeded, userID)
+	}
+
+	serializedSeeded, err := json.Marshal(seeded)
+	if err != nil {
+		return added, err
+	}
+	return added, h.stringMap.Set(KeyRickListSeeded, string(serializedSeeded))
+}
+
+// IsRickListed returns whether the user is on the ricklist, and their time
+// hasn't run out.
+func (h *ModelHelper) IsRickListed(userID model.Snowflake) (bool, error) {
+	entry, err := h.ActiveRickListEntry(userID)
+	return entry != nil, err
+}
+
+// ActiveRickListEntry returns the user's ricklist entry, or nil if they aren't
+// on the ricklist or their time ran out.
+func (h *ModelHelper) ActiveRickListEntry(userID model.Snowflake) (*model.RickListEntry, error) {
+	entry, err := h.rickListEntry(userID)
+	if err != nil || entry == nil || entry.IsExpired(h.utcClock.Now()) {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// RickList returns the users on the ricklist whose time hasn't run out, oldest
+// first.
+func (h *ModelHelper) RickList() ([]*model.RickListEntry, error) {
+	now := h.utcClock.Now()
+	return h.rickListEntries(func(entry *model.RickListEntry) bool {
+		return !entry.IsExpired(now)
+	})
+}
+
+// TimedRickListEntries returns the timed entries on the ricklist, oldest first.
+// This includes entries whose time ran out but that weren't removed yet.
+func (h *ModelHelper) TimedRickListEntries() ([]*model.RickListEntry, error) {
+	return h.rickListEntries(func(entry *model.RickListEntry) bool {
+		return entry.Expires()
+	})
+}
+
+// rickListEntries returns the stored entries that match the filter, oldest
+// first.
+func (h *ModelHelper) rickListEntries(filter func(*model.RickListEntry) bool) ([]*model.RickListEntry, error) {
+	keys, err := h.stringMap.ScanKeys(RedisRickListEntry)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []*model.RickListEntry{}
+	for _, key := range keys {
+		entry, err := h.readRickListEntry(key)
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil && filter(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].TimestampAdded.Equal(entries[j].TimestampAdded) {
+			return entries[i].TimestampAdded.Before(entries[j].TimestampAdded)
+		}
+		return entries[i].UserID < entries[j].UserID
+	})
+	return entries, nil
+}
+
+// AddToRickList puts the user on the ricklist, replacing any entry they
+// already had. A 0 duration keeps them there until they are removed.
+func (h *ModelHelper) AddToRickList(userID, addedBy, channelID model.Snowflake, duration time.Duration, shadowban bool) (*model.RickListEntry, error) {
+	now := h.utcClock.Now()
+	entry := &model.RickListEntry{
+		UserID:         userID,
+		AddedBy:        addedBy,
+		TimestampAdded: now,
+		ChannelID:      channelID,
+		Shadowban:      shadowban,
+	}
+	if duration > 0 {
+		entry.TimestampExpires = now.Add(duration)
+	}
+	if err := h.writeRickListEntry(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// RemoveFromRickList takes the user off the ricklist. Returns whether they were
+// on it.
+func (h *ModelHelper) RemoveFromRickList(userID model.Snowflake) (bool, error) {
+	listed, err := h.IsRickListed(userID)
+	if err != nil {
+		return false, err
+	}
+	ok, err := h.stringMap.Has(fmt.Sprintf(KeyRickListEntry, userID))
+	if err != nil || !ok {
+		return false, err
+	}
+	return listed, h.deleteRickListEntry(userID)
+}
+
+// ExpireRickListEntry removes the user's timed entry that expires at the given
+// time. Entries that were replaced since are left alone. Returns the removed
+// entry, or nil.
+func (h *ModelHelper) ExpireRickListEntry(userID model.Snowflake, timestampExpires time.Time) (*model.RickListEntry, error) {
+	entry, err := h.rickListEntry(userID)
+	if err != nil || entry == nil || !entry.TimestampExpires.Equal(timestampExpires) {
+		return nil, err
+	}
+	if err := h.deleteRickListEntry(userID); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// deleteRickListEntry removes the user's entry, along with anything they
+// learned while they were shadowbanned.
+func (h *ModelHelper) deleteRickListEntry(userID model.Snowflake) error {
+	keys, err := h.stringMap.ScanKeys(fmt.Sprintf(RedisShadowLearn, userID))
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := h.stringMap.Delete(key); err != nil {
+			return err
+		}
+	}
+	return h.stringMap.Delete(fmt.Sprintf(KeyRickListEntry, userID))
+}
+
+// ShadowLearn stores a call that only the shadowbanned user can see.
+func (h *ModelHelper) ShadowLearn(userID model.Snowflake, call, response string) error {
+	return h.stringMap.Set(fmt.Sprintf(KeyShadowLearn, userID, call), response)
+}
+
+// ShadowResponse returns the response to a call that the shadowbanned user
+// learned, and whether they learned it.
+func (h *ModelHelper) ShadowResponse(userID model.Snowflake, call string) (string, bool, error) {
+	key := fmt.Sprintf(KeyShadowLearn, userID, call)
+	ok, err := h.stringMap.Has(key)
+	if err != nil || !ok {
+		return "", false, err
+	}
+	response, err := h.stringMap.Get(key)
+	if err != nil {
+		return "", false, err
+	}
+	return response, true, nil
+}
+
+// RickListResponses returns the responses configured for the user, or for
+// every ricklisted user if the user is 0.
+func (h *ModelHelper) RickListResponses(userID model.Snowflake) ([]*model.RickListResponse, error) {
+	responses := []*model.RickListResponse{}
+	key := rickListResponsesKey(userID)
+	ok, err := h.stringMap.Has(key)
+	if err != nil || !ok {
+		return responses, err
+	}
+	serializedResponses, err := h.stringMap.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(serializedResponses), &responses); err != nil {
+		return nil, err
+	}
+	return responses, nil
+}
+
+// PunishmentResponses returns the responses that the ricklisted user may get.
+// These are the user's own responses if they have any, and otherwise the
+// responses for every ricklisted user.
+func (h *ModelHelper) PunishmentResponses(userID model.Snowflake) ([]*model.RickListResponse, error) {
+	responses, err := h.RickListResponses(userID)
+	if err != nil || len(responses) > 0 {
+		return responses, err
+	}
+	return h.RickListResponses(0)
+}
+
+// AddRickListResponse adds a response for the user, or for every ricklisted
+// user if the user is 0.
+func (h *ModelHelper) AddRickListResponse(userID model.Snowflake, response *model.RickListResponse) error {
+	responses, err := h.RickListResponses(userID)
+	if err != nil {
+		return err
+	}
+	return h.writeRickListResponses(userID, append(responses, response))
+}
+
+// RemoveRickListResponse removes the user's response with the given number,
+// counting from 1. Returns whether there was such a response.
+func (h *ModelHelper) RemoveRickListResponse(userID model.Snowflake, number int) (bool, error) {
+	responses, err := h.RickListResponses(userID)
+	if err != nil || number < 1 || number > len(responses) {
+		return false, err
+	}
+	responses = append(responses[:number-1], responses[number:]...)
+	if len(responses) == 0 {
+		return true, h.stringMap.Delete(rickListResponsesKey(userID))
+	}
+	return true, h.writeRickListResponses(userID, responses)
+}
+
+func (h *ModelHelper) writeRickListResponses(userID model.Snowflake, responses []*model.RickListResponse) error {
+	serializedResponses, err := json.Marshal(responses)
+	if err != nil {
+		return err
+	}
+	return h.stringMap.Set(rickListResponsesKey(userID), string(serializedResponses))
+}
+
+func rickListResponsesKey(userID model.Snowflake) string {
+	if userID == 0 {
+		return KeyRickListResponses
+	}
+	return fmt.Sprintf(KeyRickListUserResponses, userID)
+}
+
+// rickListEntry returns the user's stored entry, or nil. Expired entries that
+// weren't cleaned up yet are returned too.
+func (h *ModelHelper) rickListEntry(userID model.Snowflake) (*model.RickListEntry, error) {
+	return h.readRickListEntry(fmt.Sprintf(KeyRickListEntry, userID))
+}
+
+func (h *ModelHelper) readRickListEntry(key string) (*model.RickListEntry, error) {
+	ok, err := h.stringMap.Has(key)
+	if err != nil || !ok {
+		return nil, err
+	}
+	serializedEntry, err := h.stringMap.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	var entry model.RickListEntry
+	if err := json.Unmarshal([]byte(serializedEntry), &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (h *ModelHelper) writeRickListEntry(entry *model.RickListEntry) error {
+	serializedEntry, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return h.stringMap.Set(fmt.Sprintf(KeyRickListEntry, entry.UserID), string(serializedEntry))
+}
+
+// Mute adds the mute, replacing any mute of the same user or channel.
+func (h *ModelHelper) Mute(userID, channelID, addedBy, announceChannelID model.Snowflake, duration time.Duration, reason string) (*model.MuteEntry, error) {
+	now := h.utcClock.Now()
+	entry := &model.MuteEntry{
+		UserID:            userID,
+		ChannelID:         channelID,
+		AddedBy:           addedBy,
+		Reason:            reason,
+		TimestampAdded:    now,
+		TimestampExpires:  now.Add(duration),
+		AnnounceChannelID: announceChannelID,
+	}
+	serializedEntry, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.stringMap.Set(muteKey(userID, channelID), string(serializedEntry)); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// IsMuted returns whether the user or the channel is muted, and the mute's
+// time hasn't run out.
+func (h *ModelHelper) IsMuted(userID, channelID model.Snowflake) (bool, error) {
+	for _, key := range []string{fmt.Sprintf(KeyMuteUser, userID), fmt.Sprintf(KeyMuteChannel, channelID)} {
+		entry, err := h.readMute(key)
+		if err != nil {
+			return false, err
+		}
+		if entry != nil && !entry.IsExpired(h.utcClock.Now()) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Mutes returns the mutes whose time hasn't run out, soonest to expire first.
+func (h *ModelHelper) Mutes() ([]*model.MuteEntry, error) {
+	now := h.utcClock.Now()
+	return h.mutes(func(entry *model.MuteEntry) bool {
+		return !entry.IsExpired(now)
+	})
+}
+
+// AllMutes returns every stored mute, soonest to expire first. This includes
+// mutes whose time ran out but that weren't removed yet.
+func (h *ModelHelper) AllMutes() ([]*model.MuteEntry, error) {
+	return h.mutes(func(entry *model.MuteEntry) bool {
+		return true
+	})
+}
+
+func (h *ModelHelper) mutes(filter func(*model.MuteEntry) bool) ([]*model.MuteEntry, error) {
+	keys, err := h.stringMap.ScanKeys(RedisMute)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []*model.MuteEntry{}
+	for _, key := range keys {
+		entry, err := h.readMute(key)
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil && filter(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].TimestampExpires.Equal(entries[j].TimestampExpires) {
+			return entries[i].TimestampExpires.Before(entries[j].TimestampExpires)
+		}
+		if entries[i].UserID != entries[j].UserID {
+			return entries[i].UserID < entries[j].UserID
+		}
+		return entries[i].ChannelID < entries[j].ChannelID
+	})
+	return entries, nil
+}
+
+// ExpireMute removes the mute of the user or channel that expires at the given
+// time. Mutes that were replaced since are left alone. Returns the removed
+// mute, or nil.
+func (h *ModelHelper) ExpireMute(userID, channelID model.Snowflake, timestampExpires time.Time) (*model.MuteEntry, error) {
+	key := muteKey(userID, channelID)
+	entry, err := h.readMute(key)
+	if err != nil || entry == nil || !entry.TimestampExpires.Equal(timestampExpires) {
+		return nil, err
+	}
+	if err := h.stringMap.Delete(key); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (h *ModelHelper) readMute(key string) (*model.MuteEntry, error) {
+	ok, err := h.stringMap.Has(key)
+	if err != nil || !ok {
+		return nil, err
+	}
+	serializedEntry, err := h.stringMap.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	var entry model.MuteEntry
+	if err := json.Unmarshal([]byte(serializedEntry), &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// muteKey returns the key of the user's mute, or the channel's if the user is 0.
+func muteKey(userID, channelID model.Snowflake) string {
+	if userID > 0 {
+		return fmt.Sprintf(KeyMuteUser, userID)
+	}
+	return fmt.Sprintf(KeyMuteChannel, channelID)
+}
+
+func containsSnowflake(ids []model.Snowflake, id model.Snowflake) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package moderation
+
+import (
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/config"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// MuteCommandInterceptor drops the commands of muted users, and the commands
+// in muted channels.
+type MuteCommandInterceptor struct {
+	modelHelper *ModelHelper
+	config      *config.Config
+}
+
+// NewMuteCommandInterceptor works as advertised.
+func NewMuteCommandInterceptor(modelHelper *ModelHelper, config *config.Config) *MuteCommandInterceptor {
+	return &MuteCommandInterceptor{
+		modelHelper: modelHelper,
+		config:      config,
+	}
+}
+
+// Intercept replaces the command with one that does nothing if its author or
+// channel is muted. If the mutes can't be read, the command goes through, since
+// an error here would stop crbot.
+func (i *MuteCommandInterceptor) Intercept(command *model.Command, s api.DiscordSession) (*model.Command, error) {
+	// Commands without an author come from crbot itself, and moderators are
+	// never muted, so that they can still moderate muted channels. Messages
+	// that aren't commands have nothing to drop.
+	if command.Author == nil || command.Type == model.CommandTypeNone {
+		return command, nil
+	}
+	userID, err := model.ParseSnowflake(command.Author.ID)
+	if err != nil || i.config.IsModerator(userID) {
+		return command, nil
+	}
+
+	muted, err := i.modelHelper.IsMuted(userID, command.ChannelID)
+	if err != nil {
+		log.Info("Error reading mutes", err)
+		return command, nil
+	}
+	if muted {
+		return &model.Command{
+			Type:      model.CommandTypeNone,
+			ChannelID: command.ChannelID,
+		}, nil
+	}
+	return command, nil
+}
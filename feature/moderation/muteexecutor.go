@@ -0,0 +1,126 @@
+package moderation
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/audit"
+	"github.com/jakevoytko/crbot/config"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// MuteExecutor makes crbot ignore a user or a channel for a while.
+type MuteExecutor struct {
+	modelHelper    *ModelHelper
+	auditBus       *audit.Bus
+	utcTimer       model.UTCTimer
+	commandChannel chan<- *model.Command
+	config         *config.Config
+}
+
+// NewMuteExecutor works as advertised.
+func NewMuteExecutor(modelHelper *ModelHelper, auditBus *audit.Bus, utcTimer model.UTCTimer, commandChannel chan<- *model.Command, config *config.Config) *MuteExecutor {
+	return &MuteExecutor{
+		modelHelper:    modelHelper,
+		auditBus:       auditBus,
+		utcTimer:       utcTimer,
+		commandChannel: commandChannel,
+		config:         config,
+	}
+}
+
+// GetType returns the type.
+func (e *MuteExecutor) GetType() int {
+	return model.CommandTypeMute
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private
+// channel. Mutes are announced where everyone can see them.
+func (e *MuteExecutor) PublicOnly() bool {
+	return true
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *MuteExecutor) ModeratorOnly() bool {
+	return true
+}
+
+const (
+	// MsgMutedUser announces that a user was muted
+	MsgMutedUser = "Muted %s for %s."
+	// MsgMutedUserReason announces that a user was muted, and why
+	MsgMutedUserReason = "Muted %s for %s: %s"
+	// MsgMutedChannel announces that a channel was muted
+	MsgMutedChannel = "Muted <#%v> for %s."
+	// MsgMuteUnknownChannel is a user-visible string for muting a channel outside of the server
+	MsgMuteUnknownChannel = "I can't find that channel in this server."
+	// MsgMuteModerator is a user-visible string for muting a moderator, whose
+	// commands are never ignored
+	MsgMuteModerator = "Moderators can't be muted."
+)
+
+// Execute stores the mute, and starts the timer that lifts it.
+func (e *MuteExecutor) Execute(s api.DiscordSession, channel model.Snowflake, command *model.Command) {
+	if command.Mute == nil {
+		log.Info("Tried to mute without a user or channel", errors.New("missing mute data"))
+		return
+	}
+
+	message, err := e.mute(s, channel, command)
+	if err != nil {
+		log.Fatal("Error muting", err)
+	}
+	if _, err := s.ChannelMessageSend(channel.Format(), message); err != nil {
+		log.Info("Failed to send mute message", err)
+	}
+}
+
+// mute stores the mute, and returns the message that announces it.
+func (e *MuteExecutor) mute(s api.DiscordSession, channel model.Snowflake, command *model.Command) (string, error) {
+	data := command.Mute
+	if data.UserID > 0 && e.config.IsModerator(data.UserID) {
+		return MsgMuteModerator, nil
+	}
+	if data.ChannelID > 0 {
+		// Only channels in this server can be muted from here.
+		discordChannel, err := s.Channel(channel.Format())
+		if err != nil {
+			return "", err
+		}
+		targetChannel, err := s.Channel(data.ChannelID.Format())
+		if err != nil || targetChannel.GuildID != discordChannel.GuildID {
+			return MsgMuteUnknownChannel, nil
+		}
+	}
+
+	addedBy := authorID(command)
+	entry, err := e.modelHelper.Mute(data.UserID, data.ChannelID, addedBy, channel, data.Duration, data.Reason)
+	if err != nil {
+		return "", err
+	}
+	scheduleMuteExpiry(e.modelHelper.utcClock, e.utcTimer, e.commandChannel, entry)
+
+	duration := durationString(data.Duration)
+	event := &audit.Event{
+		Type:      audit.EventMute,
+		UserID:    addedBy,
+		TargetID:  data.UserID,
+		ChannelID: channel,
+		Details:   "for " + duration,
+	}
+	if data.ChannelID > 0 {
+		event.ChannelID = data.ChannelID
+		e.auditBus.Publish(s, event)
+		return fmt.Sprintf(MsgMutedChannel, data.ChannelID, duration), nil
+	}
+	name := rickListedName(s, data.UserID)
+	if len(data.Reason) > 0 {
+		event.Details += ": " + data.Reason
+		e.auditBus.Publish(s, event)
+		return fmt.Sprintf(MsgMutedUserReason, name, duration, data.Reason), nil
+	}
+	e.auditBus.Publish(s, event)
+	return fmt.Sprintf(MsgMutedUser, name, duration), nil
+}
@@ -0,0 +1,102 @@
+package moderation
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/audit"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// MuteExpireExecutor lifts mutes when their time runs out.
+type MuteExpireExecutor struct {
+	modelHelper *ModelHelper
+	auditBus    *audit.Bus
+}
+
+// NewMuteExpireExecutor works as advertised.
+func NewMuteExpireExecutor(modelHelper *ModelHelper, auditBus *audit.Bus) *MuteExpireExecutor {
+	return &MuteExpireExecutor{
+		modelHelper: modelHelper,
+		auditBus:    auditBus,
+	}
+}
+
+// GetType returns the type.
+func (e *MuteExpireExecutor) GetType() int {
+	return model.CommandTypeMuteExpire
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private
+// channel. Mutes are only added in public channels.
+func (e *MuteExpireExecutor) PublicOnly() bool {
+	return false
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *MuteExpireExecutor) ModeratorOnly() bool {
+	return false
+}
+
+const (
+	// MsgMuteExpiredUser announces that a user's mute is over
+	MsgMuteExpiredUser = "%s is no longer muted."
+	// MsgMuteExpiredChannel announces that a channel's mute is over
+	MsgMuteExpiredChannel = "<#%v> is no longer muted."
+)
+
+// Execute removes the mute whose timer fired. Mutes that were replaced since
+// are left alone.
+func (e *MuteExpireExecutor) Execute(s api.DiscordSession, channel model.Snowflake, command *model.Command) {
+	if command.MuteExpire == nil {
+		log.Info("Tried to expire a mute without a user or channel", errors.New("missing mute data"))
+		return
+	}
+
+	data := command.MuteExpire
+	entry, err := e.modelHelper.ExpireMute(data.UserID, data.ChannelID, data.TimestampExpires)
+	if err != nil {
+		log.Fatal("Error expiring the mute", err)
+	}
+	if entry == nil {
+		return
+	}
+
+	message := fmt.Sprintf(MsgMuteExpiredChannel, entry.ChannelID)
+	event := &audit.Event{
+		Type:      audit.EventMuteExpire,
+		TargetID:  entry.UserID,
+		ChannelID: entry.ChannelID,
+	}
+	if entry.UserID > 0 {
+		message = fmt.Sprintf(MsgMuteExpiredUser, rickListedName(s, entry.UserID))
+		event.ChannelID = channel
+	}
+	e.auditBus.Publish(s, event)
+	if _, err := s.ChannelMessageSend(channel.Format(), message); err != nil {
+		log.Info("Failed to send mute message", err)
+	}
+}
+
+// NewMuteExpireCommand returns the command that lifts the mute.
+func NewMuteExpireCommand(entry *model.MuteEntry) *model.Command {
+	return &model.Command{
+		Type:      model.CommandTypeMuteExpire,
+		ChannelID: entry.AnnounceChannelID,
+		MuteExpire: &model.MuteExpireData{
+			UserID:           entry.UserID,
+			ChannelID:        entry.ChannelID,
+			TimestampExpires: entry.TimestampExpires,
+		},
+	}
+}
+
+// scheduleMuteExpiry starts a timer that lifts the mute. Mutes whose time ran
+// out while crbot was down are lifted right away.
+func scheduleMuteExpiry(utcClock model.UTCClock, utcTimer model.UTCTimer, commandChannel chan<- *model.Command, entry *model.MuteEntry) {
+	utcTimer.ExecuteAfter(entry.TimestampExpires.Sub(utcClock.Now()), func() {
+		commandChannel <- NewMuteExpireCommand(entry)
+	})
+}
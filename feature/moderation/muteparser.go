@@ -0,0 +1,101 @@
+package moderation
+
+import (
+	"errors"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jakevoytko/crbot/model"
+	"github.com/jakevoytko/crbot/util"
+)
+
+// MuteParser parses ?mute commands.
+type MuteParser struct{}
+
+// NewMuteParser works as advertised.
+func NewMuteParser() *MuteParser {
+	return &MuteParser{}
+}
+
+// GetName returns the named type of this feature.
+func (p *MuteParser) GetName() string {
+	return model.CommandNameMute
+}
+
+const (
+	// MsgHelpMute is the help text for ?mute
+	MsgHelpMute = "Moderators can type `?mute @user <duration> [reason]` or `?mute #channel <duration>` to make the bot ignore commands from a user or in a channel for a duration like `2h`."
+)
+
+// HelpText explains how to use ?mute.
+func (p *MuteParser) HelpText(command string) (string, error) {
+	return MsgHelpMute, nil
+}
+
+// The channel is mentioned.
+var channelRegexp = regexp.MustCompile("^<#([[:digit:]]+)>$")
+
+// Parse parses the given mute command.
+func (p *MuteParser) Parse(splitContent []string, m *discordgo.MessageCreate) (*model.Command, error) {
+	if splitContent[0] != p.GetName() {
+		log.Fatal("MuteParser.Parse called with non-mute command", errors.New("wat"))
+	}
+
+	splitContent = util.CollapseWhitespace(splitContent, 1)
+	splitContent = util.CollapseWhitespace(splitContent, 2)
+	splitContent = util.CollapseWhitespace(splitContent, 3)
+	if len(splitContent) < 3 {
+		return p.help(), nil
+	}
+	duration, err := time.ParseDuration(splitContent[2])
+	if err != nil || duration <= 0 {
+		return p.help(), nil
+	}
+
+	// Only users are muted with a reason.
+	if channelID := parseChannelID(splitContent[1]); channelID > 0 && len(splitContent) == 3 {
+		return &model.Command{
+			Type: model.CommandTypeMute,
+			Mute: &model.MuteData{
+				ChannelID: channelID,
+				Duration:  duration,
+			},
+		}, nil
+	}
+	if userID := parseUserID(splitContent[1]); userID > 0 {
+		return &model.Command{
+			Type: model.CommandTypeMute,
+			Mute: &model.MuteData{
+				UserID:   userID,
+				Duration: duration,
+				Reason:   strings.TrimSpace(strings.Join(splitContent[3:], " ")),
+			},
+		}, nil
+	}
+	return p.help(), nil
+}
+
+func (p *MuteParser) help() *model.Command {
+	return &model.Command{
+		Type: model.CommandTypeHelp,
+		Help: &model.HelpData{
+			Command: p.GetName(),
+		},
+	}
+}
+
+// parseChannelID returns the ID of the channel mentioned by the token, or 0.
+func parseChannelID(token string) model.Snowflake {
+	match := channelRegexp.FindStringSubmatch(token)
+	if len(match) != 2 {
+		return 0
+	}
+	channelID, err := model.ParseSnowflake(match[1])
+	if err != nil {
+		return 0
+	}
+	return channelID
+}
@@ -0,0 +1,82 @@
+package moderation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// MutesExecutor prints the active mutes.
+type MutesExecutor struct {
+	modelHelper *ModelHelper
+	utcClock    model.UTCClock
+}
+
+// NewMutesExecutor works as advertised.
+func NewMutesExecutor(modelHelper *ModelHelper, utcClock model.UTCClock) *MutesExecutor {
+	return &MutesExecutor{
+		modelHelper: modelHelper,
+		utcClock:    utcClock,
+	}
+}
+
+// GetType returns the type.
+func (e *MutesExecutor) GetType() int {
+	return model.CommandTypeMutes
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private channel.
+func (e *MutesExecutor) PublicOnly() bool {
+	return false
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *MutesExecutor) ModeratorOnly() bool {
+	return false
+}
+
+const (
+	// MsgMutesEmpty prints that nobody is muted
+	MsgMutesEmpty = "Nobody is muted."
+	// MsgMutes is a header for the active mutes
+	MsgMutes = "Muted:"
+	// MsgMuteLine describes a mute and its remaining time
+	MsgMuteLine = " - %s (%s left)"
+	// MsgMuteLineReason describes a mute, its remaining time, and why
+	MsgMuteLineReason = " - %s (%s left): %s"
+)
+
+// Execute replies over the given channel with the active mutes, soonest to
+// expire first.
+func (e *MutesExecutor) Execute(s api.DiscordSession, channel model.Snowflake, command *model.Command) {
+	entries, err := e.modelHelper.Mutes()
+	if err != nil {
+		log.Fatal("Error reading the mutes", err)
+	}
+
+	message := MsgMutesEmpty
+	if len(entries) > 0 {
+		lines := []string{MsgMutes}
+		now := e.utcClock.Now()
+		for _, entry := range entries {
+			name := fmt.Sprintf("<#%v>", entry.ChannelID)
+			if entry.UserID > 0 {
+				name = rickListedName(s, entry.UserID)
+			}
+			remaining := remainingString(now, entry.TimestampExpires)
+			if len(entry.Reason) > 0 {
+				lines = append(lines, fmt.Sprintf(MsgMuteLineReason, name, remaining, entry.Reason))
+			} else {
+				lines = append(lines, fmt.Sprintf(MsgMuteLine, name, remaining))
+			}
+		}
+		message = strings.Join(lines, "\n")
+	}
+
+	if _, err := s.ChannelMessageSend(channel.Format(), message); err != nil {
+		log.Info("Failed to send mutes message", err)
+	}
+}
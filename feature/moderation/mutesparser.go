@@ -0,0 +1,53 @@
+package moderation
+
+import (
+	"errors"
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jakevoytko/crbot/model"
+	"github.com/jakevoytko/crbot/util"
+)
+
+// MutesParser parses ?mutes commands.
+type MutesParser struct{}
+
+// NewMutesParser works as advertised.
+func NewMutesParser() *MutesParser {
+	return &MutesParser{}
+}
+
+// GetName returns the named type of this feature.
+func (p *MutesParser) GetName() string {
+	return model.CommandNameMutes
+}
+
+const (
+	// MsgHelpMutes is the help text for ?mutes
+	MsgHelpMutes = "Type `?mutes` to list the users and channels that the bot is ignoring, and for how much longer."
+)
+
+// HelpText explains how to use ?mutes.
+func (p *MutesParser) HelpText(command string) (string, error) {
+	return MsgHelpMutes, nil
+}
+
+// Parse parses the given mutes command.
+func (p *MutesParser) Parse(splitContent []string, m *discordgo.MessageCreate) (*model.Command, error) {
+	if splitContent[0] != p.GetName() {
+		log.Fatal("MutesParser.Parse called with non-mutes command", errors.New("wat"))
+	}
+
+	splitContent = util.CollapseWhitespace(splitContent, 1)
+	if len(splitContent) > 1 {
+		return &model.Command{
+			Type: model.CommandTypeHelp,
+			Help: &model.HelpData{
+				Command: p.GetName(),
+			},
+		}, nil
+	}
+	return &model.Command{
+		Type: model.CommandTypeMutes,
+	}, nil
+}
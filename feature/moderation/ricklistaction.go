@@ -0,0 +1,129 @@
+package moderation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/audit"
+	"github.com/jakevoytko/crbot/config"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// RickListAction lets a vote put a user on the ricklist.
+type RickListAction struct {
+	modelHelper    *ModelHelper
+	auditBus       *audit.Bus
+	utcTimer       model.UTCTimer
+	commandChannel chan<- *model.Command
+	config         *config.Config
+}
+
+// NewRickListAction works as advertised.
+func NewRickListAction(modelHelper *ModelHelper, auditBus *audit.Bus, utcTimer model.UTCTimer, commandChannel chan<- *model.Command, config *config.Config) *RickListAction {
+	return &RickListAction{
+		modelHelper:    modelHelper,
+		auditBus:       auditBus,
+		utcTimer:       utcTimer,
+		commandChannel: commandChannel,
+		config:         config,
+	}
+}
+
+// ActionNameRickList is the name used to call a vote on ricklisting a user.
+const ActionNameRickList = "ricklist"
+
+const (
+	// MsgHelpRickListAction is the help text for votes on the ricklist action
+	MsgHelpRickListAction = "Type `?vote action ricklist <user> [duration]` to vote on putting a user on the ricklist."
+	// MsgRickListActionDescription describes a vote on the ricklist action
+	MsgRickListActionDescription = "Add <@%v> to the ricklist"
+	// MsgRickListActionDescriptionFor describes a vote on the timed ricklist action
+	MsgRickListActionDescriptionFor = "Add <@%v> to the ricklist for %s"
+	// MsgRickListModerator prints that moderators can't be ricklisted by vote
+	MsgRickListModerator = "Moderators can't be ricklisted by vote."
+	// MsgRickListAlreadyListed prints that the user is already ricklisted for
+	// at least as long
+	MsgRickListAlreadyListed = "%s is already on the ricklist."
+)
+
+// GetName returns the name of the action.
+func (a *RickListAction) GetName() string {
+	return ActionNameRickList
+}
+
+// Describe checks that the user can be ricklisted.
+func (a *RickListAction) Describe(args string) (string, error) {
+	userID, duration, err := a.parseArgs(args)
+	if err != nil {
+		return "", err
+	}
+	if duration > 0 {
+		return fmt.Sprintf(MsgRickListActionDescriptionFor, userID, durationString(duration)), nil
+	}
+	return fmt.Sprintf(MsgRickListActionDescription, userID), nil
+}
+
+// Execute ricklists the user, unless they are already ricklisted for at least
+// as long.
+func (a *RickListAction) Execute(s api.DiscordSession, channelID model.Snowflake, args string) (string, error) {
+	userID, duration, err := a.parseArgs(args)
+	if err != nil {
+		return "", err
+	}
+
+	name := rickListedName(s, userID)
+	existing, err := a.modelHelper.ActiveRickListEntry(userID)
+	if err != nil {
+		return "", err
+	}
+	if existing != nil && (!existing.Expires() || (duration > 0 && !existing.TimestampExpires.Before(a.modelHelper.utcClock.Now().Add(duration)))) {
+		return "", fmt.Errorf(MsgRickListAlreadyListed, name)
+	}
+
+	entry, err := a.modelHelper.AddToRickList(userID, 0, channelID, duration, false)
+	if err != nil {
+		return "", err
+	}
+	event := &audit.Event{
+		Type:      audit.EventRickListAdd,
+		TargetID:  entry.UserID,
+		ChannelID: channelID,
+		Details:   "by vote",
+	}
+	message := fmt.Sprintf(MsgRickListAdded, name)
+	if entry.Expires() {
+		scheduleRickListExpiry(a.modelHelper.utcClock, a.utcTimer, a.commandChannel, entry)
+		event.Details = "by vote for " + durationString(duration)
+		message = fmt.Sprintf(MsgRickListAddedFor, name, durationString(duration))
+	}
+	a.auditBus.Publish(s, event)
+	return message, nil
+}
+
+// parseArgs returns the user and the duration named by the args, or a
+// user-visible error if they can't be ricklisted. Entries without a duration
+// last until they are removed.
+func (a *RickListAction) parseArgs(args string) (model.Snowflake, time.Duration, error) {
+	splitArgs := strings.Fields(args)
+	if len(splitArgs) < 1 || len(splitArgs) > 2 {
+		return 0, 0, errors.New(MsgHelpRickListAction)
+	}
+	userID := parseUserID(splitArgs[0])
+	if userID == 0 {
+		return 0, 0, errors.New(MsgHelpRickListAction)
+	}
+	var duration time.Duration
+	if len(splitArgs) == 2 {
+		var err error
+		if duration, err = time.ParseDuration(splitArgs[1]); err != nil || duration <= 0 {
+			return 0, 0, errors.New(MsgHelpRickListAction)
+		}
+	}
+	if a.config.IsModerator(userID) {
+		return 0, 0, errors.New(MsgRickListModerator)
+	}
+	return userID, duration, nil
+}
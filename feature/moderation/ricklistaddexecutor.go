@@ -0,0 +1,100 @@
+package moderation
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/audit"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// RickListAddExecutor puts a user on the ricklist.
+type RickListAddExecutor struct {
+	modelHelper    *ModelHelper
+	auditBus       *audit.Bus
+	utcTimer       model.UTCTimer
+	commandChannel chan<- *model.Command
+}
+
+// NewRickListAddExecutor works as advertised.
+func NewRickListAddExecutor(modelHelper *ModelHelper, auditBus *audit.Bus, utcTimer model.UTCTimer, commandChannel chan<- *model.Command) *RickListAddExecutor {
+	return &RickListAddExecutor{
+		modelHelper:    modelHelper,
+		auditBus:       auditBus,
+		utcTimer:       utcTimer,
+		commandChannel: commandChannel,
+	}
+}
+
+// GetType returns the type.
+func (e *RickListAddExecutor) GetType() int {
+	return model.CommandTypeRickListAdd
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private
+// channel. Additions are announced where everyone can see them.
+func (e *RickListAddExecutor) PublicOnly() bool {
+	return true
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *RickListAddExecutor) ModeratorOnly() bool {
+	return true
+}
+
+const (
+	// MsgRickListAdded announces that a user was put on the ricklist
+	MsgRickListAdded = "Added %s to the ricklist."
+	// MsgRickListAddedFor announces that a user was put on the ricklist for a while
+	MsgRickListAddedFor = "Added %s to the ricklist for %s."
+	// MsgRickListShadowbanned announces that a user was shadowbanned
+	MsgRickListShadowbanned = "Shadowbanned %s."
+	// MsgRickListShadowbannedFor announces that a user was shadowbanned for a while
+	MsgRickListShadowbannedFor = "Shadowbanned %s for %s."
+)
+
+// Execute adds the user to the ricklist, and starts the timer for timed entries.
+func (e *RickListAddExecutor) Execute(s api.DiscordSession, channel model.Snowflake, command *model.Command) {
+	if command.RickListAdd == nil {
+		log.Info("Tried to ricklist without a user", errors.New("missing user ID"))
+		return
+	}
+
+	addedBy := authorID(command)
+	entry, err := e.modelHelper.AddToRickList(command.RickListAdd.UserID, addedBy, channel, command.RickListAdd.Duration, command.RickListAdd.Shadowban)
+	if err != nil {
+		log.Fatal("Error adding the user to the ricklist", err)
+	}
+	if entry.Expires() {
+		scheduleRickListExpiry(e.modelHelper.utcClock, e.utcTimer, e.commandChannel, entry)
+	}
+
+	name := rickListedName(s, entry.UserID)
+	message := fmt.Sprintf(MsgRickListAdded, name)
+	event := &audit.Event{
+		Type:      audit.EventRickListAdd,
+		UserID:    addedBy,
+		TargetID:  entry.UserID,
+		ChannelID: channel,
+	}
+	if entry.Shadowban {
+		message = fmt.Sprintf(MsgRickListShadowbanned, name)
+		event.Details = "shadowban"
+	}
+	if entry.Expires() {
+		duration := durationString(command.RickListAdd.Duration)
+		if entry.Shadowban {
+			message = fmt.Sprintf(MsgRickListShadowbannedFor, name, duration)
+			event.Details = "shadowban for " + duration
+		} else {
+			message = fmt.Sprintf(MsgRickListAddedFor, name, duration)
+			event.Details = "for " + duration
+		}
+	}
+	e.auditBus.Publish(s, event)
+	if _, err := s.ChannelMessageSend(channel.Format(), message); err != nil {
+		log.Info("Failed to send ricklist message", err)
+	}
+}
@@ -1,21 +1,26 @@
 package moderation
 
 import (
+	"strings"
+
 	"github.com/bwmarrin/discordgo"
 	"github.com/jakevoytko/crbot/api"
-	"github.com/jakevoytko/crbot/config"
+	"github.com/jakevoytko/crbot/audit"
+	"github.com/jakevoytko/crbot/log"
 	"github.com/jakevoytko/crbot/model"
 )
 
 // RickListCommandInterceptor asserts that the
 type RickListCommandInterceptor struct {
-	rickList []model.Snowflake
+	modelHelper *ModelHelper
+	auditBus    *audit.Bus
 }
 
 // NewRickListCommandInterceptor returns a new ricklist command interceptor.
-func NewRickListCommandInterceptor(config *config.Config) *RickListCommandInterceptor {
+func NewRickListCommandInterceptor(modelHelper *ModelHelper, auditBus *audit.Bus) *RickListCommandInterceptor {
 	return &RickListCommandInterceptor{
-		rickList: config.RickList,
+		modelHelper: modelHelper,
+		auditBus:    auditBus,
 	}
 }
 
@@ -24,22 +29,93 @@ func (i *RickListCommandInterceptor) Intercept(command *model.Command, s api.Dis
 	// Check moderation.
 	// RickList
 	// - RickListed users can only use ?learn in private channels, without it responding with
-	//   a rickroll.
-	if channel, err := s.Channel(command.ChannelID.Format()); err == nil {
-		isPrivate := channel.Type == discordgo.ChannelTypeDM || channel.Type == discordgo.ChannelTypeGroupDM
-		isAllowed := command.Type == model.CommandTypeLearn || command.Type == model.CommandTypeNone
-		if isPrivate && !isAllowed {
-			for _, ricked := range i.rickList {
-				if ricked.Format() == command.Author.ID {
-					return &model.Command{
-						Type:      model.CommandTypeRickList,
-						Author:    nil,
-						ChannelID: command.ChannelID,
-					}, nil
-				}
-			}
-		}
+	//   a rickroll. Reactions aren't commands, so they aren't rickrolled either.
+	// - Shadowbanned users are never rickrolled. What they ?learn is only visible to them.
+	// - Commands without an author come from crbot itself, and are never rickrolled.
+	// - Messages that aren't commands are never rickrolled or shadowed.
+	// - If the ricklist can't be read, the command goes through, since an error here
+	//   would stop crbot.
+	if command.Author == nil || command.Type == model.CommandTypeNone {
+		return command, nil
+	}
+	channel, err := s.Channel(command.ChannelID.Format())
+	if err != nil {
+		return command, nil
+	}
+	isPrivate := channel.Type == discordgo.ChannelTypeDM || channel.Type == discordgo.ChannelTypeGroupDM
+	isAllowed := command.Type == model.CommandTypeLearn || command.Type == model.CommandTypeNone || command.Type == model.CommandTypeReaction
+	isShadowable := command.Type == model.CommandTypeLearn || command.Type == model.CommandTypeUnrecognized
+	if (!isPrivate || isAllowed) && !isShadowable {
+		return command, nil
+	}
+
+	// Only snowflakes can be added to the ricklist.
+	userID, err := model.ParseSnowflake(command.Author.ID)
+	if err != nil {
+		return command, nil
+	}
+	entry, err := i.modelHelper.ActiveRickListEntry(userID)
+	if err != nil {
+		log.Info("Error reading the ricklist entry", err)
+		return command, nil
+	}
+	if entry == nil {
+		return command, nil
+	}
+	if entry.Shadowban {
+		return i.shadow(command, userID)
 	}
+	if !isPrivate || isAllowed {
+		return command, nil
+	}
+
+	i.auditBus.Publish(s, &audit.Event{
+		Type:      audit.EventRickListHit,
+		UserID:    userID,
+		ChannelID: command.ChannelID,
+		Command:   command.OriginalName,
+	})
+	return &model.Command{
+		Type:      model.CommandTypeRickList,
+		Author:    nil,
+		ChannelID: command.ChannelID,
+		RickList: &model.RickListData{
+			UserID: userID,
+		},
+	}, nil
+}
 
+// shadow sends the shadowbanned user's ?learn commands, and the calls that they
+// learned, to executors that only they can see the results of.
+func (i *RickListCommandInterceptor) shadow(command *model.Command, userID model.Snowflake) (*model.Command, error) {
+	switch command.Type {
+	case model.CommandTypeLearn:
+		shadowed := *command
+		shadowed.Type = model.CommandTypeShadowLearn
+		return &shadowed, nil
+
+	case model.CommandTypeUnrecognized:
+		splitContent := strings.Fields(command.Content)
+		if len(splitContent) == 0 || !strings.HasPrefix(splitContent[0], "?") {
+			return command, nil
+		}
+		call := splitContent[0][1:]
+		_, ok, err := i.modelHelper.ShadowResponse(userID, call)
+		if err != nil {
+			log.Info("Error reading a shadow learn", err)
+			return command, nil
+		}
+		if !ok {
+			return command, nil
+		}
+		shadowed := *command
+		shadowed.Type = model.CommandTypeShadowCustom
+		shadowed.OriginalName = splitContent[0]
+		shadowed.Custom = &model.CustomData{
+			Call: call,
+			Args: strings.Join(splitContent[1:], " "),
+		}
+		return &shadowed, nil
+	}
 	return command, nil
 }
@@ -1,9 +1,13 @@
 package moderation
 
 import (
+	"math/rand"
+
 	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/feature/learn"
 	"github.com/jakevoytko/crbot/log"
 	"github.com/jakevoytko/crbot/model"
+	stringmap "github.com/jakevoytko/go-stringmap"
 )
 
 const (
@@ -11,13 +15,19 @@ const (
 	MsgRickList = "https://www.youtube.com/watch?v=dQw4w9WgXcQ"
 )
 
-// RickListExecutor prints a rick roll.
+// RickListExecutor punishes a ricklisted user with one of their responses, or
+// a rick roll.
 type RickListExecutor struct {
+	modelHelper *ModelHelper
+	commandMap  stringmap.StringMap
 }
 
 // NewRickListExecutor works as advertised.
-func NewRickListExecutor() *RickListExecutor {
-	return &RickListExecutor{}
+func NewRickListExecutor(modelHelper *ModelHelper, commandMap stringmap.StringMap) *RickListExecutor {
+	return &RickListExecutor{
+		modelHelper: modelHelper,
+		commandMap:  commandMap,
+	}
 }
 
 // GetType returns the type.
@@ -30,9 +40,53 @@ func (e *RickListExecutor) PublicOnly() bool {
 	return false
 }
 
-// Execute replies over the given channel with a rick roll.
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *RickListExecutor) ModeratorOnly() bool {
+	return false
+}
+
+// Execute replies over the given channel with a random pick from the user's
+// responses. Users without responses get a rick roll.
 func (e *RickListExecutor) Execute(s api.DiscordSession, channel model.Snowflake, command *model.Command) {
-	if _, err := s.ChannelMessageSend(channel.Format(), MsgRickList); err != nil {
+	message := MsgRickList
+	if command.RickList != nil {
+		responses, err := e.modelHelper.PunishmentResponses(command.RickList.UserID)
+		if err != nil {
+			log.Fatal("Error reading the ricklist responses", err)
+		}
+		if len(responses) > 0 {
+			message = e.responseMessage(responses[rand.Intn(len(responses))])
+		}
+	}
+	if len(message) == 0 {
+		return
+	}
+
+	if _, err := s.ChannelMessageSend(channel.Format(), message); err != nil {
 		log.Info("Failed to send ricklist message", err)
 	}
 }
+
+// responseMessage returns the message to send for the response, or the empty
+// string to send nothing. Learned commands that were forgotten since fall back
+// to a rick roll.
+func (e *RickListExecutor) responseMessage(response *model.RickListResponse) string {
+	switch response.Kind {
+	case model.RickListResponseDrop:
+		return ""
+	case model.RickListResponseCommand:
+		ok, err := e.commandMap.Has(response.Text)
+		if err != nil {
+			log.Fatal("Error testing the ricklist response command", err)
+		}
+		if !ok {
+			return MsgRickList
+		}
+		learned, err := e.commandMap.Get(response.Text)
+		if err != nil {
+			log.Fatal("Error reading the ricklist response command", err)
+		}
+		return learn.FormatResponse(learned, "")
+	}
+	return response.Text
+}
@@ -0,0 +1,92 @@
+package moderation
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/audit"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// RickListExpireExecutor takes users off the ricklist when their time runs out.
+type RickListExpireExecutor struct {
+	modelHelper *ModelHelper
+	auditBus    *audit.Bus
+}
+
+// NewRickListExpireExecutor works as advertised.
+func NewRickListExpireExecutor(modelHelper *ModelHelper, auditBus *audit.Bus) *RickListExpireExecutor {
+	return &RickListExpireExecutor{
+		modelHelper: modelHelper,
+		auditBus:    auditBus,
+	}
+}
+
+// GetType returns the type.
+func (e *RickListExpireExecutor) GetType() int {
+	return model.CommandTypeRickListExpire
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private
+// channel. Timed entries are only added in public channels.
+func (e *RickListExpireExecutor) PublicOnly() bool {
+	return false
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *RickListExpireExecutor) ModeratorOnly() bool {
+	return false
+}
+
+const (
+	// MsgRickListExpired announces that a user's time on the ricklist is up
+	MsgRickListExpired = "%s's time on the ricklist is up."
+)
+
+// Execute removes the timed entry whose timer fired. Entries that were removed
+// or replaced since are left alone.
+func (e *RickListExpireExecutor) Execute(s api.DiscordSession, channel model.Snowflake, command *model.Command) {
+	if command.RickListExpire == nil {
+		log.Info("Tried to expire a ricklist entry without a user", errors.New("missing user ID"))
+		return
+	}
+
+	entry, err := e.modelHelper.ExpireRickListEntry(command.RickListExpire.UserID, command.RickListExpire.TimestampExpires)
+	if err != nil {
+		log.Fatal("Error expiring the ricklist entry", err)
+	}
+	if entry == nil {
+		return
+	}
+	e.auditBus.Publish(s, &audit.Event{
+		Type:      audit.EventRickListExpire,
+		TargetID:  entry.UserID,
+		ChannelID: channel,
+	})
+
+	if _, err := s.ChannelMessageSend(channel.Format(), fmt.Sprintf(MsgRickListExpired, rickListedName(s, entry.UserID))); err != nil {
+		log.Info("Failed to send ricklist message", err)
+	}
+}
+
+// NewRickListExpireCommand returns the command that expires the timed entry.
+func NewRickListExpireCommand(entry *model.RickListEntry) *model.Command {
+	return &model.Command{
+		Type:      model.CommandTypeRickListExpire,
+		ChannelID: entry.ChannelID,
+		RickListExpire: &model.RickListExpireData{
+			UserID:           entry.UserID,
+			TimestampExpires: entry.TimestampExpires,
+		},
+	}
+}
+
+// scheduleRickListExpiry starts a timer that expires the timed entry. Entries
+// whose time ran out while crbot was down expire right away.
+func scheduleRickListExpiry(utcClock model.UTCClock, utcTimer model.UTCTimer, commandChannel chan<- *model.Command, entry *model.RickListEntry) {
+	utcTimer.ExecuteAfter(entry.TimestampExpires.Sub(utcClock.Now()), func() {
+		commandChannel <- NewRickListExpireCommand(entry)
+	})
+}
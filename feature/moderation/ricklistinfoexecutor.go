@@ -2,23 +2,26 @@ package moderation
 
 import (
 	"fmt"
+	"math"
 	"strings"
+	"time"
 
 	"github.com/jakevoytko/crbot/api"
-	"github.com/jakevoytko/crbot/config"
 	"github.com/jakevoytko/crbot/log"
 	"github.com/jakevoytko/crbot/model"
 )
 
-// RickListInfoExecutor prints a rick roll.
+// RickListInfoExecutor prints who is on the ricklist.
 type RickListInfoExecutor struct {
-	config *config.Config
+	modelHelper *ModelHelper
+	utcClock    model.UTCClock
 }
 
 // NewRickListInfoExecutor works as advertised.
-func NewRickListInfoExecutor(config *config.Config) *RickListInfoExecutor {
+func NewRickListInfoExecutor(modelHelper *ModelHelper, utcClock model.UTCClock) *RickListInfoExecutor {
 	return &RickListInfoExecutor{
-		config: config,
+		modelHelper: modelHelper,
+		utcClock:    utcClock,
 	}
 }
 
@@ -32,32 +35,44 @@ func (e *RickListInfoExecutor) PublicOnly() bool {
 	return false
 }
 
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *RickListInfoExecutor) ModeratorOnly() bool {
+	return false
+}
+
 const (
 	// MsgRickListEmpty prints that nobody is on the rickroll moderation list
 	MsgRickListEmpty = "Nobody is on the ricklist."
 	// MsgRickListUsers is a header for who is on the rickroll moderation list
 	MsgRickListUsers = "On the Rick list: "
+	// MsgRickListRemaining follows a timed ricklist entry with its remaining time
+	MsgRickListRemaining = " (%s left)"
 )
 
-// Execute replies over the given channel with a rick roll.
+// Execute replies over the given channel with the users on the ricklist.
 func (e *RickListInfoExecutor) Execute(s api.DiscordSession, channel model.Snowflake, command *model.Command) {
-	if len(e.config.RickList) == 0 {
+	allEntries, err := e.modelHelper.RickList()
+	if err != nil {
+		log.Fatal("Error reading the ricklist", err)
+	}
+	// Shadowbanned users would find out from the list.
+	entries := []*model.RickListEntry{}
+	for _, entry := range allEntries {
+		if !entry.Shadowban {
+			entries = append(entries, entry)
+		}
+	}
+
+	if len(entries) == 0 {
 		if _, err := s.ChannelMessageSend(channel.Format(), MsgRickListEmpty); err != nil {
 			log.Info("Failed to send ricklist message", err)
 		}
 		return
 	}
 
-	users := make([]string, 0, len(e.config.RickList))
-	for _, ricklisted := range e.config.RickList {
-		ricklistedFormat := ricklisted.Format()
-		user, err := s.User(ricklistedFormat)
-		if err != nil {
-			log.Info(fmt.Sprintf("Unable to get info for user %v", ricklisted), err)
-			users = append(users, ricklistedFormat)
-			continue
-		}
-		users = append(users, "@"+user.Username)
+	users := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		users = append(users, rickListedName(s, entry.UserID)+e.remainingString(entry))
 	}
 
 	finalString := MsgRickListUsers + "[" + strings.Join(users, ", ") + "]"
@@ -66,3 +81,57 @@ func (e *RickListInfoExecutor) Execute(s api.DiscordSession, channel model.Snowf
 		log.Info("Failed to send ricklist message", err)
 	}
 }
+
+// remainingString returns the time left on a timed entry, rounded up to the
+// minute, or the empty string for entries that don't expire.
+func (e *RickListInfoExecutor) remainingString(entry *model.RickListEntry) string {
+	if !entry.Expires() {
+		return ""
+	}
+	return fmt.Sprintf(MsgRickListRemaining, remainingString(e.utcClock.Now(), entry.TimestampExpires))
+}
+
+// remainingString returns the time left until the expiry, rounded up to the
+// minute.
+func remainingString(now, expires time.Time) string {
+	minutes := math.Ceil(float64(expires.Sub(now)) / float64(time.Minute))
+	return durationString(time.Duration(minutes) * time.Minute)
+}
+
+// durationString formats the duration like 45m, 2h, or 1h30m, without the
+// trailing zero units that time.Duration prints.
+func durationString(duration time.Duration) string {
+	formatted := duration.String()
+	if strings.HasSuffix(formatted, "m0s") {
+		formatted = strings.TrimSuffix(formatted, "0s")
+	}
+	if strings.HasSuffix(formatted, "h0m") {
+		formatted = strings.TrimSuffix(formatted, "0m")
+	}
+	return formatted
+}
+
+// rickListedName returns the user's @name, or their ID when it can't be looked
+// up.
+func rickListedName(s api.DiscordSession, userID model.Snowflake) string {
+	user, err := s.User(userID.Format())
+	if err != nil {
+		log.Info(fmt.Sprintf("Unable to get info for user %v", userID), err)
+		return userID.Format()
+	}
+	return "@" + user.Username
+}
+
+// authorID returns the ID of the command's author, or 0 for commands that crbot
+// generated.
+func authorID(command *model.Command) model.Snowflake {
+	if command.Author == nil {
+		return 0
+	}
+	userID, err := model.ParseSnowflake(command.Author.ID)
+	if err != nil {
+		log.Info("Error parsing the ID of the author", err)
+		return 0
+	}
+	return userID
+}
@@ -3,12 +3,17 @@ package moderation
 import (
 	"errors"
 	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/jakevoytko/crbot/model"
+	"github.com/jakevoytko/crbot/util"
 )
 
-// RickListInfoParser parses ?list commands.
+// RickListInfoParser parses ?ricklist commands.
 type RickListInfoParser struct{}
 
 // NewRickListInfoParser works as advertised.
@@ -23,7 +28,22 @@ func (p *RickListInfoParser) GetName() string {
 
 const (
 	// MsgHelpRickListInfo is the help text for ?ricklist
-	MsgHelpRickListInfo = "Type `?ricklist` to print all users on the moderation rick list. These are whitelisted users who will get rickrolled every time they try to run a private command."
+	MsgHelpRickListInfo = "Type `?ricklist` to print all users on the moderation rick list. These are whitelisted users who will get rickrolled every time they try to run a private command. Moderators can type `?ricklist add @user [duration]` to add a user, optionally for a duration like `2h`, `?ricklist shadowban @user [duration]` to add them without them noticing, and `?ricklist remove @user` to remove them.\n\nModerators can type `?ricklist responses [@user]` to see what ricklisted users get instead of a rickroll, `?ricklist response add [@user] <response>` to add a response, and `?ricklist response remove [@user] <number>` to remove one. A response is a message, a learned `?call`, or `drop` to ignore the command. Without a user, responses go to everyone who has none of their own."
+)
+
+const (
+	// SubcommandRickListAdd adds a user to the ricklist
+	SubcommandRickListAdd = "add"
+	// SubcommandRickListRemove removes a user from the ricklist
+	SubcommandRickListRemove = "remove"
+	// SubcommandRickListShadowban adds a user to the ricklist without them noticing
+	SubcommandRickListShadowban = "shadowban"
+	// SubcommandRickListResponses lists the responses that ricklisted users get
+	SubcommandRickListResponses = "responses"
+	// SubcommandRickListResponse adds or removes a response that ricklisted users get
+	SubcommandRickListResponse = "response"
+	// RickListResponseDrop is typed to add a response that drops the command
+	RickListResponseDrop = "drop"
 )
 
 // HelpText explains how to use ?ricklist.
@@ -31,12 +51,176 @@ func (p *RickListInfoParser) HelpText(command string) (string, error) {
 	return MsgHelpRickListInfo, nil
 }
 
-// Parse parses the given list command.
+// The user is mentioned, or named by their ID.
+var userRegexp = regexp.MustCompile("^(?:<@!?([[:digit:]]+)>|([[:digit:]]+))$")
+
+// A learned command's call, like ?call.
+var callRegexp = regexp.MustCompile(`^\?[[:alnum:]]\S*$`)
+
+// Parse parses the given ricklist command.
 func (p *RickListInfoParser) Parse(splitContent []string, m *discordgo.MessageCreate) (*model.Command, error) {
 	if splitContent[0] != p.GetName() {
-		log.Fatal("parse ricklist called with non-list command", errors.New("wat"))
+		log.Fatal("parse ricklist called with non-ricklist command", errors.New("wat"))
+	}
+
+	splitContent = util.CollapseWhitespace(splitContent, 1)
+	splitContent = util.CollapseWhitespace(splitContent, 2)
+	splitContent = util.CollapseWhitespace(splitContent, 3)
+	if len(splitContent) == 1 {
+		return &model.Command{
+			Type: model.CommandTypeRickListInfo,
+		}, nil
+	}
+
+	if splitContent[1] == SubcommandRickListResponses || splitContent[1] == SubcommandRickListResponse {
+		if command := p.parseResponse(splitContent); command != nil {
+			return command, nil
+		}
+		return p.help(), nil
 	}
+
+	var userID model.Snowflake
+	if len(splitContent) > 2 {
+		userID = parseUserID(splitContent[2])
+	}
+	isAdd := splitContent[1] == SubcommandRickListAdd || splitContent[1] == SubcommandRickListShadowban
+	switch {
+	case isAdd && userID > 0 && len(splitContent) <= 4:
+		// Entries without a duration last until they are removed.
+		var duration time.Duration
+		if len(splitContent) == 4 {
+			var err error
+			if duration, err = time.ParseDuration(splitContent[3]); err != nil || duration <= 0 {
+				break
+			}
+		}
+		return &model.Command{
+			Type: model.CommandTypeRickListAdd,
+			RickListAdd: &model.RickListUpdateData{
+				UserID:    userID,
+				Duration:  duration,
+				Shadowban: splitContent[1] == SubcommandRickListShadowban,
+			},
+		}, nil
+
+	case splitContent[1] == SubcommandRickListRemove && userID > 0 && len(splitContent) == 3:
+		return &model.Command{
+			Type: model.CommandTypeRickListRemove,
+			RickListRemove: &model.RickListUpdateData{
+				UserID: userID,
+			},
+		}, nil
+	}
+
+	return p.help(), nil
+}
+
+// parseResponse parses the subcommands that list, add, and remove ricklist
+// responses. Returns nil if the command is malformed.
+func (p *RickListInfoParser) parseResponse(splitContent []string) *model.Command {
+	if splitContent[1] == SubcommandRickListResponses {
+		if len(splitContent) > 3 {
+			return nil
+		}
+		var userID model.Snowflake
+		if len(splitContent) == 3 {
+			if userID = parseUserID(splitContent[2]); userID == 0 {
+				return nil
+			}
+		}
+		return &model.Command{
+			Type: model.CommandTypeRickListResponses,
+			RickListResponse: &model.RickListResponseData{
+				UserID: userID,
+			},
+		}
+	}
+
+	// ?ricklist response add|remove [@user] <response|number>
+	if len(splitContent) < 4 {
+		return nil
+	}
+	// The user is optional. Mentions always name the user, but a lone ID is a
+	// response number.
+	args := splitContent[3:]
+	userID := parseUserID(args[0])
+	if userID > 0 && (len(args) > 1 || strings.HasPrefix(args[0], "<@")) {
+		args = args[1:]
+	} else {
+		userID = 0
+	}
+	arg := strings.TrimSpace(strings.Join(args, " "))
+	if len(arg) == 0 {
+		return nil
+	}
+
+	switch splitContent[2] {
+	case SubcommandRickListAdd:
+		response := parseRickListResponse(arg)
+		if response == nil {
+			return nil
+		}
+		return &model.Command{
+			Type: model.CommandTypeRickListResponseAdd,
+			RickListResponse: &model.RickListResponseData{
+				UserID:   userID,
+				Response: response,
+			},
+		}
+
+	case SubcommandRickListRemove:
+		number, err := strconv.Atoi(arg)
+		if err != nil || number < 1 {
+			return nil
+		}
+		return &model.Command{
+			Type: model.CommandTypeRickListResponseRemove,
+			RickListResponse: &model.RickListResponseData{
+				UserID: userID,
+				Number: number,
+			},
+		}
+	}
+	return nil
+}
+
+func (p *RickListInfoParser) help() *model.Command {
 	return &model.Command{
-		Type: model.CommandTypeRickListInfo,
-	}, nil
+		Type: model.CommandTypeHelp,
+		Help: &model.HelpData{
+			Command: p.GetName(),
+		},
+	}
+}
+
+// parseRickListResponse returns the response that the moderator typed, or nil.
+// Like learned responses, messages can't start with a character that might
+// trigger another bot.
+func parseRickListResponse(arg string) *model.RickListResponse {
+	switch {
+	case arg == RickListResponseDrop:
+		return &model.RickListResponse{Kind: model.RickListResponseDrop}
+	case callRegexp.MatchString(arg):
+		return &model.RickListResponse{Kind: model.RickListResponseCommand, Text: arg[1:]}
+	case strings.HasPrefix(arg, "/") || strings.HasPrefix(arg, "?") || strings.HasPrefix(arg, "!"):
+		return nil
+	}
+	return &model.RickListResponse{Kind: model.RickListResponseText, Text: arg}
+}
+
+// parseUserID returns the ID of the user named by the token, or 0.
+func parseUserID(token string) model.Snowflake {
+	match := userRegexp.FindStringSubmatch(token)
+	if len(match) != 3 {
+		return 0
+	}
+	id := match[1]
+	if len(id) == 0 {
+		id = match[2]
+	}
+	userID, err := model.ParseSnowflake(id)
+	if err != nil {
+		return 0
+	}
+	return userID
 }
@@ -0,0 +1,77 @@
+package moderation
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/audit"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// RickListRemoveExecutor takes a user off the ricklist.
+type RickListRemoveExecutor struct {
+	modelHelper *ModelHelper
+	auditBus    *audit.Bus
+}
+
+// NewRickListRemoveExecutor works as advertised.
+func NewRickListRemoveExecutor(modelHelper *ModelHelper, auditBus *audit.Bus) *RickListRemoveExecutor {
+	return &RickListRemoveExecutor{
+		modelHelper: modelHelper,
+		auditBus:    auditBus,
+	}
+}
+
+// GetType returns the type.
+func (e *RickListRemoveExecutor) GetType() int {
+	return model.CommandTypeRickListRemove
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private
+// channel. Removals are announced where everyone can see them.
+func (e *RickListRemoveExecutor) PublicOnly() bool {
+	return true
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *RickListRemoveExecutor) ModeratorOnly() bool {
+	return true
+}
+
+const (
+	// MsgRickListRemoved announces that a user was taken off the ricklist
+	MsgRickListRemoved = "Removed %s from the ricklist."
+	// MsgRickListNotListed is a user-visible string for removing a user who isn't on the ricklist
+	MsgRickListNotListed = "%s isn't on the ricklist."
+)
+
+// Execute removes the user from the ricklist. The timers of timed entries
+// still fire, but find nothing to expire.
+func (e *RickListRemoveExecutor) Execute(s api.DiscordSession, channel model.Snowflake, command *model.Command) {
+	if command.RickListRemove == nil {
+		log.Info("Tried to un-ricklist without a user", errors.New("missing user ID"))
+		return
+	}
+
+	removed, err := e.modelHelper.RemoveFromRickList(command.RickListRemove.UserID)
+	if err != nil {
+		log.Fatal("Error removing the user from the ricklist", err)
+	}
+
+	message := MsgRickListRemoved
+	if removed {
+		e.auditBus.Publish(s, &audit.Event{
+			Type:      audit.EventRickListRemove,
+			UserID:    authorID(command),
+			TargetID:  command.RickListRemove.UserID,
+			ChannelID: channel,
+		})
+	} else {
+		message = MsgRickListNotListed
+	}
+	if _, err := s.ChannelMessageSend(channel.Format(), fmt.Sprintf(message, rickListedName(s, command.RickListRemove.UserID))); err != nil {
+		log.Info("Failed to send ricklist message", err)
+	}
+}
@@ -0,0 +1,78 @@
+package moderation
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+	stringmap "github.com/jakevoytko/go-stringmap"
+)
+
+// RickListResponseAddExecutor adds a response that ricklisted users get.
+type RickListResponseAddExecutor struct {
+	modelHelper *ModelHelper
+	commandMap  stringmap.StringMap
+}
+
+// NewRickListResponseAddExecutor works as advertised.
+func NewRickListResponseAddExecutor(modelHelper *ModelHelper, commandMap stringmap.StringMap) *RickListResponseAddExecutor {
+	return &RickListResponseAddExecutor{
+		modelHelper: modelHelper,
+		commandMap:  commandMap,
+	}
+}
+
+// GetType returns the type.
+func (e *RickListResponseAddExecutor) GetType() int {
+	return model.CommandTypeRickListResponseAdd
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private channel.
+func (e *RickListResponseAddExecutor) PublicOnly() bool {
+	return false
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *RickListResponseAddExecutor) ModeratorOnly() bool {
+	return true
+}
+
+const (
+	// MsgRickListResponseAdded acknowledges a new ricklist response
+	MsgRickListResponseAdded = "Added a ricklist response for %s."
+	// MsgRickListResponseUnknownCommand indicates that the response refers to a
+	// command that wasn't learned
+	MsgRickListResponseUnknownCommand = "I don't know `?%s`."
+)
+
+// Execute adds the response. Learned commands must exist.
+func (e *RickListResponseAddExecutor) Execute(s api.DiscordSession, channel model.Snowflake, command *model.Command) {
+	if command.RickListResponse == nil || command.RickListResponse.Response == nil {
+		log.Info("Tried to add a ricklist response without a response", errors.New("missing response"))
+		return
+	}
+
+	response := command.RickListResponse.Response
+	message := fmt.Sprintf(MsgRickListResponseAdded, rickListResponseScope(s, command.RickListResponse.UserID))
+	ok := true
+	if response.Kind == model.RickListResponseCommand {
+		var err error
+		if ok, err = e.commandMap.Has(response.Text); err != nil {
+			log.Fatal("Error testing the ricklist response command", err)
+		}
+		if !ok {
+			message = fmt.Sprintf(MsgRickListResponseUnknownCommand, response.Text)
+		}
+	}
+	if ok {
+		if err := e.modelHelper.AddRickListResponse(command.RickListResponse.UserID, response); err != nil {
+			log.Fatal("Error adding the ricklist response", err)
+		}
+	}
+
+	if _, err := s.ChannelMessageSend(channel.Format(), message); err != nil {
+		log.Info("Failed to send ricklist message", err)
+	}
+}
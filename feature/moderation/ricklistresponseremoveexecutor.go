@@ -0,0 +1,66 @@
+package moderation
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// RickListResponseRemoveExecutor removes a response that ricklisted users get.
+type RickListResponseRemoveExecutor struct {
+	modelHelper *ModelHelper
+}
+
+// NewRickListResponseRemoveExecutor works as advertised.
+func NewRickListResponseRemoveExecutor(modelHelper *ModelHelper) *RickListResponseRemoveExecutor {
+	return &RickListResponseRemoveExecutor{
+		modelHelper: modelHelper,
+	}
+}
+
+// GetType returns the type.
+func (e *RickListResponseRemoveExecutor) GetType() int {
+	return model.CommandTypeRickListResponseRemove
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private channel.
+func (e *RickListResponseRemoveExecutor) PublicOnly() bool {
+	return false
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *RickListResponseRemoveExecutor) ModeratorOnly() bool {
+	return true
+}
+
+const (
+	// MsgRickListResponseRemoved acknowledges a removed ricklist response
+	MsgRickListResponseRemoved = "Removed ricklist response %d for %s."
+	// MsgRickListResponseNotFound indicates that there is no response with the number
+	MsgRickListResponseNotFound = "There's no ricklist response %d for %s."
+)
+
+// Execute removes the response with the given number.
+func (e *RickListResponseRemoveExecutor) Execute(s api.DiscordSession, channel model.Snowflake, command *model.Command) {
+	if command.RickListResponse == nil {
+		log.Info("Tried to remove a ricklist response without a number", errors.New("missing number"))
+		return
+	}
+
+	data := command.RickListResponse
+	ok, err := e.modelHelper.RemoveRickListResponse(data.UserID, data.Number)
+	if err != nil {
+		log.Fatal("Error removing the ricklist response", err)
+	}
+	message := fmt.Sprintf(MsgRickListResponseRemoved, data.Number, rickListResponseScope(s, data.UserID))
+	if !ok {
+		message = fmt.Sprintf(MsgRickListResponseNotFound, data.Number, rickListResponseScope(s, data.UserID))
+	}
+
+	if _, err := s.ChannelMessageSend(channel.Format(), message); err != nil {
+		log.Info("Failed to send ricklist message", err)
+	}
+}
@@ -0,0 +1,84 @@
+package moderation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// RickListResponsesExecutor prints the responses that ricklisted users get.
+type RickListResponsesExecutor struct {
+	modelHelper *ModelHelper
+}
+
+// NewRickListResponsesExecutor works as advertised.
+func NewRickListResponsesExecutor(modelHelper *ModelHelper) *RickListResponsesExecutor {
+	return &RickListResponsesExecutor{
+		modelHelper: modelHelper,
+	}
+}
+
+// GetType returns the type.
+func (e *RickListResponsesExecutor) GetType() int {
+	return model.CommandTypeRickListResponses
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private
+// channel. Moderators can check the responses without ricklisted users seeing
+// them.
+func (e *RickListResponsesExecutor) PublicOnly() bool {
+	return false
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *RickListResponsesExecutor) ModeratorOnly() bool {
+	return true
+}
+
+const (
+	// MsgRickListResponses is a header for the responses that ricklisted users get
+	MsgRickListResponses = "Ricklist responses for %s:"
+	// MsgRickListResponsesEmpty prints that there are no responses, so ricklisted
+	// users get a rick roll
+	MsgRickListResponsesEmpty = "There are no ricklist responses for %s."
+	// MsgRickListResponsesEveryone names the responses that every ricklisted user gets
+	MsgRickListResponsesEveryone = "everyone"
+)
+
+// Execute replies over the given channel with the numbered responses.
+func (e *RickListResponsesExecutor) Execute(s api.DiscordSession, channel model.Snowflake, command *model.Command) {
+	if command.RickListResponse == nil {
+		log.Info("Tried to list ricklist responses without a user", errors.New("missing data"))
+		return
+	}
+
+	responses, err := e.modelHelper.RickListResponses(command.RickListResponse.UserID)
+	if err != nil {
+		log.Fatal("Error reading the ricklist responses", err)
+	}
+	scope := rickListResponseScope(s, command.RickListResponse.UserID)
+	message := fmt.Sprintf(MsgRickListResponsesEmpty, scope)
+	if len(responses) > 0 {
+		lines := []string{fmt.Sprintf(MsgRickListResponses, scope)}
+		for i, response := range responses {
+			lines = append(lines, fmt.Sprintf("%d. %s", i+1, response.Format()))
+		}
+		message = strings.Join(lines, "\n")
+	}
+
+	if _, err := s.ChannelMessageSend(channel.Format(), message); err != nil {
+		log.Info("Failed to send ricklist message", err)
+	}
+}
+
+// rickListResponseScope names whose responses are shown or changed.
+func rickListResponseScope(s api.DiscordSession, userID model.Snowflake) string {
+	if userID == 0 {
+		return MsgRickListResponsesEveryone
+	}
+	return rickListedName(s, userID)
+}
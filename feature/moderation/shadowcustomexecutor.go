@@ -0,0 +1,65 @@
+package moderation
+
+import (
+	"errors"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/feature/learn"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// ShadowCustomExecutor responds to the calls that a shadowbanned user learned.
+// It only responds in private channels, so nobody else sees the responses.
+type ShadowCustomExecutor struct {
+	modelHelper *ModelHelper
+}
+
+// NewShadowCustomExecutor works as advertised.
+func NewShadowCustomExecutor(modelHelper *ModelHelper) *ShadowCustomExecutor {
+	return &ShadowCustomExecutor{
+		modelHelper: modelHelper,
+	}
+}
+
+// GetType returns the type.
+func (e *ShadowCustomExecutor) GetType() int {
+	return model.CommandTypeShadowCustom
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private channel.
+func (e *ShadowCustomExecutor) PublicOnly() bool {
+	return false
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *ShadowCustomExecutor) ModeratorOnly() bool {
+	return false
+}
+
+// Execute replies the same way as a learned command in private channels, and
+// silently drops the call in public ones.
+func (e *ShadowCustomExecutor) Execute(s api.DiscordSession, channel model.Snowflake, command *model.Command) {
+	if command.Custom == nil {
+		log.Fatal("Incorrectly generated shadow custom command", errors.New("wat"))
+	}
+
+	discordChannel, err := s.Channel(channel.Format())
+	if err != nil {
+		log.Info("Error retrieving channel for a shadow learn", err)
+		return
+	}
+	if discordChannel.Type != discordgo.ChannelTypeDM && discordChannel.Type != discordgo.ChannelTypeGroupDM {
+		return
+	}
+
+	response, ok, err := e.modelHelper.ShadowResponse(authorID(command), command.Custom.Call)
+	if err != nil {
+		log.Fatal("Error reading a shadow learn", err)
+	}
+	if !ok {
+		return
+	}
+	s.ChannelMessageSend(channel.Format(), learn.FormatResponse(response, command.Custom.Args))
+}
@@ -0,0 +1,65 @@
+package moderation
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/feature/learn"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// ShadowLearnExecutor pretends to learn a shadowbanned user's command. Only
+// they can call it.
+type ShadowLearnExecutor struct {
+	modelHelper *ModelHelper
+}
+
+// NewShadowLearnExecutor works as advertised.
+func NewShadowLearnExecutor(modelHelper *ModelHelper) *ShadowLearnExecutor {
+	return &ShadowLearnExecutor{
+		modelHelper: modelHelper,
+	}
+}
+
+// GetType returns the type.
+func (e *ShadowLearnExecutor) GetType() int {
+	return model.CommandTypeShadowLearn
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private channel.
+func (e *ShadowLearnExecutor) PublicOnly() bool {
+	return false
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *ShadowLearnExecutor) ModeratorOnly() bool {
+	return false
+}
+
+// Execute replies the same way as ?learn, but stores the call where only the
+// shadowbanned user finds it.
+func (e *ShadowLearnExecutor) Execute(s api.DiscordSession, channel model.Snowflake, command *model.Command) {
+	if command.Learn == nil {
+		log.Fatal("Incorrectly generated learn command", errors.New("wat"))
+	}
+	userID := authorID(command)
+	if !command.Learn.CallOpen {
+		s.ChannelMessageSend(channel.Format(), fmt.Sprintf(learn.MsgLearnFail, command.Learn.Call))
+		return
+	}
+	_, ok, err := e.modelHelper.ShadowResponse(userID, command.Learn.Call)
+	if err != nil {
+		log.Fatal("Error testing a shadow learn", err)
+	}
+	if ok {
+		s.ChannelMessageSend(channel.Format(), fmt.Sprintf(learn.MsgLearnFail, command.Learn.Call))
+		return
+	}
+
+	if err := e.modelHelper.ShadowLearn(userID, command.Learn.Call, command.Learn.Response); err != nil {
+		log.Fatal("Error storing a shadow learn", err)
+	}
+	s.ChannelMessageSend(channel.Format(), fmt.Sprintf(learn.MsgLearnSuccess, command.Learn.Call))
+}
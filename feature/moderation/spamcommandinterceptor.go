@@ -0,0 +1,183 @@
+package moderation
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/config"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// SpamCommandInterceptor ignores bursts of near-identical commands from a user
+// or across a channel. Users who keep going past their own limit are warned,
+// and then put on the ricklist for a while. Channel bursts are only throttled,
+// since the latest command isn't necessarily the spam.
+type SpamCommandInterceptor struct {
+	utcClock model.UTCClock
+	config   *config.Config
+	// Recent commands, within the spam window.
+	userCommands    map[model.Snowflake][]spamRecord
+	channelCommands map[model.Snowflake][]spamRecord
+	// Recently ignored commands that went past the user's own limit, within the
+	// strike window.
+	strikes map[model.Snowflake][]time.Time
+	// When the users and channels that went quiet were last forgotten.
+	lastSweep time.Time
+}
+
+// spamRecord is a command that was seen at a given time.
+type spamRecord struct {
+	timestamp time.Time
+	signature string
+}
+
+// NewSpamCommandInterceptor works as advertised.
+func NewSpamCommandInterceptor(utcClock model.UTCClock, config *config.Config) *SpamCommandInterceptor {
+	return &SpamCommandInterceptor{
+		utcClock:        utcClock,
+		config:          config,
+		userCommands:    map[model.Snowflake][]spamRecord{},
+		channelCommands: map[model.Snowflake][]spamRecord{},
+		strikes:         map[model.Snowflake][]time.Time{},
+	}
+}
+
+// Intercept ignores the command if it was repeated too often, and escalates
+// to warning or ricklisting its author if they keep repeating it.
+func (i *SpamCommandInterceptor) Intercept(command *model.Command, s api.DiscordSession) (*model.Command, error) {
+	// Only commands that users typed count. Commands without an author come from
+	// crbot itself, and moderators are trusted.
+	if command.Author == nil || len(command.OriginalName) == 0 || !i.config.SpamProtected() {
+		return command, nil
+	}
+	userID, err := model.ParseSnowflake(command.Author.ID)
+	if err != nil || i.config.IsModerator(userID) {
+		return command, nil
+	}
+
+	now := i.utcClock.Now()
+	record := spamRecord{timestamp: now, signature: spamSignature(command)}
+	windowStart := now.Add(-i.config.SpamWindow())
+	strikeWindowStart := now.Add(-i.config.SpamStrikeWindow())
+	if i.lastSweep.Before(windowStart) {
+		i.sweep(windowStart, strikeWindowStart)
+		i.lastSweep = now
+	}
+	userCommands := append(pruneSpamRecords(i.userCommands[userID], windowStart), record)
+	i.userCommands[userID] = userCommands
+	channelCommands := append(pruneSpamRecords(i.channelCommands[command.ChannelID], windowStart), record)
+	i.channelCommands[command.ChannelID] = channelCommands
+
+	userSpam := i.config.SpamUserLimit > 0 && countSpamRecords(userCommands, record.signature) > i.config.SpamUserLimit
+	channelSpam := i.config.SpamChannelLimit > 0 && countSpamRecords(channelCommands, record.signature) > i.config.SpamChannelLimit
+	if !userSpam && !channelSpam {
+		return command, nil
+	}
+	ignored := &model.Command{
+		Type:      model.CommandTypeNone,
+		ChannelID: command.ChannelID,
+	}
+	if !userSpam {
+		return ignored, nil
+	}
+
+	strikes := append(pruneStrikes(i.strikes[userID], strikeWindowStart), now)
+	i.strikes[userID] = strikes
+
+	switch {
+	case len(strikes) >= i.config.SpamRickListLimit():
+		delete(i.strikes, userID)
+		return newSpamCommand(model.CommandTypeSpamRickList, command, userID), nil
+	case len(strikes) == i.config.SpamWarnLimit():
+		return newSpamCommand(model.CommandTypeSpamWarning, command, userID), nil
+	}
+	return ignored, nil
+}
+
+// sweep forgets the users and channels that have nothing left in their
+// windows, so that the maps don't grow with everyone who ever sent a command.
+func (i *SpamCommandInterceptor) sweep(windowStart, strikeWindowStart time.Time) {
+	for _, commands := range []map[model.Snowflake][]spamRecord{i.userCommands, i.channelCommands} {
+		for id, records := range commands {
+			if pruned := pruneSpamRecords(records, windowStart); len(pruned) > 0 {
+				commands[id] = pruned
+			} else {
+				delete(commands, id)
+			}
+		}
+	}
+	for userID, strikes := range i.strikes {
+		if pruned := pruneStrikes(strikes, strikeWindowStart); len(pruned) > 0 {
+			i.strikes[userID] = pruned
+		} else {
+			delete(i.strikes, userID)
+		}
+	}
+}
+
+// newSpamCommand returns a command that acts on the user who repeated the
+// command. It has no author, so it isn't intercepted again.
+func newSpamCommand(commandType int, command *model.Command, userID model.Snowflake) *model.Command {
+	return &model.Command{
+		Type:      commandType,
+		Author:    nil,
+		ChannelID: command.ChannelID,
+		GuildID:   command.GuildID,
+		Spam: &model.SpamData{
+			UserID:  userID,
+			Command: command.OriginalName,
+		},
+	}
+}
+
+// spamSignature returns the text that near-identical commands share. Case,
+// punctuation, and spacing are ignored.
+func spamSignature(command *model.Command) string {
+	content := command.Content
+	if len(content) == 0 {
+		content = command.OriginalName
+	}
+	content = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '?' {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, content)
+	return strings.Join(strings.Fields(content), " ")
+}
+
+// pruneSpamRecords returns the records that were seen after the start of the
+// window.
+func pruneSpamRecords(records []spamRecord, windowStart time.Time) []spamRecord {
+	pruned := []spamRecord{}
+	for _, record := range records {
+		if record.timestamp.After(windowStart) {
+			pruned = append(pruned, record)
+		}
+	}
+	return pruned
+}
+
+// pruneStrikes returns the strikes that were given after the start of the
+// window.
+func pruneStrikes(strikes []time.Time, windowStart time.Time) []time.Time {
+	pruned := []time.Time{}
+	for _, strike := range strikes {
+		if strike.After(windowStart) {
+			pruned = append(pruned, strike)
+		}
+	}
+	return pruned
+}
+
+func countSpamRecords(records []spamRecord, signature string) int {
+	count := 0
+	for _, record := range records {
+		if record.signature == signature {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,124 @@
+package moderation
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/audit"
+	"github.com/jakevoytko/crbot/config"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// SpamRickListExecutor puts users who keep repeating a command on the ricklist
+// for a while. The ricklist only answers private commands, so they are muted
+// for as long, which drops their public commands too.
+type SpamRickListExecutor struct {
+	modelHelper    *ModelHelper
+	auditBus       *audit.Bus
+	utcTimer       model.UTCTimer
+	commandChannel chan<- *model.Command
+	config         *config.Config
+}
+
+// NewSpamRickListExecutor works as advertised.
+func NewSpamRickListExecutor(modelHelper *ModelHelper, auditBus *audit.Bus, utcTimer model.UTCTimer, commandChannel chan<- *model.Command, config *config.Config) *SpamRickListExecutor {
+	return &SpamRickListExecutor{
+		modelHelper:    modelHelper,
+		auditBus:       auditBus,
+		utcTimer:       utcTimer,
+		commandChannel: commandChannel,
+		config:         config,
+	}
+}
+
+// GetType returns the type.
+func (e *SpamRickListExecutor) GetType() int {
+	return model.CommandTypeSpamRickList
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private
+// channel.
+func (e *SpamRickListExecutor) PublicOnly() bool {
+	return false
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *SpamRickListExecutor) ModeratorOnly() bool {
+	return false
+}
+
+const (
+	// MsgSpamRickListed announces that a user was put on the ricklist for
+	// repeating a command
+	MsgSpamRickListed = "Added %s to the ricklist for %s for repeating `%s`."
+	// MsgSpamMuted announces that a user who is already on the ricklist was
+	// muted for repeating a command
+	MsgSpamMuted = "Muted %s for %s for repeating `%s`."
+)
+
+// Execute ricklists and mutes the user, unless they are already ricklisted or
+// muted for longer. Shadowbanned users are left alone, so that they don't find
+// out.
+func (e *SpamRickListExecutor) Execute(s api.DiscordSession, channel model.Snowflake, command *model.Command) {
+	if command.Spam == nil {
+		log.Info("Tried to ricklist a spammer without a user", errors.New("missing user ID"))
+		return
+	}
+
+	userID := command.Spam.UserID
+	duration := e.config.SpamRickListDuration()
+	expires := e.modelHelper.utcClock.Now().Add(duration)
+	existing, err := e.modelHelper.ActiveRickListEntry(userID)
+	if err != nil {
+		log.Fatal("Error reading the ricklist entry", err)
+	}
+	if existing != nil && existing.Shadowban {
+		return
+	}
+
+	rickListed := false
+	if existing == nil || (existing.Expires() && existing.TimestampExpires.Before(expires)) {
+		entry, err := e.modelHelper.AddToRickList(userID, 0, channel, duration, false)
+		if err != nil {
+			log.Fatal("Error adding the spammer to the ricklist", err)
+		}
+		scheduleRickListExpiry(e.modelHelper.utcClock, e.utcTimer, e.commandChannel, entry)
+		rickListed = true
+	}
+
+	muted := false
+	existingMute, err := e.modelHelper.readMute(fmt.Sprintf(KeyMuteUser, userID))
+	if err != nil {
+		log.Fatal("Error reading the spammer's mute", err)
+	}
+	if existingMute == nil || existingMute.TimestampExpires.Before(expires) {
+		mute, err := e.modelHelper.Mute(userID, 0, 0, channel, duration, "repeating `"+command.Spam.Command+"`")
+		if err != nil {
+			log.Fatal("Error muting the spammer", err)
+		}
+		scheduleMuteExpiry(e.modelHelper.utcClock, e.utcTimer, e.commandChannel, mute)
+		muted = true
+	}
+
+	var message string
+	switch {
+	case rickListed:
+		message = fmt.Sprintf(MsgSpamRickListed, rickListedName(s, userID), durationString(duration), command.Spam.Command)
+	case muted:
+		message = fmt.Sprintf(MsgSpamMuted, rickListedName(s, userID), durationString(duration), command.Spam.Command)
+	default:
+		return
+	}
+	e.auditBus.Publish(s, &audit.Event{
+		Type:      audit.EventSpamRickList,
+		TargetID:  userID,
+		ChannelID: channel,
+		Command:   command.Spam.Command,
+		Details:   "for " + durationString(duration),
+	})
+	if _, err := s.ChannelMessageSend(channel.Format(), message); err != nil {
+		log.Info("Failed to send ricklist message", err)
+	}
+}
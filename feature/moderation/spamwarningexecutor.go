@@ -0,0 +1,64 @@
+package moderation
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/audit"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// SpamWarningExecutor warns users who keep repeating a command.
+type SpamWarningExecutor struct {
+	auditBus *audit.Bus
+}
+
+// NewSpamWarningExecutor works as advertised.
+func NewSpamWarningExecutor(auditBus *audit.Bus) *SpamWarningExecutor {
+	return &SpamWarningExecutor{
+		auditBus: auditBus,
+	}
+}
+
+// GetType returns the type.
+func (e *SpamWarningExecutor) GetType() int {
+	return model.CommandTypeSpamWarning
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private
+// channel.
+func (e *SpamWarningExecutor) PublicOnly() bool {
+	return false
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *SpamWarningExecutor) ModeratorOnly() bool {
+	return false
+}
+
+const (
+	// MsgSpamWarning warns a user that they will be ricklisted if they keep
+	// repeating a command
+	MsgSpamWarning = "%s, please stop repeating `%s`, or you'll be put on the ricklist."
+)
+
+// Execute warns the user.
+func (e *SpamWarningExecutor) Execute(s api.DiscordSession, channel model.Snowflake, command *model.Command) {
+	if command.Spam == nil {
+		log.Info("Tried to warn about spam without a user", errors.New("missing user ID"))
+		return
+	}
+
+	e.auditBus.Publish(s, &audit.Event{
+		Type:      audit.EventSpamWarning,
+		TargetID:  command.Spam.UserID,
+		ChannelID: channel,
+		Command:   command.Spam.Command,
+	})
+	message := fmt.Sprintf(MsgSpamWarning, rickListedName(s, command.Spam.UserID), command.Spam.Command)
+	if _, err := s.ChannelMessageSend(channel.Format(), message); err != nil {
+		log.Info("Failed to send spam warning", err)
+	}
+}
@@ -0,0 +1,67 @@
+package permissions
+
+import (
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/audit"
+	"github.com/jakevoytko/crbot/config"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// ACLCommandInterceptor refuses commands that the config's command policies
+// don't allow.
+type ACLCommandInterceptor struct {
+	auditBus *audit.Bus
+	config   *config.Config
+}
+
+// NewACLCommandInterceptor works as advertised.
+func NewACLCommandInterceptor(auditBus *audit.Bus, config *config.Config) *ACLCommandInterceptor {
+	return &ACLCommandInterceptor{
+		auditBus: auditBus,
+		config:   config,
+	}
+}
+
+// Intercept rewrites commands that the caller isn't allowed to run into a
+// refusal. Commands without an author come from crbot itself, and are always
+// allowed.
+func (i *ACLCommandInterceptor) Intercept(command *model.Command, s api.DiscordSession) (*model.Command, error) {
+	if command.Author == nil {
+		return command, nil
+	}
+	name := commandName(command)
+	policy := i.config.CommandPolicy(name)
+	if policy == nil {
+		return command, nil
+	}
+
+	if caller, reason := Check(s, policy, command); len(reason) > 0 {
+		return i.deny(s, command, name, caller, reason), nil
+	}
+	return command, nil
+}
+
+// deny records the denial, and returns the refusal that replaces the command.
+func (i *ACLCommandInterceptor) deny(s api.DiscordSession, command *model.Command, name string, caller *Caller, reason string) *model.Command {
+	i.auditBus.Publish(s, &audit.Event{
+		Type:      audit.EventPermissionDenied,
+		UserID:    caller.UserID,
+		ChannelID: caller.ChannelID,
+		Command:   name,
+		Details:   reason,
+	})
+	return newPermissionDeniedCommand(command, name)
+}
+
+// newPermissionDeniedCommand returns the refusal that replaces the command.
+func newPermissionDeniedCommand(command *model.Command, name string) *model.Command {
+	return &model.Command{
+		Type:         model.CommandTypePermissionDenied,
+		Author:       nil,
+		ChannelID:    command.ChannelID,
+		OriginalName: name,
+		PermissionDenied: &model.PermissionsData{
+			Command: name,
+		},
+	}
+}
@@ -0,0 +1,66 @@
+package permissions
+
+import (
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/audit"
+	"github.com/jakevoytko/crbot/config"
+	"github.com/jakevoytko/crbot/feature"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// Feature restricts commands to the users, roles, and channels in the config's
+// command policies.
+type Feature struct {
+	featureRegistry *feature.Registry
+	auditBus        *audit.Bus
+	config          *config.Config
+}
+
+// NewFeature returns a new Feature.
+func NewFeature(featureRegistry *feature.Registry, auditBus *audit.Bus, config *config.Config) *Feature {
+	return &Feature{
+		featureRegistry: featureRegistry,
+		auditBus:        auditBus,
+		config:          config,
+	}
+}
+
+// GetName returns the name that channels enable and disable the feature by.
+func (f *Feature) GetName() string {
+	return model.FeatureNamePermissions
+}
+
+// Parsers returns the parsers.
+func (f *Feature) Parsers() []feature.Parser {
+	return []feature.Parser{
+		NewPermissionsParser(),
+	}
+}
+
+// CommandInterceptors returns command interceptors.
+func (f *Feature) CommandInterceptors() []feature.CommandInterceptor {
+	return []feature.CommandInterceptor{
+		NewACLCommandInterceptor(f.auditBus, f.config),
+	}
+}
+
+// FallbackParser returns nil.
+func (f *Feature) FallbackParser() feature.Parser {
+	return nil
+}
+
+// Executors gets the executors.
+func (f *Feature) Executors() []feature.Executor {
+	return []feature.Executor{
+		NewPermissionDeniedExecutor(),
+		NewPermissionsExecutor(f.config),
+	}
+}
+
+// Actions returns nothing.
+func (f *Feature) Actions() []feature.Action {
+	return []feature.Action{}
+}
+
+// OnInitialLoad does nothing.
+func (f *Feature) OnInitialLoad(s api.DiscordSession) error { return nil }
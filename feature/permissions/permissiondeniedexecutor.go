@@ -0,0 +1,48 @@
+package permissions
+
+import (
+	"fmt"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// PermissionDeniedExecutor politely refuses a command.
+type PermissionDeniedExecutor struct{}
+
+// NewPermissionDeniedExecutor works as advertised.
+func NewPermissionDeniedExecutor() *PermissionDeniedExecutor {
+	return &PermissionDeniedExecutor{}
+}
+
+// GetType returns the type.
+func (e *PermissionDeniedExecutor) GetType() int {
+	return model.CommandTypePermissionDenied
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private channel.
+func (e *PermissionDeniedExecutor) PublicOnly() bool {
+	return false
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *PermissionDeniedExecutor) ModeratorOnly() bool {
+	return false
+}
+
+const (
+	// MsgPermissionDenied is a user-visible string for commands that the caller isn't allowed to run
+	MsgPermissionDenied = "Sorry, you don't have permission to use `%s` here. Type `?permissions %s` to find out why."
+)
+
+// Execute replies over the given channel with the refusal.
+func (e *PermissionDeniedExecutor) Execute(s api.DiscordSession, channel model.Snowflake, command *model.Command) {
+	name := ""
+	if command.PermissionDenied != nil {
+		name = command.PermissionDenied.Command
+	}
+	if _, err := s.ChannelMessageSend(channel.Format(), fmt.Sprintf(MsgPermissionDenied, name, name)); err != nil {
+		log.Info("Failed to send permission denied message", err)
+	}
+}
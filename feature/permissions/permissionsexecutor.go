@@ -0,0 +1,109 @@
+package permissions
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/config"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// PermissionsExecutor explains a command's policy to the caller.
+type PermissionsExecutor struct {
+	config *config.Config
+}
+
+// NewPermissionsExecutor works as advertised.
+func NewPermissionsExecutor(config *config.Config) *PermissionsExecutor {
+	return &PermissionsExecutor{
+		config: config,
+	}
+}
+
+// GetType returns the type.
+func (e *PermissionsExecutor) GetType() int {
+	return model.CommandTypePermissions
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private channel.
+func (e *PermissionsExecutor) PublicOnly() bool {
+	return false
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *PermissionsExecutor) ModeratorOnly() bool {
+	return false
+}
+
+const (
+	// MsgPermissionsUnrestricted explains that a command has no policy
+	MsgPermissionsUnrestricted = "Everyone can use `%s` everywhere."
+	// MsgPermissionsAllowed explains that the caller can use the command in this channel
+	MsgPermissionsAllowed = "You can use `%s` here."
+	// MsgPermissionsDenied explains why the caller can't use the command in this channel
+	MsgPermissionsDenied = "You can't use `%s` here, because %s."
+	// MsgPolicyAllowedUsers lists the users allowed to use a command
+	MsgPolicyAllowedUsers = "Allowed users: %s"
+	// MsgPolicyAllowedRoles lists the roles allowed to use a command
+	MsgPolicyAllowedRoles = "Allowed roles: %s"
+	// MsgPolicyAllowedChannels lists the channels where a command can be used
+	MsgPolicyAllowedChannels = "Allowed channels: %s"
+	// MsgPolicyDeniedUsers lists the users who can't use a command
+	MsgPolicyDeniedUsers = "Denied users: %s"
+	// MsgPolicyDeniedRoles lists the roles that can't use a command
+	MsgPolicyDeniedRoles = "Denied roles: %s"
+	// MsgPolicyDeniedChannels lists the channels where a command can't be used
+	MsgPolicyDeniedChannels = "Denied channels: %s"
+)
+
+// Execute replies over the given channel with whether the caller can use the
+// command here, followed by the command's policy.
+func (e *PermissionsExecutor) Execute(s api.DiscordSession, channel model.Snowflake, command *model.Command) {
+	if command.Permissions == nil || command.Author == nil {
+		return
+	}
+	name := command.Permissions.Command
+	policy := e.config.CommandPolicy(name)
+	if policy == nil {
+		if _, err := s.ChannelMessageSend(channel.Format(), fmt.Sprintf(MsgPermissionsUnrestricted, name)); err != nil {
+			log.Info("Failed to send permissions message", err)
+		}
+		return
+	}
+
+	caller, err := NewCaller(s, policy, command)
+	if err != nil {
+		log.Info("Unable to look up the caller of ?permissions", err)
+		return
+	}
+
+	lines := []string{fmt.Sprintf(MsgPermissionsAllowed, name)}
+	if reason := DeniedReason(policy, caller); len(reason) > 0 {
+		lines[0] = fmt.Sprintf(MsgPermissionsDenied, name, reason)
+	}
+	lines = appendPolicyLine(lines, MsgPolicyAllowedUsers, "<@%v>", policy.AllowUsers)
+	lines = appendPolicyLine(lines, MsgPolicyAllowedRoles, "<@&%v>", policy.AllowRoles)
+	lines = appendPolicyLine(lines, MsgPolicyAllowedChannels, "<#%v>", policy.AllowChannels)
+	lines = appendPolicyLine(lines, MsgPolicyDeniedUsers, "<@%v>", policy.DenyUsers)
+	lines = appendPolicyLine(lines, MsgPolicyDeniedRoles, "<@&%v>", policy.DenyRoles)
+	lines = appendPolicyLine(lines, MsgPolicyDeniedChannels, "<#%v>", policy.DenyChannels)
+
+	if _, err := s.ChannelMessageSend(channel.Format(), strings.Join(lines, "\n")); err != nil {
+		log.Info("Failed to send permissions message", err)
+	}
+}
+
+// appendPolicyLine appends a line listing the IDs as Discord mentions, unless
+// there are none.
+func appendPolicyLine(lines []string, message, mentionFormat string, ids []model.Snowflake) []string {
+	if len(ids) == 0 {
+		return lines
+	}
+	mentions := make([]string, 0, len(ids))
+	for _, id := range ids {
+		mentions = append(mentions, fmt.Sprintf(mentionFormat, id))
+	}
+	return append(lines, fmt.Sprintf(message, strings.Join(mentions, ", ")))
+}
@@ -0,0 +1,59 @@
+package permissions
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jakevoytko/crbot/model"
+	"github.com/jakevoytko/crbot/util"
+)
+
+// PermissionsParser parses ?permissions commands.
+type PermissionsParser struct{}
+
+// NewPermissionsParser works as advertised.
+func NewPermissionsParser() *PermissionsParser {
+	return &PermissionsParser{}
+}
+
+// GetName returns the named type of this feature.
+func (p *PermissionsParser) GetName() string {
+	return model.CommandNamePermissions
+}
+
+const (
+	// MsgHelpPermissions is the help text for ?permissions
+	MsgHelpPermissions = "Type `?permissions <command>` to find out whether you can use the command here, and who it is restricted to."
+)
+
+// HelpText explains how to use ?permissions.
+func (p *PermissionsParser) HelpText(command string) (string, error) {
+	return MsgHelpPermissions, nil
+}
+
+// Parse parses the given permissions command.
+func (p *PermissionsParser) Parse(splitContent []string, m *discordgo.MessageCreate) (*model.Command, error) {
+	if splitContent[0] != p.GetName() {
+		log.Fatal("PermissionsParser.Parse called with non-permissions command", errors.New("wat"))
+	}
+
+	splitContent = util.CollapseWhitespace(splitContent, 1)
+	splitContent = util.CollapseWhitespace(splitContent, 2)
+	if len(splitContent) != 2 || len(strings.TrimPrefix(splitContent[1], "?")) == 0 {
+		return &model.Command{
+			Type: model.CommandTypeHelp,
+			Help: &model.HelpData{
+				Command: p.GetName(),
+			},
+		}, nil
+	}
+
+	return &model.Command{
+		Type: model.CommandTypePermissions,
+		Permissions: &model.PermissionsData{
+			Command: "?" + strings.TrimPrefix(splitContent[1], "?"),
+		},
+	}, nil
+}
@@ -0,0 +1,137 @@
+package permissions
+
+import (
+	"fmt"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/config"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// Caller is who ran a command, and where.
+type Caller struct {
+	UserID    model.Snowflake
+	ChannelID model.Snowflake
+	RoleIDs   []model.Snowflake
+}
+
+const (
+	// MsgReasonDeniedUser explains that the caller is on a deny list
+	MsgReasonDeniedUser = "you are not allowed to use it"
+	// MsgReasonDeniedRole explains that one of the caller's roles is on a deny list
+	MsgReasonDeniedRole = "one of your roles is not allowed to use it"
+	// MsgReasonDeniedChannel explains that the channel is on a deny list
+	MsgReasonDeniedChannel = "it can't be used in this channel"
+	// MsgReasonNotAllowedUser explains that the caller isn't on the allowed users list
+	MsgReasonNotAllowedUser = "only certain users can use it"
+	// MsgReasonNotAllowedRole explains that the caller has none of the allowed roles
+	MsgReasonNotAllowedRole = "only certain roles can use it"
+	// MsgReasonNotAllowedChannel explains that the channel isn't on the allowed channels list
+	MsgReasonNotAllowedChannel = "it can only be used in certain channels"
+)
+
+// DeniedReason returns why the policy refuses the caller, or the empty string
+// if the caller is allowed. Denials are checked before allowances.
+func DeniedReason(policy *config.CommandPolicy, caller *Caller) string {
+	if policy == nil {
+		return ""
+	}
+	switch {
+	case containsSnowflake(policy.DenyUsers, caller.UserID):
+		return MsgReasonDeniedUser
+	case containsAnySnowflake(policy.DenyRoles, caller.RoleIDs):
+		return MsgReasonDeniedRole
+	case containsSnowflake(policy.DenyChannels, caller.ChannelID):
+		return MsgReasonDeniedChannel
+	case len(policy.AllowUsers) > 0 && !containsSnowflake(policy.AllowUsers, caller.UserID):
+		return MsgReasonNotAllowedUser
+	case len(policy.AllowRoles) > 0 && !containsAnySnowflake(policy.AllowRoles, caller.RoleIDs):
+		return MsgReasonNotAllowedRole
+	case len(policy.AllowChannels) > 0 && !containsSnowflake(policy.AllowChannels, caller.ChannelID):
+		return MsgReasonNotAllowedChannel
+	}
+	return ""
+}
+
+// Check returns the caller, and why the policy refuses them the command, or
+// the empty string if they are allowed. Callers that can't be looked up are
+// refused.
+func Check(s api.DiscordSession, policy *config.CommandPolicy, command *model.Command) (*Caller, string) {
+	caller, err := NewCaller(s, policy, command)
+	if err != nil {
+		log.Info("Unable to look up the caller of a restricted command", err)
+		return &Caller{ChannelID: command.ChannelID}, err.Error()
+	}
+	return caller, DeniedReason(policy, caller)
+}
+
+// NewCaller looks up the author of the command. Roles are only looked up when
+// the policy mentions roles, since that takes a call to Discord. Callers whose
+// roles can't be looked up have no roles.
+func NewCaller(s api.DiscordSession, policy *config.CommandPolicy, command *model.Command) (*Caller, error) {
+	userID, err := model.ParseSnowflake(command.Author.ID)
+	if err != nil {
+		return nil, err
+	}
+	caller := &Caller{
+		UserID:    userID,
+		ChannelID: command.ChannelID,
+		RoleIDs:   []model.Snowflake{},
+	}
+	if policy == nil || len(policy.AllowRoles)+len(policy.DenyRoles) == 0 {
+		return caller, nil
+	}
+
+	discordChannel, err := s.Channel(command.ChannelID.Format())
+	if err != nil {
+		return nil, err
+	}
+	// Private channels aren't in a guild, so nobody has roles there.
+	if len(discordChannel.GuildID) == 0 {
+		return caller, nil
+	}
+	member, err := s.GuildMember(discordChannel.GuildID, command.Author.ID)
+	if err != nil {
+		log.Info(fmt.Sprintf("Unable to get the roles of user %v", userID), err)
+		return caller, nil
+	}
+	for _, role := range member.Roles {
+		roleID, err := model.ParseSnowflake(role)
+		if err != nil {
+			return nil, err
+		}
+		caller.RoleIDs = append(caller.RoleIDs, roleID)
+	}
+	return caller, nil
+}
+
+// commandName returns the name the command was invoked with, including the
+// leading ?, or the empty string for messages that aren't commands.
+func commandName(command *model.Command) string {
+	if len(command.OriginalName) > 0 {
+		return command.OriginalName
+	}
+	if command.Custom != nil {
+		return "?" + command.Custom.Call
+	}
+	return ""
+}
+
+func containsSnowflake(ids []model.Snowflake, id model.Snowflake) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAnySnowflake(ids []model.Snowflake, candidates []model.Snowflake) bool {
+	for _, candidate := range candidates {
+		if containsSnowflake(ids, candidate) {
+			return true
+		}
+	}
+	return false
+}
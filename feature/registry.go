@@ -3,6 +3,7 @@ package feature
 import (
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/jakevoytko/crbot/api"
 )
@@ -16,8 +17,14 @@ type Registry struct {
 	nameToParser          map[string]Parser
 	interceptors          []CommandInterceptor
 	typeToExecutor        map[int]Executor
+	nameToAction          map[string]Action
 	invokableFeatureNames []string
 	initialLoadFns        []func(s api.DiscordSession) error
+
+	// Which feature owns each parser and executor, by feature name.
+	featureNames            []string
+	parserNameToFeatureName map[string]string
+	typeToFeatureName       map[int]string
 }
 
 // NewRegistry works as advertised.
@@ -26,14 +33,24 @@ func NewRegistry() *Registry {
 		nameToParser:          map[string]Parser{},
 		interceptors:          []CommandInterceptor{},
 		typeToExecutor:        map[int]Executor{},
+		nameToAction:          map[string]Action{},
 		invokableFeatureNames: []string{},
 		initialLoadFns:        []func(s api.DiscordSession) error{},
+
+		featureNames:            []string{},
+		parserNameToFeatureName: map[string]string{},
+		typeToFeatureName:       map[int]string{},
 	}
 }
 
 // Register attempts to register the given feature by name. If a feature with
 // the given name exists, then error.
 func (r *Registry) Register(feature Feature) error {
+	if r.IsFeature(feature.GetName()) {
+		return fmt.Errorf("duplicate feature: %v", feature.GetName())
+	}
+	r.featureNames = append(r.featureNames, feature.GetName())
+
 	// Register regular parsers.
 	for _, parser := range feature.Parsers() {
 		if _, ok := r.nameToParser[parser.GetName()]; ok {
@@ -41,6 +58,7 @@ func (r *Registry) Register(feature Feature) error {
 		}
 		if len(parser.GetName()) > 0 {
 			r.nameToParser[parser.GetName()] = parser
+			r.parserNameToFeatureName[parser.GetName()] = feature.GetName()
 			r.invokableFeatureNames = append(r.invokableFeatureNames, parser.GetName())
 		}
 	}
@@ -62,6 +80,15 @@ func (r *Registry) Register(feature Feature) error {
 			return fmt.Errorf("duplicate executor: %v", executor.GetType())
 		}
 		r.typeToExecutor[executor.GetType()] = executor
+		r.typeToFeatureName[executor.GetType()] = feature.GetName()
+	}
+
+	// Register actions.
+	for _, action := range feature.Actions() {
+		if _, ok := r.nameToAction[action.GetName()]; ok {
+			return fmt.Errorf("duplicate action: %v", action.GetName())
+		}
+		r.nameToAction[action.GetName()] = action
 	}
 
 	// Register initial load function. Store as closures.
@@ -93,6 +120,15 @@ func (r *Registry) GetExecutorByType(commandType int) Executor {
 	return nil
 }
 
+// GetActionByName returns the action with the given name, or nil if no feature
+// provides it.
+func (r *Registry) GetActionByName(name string) Action {
+	if a, ok := r.nameToAction[name]; ok {
+		return a
+	}
+	return nil
+}
+
 // IsInvokable tests that the given string is a user-invokable command. Will
 // pass whether the string is prefixed by a ? or not.
 func (r *Registry) IsInvokable(name string) bool {
@@ -115,3 +151,35 @@ func (r *Registry) CommandInterceptors() []CommandInterceptor {
 func (r *Registry) GetInitialLoadFns() []func(api.DiscordSession) error {
 	return r.initialLoadFns
 }
+
+// GetFeatureNames returns the names of the registered features, sorted.
+func (r *Registry) GetFeatureNames() []string {
+	names := append([]string{}, r.featureNames...)
+	sort.Strings(names)
+	return names
+}
+
+// IsFeature returns whether a feature with the given name is registered.
+func (r *Registry) IsFeature(name string) bool {
+	for _, featureName := range r.featureNames {
+		if featureName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// GetFeatureNameByParserName returns the name of the feature that owns the
+// parser with the given name, or the empty string if no feature does.
+func (r *Registry) GetFeatureNameByParserName(name string) string {
+	if featureName, ok := r.parserNameToFeatureName[name]; ok {
+		return featureName
+	}
+	return r.parserNameToFeatureName["?"+name]
+}
+
+// GetFeatureNameByType returns the name of the feature that owns the executor
+// with the given type, or the empty string if no feature does.
+func (r *Registry) GetFeatureNameByType(commandType int) string {
+	return r.typeToFeatureName[commandType]
+}
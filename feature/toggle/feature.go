@@ -0,0 +1,61 @@
+package toggle
+
+import (
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/feature"
+	"github.com/jakevoytko/crbot/model"
+	stringmap "github.com/jakevoytko/go-stringmap"
+)
+
+// Feature lets moderators enable and disable features by channel.
+type Feature struct {
+	featureRegistry *feature.Registry
+	modelHelper     *ModelHelper
+}
+
+// NewFeature returns a new Feature.
+func NewFeature(featureRegistry *feature.Registry, toggleMap stringmap.StringMap) *Feature {
+	return &Feature{
+		featureRegistry: featureRegistry,
+		modelHelper:     NewModelHelper(toggleMap),
+	}
+}
+
+// GetName returns the name that channels enable and disable the feature by.
+// This feature can't be disabled, or nobody could enable it again.
+func (f *Feature) GetName() string {
+	return model.FeatureNameToggle
+}
+
+// Parsers returns the parsers.
+func (f *Feature) Parsers() []feature.Parser {
+	return []feature.Parser{
+		NewFeatureParser(),
+	}
+}
+
+// CommandInterceptors returns nothing.
+func (f *Feature) CommandInterceptors() []feature.CommandInterceptor {
+	return []feature.CommandInterceptor{}
+}
+
+// FallbackParser returns nil.
+func (f *Feature) FallbackParser() feature.Parser {
+	return nil
+}
+
+// Executors gets the executors.
+func (f *Feature) Executors() []feature.Executor {
+	return []feature.Executor{
+		NewFeatureListExecutor(f.modelHelper, f.featureRegistry),
+		NewFeatureToggleExecutor(f.modelHelper, f.featureRegistry),
+	}
+}
+
+// Actions returns nothing.
+func (f *Feature) Actions() []feature.Action {
+	return []feature.Action{}
+}
+
+// OnInitialLoad does nothing.
+func (f *Feature) OnInitialLoad(s api.DiscordSession) error { return nil }
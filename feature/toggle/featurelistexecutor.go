@@ -0,0 +1,82 @@
+package toggle
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/feature"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// FeatureListExecutor prints which features are enabled in a channel.
+type FeatureListExecutor struct {
+	modelHelper     *ModelHelper
+	featureRegistry *feature.Registry
+}
+
+// NewFeatureListExecutor works as advertised.
+func NewFeatureListExecutor(modelHelper *ModelHelper, featureRegistry *feature.Registry) *FeatureListExecutor {
+	return &FeatureListExecutor{
+		modelHelper:     modelHelper,
+		featureRegistry: featureRegistry,
+	}
+}
+
+// GetType returns the type.
+func (e *FeatureListExecutor) GetType() int {
+	return model.CommandTypeFeatureList
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private channel.
+func (e *FeatureListExecutor) PublicOnly() bool {
+	return false
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *FeatureListExecutor) ModeratorOnly() bool {
+	return false
+}
+
+const (
+	// MsgFeaturesEnabled lists the features enabled in a channel
+	MsgFeaturesEnabled = "Enabled here: %s"
+	// MsgFeaturesDisabled lists the features disabled in a channel
+	MsgFeaturesDisabled = "Disabled here: %s"
+)
+
+// Execute replies over the given channel with the features enabled and
+// disabled in it.
+func (e *FeatureListExecutor) Execute(s api.DiscordSession, channel model.Snowflake, command *model.Command) {
+	discordChannel, err := s.Channel(channel.Format())
+	if err != nil {
+		log.Info("Error retrieving channel to list features", err)
+		return
+	}
+
+	enabled := []string{}
+	disabled := []string{}
+	for _, name := range toggleableNames(e.featureRegistry) {
+		ok, err := e.modelHelper.IsEnabledIn(discordChannel, name)
+		if err != nil {
+			log.Fatal("Error reading feature toggles", err)
+		}
+		if ok {
+			enabled = append(enabled, name)
+		} else {
+			disabled = append(disabled, name)
+		}
+	}
+
+	lines := []string{}
+	if len(enabled) > 0 {
+		lines = append(lines, fmt.Sprintf(MsgFeaturesEnabled, strings.Join(enabled, ", ")))
+	}
+	if len(disabled) > 0 {
+		lines = append(lines, fmt.Sprintf(MsgFeaturesDisabled, strings.Join(disabled, ", ")))
+	}
+	if _, err := s.ChannelMessageSend(channel.Format(), strings.Join(lines, "\n")); err != nil {
+		log.Info("Failed to send feature list message", err)
+	}
+}
@@ -0,0 +1,99 @@
+package toggle
+
+import (
+	"errors"
+	"log"
+	"regexp"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jakevoytko/crbot/model"
+	"github.com/jakevoytko/crbot/util"
+)
+
+// FeatureParser parses ?feature commands.
+type FeatureParser struct{}
+
+// NewFeatureParser works as advertised.
+func NewFeatureParser() *FeatureParser {
+	return &FeatureParser{}
+}
+
+// GetName returns the named type of this feature.
+func (p *FeatureParser) GetName() string {
+	return model.CommandNameFeature
+}
+
+const (
+	// MsgHelpFeature is the help text for ?feature
+	MsgHelpFeature = "Type `?feature` to see which features are enabled in this channel. Moderators can type `?feature enable <name> [#channel]` or `?feature disable <name> [#channel]` to toggle a feature in a channel, or `?feature disable <name> everywhere` to toggle it in every channel that doesn't have its own setting. The learn feature includes learned commands."
+)
+
+const (
+	// SubcommandEnable enables a feature
+	SubcommandEnable = "enable"
+	// SubcommandDisable disables a feature
+	SubcommandDisable = "disable"
+	// ScopeEverywhere toggles a feature in the whole server
+	ScopeEverywhere = "everywhere"
+)
+
+// HelpText explains how to use ?feature.
+func (p *FeatureParser) HelpText(command string) (string, error) {
+	return MsgHelpFeature, nil
+}
+
+// The channel is mentioned, like <#1234>.
+var channelRegexp = regexp.MustCompile("^<#([[:digit:]]+)>$")
+
+// Parse parses the given feature command.
+func (p *FeatureParser) Parse(splitContent []string, m *discordgo.MessageCreate) (*model.Command, error) {
+	if splitContent[0] != p.GetName() {
+		log.Fatal("FeatureParser.Parse called with non-feature command", errors.New("wat"))
+	}
+
+	splitContent = util.CollapseWhitespace(splitContent, 1)
+	splitContent = util.CollapseWhitespace(splitContent, 2)
+	splitContent = util.CollapseWhitespace(splitContent, 3)
+	splitContent = util.CollapseWhitespace(splitContent, 4)
+	if len(splitContent) == 1 {
+		return &model.Command{
+			Type: model.CommandTypeFeatureList,
+		}, nil
+	}
+
+	isToggle := splitContent[1] == SubcommandEnable || splitContent[1] == SubcommandDisable
+	if !isToggle || len(splitContent) < 3 || len(splitContent) > 4 {
+		return p.help(), nil
+	}
+	data := &model.FeatureToggleData{
+		Name:    splitContent[2],
+		Enabled: splitContent[1] == SubcommandEnable,
+	}
+	if len(splitContent) == 4 {
+		if splitContent[3] == ScopeEverywhere {
+			data.Everywhere = true
+		} else if match := channelRegexp.FindStringSubmatch(splitContent[3]); len(match) == 2 {
+			channelID, err := model.ParseSnowflake(match[1])
+			if err != nil {
+				return p.help(), nil
+			}
+			data.ChannelID = channelID
+		} else {
+			return p.help(), nil
+		}
+	}
+
+	return &model.Command{
+		Type:          model.CommandTypeFeatureToggle,
+		FeatureToggle: data,
+	}, nil
+}
+
+func (p *FeatureParser) help() *model.Command {
+	return &model.Command{
+		Type: model.CommandTypeHelp,
+		Help: &model.HelpData{
+			Command: p.GetName(),
+		},
+	}
+}
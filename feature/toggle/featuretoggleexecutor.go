@@ -0,0 +1,135 @@
+package toggle
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/feature"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// FeatureToggleExecutor enables or disables a feature in a channel, or
+// everywhere in the guild.
+type FeatureToggleExecutor struct {
+	modelHelper     *ModelHelper
+	featureRegistry *feature.Registry
+}
+
+// NewFeatureToggleExecutor works as advertised.
+func NewFeatureToggleExecutor(modelHelper *ModelHelper, featureRegistry *feature.Registry) *FeatureToggleExecutor {
+	return &FeatureToggleExecutor{
+		modelHelper:     modelHelper,
+		featureRegistry: featureRegistry,
+	}
+}
+
+// GetType returns the type.
+func (e *FeatureToggleExecutor) GetType() int {
+	return model.CommandTypeFeatureToggle
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private
+// channel. Features are toggled for the guild's channels.
+func (e *FeatureToggleExecutor) PublicOnly() bool {
+	return true
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *FeatureToggleExecutor) ModeratorOnly() bool {
+	return true
+}
+
+const (
+	// MsgUnknownFeature is a user-visible string for toggling a feature that doesn't exist
+	MsgUnknownFeature = "There is no feature named `%s`. Features: %s"
+	// MsgFeatureNotToggleable is a user-visible string for toggling the feature that toggles features
+	MsgFeatureNotToggleable = "The `%s` feature can't be toggled."
+	// MsgUnknownChannel is a user-visible string for toggling a feature in a channel outside of the server
+	MsgUnknownChannel = "I can't find that channel in this server."
+	// MsgFeatureEnabled announces that a feature was enabled in a channel
+	MsgFeatureEnabled = "Enabled `%s` in <#%v>."
+	// MsgFeatureDisabled announces that a feature was disabled in a channel
+	MsgFeatureDisabled = "Disabled `%s` in <#%v>."
+	// MsgFeatureEnabledEverywhere announces that a feature was enabled in the server
+	MsgFeatureEnabledEverywhere = "Enabled `%s` everywhere, except in channels with their own setting."
+	// MsgFeatureDisabledEverywhere announces that a feature was disabled in the server
+	MsgFeatureDisabledEverywhere = "Disabled `%s` everywhere, except in channels with their own setting."
+)
+
+// Execute toggles the feature, and replies over the given channel.
+func (e *FeatureToggleExecutor) Execute(s api.DiscordSession, channel model.Snowflake, command *model.Command) {
+	if command.FeatureToggle == nil {
+		log.Info("Tried to toggle a feature without a name", errors.New("missing feature name"))
+		return
+	}
+	data := command.FeatureToggle
+
+	message, err := e.toggle(s, channel, data)
+	if err != nil {
+		log.Fatal("Error toggling feature", err)
+	}
+	if _, err := s.ChannelMessageSend(channel.Format(), message); err != nil {
+		log.Info("Failed to send feature toggle message", err)
+	}
+}
+
+// toggle stores the toggle, and returns the message describing what happened.
+func (e *FeatureToggleExecutor) toggle(s api.DiscordSession, channel model.Snowflake, data *model.FeatureToggleData) (string, error) {
+	if !e.featureRegistry.IsFeature(data.Name) {
+		return fmt.Sprintf(MsgUnknownFeature, data.Name, strings.Join(toggleableNames(e.featureRegistry), ", ")), nil
+	}
+	if data.Name == model.FeatureNameToggle {
+		return fmt.Sprintf(MsgFeatureNotToggleable, data.Name), nil
+	}
+
+	discordChannel, err := s.Channel(channel.Format())
+	if err != nil {
+		return "", err
+	}
+	guildID, err := GuildID(discordChannel)
+	if err != nil {
+		return "", err
+	}
+
+	if data.Everywhere {
+		if err := e.modelHelper.SetGuildEnabled(guildID, data.Name, data.Enabled); err != nil {
+			return "", err
+		}
+		if data.Enabled {
+			return fmt.Sprintf(MsgFeatureEnabledEverywhere, data.Name), nil
+		}
+		return fmt.Sprintf(MsgFeatureDisabledEverywhere, data.Name), nil
+	}
+
+	target := channel
+	if data.ChannelID > 0 {
+		// Only channels in this server can be toggled from here.
+		targetChannel, err := s.Channel(data.ChannelID.Format())
+		if err != nil || targetChannel.GuildID != discordChannel.GuildID {
+			return MsgUnknownChannel, nil
+		}
+		target = data.ChannelID
+	}
+	if err := e.modelHelper.SetChannelEnabled(target, data.Name, data.Enabled); err != nil {
+		return "", err
+	}
+	if data.Enabled {
+		return fmt.Sprintf(MsgFeatureEnabled, data.Name, target), nil
+	}
+	return fmt.Sprintf(MsgFeatureDisabled, data.Name, target), nil
+}
+
+// toggleableNames returns the names of the features that can be toggled,
+// sorted.
+func toggleableNames(featureRegistry *feature.Registry) []string {
+	names := []string{}
+	for _, name := range featureRegistry.GetFeatureNames() {
+		if name != model.FeatureNameToggle {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,109 @@
+package toggle
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jakevoytko/crbot/model"
+	stringmap "github.com/jakevoytko/go-stringmap"
+)
+
+// ModelHelper provides helpers for working with feature toggle storage.
+type ModelHelper struct {
+	stringMap stringmap.StringMap
+}
+
+// NewModelHelper works as advertised.
+func NewModelHelper(stringMap stringmap.StringMap) *ModelHelper {
+	return &ModelHelper{
+		stringMap: stringMap,
+	}
+}
+
+const (
+	// KeyChannelToggles is the key/value store key for the features toggled in a
+	// channel
+	KeyChannelToggles = "channel-%v"
+	// KeyGuildToggles is the key/value store key for the features toggled
+	// everywhere in a guild
+	KeyGuildToggles = "guild-%v"
+)
+
+// IsEnabled returns whether the feature is enabled in the channel. Channel
+// toggles take precedence over guild toggles, and features that were never
+// toggled are enabled. Private channels have a 0 guild.
+func (h *ModelHelper) IsEnabled(guildID, channelID model.Snowflake, name string) (bool, error) {
+	for _, key := range []string{fmt.Sprintf(KeyChannelToggles, channelID), fmt.Sprintf(KeyGuildToggles, guildID)} {
+		toggles, err := h.toggles(key)
+		if err != nil {
+			return false, err
+		}
+		if enabled, ok := toggles[name]; ok {
+			return enabled, nil
+		}
+	}
+	return true, nil
+}
+
+// IsEnabledIn returns whether the feature is enabled in the Discord channel.
+func (h *ModelHelper) IsEnabledIn(discordChannel *discordgo.Channel, name string) (bool, error) {
+	guildID, err := GuildID(discordChannel)
+	if err != nil {
+		return false, err
+	}
+	channelID, err := model.ParseSnowflake(discordChannel.ID)
+	if err != nil {
+		return false, err
+	}
+	return h.IsEnabled(guildID, channelID, name)
+}
+
+// SetChannelEnabled enables or disables the feature in the channel.
+func (h *ModelHelper) SetChannelEnabled(channelID model.Snowflake, name string, enabled bool) error {
+	return h.setEnabled(fmt.Sprintf(KeyChannelToggles, channelID), name, enabled)
+}
+
+// SetGuildEnabled enables or disables the feature everywhere in the guild,
+// except in channels where it was toggled.
+func (h *ModelHelper) SetGuildEnabled(guildID model.Snowflake, name string, enabled bool) error {
+	return h.setEnabled(fmt.Sprintf(KeyGuildToggles, guildID), name, enabled)
+}
+
+func (h *ModelHelper) setEnabled(key, name string, enabled bool) error {
+	toggles, err := h.toggles(key)
+	if err != nil {
+		return err
+	}
+	toggles[name] = enabled
+	serializedToggles, err := json.Marshal(toggles)
+	if err != nil {
+		return err
+	}
+	return h.stringMap.Set(key, string(serializedToggles))
+}
+
+// toggles returns the features toggled under the key, by name.
+func (h *ModelHelper) toggles(key string) (map[string]bool, error) {
+	toggles := map[string]bool{}
+	ok, err := h.stringMap.Has(key)
+	if err != nil || !ok {
+		return toggles, err
+	}
+	serializedToggles, err := h.stringMap.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(serializedToggles), &toggles); err != nil {
+		return nil, err
+	}
+	return toggles, nil
+}
+
+// GuildID returns the guild of the Discord channel, or 0 for private channels.
+func GuildID(discordChannel *discordgo.Channel) (model.Snowflake, error) {
+	if len(discordChannel.GuildID) == 0 {
+		return 0, nil
+	}
+	return model.ParseSnowflake(discordChannel.GuildID)
+}
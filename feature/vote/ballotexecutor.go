@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/bwmarrin/discordgo"
 	"github.com/jakevoytko/crbot/api"
 	"github.com/jakevoytko/crbot/log"
 	"github.com/jakevoytko/crbot/model"
@@ -12,12 +13,14 @@ import (
 // BallotExecutor executes a vote
 type BallotExecutor struct {
 	modelHelper *ModelHelper
+	weightings  *Weightings
 }
 
 // NewBallotExecutor works as advertised
-func NewBallotExecutor(modelHelper *ModelHelper) *BallotExecutor {
+func NewBallotExecutor(modelHelper *ModelHelper, weightings *Weightings) *BallotExecutor {
 	return &BallotExecutor{
 		modelHelper: modelHelper,
+		weightings:  weightings,
 	}
 }
 
@@ -31,6 +34,11 @@ func (e *BallotExecutor) PublicOnly() bool {
 	return true
 }
 
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *BallotExecutor) ModeratorOnly() bool {
+	return false
+}
+
 const (
 	// MsgAlreadyVoted returns that the user already voted
 	MsgAlreadyVoted = "%v already voted"
@@ -38,6 +46,14 @@ const (
 	MsgVotedAgainst = "%v voted no"
 	// MsgVotedInFavor returns that the user voted for the vote
 	MsgVotedInFavor = "%v voted yes"
+	// MsgChangedVoteAgainst returns that the user changed their ballot to no
+	MsgChangedVoteAgainst = "%v changed their ballot to no"
+	// MsgChangedVoteInFavor returns that the user changed their ballot to yes
+	MsgChangedVoteInFavor = "%v changed their ballot to yes"
+	// MsgBallotsLocked returns that ballots in the vote can't be changed
+	MsgBallotsLocked = "Ballots in this vote are locked, and can't be changed or retracted"
+	// MsgPollActive returns that the active vote is a poll
+	MsgPollActive = "A poll is active. Type `?pick <number>` to vote."
 )
 
 // Execute runs the command
@@ -47,7 +63,15 @@ func (e *BallotExecutor) Execute(s api.DiscordSession, channelID model.Snowflake
 		log.Fatal("Error parsing discord user ID", err)
 	}
 
-	vote, err := e.modelHelper.CastBallot(channelID, userID, command.Ballot.InFavor)
+	// Ballots that move to the other side are announced as changes.
+	previousVote, ok := activeVote(s, e.modelHelper, channelID, command.Ballot.VoteID)
+	if !ok {
+		return
+	}
+	changed, _ := previousVote.Ballot(e.modelHelper.BallotSecret, userID)
+
+	weight := e.weightings.BallotWeight(s, previousVote, userID)
+	vote, err := e.modelHelper.CastBallotByID(channelID, previousVote.VoteID, userID, command.Ballot.InFavor, weight)
 	switch err {
 	case ErrorNoVoteActive:
 		if _, err := s.ChannelMessageSend(channelID.Format(), MsgNoActiveVote); err != nil {
@@ -60,16 +84,47 @@ func (e *BallotExecutor) Execute(s api.DiscordSession, channelID model.Snowflake
 			log.Fatal("Unable to send already voted message to user", err)
 		}
 		return
-	}
 
-	voteMessage := fmt.Sprintf(MsgVotedAgainst, command.Author.Mention())
-	if command.Ballot.InFavor {
-		voteMessage = fmt.Sprintf(MsgVotedInFavor, command.Author.Mention())
+	case ErrorPollActive:
+		if _, err := s.ChannelMessageSend(channelID.Format(), MsgPollActive); err != nil {
+			log.Fatal("Unable to send poll-active message to user", err)
+		}
+		return
+
+	case ErrorBallotsLocked:
+		if _, err := s.ChannelMessageSend(channelID.Format(), MsgBallotsLocked); err != nil {
+			log.Fatal("Unable to send ballots-locked message to user", err)
+		}
+		return
+
+	case ErrorSecretVote:
+		reference := SecretBallotReference(previousVote)
+		if _, err := s.ChannelMessageSend(channelID.Format(), fmt.Sprintf(MsgSecretVote, reference, reference)); err != nil {
+			log.Fatal("Unable to send secret-vote message to user", err)
+		}
+		return
 	}
 
-	messages := []string{voteMessage, StatusLine(e.modelHelper.UTCClock, vote)}
-	message := strings.Join(messages, "\n")
+	message := ballotMessage(e.modelHelper.UTCClock, command.Author, vote, command.Ballot.InFavor, changed)
 	if _, err := s.ChannelMessageSend(channelID.Format(), message); err != nil {
 		log.Info("Failed to send ballot status message", err)
 	}
 }
+
+// ballotMessage announces the user's new or changed ballot, along with the
+// status of the vote.
+func ballotMessage(clock model.UTCClock, author *discordgo.User, vote *model.Vote, inFavor, changed bool) string {
+	voteMessage := fmt.Sprintf(MsgVotedAgainst, author.Mention())
+	if inFavor {
+		voteMessage = fmt.Sprintf(MsgVotedInFavor, author.Mention())
+	}
+	if changed {
+		voteMessage = fmt.Sprintf(MsgChangedVoteAgainst, author.Mention())
+		if inFavor {
+			voteMessage = fmt.Sprintf(MsgChangedVoteInFavor, author.Mention())
+		}
+	}
+
+	messages := []string{voteMessage, StatusLine(clock, vote)}
+	return strings.Join(messages, "\n")
+}
@@ -31,9 +31,9 @@ func (p *BallotParser) GetName() string {
 
 const (
 	// MsgHelpBallotInFavor is help text for ?yes
-	MsgHelpBallotInFavor = "Casts a ballot in favor of the current vote, if one is active"
+	MsgHelpBallotInFavor = "Casts a ballot in favor of the current vote, if one is active. When several votes are active, add the ID of the vote, like `?yes 12`"
 	// MsgHelpBallotAgainst is help text for ?no
-	MsgHelpBallotAgainst = "Casts a ballot against the current vote, if one is active"
+	MsgHelpBallotAgainst = "Casts a ballot against the current vote, if one is active. When several votes are active, add the ID of the vote, like `?no 12`"
 )
 
 // HelpText returns the help text.
@@ -49,9 +49,12 @@ func (p *BallotParser) Parse(splitContent []string, m *discordgo.MessageCreate)
 	if splitContent[0] != p.GetName() {
 		log.Fatal("parseVoteBallot called with non-list command", errors.New("wat"))
 	}
+	// Anything other than a vote ID is ignored, like it always was.
+	voteID, _ := voteTarget(splitContent)
 	return &model.Command{
 		Type: model.CommandTypeVoteBallot,
 		Ballot: &model.BallotData{
+			VoteID:  voteID,
 			InFavor: p.InFavor,
 		},
 	}, nil
@@ -0,0 +1,121 @@
+package vote
+
+import (
+	"fmt"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/audit"
+	"github.com/jakevoytko/crbot/config"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// CancelExecutor cancels the active vote without an outcome
+type CancelExecutor struct {
+	modelHelper *ModelHelper
+	auditBus    *audit.Bus
+	config      *config.Config
+}
+
+// NewCancelExecutor works as advertised
+func NewCancelExecutor(modelHelper *ModelHelper, auditBus *audit.Bus, config *config.Config) *CancelExecutor {
+	return &CancelExecutor{
+		modelHelper: modelHelper,
+		auditBus:    auditBus,
+		config:      config,
+	}
+}
+
+// GetType returns the type of this feature.
+func (e *CancelExecutor) GetType() int {
+	return model.CommandTypeVoteCancel
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private channel.
+func (e *CancelExecutor) PublicOnly() bool {
+	return true
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+// The person who started the vote can also cancel it, which is checked when
+// executing.
+func (e *CancelExecutor) ModeratorOnly() bool {
+	return false
+}
+
+const (
+	// MsgCancelNotAllowed prints that only the initiator or a moderator can cancel the vote
+	MsgCancelNotAllowed = "Only the person who started the vote or a moderator can cancel it"
+	// MsgVoteCancelled prints that the vote was cancelled
+	MsgVoteCancelled = "@here -- %v cancelled the vote: %s"
+	// MsgPollCancelled prints that the poll was cancelled
+	MsgPollCancelled = "@here -- %v cancelled the poll: %s"
+)
+
+// Execute cancels the active or scheduled vote, if the author started it or is
+// a moderator. Scheduled votes must be named by their ID.
+func (e *CancelExecutor) Execute(s api.DiscordSession, channelID model.Snowflake, command *model.Command) {
+	vote, err := e.modelHelper.ScheduledVote(channelID, targetVoteID(command))
+	if err != nil {
+		log.Fatal("Error reading scheduled vote", err)
+	}
+	if vote == nil {
+		var ok bool
+		if vote, ok = activeVote(s, e.modelHelper, channelID, targetVoteID(command)); !ok {
+			return
+		}
+	}
+
+	userID, err := model.ParseSnowflake(command.Author.ID)
+	if err != nil {
+		log.Fatal("Error parsing discord user ID", err)
+	}
+	if vote.UserID != userID && !e.config.IsModerator(userID) {
+		if _, err := s.ChannelMessageSend(channelID.Format(), MsgCancelNotAllowed); err != nil {
+			log.Info("Failed to send cancel-not-allowed message", err)
+		}
+		return
+	}
+
+	if err := e.modelHelper.SetVoteOutcomeByID(channelID, vote.VoteID, model.VoteOutcomeCancelled); err != nil {
+		log.Fatal("Error cancelling vote", err)
+	}
+	e.auditBus.Publish(s, &audit.Event{
+		Type:      audit.EventVoteCancel,
+		UserID:    userID,
+		TargetID:  vote.UserID,
+		ChannelID: channelID,
+		Command:   model.CommandNameVote,
+		Details:   fmt.Sprintf("#%d: %s", vote.VoteID, vote.Message),
+	})
+
+	message := fmt.Sprintf(MsgVoteCancelled, command.Author.Mention(), vote.Message)
+	if vote.IsPoll() {
+		message = fmt.Sprintf(MsgPollCancelled, command.Author.Mention(), vote.Message)
+	}
+	if _, err := s.ChannelMessageSend(channelID.Format(), message); err != nil {
+		log.Info("Failed to send cancel message", err)
+	}
+}
+
+// activeVote returns the channel's active vote with the given ID, or its only
+// active vote for ID 0. If there isn't one, it tells the channel and returns
+// false.
+func activeVote(s api.DiscordSession, modelHelper *ModelHelper, channelID model.Snowflake, voteID int) (*model.Vote, bool) {
+	vote, err := modelHelper.ActiveVote(channelID, voteID)
+	var message string
+	switch err {
+	case nil:
+		return vote, true
+	case ErrorNoVoteActive:
+		message = MsgNoActiveVote
+	case ErrorAmbiguousVote:
+		message = MsgAmbiguousVote
+	default:
+		log.Fatal("Error reading vote status", err)
+	}
+	if _, err := s.ChannelMessageSend(channelID.Format(), message); err != nil {
+		log.Info("Failed to send no-active-vote message", err)
+	}
+	return nil, false
+}
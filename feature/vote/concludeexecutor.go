@@ -1,23 +1,28 @@
 package vote
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
+	"github.com/bwmarrin/discordgo"
 	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/feature"
 	"github.com/jakevoytko/crbot/log"
 	"github.com/jakevoytko/crbot/model"
 )
 
 // ConcludeExecutor concludes the active vote and prints the results
 type ConcludeExecutor struct {
-	modelHelper *ModelHelper
+	modelHelper     *ModelHelper
+	featureRegistry *feature.Registry
 }
 
 // NewConcludeExecutor works as advertised
-func NewConcludeExecutor(modelHelper *ModelHelper) *ConcludeExecutor {
+func NewConcludeExecutor(modelHelper *ModelHelper, featureRegistry *feature.Registry) *ConcludeExecutor {
 	return &ConcludeExecutor{
-		modelHelper: modelHelper,
+		modelHelper:     modelHelper,
+		featureRegistry: featureRegistry,
 	}
 }
 
@@ -34,39 +39,83 @@ func (e *ConcludeExecutor) PublicOnly() bool {
 	return false
 }
 
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *ConcludeExecutor) ModeratorOnly() bool {
+	return false
+}
+
 const (
 	// MsgVoteConcluded is the header for a concluded vote
 	MsgVoteConcluded = "@here -- Vote started by %s has concluded"
+	// MsgActionExecuted prints the result of the action carried out by a vote
+	MsgActionExecuted = "Carried out the vote: %s"
+	// MsgActionFailed prints that the action of a passed vote could not be carried out
+	MsgActionFailed = "The vote passed, but I couldn't carry it out: %v"
 )
 
-// Execute executes the command
+// Execute concludes the vote whose timer fired. Votes that were cancelled or
+// ended early already have an outcome, and are left alone.
 func (e *ConcludeExecutor) Execute(s api.DiscordSession, channelID model.Snowflake, command *model.Command) {
-	vote, err := e.modelHelper.MostRecentVote(channelID)
+	if command.VoteConclude == nil {
+		log.Info("Tried to conclude a vote without an ID", errors.New("missing vote ID"))
+		return
+	}
+
+	vote, err := e.modelHelper.Vote(channelID, command.VoteConclude.VoteID)
 	if err != nil {
-		log.Info("Error grabbing most recent vote", err)
+		log.Info("Error grabbing vote to conclude", err)
 		return
 	}
 	if vote == nil {
 		log.Info("Tried to conclude nonexistant vote", err)
 		return
 	}
+	if vote.VoteOutcome != model.VoteOutcomeNotDone {
+		return
+	}
+
+	concludeVote(s, e.modelHelper, e.featureRegistry, channelID, vote)
+}
+
+// NewConcludeCommand returns the command that concludes the vote once its timer
+// fires.
+func NewConcludeCommand(vote *model.Vote) *model.Command {
+	return &model.Command{
+		Type:      model.CommandTypeVoteConclude,
+		ChannelID: vote.ChannelID,
+		VoteConclude: &model.VoteConcludeData{
+			VoteID: vote.VoteID,
+		},
+	}
+}
 
+// concludeVote records the outcome of the vote, and prints the results. If the
+// vote passed, its action is carried out. Nothing is announced or carried out
+// unless the outcome was saved, so the vote is concluded again after a
+// restart instead of carrying out its action twice.
+func concludeVote(s api.DiscordSession, modelHelper *ModelHelper, featureRegistry *feature.Registry, channelID model.Snowflake, vote *model.Vote) {
 	user, err := s.User(vote.UserID.Format())
 	if err != nil {
 		log.Info("Error fetching the owner when rendering the status message", err)
 		return
 	}
 
+	if vote.IsPoll() {
+		concludePoll(s, modelHelper, channelID, user, vote)
+		return
+	}
+
 	voteOutcome := model.VoteOutcomeNotEnough
 	if vote.HasEnoughVotes() {
 		voteOutcome = vote.CalculateActiveStatus()
 	}
 	vote.VoteOutcome = voteOutcome
 
-	err = e.modelHelper.SetVoteOutcome(channelID, voteOutcome)
+	err = modelHelper.SetVoteOutcomeByID(channelID, vote.VoteID, voteOutcome)
 	if err != nil {
 		// Log as info so that this doesn't crash-loop on startup.
 		log.Info("Error setting vote outcome", err)
+		return
 	}
 
 	messages := []string{
@@ -77,4 +126,54 @@ func (e *ConcludeExecutor) Execute(s api.DiscordSession, channelID model.Snowfla
 	if _, err := s.ChannelMessageSend(channelID.Format(), message); err != nil {
 		log.Info("Error sending conclude message", err)
 	}
+
+	if voteOutcome == model.VoteOutcomePassed && vote.Action != nil {
+		executeAction(s, modelHelper, featureRegistry, channelID, vote)
+	}
+}
+
+// executeAction carries out the action of a passed vote, and records the result
+// on the vote so that there is an audit trail of what each vote did.
+func executeAction(s api.DiscordSession, modelHelper *ModelHelper, featureRegistry *feature.Registry, channelID model.Snowflake, vote *model.Vote) {
+	if vote.Action.Executed() {
+		return
+	}
+
+	var result string
+	var err error
+	if action := featureRegistry.GetActionByName(vote.Action.Name); action != nil {
+		result, err = action.Execute(s, channelID, vote.Action.Args)
+	} else {
+		err = fmt.Errorf("unknown action %v", vote.Action.Name)
+	}
+
+	if _, auditErr := modelHelper.SetVoteActionResult(channelID, vote.VoteID, result, err); auditErr != nil {
+		log.Info("Error recording the result of a vote action", auditErr)
+	}
+
+	message := fmt.Sprintf(MsgActionExecuted, result)
+	if err != nil {
+		log.Info(fmt.Sprintf("Error carrying out action %v of vote %v", vote.Action.Name, vote.VoteID), err)
+		message = fmt.Sprintf(MsgActionFailed, err)
+	}
+	if _, err := s.ChannelMessageSend(channelID.Format(), message); err != nil {
+		log.Info("Error sending vote action result", err)
+	}
+}
+
+func concludePoll(s api.DiscordSession, modelHelper *ModelHelper, channelID model.Snowflake, user *discordgo.User, poll *model.Vote) {
+	result := poll.TallyPoll()
+
+	err := modelHelper.SetVoteOutcomeByID(channelID, poll.VoteID, PollOutcome(result))
+	if err != nil {
+		// Log as info so that this doesn't crash-loop on startup.
+		log.Info("Error setting poll outcome", err)
+	}
+
+	messages := []string{fmt.Sprintf(MsgPollConcluded, user.Mention(), poll.Message)}
+	messages = append(messages, CompletedPollLines(poll, result)...)
+	message := strings.Join(messages, "\n")
+	if _, err := s.ChannelMessageSend(channelID.Format(), message); err != nil {
+		log.Info("Error sending conclude message", err)
+	}
 }
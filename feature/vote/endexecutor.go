@@ -0,0 +1,46 @@
+package vote
+
+import (
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/feature"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// EndExecutor concludes the active vote before its time is up
+type EndExecutor struct {
+	modelHelper     *ModelHelper
+	featureRegistry *feature.Registry
+}
+
+// NewEndExecutor works as advertised
+func NewEndExecutor(modelHelper *ModelHelper, featureRegistry *feature.Registry) *EndExecutor {
+	return &EndExecutor{
+		modelHelper:     modelHelper,
+		featureRegistry: featureRegistry,
+	}
+}
+
+// GetType returns the type of this feature.
+func (e *EndExecutor) GetType() int {
+	return model.CommandTypeVoteEnd
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private channel.
+func (e *EndExecutor) PublicOnly() bool {
+	return true
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *EndExecutor) ModeratorOnly() bool {
+	return true
+}
+
+// Execute concludes the active vote with the ballots cast so far. Its timer
+// still fires later, but the conclusion is a no-op by then.
+func (e *EndExecutor) Execute(s api.DiscordSession, channelID model.Snowflake, command *model.Command) {
+	vote, ok := activeVote(s, e.modelHelper, channelID, targetVoteID(command))
+	if !ok {
+		return
+	}
+	concludeVote(s, e.modelHelper, e.featureRegistry, channelID, vote)
+}
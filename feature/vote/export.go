@@ -0,0 +1,167 @@
+package vote
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jakevoytko/crbot/model"
+)
+
+const (
+	// ExportFormatCSV exports votes as CSV, one vote per row
+	ExportFormatCSV = "csv"
+	// ExportFormatJSON exports votes as a JSON array
+	ExportFormatJSON = "json"
+)
+
+// ErrorUnknownExportFormat indicates that votes can't be exported in the
+// requested format
+var ErrorUnknownExportFormat = errors.New("unknown export format")
+
+// ExportedVote is the record of a single vote in an export. Secret votes only
+// have totals, so their voters are left out.
+type ExportedVote struct {
+	VoteID         int                  `json:"vote_id"`
+	ChannelID      string               `json:"channel_id"`
+	Message        string               `json:"message"`
+	InitiatorID    string               `json:"initiator_id"`
+	Initiator      string               `json:"initiator,omitempty"`
+	TimestampStart time.Time            `json:"timestamp_start"`
+	TimestampEnd   time.Time            `json:"timestamp_end"`
+	Outcome        string               `json:"outcome"`
+	VotesFor       int                  `json:"votes_for"`
+	VotesAgainst   int                  `json:"votes_against"`
+	VotersFor      []string             `json:"voters_for"`
+	VotersAgainst  []string             `json:"voters_against"`
+	PollOptions    []string             `json:"poll_options,omitempty"`
+	PollBallots    []ExportedPollBallot `json:"poll_ballots,omitempty"`
+}
+
+// ExportedPollBallot is a poll ballot in an export. Choices are numbered from
+// 1, like in ?pick, most preferred first.
+type ExportedPollBallot struct {
+	UserID  string `json:"user_id"`
+	Choices []int  `json:"choices"`
+}
+
+// exportHeader names the CSV columns.
+var exportHeader = []string{
+	"vote_id", "channel_id", "message", "initiator_id", "initiator", "timestamp_start", "timestamp_end",
+	"outcome", "votes_for", "votes_against", "voters_for", "voters_against", "poll_options", "poll_ballots",
+}
+
+// NewExportedVote converts the vote for export. The username of the initiator
+// is filled in when it is known, and may be empty.
+func NewExportedVote(vote *model.Vote, initiator string) *ExportedVote {
+	votesFor, votesAgainst := vote.Tally()
+	exported := &ExportedVote{
+		VoteID:         vote.VoteID,
+		ChannelID:      vote.ChannelID.Format(),
+		Message:        vote.Message,
+		InitiatorID:    vote.UserID.Format(),
+		Initiator:      initiator,
+		TimestampStart: vote.TimestampStart,
+		TimestampEnd:   vote.TimestampEnd,
+		Outcome:        outcomeName(vote),
+		VotesFor:       votesFor,
+		VotesAgainst:   votesAgainst,
+		VotersFor:      formatSnowflakes(vote.VotesFor),
+		VotersAgainst:  formatSnowflakes(vote.VotesAgainst),
+		PollOptions:    vote.PollOptions,
+	}
+	for _, ballot := range vote.PollBallots {
+		choices := []int{}
+		for _, choice := range ballot.Choices {
+			choices = append(choices, choice+1)
+		}
+		exported.PollBallots = append(exported.PollBallots, ExportedPollBallot{
+			UserID:  ballot.UserID.Format(),
+			Choices: choices,
+		})
+	}
+	return exported
+}
+
+// ExportVotes writes the votes in the given format.
+func ExportVotes(votes []*ExportedVote, format string) (string, error) {
+	switch format {
+	case ExportFormatJSON:
+		serialized, err := json.MarshalIndent(votes, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(serialized), nil
+
+	case ExportFormatCSV:
+		var buffer bytes.Buffer
+		writer := csv.NewWriter(&buffer)
+		if err := writer.Write(exportHeader); err != nil {
+			return "", err
+		}
+		for _, vote := range votes {
+			ballots := []string{}
+			for _, ballot := range vote.PollBallots {
+				choices := []string{}
+				for _, choice := range ballot.Choices {
+					choices = append(choices, strconv.Itoa(choice))
+				}
+				ballots = append(ballots, ballot.UserID+"="+strings.Join(choices, ">"))
+			}
+			row := []string{
+				strconv.Itoa(vote.VoteID),
+				vote.ChannelID,
+				vote.Message,
+				vote.InitiatorID,
+				vote.Initiator,
+				vote.TimestampStart.Format(time.RFC3339),
+				vote.TimestampEnd.Format(time.RFC3339),
+				vote.Outcome,
+				strconv.Itoa(vote.VotesFor),
+				strconv.Itoa(vote.VotesAgainst),
+				strings.Join(vote.VotersFor, " "),
+				strings.Join(vote.VotersAgainst, " "),
+				strings.Join(vote.PollOptions, " | "),
+				strings.Join(ballots, " "),
+			}
+			if err := writer.Write(row); err != nil {
+				return "", err
+			}
+		}
+		writer.Flush()
+		return buffer.String(), writer.Error()
+	}
+	return "", fmt.Errorf("%w: %s", ErrorUnknownExportFormat, format)
+}
+
+// outcomeName names the vote's outcome in exports.
+func outcomeName(vote *model.Vote) string {
+	switch vote.VoteOutcome {
+	case model.VoteOutcomePassed:
+		return "passed"
+	case model.VoteOutcomeFailed:
+		return "failed"
+	case model.VoteOutcomeNotEnough:
+		return "not enough ballots"
+	case model.VoteOutcomePollWinner:
+		return "winner"
+	case model.VoteOutcomePollTie:
+		return "tie"
+	case model.VoteOutcomeCancelled:
+		return "cancelled"
+	}
+	return "in progress"
+}
+
+func formatSnowflakes(ids []model.Snowflake) []string {
+	formatted := []string{}
+	for _, id := range ids {
+		formatted = append(formatted, id.Format())
+	}
+	return formatted
+}
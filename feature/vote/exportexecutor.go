@@ -0,0 +1,100 @@
+package vote
+
+import (
+	"fmt"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// ExportExecutor exports votes in a channel
+type ExportExecutor struct {
+	modelHelper *ModelHelper
+	gist        api.Gist
+}
+
+// NewExportExecutor works as advertised
+func NewExportExecutor(modelHelper *ModelHelper, gist api.Gist) *ExportExecutor {
+	return &ExportExecutor{
+		modelHelper: modelHelper,
+		gist:        gist,
+	}
+}
+
+// GetType returns the type of this feature.
+func (e *ExportExecutor) GetType() int {
+	return model.CommandTypeVoteExport
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private channel.
+func (e *ExportExecutor) PublicOnly() bool {
+	return true
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *ExportExecutor) ModeratorOnly() bool {
+	return false
+}
+
+const (
+	// MsgGistExportAddress is a user-visible string announcing the url of a vote export
+	MsgGistExportAddress = "The vote export is here"
+)
+
+// Execute uploads the requested votes in the channel to the gist API.
+func (e *ExportExecutor) Execute(s api.DiscordSession, channel model.Snowflake, command *model.Command) {
+	var votes []*model.Vote
+	if command.VoteExport.VoteID > 0 {
+		vote, err := e.modelHelper.Vote(channel, command.VoteExport.VoteID)
+		if err != nil {
+			log.Fatal("Error reading vote to export", err)
+		}
+		if vote == nil {
+			if _, err := s.ChannelMessageSend(channel.Format(), fmt.Sprintf(MsgVoteNotFound, command.VoteExport.VoteID)); err != nil {
+				log.Info("Failed to send vote not found message", err)
+			}
+			return
+		}
+		votes = []*model.Vote{vote}
+	} else {
+		channelVotes, err := e.modelHelper.ChannelVotes(channel)
+		if err != nil {
+			log.Fatal("Error reading votes to export", err)
+		}
+		votes = channelVotes
+	}
+	if len(votes) == 0 {
+		if _, err := s.ChannelMessageSend(channel.Format(), MsgNoVoteHistory); err != nil {
+			log.Info("Failed to send vote history message", err)
+		}
+		return
+	}
+
+	// Initiators are looked up once each.
+	usernames := map[model.Snowflake]string{}
+	exported := []*ExportedVote{}
+	for _, vote := range votes {
+		if _, ok := usernames[vote.UserID]; !ok {
+			owner, err := s.User(vote.UserID.Format())
+			if err != nil {
+				log.Info("Error fetching the owner when exporting votes", err)
+			} else {
+				usernames[vote.UserID] = owner.Username
+			}
+		}
+		exported = append(exported, NewExportedVote(vote, usernames[vote.UserID]))
+	}
+
+	export, err := ExportVotes(exported, command.VoteExport.Format)
+	if err != nil {
+		log.Info("Error exporting votes", err)
+		return
+	}
+	if url, err := e.gist.Upload(export); err != nil {
+		s.ChannelMessageSend(channel.Format(), err.Error())
+		log.Info("Gist API failed", err)
+	} else {
+		s.ChannelMessageSend(channel.Format(), MsgGistExportAddress+": "+url)
+	}
+}
@@ -2,7 +2,10 @@ package vote
 
 import (
 	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/audit"
+	"github.com/jakevoytko/crbot/config"
 	"github.com/jakevoytko/crbot/feature"
+	"github.com/jakevoytko/crbot/feature/karma"
 	"github.com/jakevoytko/crbot/model"
 	stringmap "github.com/jakevoytko/go-stringmap"
 )
@@ -11,31 +14,54 @@ import (
 type Feature struct {
 	featureRegistry *feature.Registry
 	modelHelper     *ModelHelper
+	gist            api.Gist
+	auditBus        *audit.Bus
 	commandChannel  chan<- *model.Command
 	utcTimer        model.UTCTimer
 	utcClock        model.UTCClock
+	config          *config.Config
+	weightings      *Weightings
 }
 
 // NewFeature returns a new Feature.
-func NewFeature(featureRegistry *feature.Registry, voteMap stringmap.StringMap, clock model.UTCClock, timer model.UTCTimer, commandChannel chan<- *model.Command) *Feature {
+func NewFeature(featureRegistry *feature.Registry, voteMap, karmaMap, karmaHistoryMap, karmaMergeMap stringmap.StringMap, gist api.Gist, auditBus *audit.Bus, clock model.UTCClock, timer model.UTCTimer, commandChannel chan<- *model.Command, config *config.Config) *Feature {
+	karmaHelper := karma.NewModelHelper(karmaMap, karmaHistoryMap, karmaMergeMap, clock)
 	return &Feature{
 		featureRegistry: featureRegistry,
-		modelHelper:     NewModelHelper(voteMap, clock),
+		modelHelper:     NewModelHelper(voteMap, clock, config.VoteBallotSecret),
+		gist:            gist,
+		auditBus:        auditBus,
 		utcTimer:        timer,
 		utcClock:        clock,
 		commandChannel:  commandChannel,
+		config:          config,
+		weightings: NewWeightings(
+			NewRoleWeighting(config),
+			NewKarmaWeighting(karmaHelper, config),
+		),
 	}
 }
 
+// GetName returns the name that channels enable and disable the feature by.
+func (f *Feature) GetName() string {
+	return model.FeatureNameVote
+}
+
 // Parsers returns the parsers.
 func (f *Feature) Parsers() []feature.Parser {
 	return []feature.Parser{
 		NewStatusParser(),
-		NewStartVoteParser(),
+		NewHistoryParser(),
+		NewStartVoteParser(f.featureRegistry),
 		NewBallotParser(model.CommandNameVoteInFavorF1, true /* inFavor */),
 		NewBallotParser(model.CommandNameVoteInFavorYes, true /* inFavor */),
 		NewBallotParser(model.CommandNameVoteAgainstF2, false /* inFavor */),
 		NewBallotParser(model.CommandNameVoteAgainstNo, false /* inFavor */),
+		NewPollParser(),
+		NewRetractParser(),
+		NewSecretBallotParser(),
+		NewPollBallotParser(model.CommandNamePollPick, false /* ranked */),
+		NewPollBallotParser(model.CommandNamePollRank, true /* ranked */),
 	}
 }
 
@@ -52,14 +78,31 @@ func (f *Feature) FallbackParser() feature.Parser {
 // Executors gets the executors.
 func (f *Feature) Executors() []feature.Executor {
 	return []feature.Executor{
-		NewBallotExecutor(f.modelHelper),
-		NewConcludeExecutor(f.modelHelper),
+		NewBallotExecutor(f.modelHelper, f.weightings),
+		NewCancelExecutor(f.modelHelper, f.auditBus, f.config),
+		NewConcludeExecutor(f.modelHelper, f.featureRegistry),
+		NewEndExecutor(f.modelHelper, f.featureRegistry),
+		NewExportExecutor(f.modelHelper, f.gist),
+		NewHistoryExecutor(f.modelHelper, f.gist),
+		NewOpenExecutor(f.modelHelper),
+		NewReminderExecutor(f.modelHelper),
+		NewShowExecutor(f.modelHelper),
 		NewStatusExecutor(f.modelHelper),
-		NewStartVoteExecutor(f.modelHelper, f.commandChannel, f.utcTimer),
+		NewStartVoteExecutor(f.modelHelper, f.featureRegistry, f.weightings, f.commandChannel, f.utcTimer, f.config),
+		NewPollBallotExecutor(f.modelHelper),
+		NewReactionExecutor(f.modelHelper, f.weightings, f.auditBus, f.config),
+		NewRetractExecutor(f.modelHelper),
+		NewSecretBallotExecutor(f.modelHelper, f.weightings),
+		NewStartPollExecutor(f.modelHelper, f.commandChannel, f.utcTimer, f.config),
 	}
 }
 
+// Actions returns nothing.
+func (f *Feature) Actions() []feature.Action {
+	return []feature.Action{}
+}
+
 // OnInitialLoad cleans up from any votes that were already active when crbot shut down.
 func (f *Feature) OnInitialLoad(s api.DiscordSession) error {
-	return HandleVotesOnInitialLoad(s, f.modelHelper, f.utcClock, f.utcTimer, f.commandChannel)
+	return HandleVotesOnInitialLoad(s, f.modelHelper, f.utcClock, f.utcTimer, f.commandChannel, f.config.VoteReminders())
 }
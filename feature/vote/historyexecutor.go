@@ -0,0 +1,110 @@
+package vote
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// HistoryExecutor lists the past votes in a channel
+type HistoryExecutor struct {
+	modelHelper *ModelHelper
+	gist        api.Gist
+}
+
+// NewHistoryExecutor works as advertised
+func NewHistoryExecutor(modelHelper *ModelHelper, gist api.Gist) *HistoryExecutor {
+	return &HistoryExecutor{
+		modelHelper: modelHelper,
+		gist:        gist,
+	}
+}
+
+// GetType returns the type of this feature.
+func (e *HistoryExecutor) GetType() int {
+	return model.CommandTypeVoteHistory
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private channel.
+func (e *HistoryExecutor) PublicOnly() bool {
+	return true
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *HistoryExecutor) ModeratorOnly() bool {
+	return false
+}
+
+const (
+	// MsgNoVoteHistory prints that the channel has never had a vote
+	MsgNoVoteHistory = "No votes have been held in this channel"
+	// MsgVoteHistory is the header of the vote history
+	MsgVoteHistory = "Most recent votes in this channel:"
+	// MsgVoteHistoryEntry prints the ID, message, initiator and summary of a vote
+	MsgVoteHistoryEntry = "#%d: %s (started by %s). %s"
+	// MsgGistHistoryAddress is a user-visible string announcing the url of a long vote history
+	MsgGistHistoryAddress = "The vote history is here"
+	// MaxInlineLength is the length of the longest history posted directly in the
+	// channel. Discord rejects messages longer than 2000 characters.
+	MaxInlineLength = 2000
+)
+
+// Execute lists the most recent votes in the channel, uploading long lists to
+// the gist API.
+func (e *HistoryExecutor) Execute(s api.DiscordSession, channel model.Snowflake, command *model.Command) {
+	votes, err := e.modelHelper.VoteHistory(channel, command.VoteHistory.Count)
+	if err != nil {
+		log.Fatal("Error reading vote history", err)
+	}
+	if len(votes) == 0 {
+		if _, err := s.ChannelMessageSend(channel.Format(), MsgNoVoteHistory); err != nil {
+			log.Info("Failed to send vote history message", err)
+		}
+		return
+	}
+
+	lines := []string{MsgVoteHistory}
+	for _, vote := range votes {
+		owner, err := s.User(vote.UserID.Format())
+		if err != nil {
+			log.Info("Error fetching the owner when rendering the vote history", err)
+			return
+		}
+		lines = append(lines, fmt.Sprintf(MsgVoteHistoryEntry, vote.VoteID, vote.Message, owner.Username, SummaryLine(e.modelHelper.UTCClock, vote)))
+	}
+	history := strings.Join(lines, "\n")
+
+	if len(history) <= MaxInlineLength {
+		if _, err := s.ChannelMessageSend(channel.Format(), history); err != nil {
+			log.Info("Failed to send vote history message", err)
+		}
+		return
+	}
+
+	if url, err := e.gist.Upload(history); err != nil {
+		s.ChannelMessageSend(channel.Format(), err.Error())
+		log.Info("Gist API failed", err)
+	} else {
+		s.ChannelMessageSend(channel.Format(), MsgGistHistoryAddress+": "+url)
+	}
+}
+
+// SummaryLine returns a one-line summary of the tallies and outcome of a vote or
+// poll, whether or not it has concluded.
+func SummaryLine(clock model.UTCClock, vote *model.Vote) string {
+	concluded := vote.VoteOutcome != model.VoteOutcomeNotDone
+	switch {
+	case vote.IsPoll() && vote.VoteOutcome == model.VoteOutcomeCancelled:
+		return MsgStatusPollCancelled + " " + ballotsString(vote)
+	case vote.IsPoll() && concluded:
+		return CompletedPollLines(vote, vote.TallyPoll())[0] + ". " + ballotsString(vote)
+	case vote.IsPoll():
+		return PollStatusLine(clock, vote)
+	case concluded:
+		return CompletedStatusLine(vote)
+	}
+	return StatusLine(clock, vote)
+}
@@ -0,0 +1,75 @@
+package vote
+
+import (
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jakevoytko/crbot/model"
+	"github.com/jakevoytko/crbot/util"
+)
+
+// HistoryParser parses ?votehistory commands
+type HistoryParser struct {
+}
+
+// NewHistoryParser works as advertised.
+func NewHistoryParser() *HistoryParser {
+	return &HistoryParser{}
+}
+
+// GetName returns the named type.
+func (p *HistoryParser) GetName() string {
+	return model.CommandNameVoteHistory
+}
+
+const (
+	// MsgHelpHistory is the help text for ?votehistory
+	MsgHelpHistory = "Type `?votehistory [n]` to list the last n votes and polls in this channel, or the last 10 if n is omitted. Type `?vote show <id>` to see one of them in full."
+
+	// DefaultHistoryCount is the number of votes listed when no count is given
+	DefaultHistoryCount = 10
+)
+
+// HelpText returns the help text.
+func (p *HistoryParser) HelpText(command string) (string, error) {
+	return MsgHelpHistory, nil
+}
+
+// Parse parses the given history command.
+func (p *HistoryParser) Parse(splitContent []string, m *discordgo.MessageCreate) (*model.Command, error) {
+	if splitContent[0] != p.GetName() {
+		log.Fatal("parseVoteHistory called with non-votehistory command", errors.New("wat"))
+	}
+	splitContent = util.CollapseWhitespace(splitContent, 1)
+
+	count := DefaultHistoryCount
+	switch len(splitContent) {
+	case 1:
+	case 2:
+		parsed, err := strconv.Atoi(splitContent[1])
+		if err != nil || parsed <= 0 {
+			return p.help(), nil
+		}
+		count = parsed
+	default:
+		return p.help(), nil
+	}
+
+	return &model.Command{
+		Type: model.CommandTypeVoteHistory,
+		VoteHistory: &model.VoteHistoryData{
+			Count: count,
+		},
+	}, nil
+}
+
+func (p *HistoryParser) help() *model.Command {
+	return &model.Command{
+		Type: model.CommandTypeHelp,
+		Help: &model.HelpData{
+			Command: model.CommandNameVoteHistory,
+		},
+	}
+}
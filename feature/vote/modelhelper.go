@@ -1,9 +1,13 @@
 package vote
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
+	"sort"
 	"time"
 
 	"github.com/jakevoytko/crbot/model"
@@ -21,13 +25,16 @@ import (
 type ModelHelper struct {
 	StringMap stringmap.StringMap
 	UTCClock  model.UTCClock
+	// BallotSecret keys the hashes of the voters in secret votes.
+	BallotSecret string
 }
 
 // NewModelHelper works as advertised.
-func NewModelHelper(stringMap stringmap.StringMap, utcClock model.UTCClock) *ModelHelper {
+func NewModelHelper(stringMap stringmap.StringMap, utcClock model.UTCClock, ballotSecret string) *ModelHelper {
 	return &ModelHelper{
-		StringMap: stringMap,
-		UTCClock:  utcClock,
+		StringMap:    stringMap,
+		UTCClock:     utcClock,
+		BallotSecret: ballotSecret,
 	}
 }
 
@@ -38,37 +45,208 @@ const (
 	RedisMostRecentVoteID = "most-recent-vote-id-channel-*"
 	// KeyVoteTemplate is the map from vote to channel
 	KeyVoteTemplate = "vote-%v-channel-%v"
-	// VoteDuration is the duration of a vote
+	// KeyOpenVoteIDs is the key/value store key for the IDs of the channel's
+	// votes that haven't concluded, in ascending order
+	KeyOpenVoteIDs = "open-vote-ids-channel-%v"
+	// VoteDuration is the duration of a vote that doesn't specify one
 	VoteDuration = time.Duration(30) * time.Minute
 )
 
-// ErrorOnlyOneVote indicates that a second vote cannot be started
-var ErrorOnlyOneVote = errors.New("tried to start vote when one is already active")
-
 // ErrorNoVoteActive indicates that the user can't vote if no vote is active
 var ErrorNoVoteActive = errors.New("cannot vote when there is no active vote")
 
+// ErrorAmbiguousVote indicates that several votes are active, so the vote must be given by ID
+var ErrorAmbiguousVote = errors.New("more than one vote is active")
+
 // ErrorAlreadyVoted indicates that the user can't vote twice
 var ErrorAlreadyVoted = errors.New("user already voted")
 
+// ErrorPollActive indicates that the user can't cast a yes/no ballot in a poll
+var ErrorPollActive = errors.New("cannot cast a yes/no ballot in a poll")
+
+// ErrorNoPollActive indicates that the user can't pick an option if no poll is active
+var ErrorNoPollActive = errors.New("cannot pick an option when there is no active poll")
+
+// ErrorInvalidChoice indicates that the user picked an option that the poll doesn't have
+var ErrorInvalidChoice = errors.New("no such poll option")
+
+// ErrorPollNotRanked indicates that the user ranked the options of a plurality poll
+var ErrorPollNotRanked = errors.New("cannot rank the options of a plurality poll")
+
+// ErrorBallotsLocked indicates that the vote's ballots can't be changed or retracted
+var ErrorBallotsLocked = errors.New("ballots are locked")
+
+// ErrorNotVoted indicates that the user can't retract a ballot they never cast
+var ErrorNotVoted = errors.New("user has not voted")
+
+// ErrorSecretVote indicates that ballots in a secret vote must be cast by direct message
+var ErrorSecretVote = errors.New("ballots in a secret vote are cast by direct message")
+
+// ErrorNotSecret indicates that ballots in an open vote are cast in its channel
+var ErrorNotSecret = errors.New("ballots in an open vote are cast in its channel")
+
 // ErrorVoteHasOutcome indicates that the application already set the vote outcome, and to give up
 var ErrorVoteHasOutcome = errors.New("cannot change vote outcome")
 
-// IsVoteActive returns whether there is a most-recent, active vote.
+// ErrorNoAction indicates that the vote doesn't carry out an action
+var ErrorNoAction = errors.New("vote has no action")
+
+// ErrorActionExecuted indicates that the vote's action already ran, and must not run again
+var ErrorActionExecuted = errors.New("vote action already executed")
+
+// IsVoteActive returns whether the channel has any active vote.
 func (h *ModelHelper) IsVoteActive(channelID model.Snowflake) (bool, error) {
-	vote, err := h.MostRecentVote(channelID)
+	votes, err := h.OpenVotes(channelID)
 	if err != nil {
 		return false, err
 	}
-	if vote == nil {
-		return false, nil
-	}
+	return len(votes) > 0, nil
+}
 
+// isActive returns whether the vote has no outcome, and the current time is
+// within the vote's range.
+func (h *ModelHelper) isActive(vote *model.Vote) bool {
 	currentTime := h.UTCClock.Now()
-
-	// Bail if the current time is not within the vote's range.
 	return vote.VoteOutcome == model.VoteOutcomeNotDone &&
-		currentTime.Sub(vote.TimestampStart) >= 0 && vote.TimestampEnd.Sub(currentTime) > 0, nil
+		currentTime.Sub(vote.TimestampStart) >= 0 && vote.TimestampEnd.Sub(currentTime) > 0
+}
+
+// IsScheduled returns whether the vote has no outcome, and hasn't opened yet.
+func (h *ModelHelper) IsScheduled(vote *model.Vote) bool {
+	return vote.VoteOutcome == model.VoteOutcomeNotDone && h.UTCClock.Now().Before(vote.TimestampStart)
+}
+
+// ScheduledVote returns the channel's scheduled vote with the given ID, or nil
+// if there is no such vote that is still waiting to open.
+func (h *ModelHelper) ScheduledVote(channelID model.Snowflake, voteID int) (*model.Vote, error) {
+	if voteID <= 0 {
+		return nil, nil
+	}
+	vote, err := h.Vote(channelID, voteID)
+	if err != nil || vote == nil || !h.IsScheduled(vote) {
+		return nil, err
+	}
+	return vote, nil
+}
+
+// OpenVotes returns the channel's active votes and polls, oldest first.
+func (h *ModelHelper) OpenVotes(channelID model.Snowflake) ([]*model.Vote, error) {
+	votes, err := h.unconcludedVotes(channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	active := []*model.Vote{}
+	for _, vote := range votes {
+		if h.isActive(vote) {
+			active = append(active, vote)
+		}
+	}
+	return active, nil
+}
+
+// ActiveVote returns the channel's active vote with the given ID. An ID of 0
+// means the channel's only active vote, which keeps commands short when there
+// is one. Returns ErrorNoVoteActive if there is no such vote, and
+// ErrorAmbiguousVote if the ID is 0 and several votes are active.
+func (h *ModelHelper) ActiveVote(channelID model.Snowflake, voteID int) (*model.Vote, error) {
+	if voteID > 0 {
+		vote, err := h.Vote(channelID, voteID)
+		if err != nil {
+			return nil, err
+		}
+		if vote == nil || !h.isActive(vote) {
+			return nil, ErrorNoVoteActive
+		}
+		return vote, nil
+	}
+
+	votes, err := h.OpenVotes(channelID)
+	if err != nil {
+		return nil, err
+	}
+	switch len(votes) {
+	case 0:
+		return nil, ErrorNoVoteActive
+	case 1:
+		return votes[0], nil
+	default:
+		return nil, ErrorAmbiguousVote
+	}
+}
+
+// UnconcludedVotes returns every vote in the database that doesn't have an
+// outcome, including ones whose time ran out while crbot was down.
+func (h *ModelHelper) UnconcludedVotes() ([]*model.Vote, error) {
+	mostRecentVotes, err := h.MostRecentVotes()
+	if err != nil {
+		return nil, err
+	}
+
+	// Every channel that ever had a vote has a most recent vote.
+	votes := []*model.Vote{}
+	for _, mostRecentVote := range mostRecentVotes {
+		channelVotes, err := h.unconcludedVotes(mostRecentVote.ChannelID)
+		if err != nil {
+			return nil, err
+		}
+		votes = append(votes, channelVotes...)
+	}
+	return votes, nil
+}
+
+// unconcludedVotes returns the channel's votes that don't have an outcome,
+// oldest first.
+func (h *ModelHelper) unconcludedVotes(channelID model.Snowflake) ([]*model.Vote, error) {
+	voteIDs, err := h.openVoteIDs(channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	votes := []*model.Vote{}
+	for _, voteID := range voteIDs {
+		vote, err := h.Vote(channelID, voteID)
+		if err != nil {
+			return nil, err
+		}
+		if vote == nil || vote.VoteOutcome != model.VoteOutcomeNotDone {
+			// Missing or stale vote for some reason. Ignore.
+			continue
+		}
+		votes = append(votes, vote)
+	}
+	return votes, nil
+}
+
+// openVoteIDs reads the index of the channel's votes that don't have an
+// outcome. Channels whose votes were stored before the index existed could
+// only have one vote open, the most recent one.
+func (h *ModelHelper) openVoteIDs(channelID model.Snowflake) ([]int, error) {
+	reifiedKey := fmt.Sprintf(KeyOpenVoteIDs, channelID)
+	ok, err := h.StringMap.Has(reifiedKey)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		vote, err := h.MostRecentVote(channelID)
+		if err != nil {
+			return nil, err
+		}
+		if vote == nil || vote.VoteOutcome != model.VoteOutcomeNotDone {
+			return []int{}, nil
+		}
+		return []int{vote.VoteID}, nil
+	}
+
+	serializedIDs, err := h.StringMap.Get(reifiedKey)
+	if err != nil {
+		return nil, err
+	}
+	var voteIDs []int
+	if err := json.Unmarshal([]byte(serializedIDs), &voteIDs); err != nil {
+		return nil, err
+	}
+	return voteIDs, nil
 }
 
 // MostRecentVotes returns every most recent vote in the database.
@@ -139,6 +317,94 @@ func (h *ModelHelper) MostRecentVote(channelID model.Snowflake) (*model.Vote, er
 	return &deserializedVote, nil
 }
 
+// Vote returns the channel's vote with the given ID, or nil if none
+// present. Returns an error on i/o problems.
+func (h *ModelHelper) Vote(channelID model.Snowflake, voteID int) (*model.Vote, error) {
+	reifiedKey := fmt.Sprintf(KeyVoteTemplate, voteID, channelID)
+	ok, err := h.StringMap.Has(reifiedKey)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	serializedVote, err := h.StringMap.Get(reifiedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var deserializedVote model.Vote
+	err = json.Unmarshal([]byte(serializedVote), &deserializedVote)
+	if err != nil {
+		return nil, err
+	}
+	return &deserializedVote, nil
+}
+
+// VoteHistory returns up to count of the channel's votes, most recent first.
+// Votes missing from storage are skipped.
+func (h *ModelHelper) VoteHistory(channelID model.Snowflake, count int) ([]*model.Vote, error) {
+	mostRecentVoteID, err := h.MostRecentVoteID(channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	votes := []*model.Vote{}
+	for voteID := mostRecentVoteID; voteID > 0 && len(votes) < count; voteID-- {
+		vote, err := h.Vote(channelID, voteID)
+		if err != nil {
+			return nil, err
+		}
+		if vote == nil {
+			// Missing vote for some reason. Ignore.
+			continue
+		}
+		votes = append(votes, vote)
+	}
+	return votes, nil
+}
+
+// ChannelVotes returns every vote and poll in the channel, oldest first.
+func (h *ModelHelper) ChannelVotes(channelID model.Snowflake) ([]*model.Vote, error) {
+	mostRecentVoteID, err := h.MostRecentVoteID(channelID)
+	if err != nil {
+		return nil, err
+	}
+	history, err := h.VoteHistory(channelID, mostRecentVoteID)
+	if err != nil {
+		return nil, err
+	}
+
+	votes := make([]*model.Vote, 0, len(history))
+	for i := len(history) - 1; i >= 0; i-- {
+		votes = append(votes, history[i])
+	}
+	return votes, nil
+}
+
+// AllVotes returns every vote and poll in the database, grouped by channel and
+// oldest first.
+func (h *ModelHelper) AllVotes() ([]*model.Vote, error) {
+	mostRecentVotes, err := h.MostRecentVotes()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(mostRecentVotes, func(i, j int) bool {
+		return mostRecentVotes[i].ChannelID < mostRecentVotes[j].ChannelID
+	})
+
+	votes := []*model.Vote{}
+	for _, mostRecentVote := range mostRecentVotes {
+		channelVotes, err := h.ChannelVotes(mostRecentVote.ChannelID)
+		if err != nil {
+			return nil, err
+		}
+		votes = append(votes, channelVotes...)
+	}
+	return votes, nil
+}
+
 // MostRecentVoteID returns the most recent ID. Returns `0, nil` if no vote has
 // ever been executed.
 func (h *ModelHelper) MostRecentVoteID(channelID model.Snowflake) (int, error) {
@@ -153,30 +419,64 @@ func (h *ModelHelper) MostRecentVoteID(channelID model.Snowflake) (int, error) {
 	return vote.VoteID, nil
 }
 
-// StartNewVote starts and returns a new vote. Returns ErrorOnlyOneVote if
-// another vote was active when trying to start this one.
-func (h *ModelHelper) StartNewVote(channelID, userID model.Snowflake, message string) (*model.Vote, error) {
-	// Don't overwrite an existing vote.
-	if ok, err := h.IsVoteActive(channelID); ok || err != nil {
+// StartNewVote starts and returns a new vote under the given options. Zero
+// options use VoteDuration, model.DefaultVoteQuorum, and a simple majority.
+// Other votes in the channel may still be active. A vote with a future start
+// is scheduled, and isn't active until then.
+func (h *ModelHelper) StartNewVote(channelID, userID model.Snowflake, message string, options model.VoteOptions) (*model.Vote, error) {
+	vote, err := h.newVote(channelID, userID, message, options.Duration)
+	if err != nil {
+		return nil, err
+	}
+	if !options.Start.IsZero() {
+		duration := vote.TimestampEnd.Sub(vote.TimestampStart)
+		vote.TimestampStart = options.Start.UTC()
+		vote.TimestampEnd = vote.TimestampStart.Add(duration)
+		vote.PendingOpen = vote.TimestampStart.After(h.UTCClock.Now())
+	}
+	vote.Quorum = options.Quorum
+	if vote.Quorum <= 0 {
+		vote.Quorum = model.DefaultVoteQuorum
+	}
+	vote.Threshold = options.Threshold
+	vote.LockBallots = options.LockBallots
+	if options.Action != nil {
+		action := *options.Action
+		vote.Action = &action
+	}
+	if len(options.Weighting) > 0 {
+		vote.Weighting = options.Weighting
+		vote.BallotWeights = map[model.Snowflake]int{}
+	}
+	if options.Secret {
+		salt, err := newSalt()
 		if err != nil {
 			return nil, err
 		}
-		return nil, ErrorOnlyOneVote
+		vote.Secret = true
+		vote.Salt = salt
+		vote.SecretBallots = []model.SecretBallot{}
 	}
 
-	// Generate a new Vote with no ballots, starting now in UTC.
-	mostRecentVote, err := h.MostRecentVote(channelID)
+	err = h.writeVote(vote)
 	if err != nil {
 		return nil, err
 	}
-	nextVoteID := 1
-	if mostRecentVote != nil {
-		nextVoteID = mostRecentVote.VoteID + 1
+
+	return vote, nil
+}
+
+// StartNewPoll starts and returns the given poll. A 0 duration uses
+// VoteDuration. Polls share the channel's vote IDs with yes/no votes.
+func (h *ModelHelper) StartNewPoll(channelID, userID model.Snowflake, poll *model.PollData) (*model.Vote, error) {
+	vote, err := h.newVote(channelID, userID, poll.Question, poll.Duration)
+	if err != nil {
+		return nil, err
 	}
-	voteStart := h.UTCClock.Now()
-	voteEnd := voteStart.Add(VoteDuration)
-	vote := model.NewVote(
-		nextVoteID, channelID, userID, message, voteStart, voteEnd, []model.Snowflake{}, []model.Snowflake{}, model.VoteOutcomeNotDone)
+	vote.PollOptions = poll.Options
+	vote.Ranked = poll.Ranked
+	vote.LockBallots = poll.LockBallots
+	vote.PollBallots = []model.PollBallot{}
 
 	err = h.writeVote(vote)
 	if err != nil {
@@ -186,45 +486,175 @@ func (h *ModelHelper) StartNewVote(channelID, userID model.Snowflake, message st
 	return vote, nil
 }
 
-// CastBallot casts a ballot against the current poll for the given user. On
-// success, it returns the vote with the ballot incorporated. Returns
-// ErrorNoVoteActive if there is no active poll, or the inner error if a
-// component errored. Returns ErrorAlreadyVoted if the user is present in either
-// list.
-func (h *ModelHelper) CastBallot(channelID model.Snowflake, userID model.Snowflake, inFavor bool) (*model.Vote, error) {
-	ok, err := h.IsVoteActive(channelID)
+// newSalt returns a random salt for hashing the voters in a secret vote.
+func newSalt() (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(salt), nil
+}
+
+// newVote returns an unsaved vote with no ballots, starting now in UTC.
+func (h *ModelHelper) newVote(channelID, userID model.Snowflake, message string, duration time.Duration) (*model.Vote, error) {
+	mostRecentVote, err := h.MostRecentVote(channelID)
 	if err != nil {
 		return nil, err
 	}
-	if !ok {
-		return nil, ErrorNoVoteActive
+	nextVoteID := 1
+	if mostRecentVote != nil {
+		nextVoteID = mostRecentVote.VoteID + 1
+	}
+	if duration <= 0 {
+		duration = VoteDuration
 	}
+	voteStart := h.UTCClock.Now()
+	voteEnd := voteStart.Add(duration)
+	return model.NewVote(
+		nextVoteID, channelID, userID, message, voteStart, voteEnd, []model.Snowflake{}, []model.Snowflake{}, model.VoteOutcomeNotDone), nil
+}
 
-	vote, err := h.MostRecentVote(channelID)
+// CastBallot casts a ballot that counts once in the channel's only active
+// vote. See CastBallotByID.
+func (h *ModelHelper) CastBallot(channelID model.Snowflake, userID model.Snowflake, inFavor bool) (*model.Vote, error) {
+	return h.CastBallotByID(channelID, 0, userID, inFavor, 1 /* weight */)
+}
+
+// CastBallotByID casts a ballot in the given vote for the given user, or moves
+// the user's ballot to the other side. An ID of 0 means the channel's only
+// active vote. On success, it returns the vote with the ballot incorporated.
+// Returns ErrorNoVoteActive if there is no such active vote,
+// ErrorAmbiguousVote if several votes are active and no ID was given, or the
+// inner error if a component errored. Returns ErrorAlreadyVoted if the user
+// already voted the same way, and ErrorBallotsLocked if the user already voted
+// in a vote with locked ballots. Returns ErrorSecretVote if the vote is secret,
+// since those ballots are cast with CastSecretBallot. The weight is recorded
+// if the vote is weighted, and ignored otherwise.
+func (h *ModelHelper) CastBallotByID(channelID model.Snowflake, voteID int, userID model.Snowflake, inFavor bool, weight int) (*model.Vote, error) {
+	vote, err := h.ActiveVote(channelID, voteID)
 	if err != nil {
 		return nil, err
 	}
-	if vote == nil {
+
+	if vote.IsPoll() {
+		return nil, ErrorPollActive
+	}
+	if vote.Secret {
+		return nil, ErrorSecretVote
+	}
+
+	return h.castBallot(vote, userID, inFavor, weight)
+}
+
+// CastSecretBallot casts a ballot in the channel's active secret vote with the
+// given ID. It follows the same rules as CastBallot. Returns ErrorNoVoteActive
+// if the vote isn't active, and ErrorNotSecret if the vote isn't secret.
+func (h *ModelHelper) CastSecretBallot(channelID model.Snowflake, voteID int, userID model.Snowflake, inFavor bool, weight int) (*model.Vote, error) {
+	if voteID <= 0 {
 		return nil, ErrorNoVoteActive
 	}
+	vote, err := h.ActiveVote(channelID, voteID)
+	if err != nil {
+		return nil, err
+	}
+	if !vote.Secret {
+		return nil, ErrorNotSecret
+	}
 
-	// Ensure the user hasn't already voted.
-	for _, id := range vote.VotesFor {
-		if id == userID {
+	return h.castBallot(vote, userID, inFavor, weight)
+}
+
+// castBallot adds the user's ballot to the active vote, and saves it.
+func (h *ModelHelper) castBallot(vote *model.Vote, userID model.Snowflake, inFavor bool, weight int) (*model.Vote, error) {
+	// Ensure the user hasn't already voted the same way.
+	voted, previouslyInFavor := vote.Ballot(h.BallotSecret, userID)
+	if voted {
+		if previouslyInFavor == inFavor {
 			return nil, ErrorAlreadyVoted
 		}
-	}
-	for _, id := range vote.VotesAgainst {
-		if id == userID {
-			return nil, ErrorAlreadyVoted
+		if vote.LockBallots {
+			return nil, ErrorBallotsLocked
 		}
+		h.removeBallot(vote, userID)
 	}
 
-	if inFavor {
+	if !vote.IsWeighted() {
+		weight = 0
+	}
+	if vote.Secret {
+		vote.SecretBallots = append(vote.SecretBallots, model.SecretBallot{
+			VoterHash: vote.VoterHash(h.BallotSecret, userID),
+			InFavor:   inFavor,
+			Weight:    weight,
+		})
+	} else if inFavor {
 		vote.VotesFor = append(vote.VotesFor, userID)
 	} else {
 		vote.VotesAgainst = append(vote.VotesAgainst, userID)
 	}
+	if vote.IsWeighted() && !vote.Secret {
+		if vote.BallotWeights == nil {
+			vote.BallotWeights = map[model.Snowflake]int{}
+		}
+		vote.BallotWeights[userID] = weight
+	}
+
+	err := h.writeVote(vote)
+	if err != nil {
+		return nil, err
+	}
+
+	return vote, nil
+}
+
+// CastPollBallot casts a ballot in the channel's only active poll. See
+// CastPollBallotByID.
+func (h *ModelHelper) CastPollBallot(channelID model.Snowflake, userID model.Snowflake, choices []int) (*model.Vote, error) {
+	return h.CastPollBallotByID(channelID, 0, userID, choices)
+}
+
+// CastPollBallotByID casts a ballot in the given poll for the given user. An ID
+// of 0 means the channel's only active vote. On success, it returns the poll
+// with the ballot incorporated. Returns ErrorNoPollActive if there is no such
+// active poll, ErrorAmbiguousVote if several votes are active and no ID was
+// given, ErrorAlreadyVoted if the user already cast a ballot,
+// ErrorInvalidChoice if a choice isn't one of the poll's options, and
+// ErrorPollNotRanked if more than one option is given in a plurality poll.
+func (h *ModelHelper) CastPollBallotByID(channelID model.Snowflake, voteID int, userID model.Snowflake, choices []int) (*model.Vote, error) {
+	vote, err := h.ActiveVote(channelID, voteID)
+	if err == ErrorNoVoteActive {
+		return nil, ErrorNoPollActive
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !vote.IsPoll() {
+		return nil, ErrorNoPollActive
+	}
+
+	for _, choice := range choices {
+		if choice < 0 || choice >= len(vote.PollOptions) {
+			return nil, ErrorInvalidChoice
+		}
+	}
+	if len(choices) > 1 && !vote.Ranked {
+		return nil, ErrorPollNotRanked
+	}
+
+	if previous := vote.PollBallot(userID); previous >= 0 {
+		if reflect.DeepEqual(vote.PollBallots[previous].Choices, choices) {
+			return nil, ErrorAlreadyVoted
+		}
+		if vote.LockBallots {
+			return nil, ErrorBallotsLocked
+		}
+		h.removeBallot(vote, userID)
+	}
+
+	vote.PollBallots = append(vote.PollBallots, model.PollBallot{
+		UserID:  userID,
+		Choices: choices,
+	})
 
 	err = h.writeVote(vote)
 	if err != nil {
@@ -234,6 +664,133 @@ func (h *ModelHelper) CastBallot(channelID model.Snowflake, userID model.Snowfla
 	return vote, nil
 }
 
+// RetractBallot removes the user's ballot from the channel's only active vote
+// or poll. See RetractBallotByID.
+func (h *ModelHelper) RetractBallot(channelID model.Snowflake, userID model.Snowflake) (*model.Vote, error) {
+	return h.RetractBallotByID(channelID, 0, userID)
+}
+
+// RetractBallotByID removes the user's ballot from the given vote or poll. An
+// ID of 0 means the channel's only active vote. On success, it returns the vote
+// without the ballot. Returns ErrorNoVoteActive if there is no such active
+// vote, ErrorAmbiguousVote if several votes are active and no ID was given,
+// ErrorNotVoted if the user hasn't cast a ballot, and ErrorBallotsLocked if the
+// vote's ballots are locked.
+func (h *ModelHelper) RetractBallotByID(channelID model.Snowflake, voteID int, userID model.Snowflake) (*model.Vote, error) {
+	vote, err := h.ActiveVote(channelID, voteID)
+	if err != nil {
+		return nil, err
+	}
+
+	if vote.Secret {
+		return nil, ErrorSecretVote
+	}
+
+	return h.retractBallot(vote, userID)
+}
+
+// RetractSecretBallot removes the user's ballot from the channel's active
+// secret vote with the given ID. It follows the same rules as
+// RetractBallotByID. Returns ErrorNoVoteActive if the vote isn't active, and
+// ErrorNotSecret if the vote isn't secret.
+func (h *ModelHelper) RetractSecretBallot(channelID model.Snowflake, voteID int, userID model.Snowflake) (*model.Vote, error) {
+	if voteID <= 0 {
+		return nil, ErrorNoVoteActive
+	}
+	vote, err := h.ActiveVote(channelID, voteID)
+	if err != nil {
+		return nil, err
+	}
+	if !vote.Secret {
+		return nil, ErrorNotSecret
+	}
+
+	return h.retractBallot(vote, userID)
+}
+
+// retractBallot removes the user's ballot from the active vote, and saves it.
+func (h *ModelHelper) retractBallot(vote *model.Vote, userID model.Snowflake) (*model.Vote, error) {
+	if !h.removeBallot(vote, userID) {
+		return nil, ErrorNotVoted
+	}
+	if vote.LockBallots {
+		return nil, ErrorBallotsLocked
+	}
+
+	err := h.writeVote(vote)
+	if err != nil {
+		return nil, err
+	}
+
+	return vote, nil
+}
+
+// removeBallot removes the user's ballot from the unsaved vote, and returns
+// whether the user had cast one.
+func (h *ModelHelper) removeBallot(vote *model.Vote, userID model.Snowflake) bool {
+	removed := false
+	remove := func(ids []model.Snowflake) []model.Snowflake {
+		kept := []model.Snowflake{}
+		for _, id := range ids {
+			if id == userID {
+				removed = true
+				continue
+			}
+			kept = append(kept, id)
+		}
+		return kept
+	}
+	vote.VotesFor = remove(vote.VotesFor)
+	vote.VotesAgainst = remove(vote.VotesAgainst)
+	delete(vote.BallotWeights, userID)
+
+	if vote.Secret {
+		voterHash := vote.VoterHash(h.BallotSecret, userID)
+		for i, ballot := range vote.SecretBallots {
+			if ballot.VoterHash == voterHash {
+				vote.SecretBallots = append(vote.SecretBallots[:i], vote.SecretBallots[i+1:]...)
+				removed = true
+				break
+			}
+		}
+	}
+
+	if index := vote.PollBallot(userID); index >= 0 {
+		vote.PollBallots = append(vote.PollBallots[:index], vote.PollBallots[index+1:]...)
+		removed = true
+	}
+	return removed
+}
+
+// SetVoteMessageID records the announcement message of the channel's vote with
+// the given ID. Returns ErrorNoVoteActive if there is no such vote.
+func (h *ModelHelper) SetVoteMessageID(channelID model.Snowflake, voteID int, messageID model.Snowflake) error {
+	vote, err := h.Vote(channelID, voteID)
+	if err != nil {
+		return err
+	}
+	if vote == nil {
+		return ErrorNoVoteActive
+	}
+	vote.MessageID = messageID
+
+	return h.writeVote(vote)
+}
+
+// SetVoteOpened records that the scheduled vote with the given ID was
+// announced when it opened. Returns ErrorNoVoteActive if there is no such vote.
+func (h *ModelHelper) SetVoteOpened(channelID model.Snowflake, voteID int) error {
+	vote, err := h.Vote(channelID, voteID)
+	if err != nil {
+		return err
+	}
+	if vote == nil {
+		return ErrorNoVoteActive
+	}
+	vote.PendingOpen = false
+	return h.writeVote(vote)
+}
+
 // SetVoteOutcome terminates an active vote with the given outcome
 func (h *ModelHelper) SetVoteOutcome(channelID model.Snowflake, voteOutcome int) error {
 	vote, err := h.MostRecentVote(channelID)
@@ -243,22 +800,71 @@ func (h *ModelHelper) SetVoteOutcome(channelID model.Snowflake, voteOutcome int)
 	if vote == nil {
 		return ErrorNoVoteActive
 	}
+	return h.setOutcome(vote, voteOutcome)
+}
+
+// SetVoteOutcomeByID sets the outcome of the channel's vote with the given ID.
+// Returns ErrorNoVoteActive if there is no such vote, and ErrorVoteHasOutcome
+// if its outcome was already set.
+func (h *ModelHelper) SetVoteOutcomeByID(channelID model.Snowflake, voteID int, voteOutcome int) error {
+	vote, err := h.Vote(channelID, voteID)
+	if err != nil {
+		return err
+	}
+	if vote == nil {
+		return ErrorNoVoteActive
+	}
+	return h.setOutcome(vote, voteOutcome)
+}
 
+// SetVoteActionResult records the audit trail of the action carried out by the
+// channel's vote with the given ID. Returns ErrorNoVoteActive if there is no
+// such vote, ErrorNoAction if the vote has no action, and ErrorActionExecuted
+// if the result was already recorded.
+func (h *ModelHelper) SetVoteActionResult(channelID model.Snowflake, voteID int, result string, actionErr error) (*model.Vote, error) {
+	vote, err := h.Vote(channelID, voteID)
+	if err != nil {
+		return nil, err
+	}
+	if vote == nil {
+		return nil, ErrorNoVoteActive
+	}
+	if vote.Action == nil {
+		return nil, ErrorNoAction
+	}
+	if vote.Action.Executed() {
+		return nil, ErrorActionExecuted
+	}
+	vote.Action.TimestampExecuted = h.UTCClock.Now()
+	vote.Action.Result = result
+	if actionErr != nil {
+		vote.Action.Error = actionErr.Error()
+	}
+
+	if err := h.writeVote(vote); err != nil {
+		return nil, err
+	}
+	return vote, nil
+}
+
+func (h *ModelHelper) setOutcome(vote *model.Vote, voteOutcome int) error {
 	// Ensure the user hasn't already set an outcome.
 	if vote.VoteOutcome != model.VoteOutcomeNotDone {
 		return ErrorVoteHasOutcome
 	}
 	vote.VoteOutcome = voteOutcome
 
-	err = h.writeVote(vote)
+	return h.writeVote(vote)
+}
+
+func (h *ModelHelper) writeVote(vote *model.Vote) error {
+	// Read the index first, since channels without one fall back to the most
+	// recent vote.
+	openVoteIDs, err := h.openVoteIDs(vote.ChannelID)
 	if err != nil {
 		return err
 	}
 
-	return nil
-}
-
-func (h *ModelHelper) writeVote(vote *model.Vote) error {
 	// Serialize and write.
 	serializedVote, err := json.Marshal(vote)
 	if err != nil {
@@ -272,6 +878,36 @@ func (h *ModelHelper) writeVote(vote *model.Vote) error {
 		return err
 	}
 
+	// Keep the index of open votes in sync with the vote's outcome.
+	updatedIDs := []int{}
+	for _, voteID := range openVoteIDs {
+		if voteID != vote.VoteID {
+			updatedIDs = append(updatedIDs, voteID)
+		}
+	}
+	if vote.VoteOutcome == model.VoteOutcomeNotDone {
+		updatedIDs = append(updatedIDs, vote.VoteID)
+		sort.Ints(updatedIDs)
+	}
+	serializedIDs, err := json.Marshal(updatedIDs)
+	if err != nil {
+		return err
+	}
+	err = h.StringMap.Set(fmt.Sprintf(KeyOpenVoteIDs, vote.ChannelID), string(serializedIDs))
+	if err != nil {
+		return err
+	}
+
+	// Older votes are only updated when they conclude late, and the metadata
+	// must keep pointing at the newer vote.
+	mostRecentVoteID, err := h.MostRecentVoteID(vote.ChannelID)
+	if err != nil {
+		return err
+	}
+	if vote.VoteID < mostRecentVoteID {
+		return nil
+	}
+
 	// Write the metadata afterwards, so it's guaranteed to always point to a
 	// valid vote record.
 	err = h.StringMap.Set(fmt.Sprintf(KeyMostRecentVoteID, vote.ChannelID), voteKey)
@@ -0,0 +1,94 @@
+package vote
+
+import (
+	"errors"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// OpenExecutor opens a scheduled vote
+type OpenExecutor struct {
+	modelHelper *ModelHelper
+}
+
+// NewOpenExecutor works as advertised
+func NewOpenExecutor(modelHelper *ModelHelper) *OpenExecutor {
+	return &OpenExecutor{
+		modelHelper: modelHelper,
+	}
+}
+
+// GetType returns the type of this feature.
+func (e *OpenExecutor) GetType() int {
+	return model.CommandTypeVoteOpen
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private
+// channel. Votes are only scheduled in public channels.
+func (e *OpenExecutor) PublicOnly() bool {
+	return false
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *OpenExecutor) ModeratorOnly() bool {
+	return false
+}
+
+// Execute announces the scheduled vote whose start time arrived. Votes that
+// were cancelled or already announced are left alone.
+func (e *OpenExecutor) Execute(s api.DiscordSession, channelID model.Snowflake, command *model.Command) {
+	if command.VoteOpen == nil {
+		log.Info("Tried to open a vote without an ID", errors.New("missing vote ID"))
+		return
+	}
+
+	vote, err := e.modelHelper.Vote(channelID, command.VoteOpen.VoteID)
+	if err != nil {
+		log.Info("Error grabbing vote to open", err)
+		return
+	}
+	if vote == nil || vote.VoteOutcome != model.VoteOutcomeNotDone || !vote.PendingOpen {
+		return
+	}
+	if err := e.modelHelper.SetVoteOpened(channelID, vote.VoteID); err != nil {
+		log.Info("Error recording that the vote opened", err)
+		return
+	}
+	// Votes whose time ran out while crbot was down just conclude.
+	if !e.modelHelper.isActive(vote) {
+		return
+	}
+
+	user, err := s.User(vote.UserID.Format())
+	if err != nil {
+		log.Info("Error fetching the owner when opening the vote", err)
+		return
+	}
+
+	openVotes, err := e.modelHelper.OpenVotes(channelID)
+	if err != nil {
+		log.Fatal("Error occurred while calling for active votes", err)
+	}
+	announceVote(s, e.modelHelper, user, vote, len(openVotes) > 1)
+}
+
+// NewOpenCommand returns the command that opens the scheduled vote.
+func NewOpenCommand(vote *model.Vote) *model.Command {
+	return &model.Command{
+		Type:      model.CommandTypeVoteOpen,
+		ChannelID: vote.ChannelID,
+		VoteOpen: &model.VoteOpenData{
+			VoteID: vote.VoteID,
+		},
+	}
+}
+
+// scheduleOpen starts a timer that opens the scheduled vote. Votes whose start
+// passed while crbot was down open right away.
+func scheduleOpen(clock model.UTCClock, timer model.UTCTimer, commandChannel chan<- *model.Command, vote *model.Vote) {
+	timer.ExecuteAfter(vote.TimestampStart.Sub(clock.Now()), func() {
+		commandChannel <- NewOpenCommand(vote)
+	})
+}
@@ -0,0 +1,106 @@
+package vote
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// PollBallotExecutor casts a ballot in a poll
+type PollBallotExecutor struct {
+	modelHelper *ModelHelper
+}
+
+// NewPollBallotExecutor works as advertised
+func NewPollBallotExecutor(modelHelper *ModelHelper) *PollBallotExecutor {
+	return &PollBallotExecutor{
+		modelHelper: modelHelper,
+	}
+}
+
+// GetType returns the type of this feature.
+func (e *PollBallotExecutor) GetType() int {
+	return model.CommandTypePollBallot
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private channel.
+func (e *PollBallotExecutor) PublicOnly() bool {
+	return true
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *PollBallotExecutor) ModeratorOnly() bool {
+	return false
+}
+
+const (
+	// MsgNoActivePoll prints that there was no active poll
+	MsgNoActivePoll = "No active poll"
+	// MsgInvalidChoice prints that the user picked an option that doesn't exist
+	MsgInvalidChoice = "The poll only has %d options"
+	// MsgPollNotRanked prints that the user ranked options in a plurality poll
+	MsgPollNotRanked = "This poll isn't ranked. Type `?pick <number>` to vote."
+	// MsgPicked prints the option that the user picked
+	MsgPicked = "%v picked %v"
+	// MsgRanked prints the options that the user ranked
+	MsgRanked = "%v ranked %v"
+	// MsgChangedPollBallot prints the user's new ballot
+	MsgChangedPollBallot = "%v changed their ballot to %v"
+)
+
+// Execute runs the command
+func (e *PollBallotExecutor) Execute(s api.DiscordSession, channelID model.Snowflake, command *model.Command) {
+	userID, err := model.ParseSnowflake(command.Author.ID)
+	if err != nil {
+		log.Fatal("Error parsing discord user ID", err)
+	}
+
+	// Replaced ballots are announced as changes.
+	changed := false
+	previousPoll, err := e.modelHelper.ActiveVote(channelID, command.PollBallot.VoteID)
+	if err != nil && err != ErrorNoVoteActive && err != ErrorAmbiguousVote {
+		log.Fatal("Error pulling active poll", err)
+	}
+	if previousPoll != nil {
+		changed = previousPoll.PollBallot(userID) >= 0
+	}
+
+	poll, err := e.modelHelper.CastPollBallotByID(channelID, command.PollBallot.VoteID, userID, command.PollBallot.Choices)
+	var message string
+	switch err {
+	case nil:
+		choices := make([]string, 0, len(command.PollBallot.Choices))
+		for _, choice := range command.PollBallot.Choices {
+			choices = append(choices, poll.PollOptions[choice])
+		}
+		messageFormat := MsgPicked
+		if poll.Ranked {
+			messageFormat = MsgRanked
+		}
+		if changed {
+			messageFormat = MsgChangedPollBallot
+		}
+		message = fmt.Sprintf(messageFormat, command.Author.Mention(), strings.Join(choices, ", ")) + "\n" + PollStatusLine(e.modelHelper.UTCClock, poll)
+	case ErrorNoPollActive:
+		message = MsgNoActivePoll
+	case ErrorAmbiguousVote:
+		message = MsgAmbiguousVote
+	case ErrorAlreadyVoted:
+		message = fmt.Sprintf(MsgAlreadyVoted, command.Author.Mention())
+	case ErrorInvalidChoice:
+		message = fmt.Sprintf(MsgInvalidChoice, len(previousPoll.PollOptions))
+	case ErrorPollNotRanked:
+		message = MsgPollNotRanked
+	case ErrorBallotsLocked:
+		message = MsgBallotsLocked
+	default:
+		log.Fatal("Error casting poll ballot", err)
+	}
+
+	if _, err := s.ChannelMessageSend(channelID.Format(), message); err != nil {
+		log.Info("Failed to send poll ballot message", err)
+	}
+}
@@ -0,0 +1,97 @@
+package vote
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// PollBallotParser parses a ballot in a poll
+type PollBallotParser struct {
+	// The message that the parser looks for.
+	Message string
+	// Whether the ballot ranks several options.
+	Ranked bool
+}
+
+// NewPollBallotParser works as advertised.
+func NewPollBallotParser(message string, ranked bool) *PollBallotParser {
+	return &PollBallotParser{
+		Message: message,
+		Ranked:  ranked,
+	}
+}
+
+// GetName returns the named type.
+func (p *PollBallotParser) GetName() string {
+	return p.Message
+}
+
+const (
+	// MsgHelpPick is help text for ?pick
+	MsgHelpPick = "Type `?pick <number>` to pick an option in the current poll, if one is active. When several votes are active, add the ID of the poll first, like `?pick #12 2`"
+	// MsgHelpRank is help text for ?rank
+	MsgHelpRank = "Type `?rank <numbers>` to rank the options in the current ranked poll, most preferred first. Example: `?rank 3 1 2`. When several votes are active, add the ID of the poll first, like `?rank #12 3 1 2`"
+)
+
+// HelpText returns the help text.
+func (p *PollBallotParser) HelpText(command string) (string, error) {
+	if p.Ranked {
+		return MsgHelpRank, nil
+	}
+	return MsgHelpPick, nil
+}
+
+// Parse parses the given ballot.
+func (p *PollBallotParser) Parse(splitContent []string, m *discordgo.MessageCreate) (*model.Command, error) {
+	if splitContent[0] != p.GetName() {
+		log.Fatal("parsePollBallot called with non-ballot command", errors.New("wat"))
+	}
+
+	// Users number the options from 1. The poll's ID may come first, like #12.
+	voteID := 0
+	choices := []int{}
+	seen := map[int]bool{}
+	valid := true
+	for _, token := range splitContent[1:] {
+		if len(token) == 0 {
+			continue
+		}
+		if strings.HasPrefix(token, "#") && voteID == 0 && len(choices) == 0 {
+			voteID, valid = parseVoteID(token)
+			if !valid {
+				break
+			}
+			continue
+		}
+		choice, err := strconv.Atoi(token)
+		if err != nil || choice <= 0 || seen[choice-1] {
+			valid = false
+			break
+		}
+		seen[choice-1] = true
+		choices = append(choices, choice-1)
+	}
+
+	// Show help when not enough data is present, or malicious data is present.
+	if !valid || len(choices) == 0 || (!p.Ranked && len(choices) > 1) {
+		return &model.Command{
+			Type: model.CommandTypeHelp,
+			Help: &model.HelpData{
+				Command: p.GetName(),
+			},
+		}, nil
+	}
+
+	return &model.Command{
+		Type: model.CommandTypePollBallot,
+		PollBallot: &model.PollBallotData{
+			VoteID:  voteID,
+			Choices: choices,
+		},
+	}, nil
+}
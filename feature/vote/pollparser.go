@@ -0,0 +1,113 @@
+package vote
+
+import (
+	"errors"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jakevoytko/crbot/model"
+	"github.com/jakevoytko/crbot/util"
+)
+
+const (
+	// MsgHelpPoll is the help text for ?poll
+	MsgHelpPoll = "Type `?poll [duration] [--ranked] [--locked] <question> | <option> | <option> ...` to start a poll with up to 10 options. Ranked polls are decided by instant runoff, and other polls by plurality. Ballots can be changed by voting again, or retracted with `?unvote`, unless the poll is `--locked`. The first character of the question must be alphanumeric.\n\nExample: `?poll --ranked best pizza topping? | pineapple | mushrooms | anchovies`"
+
+	// OptionRanked starts a ranked-choice poll
+	OptionRanked = "--ranked"
+	// PollSeparator separates the question and options of a poll
+	PollSeparator = "|"
+	// MaxPollOptions is the most options that a poll can have
+	MaxPollOptions = 10
+)
+
+// PollParser parses ?poll commands.
+type PollParser struct {
+}
+
+// NewPollParser works as advertised.
+func NewPollParser() *PollParser {
+	return &PollParser{}
+}
+
+// GetName returns the named type of this feature.
+func (p *PollParser) GetName() string {
+	return model.CommandNamePoll
+}
+
+// HelpText explains how to use ?poll.
+func (p *PollParser) HelpText(command string) (string, error) {
+	return MsgHelpPoll, nil
+}
+
+// Parse parses the given poll command.
+func (p *PollParser) Parse(splitContent []string, m *discordgo.MessageCreate) (*model.Command, error) {
+	if splitContent[0] != p.GetName() {
+		log.Fatal("parsePoll called with non poll command", errors.New("wat"))
+	}
+	splitContent = util.CollapseWhitespace(splitContent, 1)
+
+	help := &model.Command{
+		Type: model.CommandTypeHelp,
+		Help: &model.HelpData{
+			Command: model.CommandNamePoll,
+		},
+	}
+
+	// Options come before the question.
+	poll := &model.PollData{}
+	index := 1
+	if index < len(splitContent) {
+		if duration, err := time.ParseDuration(splitContent[index]); err == nil {
+			if duration <= 0 {
+				return help, nil
+			}
+			poll.Duration = duration
+			index++
+			splitContent = util.CollapseWhitespace(splitContent, index)
+		}
+	}
+	for index < len(splitContent) && strings.HasPrefix(splitContent[index], "--") {
+		switch {
+		case splitContent[index] == OptionRanked && !poll.Ranked:
+			poll.Ranked = true
+		case splitContent[index] == OptionLocked && !poll.LockBallots:
+			poll.LockBallots = true
+		default:
+			return help, nil
+		}
+		index++
+		splitContent = util.CollapseWhitespace(splitContent, index)
+	}
+	if index >= len(splitContent) {
+		return help, nil
+	}
+
+	parts := strings.Split(strings.Join(splitContent[index:], " "), PollSeparator)
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	poll.Question = parts[0]
+	poll.Options = parts[1:]
+
+	// Show help when not enough data is present, or malicious data is present.
+	questionRegexp := regexp.MustCompile("^[[:alnum:]].*$")
+	if !questionRegexp.MatchString(poll.Question) || len(poll.Options) < 2 || len(poll.Options) > MaxPollOptions {
+		return help, nil
+	}
+	seen := map[string]bool{}
+	for _, option := range poll.Options {
+		if len(option) == 0 || seen[strings.ToLower(option)] {
+			return help, nil
+		}
+		seen[strings.ToLower(option)] = true
+	}
+
+	return &model.Command{
+		Type: model.CommandTypePoll,
+		Poll: poll,
+	}, nil
+}
@@ -0,0 +1,106 @@
+package vote
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jakevoytko/crbot/model"
+)
+
+const (
+	// MsgPollOwner prints who started the poll
+	MsgPollOwner = "Poll started by %s: "
+	// MsgPollOption prints a single poll option
+	MsgPollOption = "%d. %s"
+	// MsgPollOptionCount prints a single poll option and its ballots
+	MsgPollOptionCount = "%d. %s: %d"
+	// MsgOneBallotCast is the unpluralized message for ballots cast
+	MsgOneBallotCast = "1 ballot cast"
+	// MsgBallotsCast prints the number of ballots cast
+	MsgBallotsCast = "%d ballots cast"
+	// MsgPollConcluded is the header for a concluded poll
+	MsgPollConcluded = "@here -- Poll started by %s has concluded: %s"
+	// MsgPollWinner prints the winner of a poll
+	MsgPollWinner = "Winner: %s"
+	// MsgPollTie prints the options that tied
+	MsgPollTie = "Tie between %s"
+	// MsgStatusPollCancelled prints that a poll was cancelled
+	MsgStatusPollCancelled = "Poll Cancelled."
+	// MsgPollNoBallots prints that nobody voted in the poll
+	MsgPollNoBallots = "No ballots were cast."
+	// MsgPollRounds prints how many rounds an instant runoff took
+	MsgPollRounds = "Decided by instant runoff after %d rounds."
+)
+
+// PollOptionLines returns a line for each option in the poll.
+func PollOptionLines(poll *model.Vote) []string {
+	lines := make([]string, 0, len(poll.PollOptions))
+	for i, option := range poll.PollOptions {
+		lines = append(lines, fmt.Sprintf(MsgPollOption, i+1, option))
+	}
+	return lines
+}
+
+// PollStatusLines returns the first choices and time remaining of an
+// in-progress poll.
+func PollStatusLines(clock model.UTCClock, poll *model.Vote) []string {
+	lines := []string{}
+	for i, count := range poll.FirstChoices() {
+		lines = append(lines, fmt.Sprintf(MsgPollOptionCount, i+1, poll.PollOptions[i], count))
+	}
+	return append(lines, PollStatusLine(clock, poll))
+}
+
+// PollStatusLine returns the number of ballots and time remaining of an
+// in-progress poll.
+func PollStatusLine(clock model.UTCClock, poll *model.Vote) string {
+	return ballotsString(poll) + ". " + TimeString(clock, poll.TimestampEnd)
+}
+
+func ballotsString(poll *model.Vote) string {
+	if len(poll.PollBallots) == 1 {
+		return MsgOneBallotCast
+	}
+	return fmt.Sprintf(MsgBallotsCast, len(poll.PollBallots))
+}
+
+// PollOutcome returns the outcome of the tallied poll.
+func PollOutcome(result *model.PollResult) int {
+	switch len(result.Winners) {
+	case 0:
+		return model.VoteOutcomeNotEnough
+	case 1:
+		return model.VoteOutcomePollWinner
+	}
+	return model.VoteOutcomePollTie
+}
+
+// CompletedPollLines returns the results of a concluded poll. The counts are
+// from the final round of tallying.
+func CompletedPollLines(poll *model.Vote, result *model.PollResult) []string {
+	winners := make([]string, 0, len(result.Winners))
+	for _, winner := range result.Winners {
+		winners = append(winners, poll.PollOptions[winner])
+	}
+
+	lines := []string{}
+	switch len(winners) {
+	case 0:
+		return append(lines, MsgPollNoBallots)
+	case 1:
+		lines = append(lines, fmt.Sprintf(MsgPollWinner, winners[0]))
+	default:
+		lines = append(lines, fmt.Sprintf(MsgPollTie, strings.Join(winners, ", ")))
+	}
+
+	finalRound := result.Rounds[len(result.Rounds)-1]
+	for i, option := range poll.PollOptions {
+		if count, ok := finalRound[i]; ok {
+			lines = append(lines, fmt.Sprintf(MsgPollOptionCount, i+1, option, count))
+		}
+	}
+	if len(result.Rounds) > 1 {
+		lines = append(lines, fmt.Sprintf(MsgPollRounds, len(result.Rounds)))
+	}
+	return lines
+}
@@ -0,0 +1,160 @@
+package vote
+
+import (
+	"fmt"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/audit"
+	"github.com/jakevoytko/crbot/config"
+	"github.com/jakevoytko/crbot/feature/permissions"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// ReactionExecutor counts reactions to a vote announcement as ballots
+type ReactionExecutor struct {
+	modelHelper *ModelHelper
+	weightings  *Weightings
+	auditBus    *audit.Bus
+	config      *config.Config
+}
+
+// NewReactionExecutor works as advertised
+func NewReactionExecutor(modelHelper *ModelHelper, weightings *Weightings, auditBus *audit.Bus, config *config.Config) *ReactionExecutor {
+	return &ReactionExecutor{
+		modelHelper: modelHelper,
+		weightings:  weightings,
+		auditBus:    auditBus,
+		config:      config,
+	}
+}
+
+// GetType returns the type of this feature.
+func (e *ReactionExecutor) GetType() int {
+	return model.CommandTypeReaction
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private
+// channel. Reactions to anything other than a vote announcement are ignored, so
+// there's nothing to intercept.
+func (e *ReactionExecutor) PublicOnly() bool {
+	return false
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *ReactionExecutor) ModeratorOnly() bool {
+	return false
+}
+
+const (
+	// ReactionInFavor is the reaction that casts a ballot in favor
+	ReactionInFavor = "✅"
+	// ReactionAgainst is the reaction that casts a ballot against
+	ReactionAgainst = "❌"
+)
+
+// Execute casts a ballot when a reaction is added to the active vote's
+// announcement, and retracts it when the reaction is removed. Reactions that
+// already match the user's ballot are ignored, so users can mix reactions with
+// ?yes and ?no.
+func (e *ReactionExecutor) Execute(s api.DiscordSession, channelID model.Snowflake, command *model.Command) {
+	inFavor := false
+	switch command.Reaction.Emoji {
+	case ReactionInFavor:
+		inFavor = true
+	case ReactionAgainst:
+	default:
+		return
+	}
+
+	// Find the active vote that was announced in the message.
+	votes, err := e.modelHelper.OpenVotes(channelID)
+	if err != nil {
+		log.Fatal("Error reading active votes", err)
+	}
+	var vote *model.Vote
+	for _, openVote := range votes {
+		if openVote.MessageID != 0 && openVote.MessageID == command.Reaction.MessageID {
+			vote = openVote
+		}
+	}
+	if vote == nil {
+		return
+	}
+
+	userID, err := model.ParseSnowflake(command.Author.ID)
+	if err != nil {
+		log.Fatal("Error parsing discord user ID", err)
+	}
+	voted, previouslyInFavor := vote.Ballot(e.modelHelper.BallotSecret, userID)
+
+	var message string
+	// Reactions are ballots, so they follow the policies of the ballot commands.
+	if command.Reaction.Added {
+		if !e.allowed(s, command, inFavor) {
+			return
+		}
+		weight := e.weightings.BallotWeight(s, vote, userID)
+		updatedVote, err := e.modelHelper.CastBallotByID(channelID, vote.VoteID, userID, inFavor, weight)
+		switch err {
+		case nil:
+			message = ballotMessage(e.modelHelper.UTCClock, command.Author, updatedVote, inFavor, voted)
+		case ErrorAlreadyVoted:
+			return
+		case ErrorBallotsLocked:
+			message = MsgBallotsLocked
+		default:
+			log.Fatal("Error casting ballot", err)
+		}
+	} else {
+		// Removing the reaction for the other side doesn't change the ballot.
+		if !voted || previouslyInFavor != inFavor || !e.allowed(s, command, inFavor) {
+			return
+		}
+		updatedVote, err := e.modelHelper.RetractBallotByID(channelID, vote.VoteID, userID)
+		switch err {
+		case nil:
+			message = retractedMessage(e.modelHelper.UTCClock, command.Author, updatedVote)
+		case ErrorBallotsLocked:
+			message = MsgBallotsLocked
+		default:
+			log.Fatal("Error retracting ballot", err)
+		}
+	}
+
+	if _, err := s.ChannelMessageSend(channelID.Format(), message); err != nil {
+		log.Info("Failed to send ballot status message", err)
+	}
+}
+
+// allowed returns whether the policy of the ballot command that the reaction
+// stands for allows the user. Refusals are recorded, and told to the channel.
+func (e *ReactionExecutor) allowed(s api.DiscordSession, command *model.Command, inFavor bool) bool {
+	name := model.CommandNameVoteAgainstNo
+	switch {
+	case !command.Reaction.Added:
+		name = model.CommandNameVoteRetract
+	case inFavor:
+		name = model.CommandNameVoteInFavorYes
+	}
+	policy := e.config.CommandPolicy(name)
+	if policy == nil {
+		return true
+	}
+	caller, reason := permissions.Check(s, policy, command)
+	if len(reason) == 0 {
+		return true
+	}
+
+	e.auditBus.Publish(s, &audit.Event{
+		Type:      audit.EventPermissionDenied,
+		UserID:    caller.UserID,
+		ChannelID: caller.ChannelID,
+		Command:   name,
+		Details:   reason,
+	})
+	if _, err := s.ChannelMessageSend(command.ChannelID.Format(), fmt.Sprintf(permissions.MsgPermissionDenied, name, name)); err != nil {
+		log.Info("Failed to send permission denied message", err)
+	}
+	return false
+}
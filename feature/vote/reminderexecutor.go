@@ -0,0 +1,112 @@
+package vote
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// ReminderExecutor reminds the channel about a vote that is about to close
+type ReminderExecutor struct {
+	modelHelper *ModelHelper
+}
+
+// NewReminderExecutor works as advertised
+func NewReminderExecutor(modelHelper *ModelHelper) *ReminderExecutor {
+	return &ReminderExecutor{
+		modelHelper: modelHelper,
+	}
+}
+
+// GetType returns the type of this feature.
+func (e *ReminderExecutor) GetType() int {
+	return model.CommandTypeVoteReminder
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private
+// channel. Reminders are only scheduled for votes in public channels.
+func (e *ReminderExecutor) PublicOnly() bool {
+	return false
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *ReminderExecutor) ModeratorOnly() bool {
+	return false
+}
+
+const (
+	// MsgVoteReminder reminds the channel about a vote that is about to close
+	MsgVoteReminder = "@here -- Reminder: %s started a vote: %s\n%s"
+	// MsgOneBallotNeeded prints that one more ballot is needed to reach quorum
+	MsgOneBallotNeeded = "1 more ballot is needed to reach quorum."
+	// MsgBallotsNeeded prints how many more ballots are needed to reach quorum
+	MsgBallotsNeeded = "%d more ballots are needed to reach quorum."
+)
+
+// Execute reminds the channel about the vote whose reminder fired. Votes that
+// already concluded are left alone.
+func (e *ReminderExecutor) Execute(s api.DiscordSession, channelID model.Snowflake, command *model.Command) {
+	if command.VoteReminder == nil {
+		log.Info("Tried to send a vote reminder without an ID", errors.New("missing vote ID"))
+		return
+	}
+
+	vote, err := e.modelHelper.Vote(channelID, command.VoteReminder.VoteID)
+	if err != nil {
+		log.Info("Error grabbing vote to remind about", err)
+		return
+	}
+	if vote == nil || vote.VoteOutcome != model.VoteOutcomeNotDone {
+		return
+	}
+
+	user, err := s.User(vote.UserID.Format())
+	if err != nil {
+		log.Info("Error fetching the owner when rendering the reminder", err)
+		return
+	}
+
+	message := fmt.Sprintf(MsgVoteReminder, user.Mention(), vote.Message, StatusLine(e.modelHelper.UTCClock, vote))
+	votesFor, votesAgainst := vote.Tally()
+	switch needed := vote.RequiredBallots() - votesFor - votesAgainst; {
+	case needed == 1:
+		message += "\n" + MsgOneBallotNeeded
+	case needed > 1:
+		message += "\n" + fmt.Sprintf(MsgBallotsNeeded, needed)
+	}
+	if _, err := s.ChannelMessageSend(channelID.Format(), message); err != nil {
+		log.Info("Error sending vote reminder", err)
+	}
+}
+
+// NewReminderCommand returns the command that reminds the channel about the
+// vote.
+func NewReminderCommand(vote *model.Vote) *model.Command {
+	return &model.Command{
+		Type:      model.CommandTypeVoteReminder,
+		ChannelID: vote.ChannelID,
+		VoteReminder: &model.VoteReminderData{
+			VoteID: vote.VoteID,
+		},
+	}
+}
+
+// scheduleReminders starts a timer for each reminder that is still ahead of
+// the vote. Reminders are derived from the end of the vote, so this reschedules
+// them after a restart without sending the ones that were missed.
+func scheduleReminders(clock model.UTCClock, timer model.UTCTimer, commandChannel chan<- *model.Command, vote *model.Vote, reminders []time.Duration) {
+	now := clock.Now()
+	for _, reminder := range reminders {
+		remindAt := vote.TimestampEnd.Add(-reminder)
+		if !remindAt.After(now) || !remindAt.After(vote.TimestampStart) {
+			continue
+		}
+		timer.ExecuteAfter(remindAt.Sub(now), func() {
+			commandChannel <- NewReminderCommand(vote)
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package vote
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// RetractExecutor retracts a ballot
+type RetractExecutor struct {
+	modelHelper *ModelHelper
+}
+
+// NewRetractExecutor works as advertised
+func NewRetractExecutor(modelHelper *ModelHelper) *RetractExecutor {
+	return &RetractExecutor{
+		modelHelper: modelHelper,
+	}
+}
+
+// GetType returns the type of this feature.
+func (e *RetractExecutor) GetType() int {
+	return model.CommandTypeVoteRetract
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private
+// channel. Ballots in secret votes are retracted in private channels, which is
+// checked when executing.
+func (e *RetractExecutor) PublicOnly() bool {
+	return false
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *RetractExecutor) ModeratorOnly() bool {
+	return false
+}
+
+const (
+	// MsgRetracted returns that the user retracted their ballot
+	MsgRetracted = "%v retracted their ballot"
+	// MsgNotVoted returns that the user has no ballot to retract
+	MsgNotVoted = "%v hasn't voted"
+	// MsgRetractPrivate prints how to retract ballots from a private channel
+	MsgRetractPrivate = "Type `?unvote` in the vote's channel to retract your ballot. Ballots in a secret vote are retracted by direct message with `?unvote <vote>`."
+	// MsgSecretRetract prints that ballots in the vote must be retracted by
+	// direct message
+	MsgSecretRetract = "This vote is secret. Direct message me `?unvote %s` to retract your ballot."
+	// MsgNotSecretRetract prints that the referenced vote is an open vote
+	MsgNotSecretRetract = "That vote isn't secret. Type `?unvote` in its channel to retract your ballot."
+	// MsgSecretBallotRetracted confirms the retracted secret ballot to the voter
+	MsgSecretBallotRetracted = "Your ballot was retracted"
+	// MsgSecretBallotRetractAnnounced announces a retracted secret ballot in the
+	// vote's channel
+	MsgSecretBallotRetractAnnounced = "A secret ballot was retracted"
+)
+
+// Execute runs the command
+func (e *RetractExecutor) Execute(s api.DiscordSession, channelID model.Snowflake, command *model.Command) {
+	userID, err := model.ParseSnowflake(command.Author.ID)
+	if err != nil {
+		log.Fatal("Error parsing discord user ID", err)
+	}
+
+	discordChannel, err := s.Channel(channelID.Format())
+	if err != nil {
+		log.Info("Error retrieving the retract channel", err)
+		return
+	}
+	if discordChannel.Type == discordgo.ChannelTypeDM || discordChannel.Type == discordgo.ChannelTypeGroupDM {
+		reply := MsgRetractPrivate
+		if command.VoteTarget != nil && command.VoteTarget.ChannelID != 0 {
+			reply = MsgSecretBallotPrivateOnly
+			if discordChannel.Type == discordgo.ChannelTypeDM {
+				e.retractSecretBallot(s, channelID, userID, command)
+				return
+			}
+		}
+		if _, err := s.ChannelMessageSend(channelID.Format(), reply); err != nil {
+			log.Info("Failed to send retract message", err)
+		}
+		return
+	}
+
+	voteID := targetVoteID(command)
+	vote, err := e.modelHelper.RetractBallotByID(channelID, voteID, userID)
+	var message string
+	switch err {
+	case nil:
+		message = retractedMessage(e.modelHelper.UTCClock, command.Author, vote)
+	case ErrorNoVoteActive:
+		message = MsgNoActiveVote
+	case ErrorAmbiguousVote:
+		message = MsgAmbiguousVote
+	case ErrorNotVoted:
+		message = fmt.Sprintf(MsgNotVoted, command.Author.Mention())
+	case ErrorBallotsLocked:
+		message = MsgBallotsLocked
+	case ErrorSecretVote:
+		activeVote, err := e.modelHelper.ActiveVote(channelID, voteID)
+		if err != nil {
+			log.Fatal("Error pulling active vote", err)
+		}
+		message = fmt.Sprintf(MsgSecretRetract, SecretBallotReference(activeVote))
+	default:
+		log.Fatal("Error retracting ballot", err)
+	}
+
+	if _, err := s.ChannelMessageSend(channelID.Format(), message); err != nil {
+		log.Info("Failed to send retract message", err)
+	}
+}
+
+// retractSecretBallot retracts the user's ballot in the secret vote, confirms it
+// to the voter, and announces the new totals in the vote's channel without
+// saying who retracted.
+func (e *RetractExecutor) retractSecretBallot(s api.DiscordSession, channelID, userID model.Snowflake, command *model.Command) {
+	// Everyone outside of the vote's guild is told that the vote isn't active,
+	// so that they can't find out about it.
+	data := command.VoteTarget
+	var vote *model.Vote
+	var reply string
+	if !canSeeVote(s, data.ChannelID, command.Author.ID) {
+		reply = MsgNoSecretVote
+	} else {
+		var err error
+		vote, err = e.modelHelper.RetractSecretBallot(data.ChannelID, data.VoteID, userID)
+		switch err {
+		case nil:
+			reply = MsgSecretBallotRetracted
+		case ErrorNoVoteActive:
+			reply = MsgNoSecretVote
+		case ErrorNotSecret:
+			reply = MsgNotSecretRetract
+		case ErrorNotVoted:
+			reply = fmt.Sprintf(MsgNotVoted, command.Author.Mention())
+		case ErrorBallotsLocked:
+			reply = MsgBallotsLocked
+		default:
+			log.Fatal("Error retracting secret ballot", err)
+		}
+	}
+	if _, err := s.ChannelMessageSend(channelID.Format(), reply); err != nil {
+		log.Info("Failed to send secret retract reply", err)
+	}
+	if vote == nil {
+		return
+	}
+
+	announcement := MsgSecretBallotRetractAnnounced + "\n" + StatusLine(e.modelHelper.UTCClock, vote)
+	if _, err := s.ChannelMessageSend(data.ChannelID.Format(), announcement); err != nil {
+		log.Info("Failed to announce retracted secret ballot", err)
+	}
+}
+
+// retractedMessage announces the retracted ballot, along with the status of the
+// vote.
+func retractedMessage(clock model.UTCClock, author *discordgo.User, vote *model.Vote) string {
+	statusLine := StatusLine(clock, vote)
+	if vote.IsPoll() {
+		statusLine = PollStatusLine(clock, vote)
+	}
+	return fmt.Sprintf(MsgRetracted, author.Mention()) + "\n" + statusLine
+}
@@ -0,0 +1,75 @@
+package vote
+
+import (
+	"errors"
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// RetractParser parses ?unvote commands
+type RetractParser struct {
+}
+
+// NewRetractParser works as advertised.
+func NewRetractParser() *RetractParser {
+	return &RetractParser{}
+}
+
+// GetName returns the named type.
+func (p *RetractParser) GetName() string {
+	return model.CommandNameVoteRetract
+}
+
+const (
+	// MsgHelpRetract is help text for ?unvote
+	MsgHelpRetract = "Retracts your ballot in the current vote or poll, unless its ballots are locked. When several votes are active, add the ID of the vote, like `?unvote 12`. Ballots in a secret vote are retracted by direct message, like `?unvote <vote>`, where the vote is the one in its `?ballot` command."
+)
+
+// HelpText returns the help text.
+func (p *RetractParser) HelpText(command string) (string, error) {
+	return MsgHelpRetract, nil
+}
+
+// Parse parses the given retract command.
+func (p *RetractParser) Parse(splitContent []string, m *discordgo.MessageCreate) (*model.Command, error) {
+	if splitContent[0] != p.GetName() {
+		log.Fatal("parseRetract called with non-unvote command", errors.New("wat"))
+	}
+	// Secret votes are referenced the same way as in ?ballot.
+	if reference, ok := singleArg(splitContent); ok {
+		if channelID, voteID, ok := parseSecretBallotReference(reference); ok {
+			return &model.Command{
+				Type: model.CommandTypeVoteRetract,
+				VoteTarget: &model.VoteTargetData{
+					ChannelID: channelID,
+					VoteID:    voteID,
+				},
+			}, nil
+		}
+	}
+
+	// Anything other than a vote ID is ignored.
+	voteID, _ := voteTarget(splitContent)
+	return &model.Command{
+		Type: model.CommandTypeVoteRetract,
+		VoteTarget: &model.VoteTargetData{
+			VoteID: voteID,
+		},
+	}, nil
+}
+
+// singleArg returns the only argument of the command, if there is exactly one.
+func singleArg(splitContent []string) (string, bool) {
+	args := []string{}
+	for _, token := range splitContent[1:] {
+		if len(token) > 0 {
+			args = append(args, token)
+		}
+	}
+	if len(args) != 1 {
+		return "", false
+	}
+	return args[0], true
+}
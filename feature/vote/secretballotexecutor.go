@@ -0,0 +1,147 @@
+package vote
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// SecretBallotExecutor casts a ballot in a secret vote
+type SecretBallotExecutor struct {
+	modelHelper *ModelHelper
+	weightings  *Weightings
+}
+
+// NewSecretBallotExecutor works as advertised
+func NewSecretBallotExecutor(modelHelper *ModelHelper, weightings *Weightings) *SecretBallotExecutor {
+	return &SecretBallotExecutor{
+		modelHelper: modelHelper,
+		weightings:  weightings,
+	}
+}
+
+// GetType returns the type of this feature.
+func (e *SecretBallotExecutor) GetType() int {
+	return model.CommandTypeSecretBallot
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private
+// channel. Secret ballots are only accepted in private channels, which is
+// checked when executing.
+func (e *SecretBallotExecutor) PublicOnly() bool {
+	return false
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *SecretBallotExecutor) ModeratorOnly() bool {
+	return false
+}
+
+const (
+	// MsgSecretBallotPrivateOnly prints that secret ballots must be cast by direct message
+	MsgSecretBallotPrivateOnly = "Secret ballots must be sent to me by direct message"
+	// MsgNoSecretVote prints that the referenced secret vote isn't active
+	MsgNoSecretVote = "That secret vote isn't active"
+	// MsgNotSecret prints that the referenced vote is an open vote
+	MsgNotSecret = "That vote isn't secret. Type `?yes` or `?no` in its channel to vote."
+	// MsgSecretBallotCast confirms the secret ballot to the voter
+	MsgSecretBallotCast = "Your ballot was recorded"
+	// MsgSecretBallotChanged confirms the changed secret ballot to the voter
+	MsgSecretBallotChanged = "Your ballot was changed"
+	// MsgSecretBallotAnnounced announces a new secret ballot in the vote's channel
+	MsgSecretBallotAnnounced = "A secret ballot was cast"
+	// MsgSecretBallotChangeAnnounced announces a changed secret ballot in the vote's channel
+	MsgSecretBallotChangeAnnounced = "A secret ballot was changed"
+	// MsgSecretVote prints that ballots in the vote must be cast by direct message
+	MsgSecretVote = "This vote is secret. Direct message me `?ballot %s yes` or `?ballot %s no` to vote."
+)
+
+// Execute casts the ballot, confirms it to the voter, and announces the new
+// totals in the vote's channel without saying who voted.
+func (e *SecretBallotExecutor) Execute(s api.DiscordSession, channelID model.Snowflake, command *model.Command) {
+	discordChannel, err := s.Channel(channelID.Format())
+	if err != nil {
+		log.Info("Error retrieving the ballot channel", err)
+		return
+	}
+	if discordChannel.Type != discordgo.ChannelTypeDM {
+		if _, err := s.ChannelMessageSend(channelID.Format(), MsgSecretBallotPrivateOnly); err != nil {
+			log.Info("Failed to send private-only message", err)
+		}
+		return
+	}
+
+	userID, err := model.ParseSnowflake(command.Author.ID)
+	if err != nil {
+		log.Fatal("Error parsing discord user ID", err)
+	}
+
+	// Only members of the vote's guild can vote. Everyone else is told that the
+	// vote isn't active, so that they can't find out about it.
+	data := command.SecretBallot
+	if !canSeeVote(s, data.ChannelID, command.Author.ID) {
+		if _, err := s.ChannelMessageSend(channelID.Format(), MsgNoSecretVote); err != nil {
+			log.Info("Failed to send secret ballot reply", err)
+		}
+		return
+	}
+
+	changed := false
+	weight := 1
+	previousVote, err := e.modelHelper.Vote(data.ChannelID, data.VoteID)
+	if err != nil {
+		log.Fatal("Error pulling secret vote", err)
+	}
+	if previousVote != nil {
+		changed, _ = previousVote.Ballot(e.modelHelper.BallotSecret, userID)
+		weight = e.weightings.BallotWeight(s, previousVote, userID)
+	}
+
+	vote, err := e.modelHelper.CastSecretBallot(data.ChannelID, data.VoteID, userID, data.InFavor, weight)
+	var reply string
+	switch err {
+	case nil:
+		reply = MsgSecretBallotCast
+		if changed {
+			reply = MsgSecretBallotChanged
+		}
+	case ErrorNoVoteActive:
+		reply = MsgNoSecretVote
+	case ErrorNotSecret:
+		reply = MsgNotSecret
+	case ErrorAlreadyVoted:
+		reply = fmt.Sprintf(MsgAlreadyVoted, command.Author.Mention())
+	case ErrorBallotsLocked:
+		reply = MsgBallotsLocked
+	default:
+		log.Fatal("Error casting secret ballot", err)
+	}
+	if _, err := s.ChannelMessageSend(channelID.Format(), reply); err != nil {
+		log.Info("Failed to send secret ballot reply", err)
+	}
+	if vote == nil {
+		return
+	}
+
+	announcement := MsgSecretBallotAnnounced
+	if changed {
+		announcement = MsgSecretBallotChangeAnnounced
+	}
+	if _, err := s.ChannelMessageSend(data.ChannelID.Format(), announcement+"\n"+StatusLine(e.modelHelper.UTCClock, vote)); err != nil {
+		log.Info("Failed to announce secret ballot", err)
+	}
+}
+
+// canSeeVote returns whether the user is a member of the guild that the vote's
+// channel is in.
+func canSeeVote(s api.DiscordSession, voteChannelID model.Snowflake, userID string) bool {
+	voteChannel, err := s.Channel(voteChannelID.Format())
+	if err != nil || len(voteChannel.GuildID) == 0 {
+		return false
+	}
+	_, err = s.GuildMember(voteChannel.GuildID, userID)
+	return err == nil
+}
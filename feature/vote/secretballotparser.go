@@ -0,0 +1,106 @@
+package vote
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jakevoytko/crbot/model"
+	"github.com/jakevoytko/crbot/util"
+)
+
+// SecretBallotParser parses ?ballot commands
+type SecretBallotParser struct {
+}
+
+// NewSecretBallotParser works as advertised.
+func NewSecretBallotParser() *SecretBallotParser {
+	return &SecretBallotParser{}
+}
+
+// GetName returns the named type.
+func (p *SecretBallotParser) GetName() string {
+	return model.CommandNameSecretBallot
+}
+
+const (
+	// MsgHelpSecretBallot is the help text for ?ballot
+	MsgHelpSecretBallot = "Direct message me `?ballot <vote> yes` or `?ballot <vote> no` to cast a ballot in a secret vote. The announcement of the vote has the exact command to send."
+
+	// BallotInFavor is the ?ballot choice for a ballot in favor
+	BallotInFavor = "yes"
+	// BallotAgainst is the ?ballot choice for a ballot against
+	BallotAgainst = "no"
+)
+
+// HelpText returns the help text.
+func (p *SecretBallotParser) HelpText(command string) (string, error) {
+	return MsgHelpSecretBallot, nil
+}
+
+// Parse parses the given secret ballot command.
+func (p *SecretBallotParser) Parse(splitContent []string, m *discordgo.MessageCreate) (*model.Command, error) {
+	if splitContent[0] != p.GetName() {
+		log.Fatal("parseSecretBallot called with non-ballot command", errors.New("wat"))
+	}
+	splitContent = util.CollapseWhitespace(splitContent, 1)
+	splitContent = util.CollapseWhitespace(splitContent, 2)
+
+	help := &model.Command{
+		Type: model.CommandTypeHelp,
+		Help: &model.HelpData{
+			Command: model.CommandNameSecretBallot,
+		},
+	}
+	if len(splitContent) != 3 {
+		return help, nil
+	}
+
+	channelID, voteID, ok := parseSecretBallotReference(splitContent[1])
+	if !ok {
+		return help, nil
+	}
+
+	inFavor := false
+	switch strings.ToLower(splitContent[2]) {
+	case BallotInFavor:
+		inFavor = true
+	case BallotAgainst:
+	default:
+		return help, nil
+	}
+
+	return &model.Command{
+		Type: model.CommandTypeSecretBallot,
+		SecretBallot: &model.SecretBallotData{
+			ChannelID: channelID,
+			VoteID:    voteID,
+			InFavor:   inFavor,
+		},
+	}, nil
+}
+
+// SecretBallotReference returns how ?ballot refers to the secret vote.
+func SecretBallotReference(vote *model.Vote) string {
+	return fmt.Sprintf("%v-%d", vote.ChannelID, vote.VoteID)
+}
+
+// parseSecretBallotReference parses the output of SecretBallotReference.
+func parseSecretBallotReference(reference string) (model.Snowflake, int, bool) {
+	parts := strings.Split(reference, "-")
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	channelID, err := model.ParseSnowflake(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	voteID, err := strconv.Atoi(parts[1])
+	if err != nil || voteID <= 0 {
+		return 0, 0, false
+	}
+	return channelID, voteID, true
+}
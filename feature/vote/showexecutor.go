@@ -0,0 +1,97 @@
+package vote
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// ShowExecutor shows a past vote in full
+type ShowExecutor struct {
+	modelHelper *ModelHelper
+}
+
+// NewShowExecutor works as advertised
+func NewShowExecutor(modelHelper *ModelHelper) *ShowExecutor {
+	return &ShowExecutor{
+		modelHelper: modelHelper,
+	}
+}
+
+// GetType returns the type of this feature.
+func (e *ShowExecutor) GetType() int {
+	return model.CommandTypeVoteShow
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private channel.
+func (e *ShowExecutor) PublicOnly() bool {
+	return true
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *ShowExecutor) ModeratorOnly() bool {
+	return false
+}
+
+const (
+	// MsgVoteNotFound prints that the channel has no vote with the given ID
+	MsgVoteNotFound = "No vote #%d in this channel"
+	// MsgShowVote prints the ID and initiator of a vote
+	MsgShowVote = "Vote #%d, started by %s: "
+	// MsgShowPoll prints the ID and initiator of a poll
+	MsgShowPoll = "Poll #%d, started by %s: "
+)
+
+// Execute prints the given vote, with its tallies and outcome.
+func (e *ShowExecutor) Execute(s api.DiscordSession, channel model.Snowflake, command *model.Command) {
+	vote, err := e.modelHelper.Vote(channel, command.VoteShow.VoteID)
+	if err != nil {
+		log.Fatal("Error reading vote", err)
+	}
+	if vote == nil {
+		if _, err := s.ChannelMessageSend(channel.Format(), fmt.Sprintf(MsgVoteNotFound, command.VoteShow.VoteID)); err != nil {
+			log.Info("Failed to send vote-not-found message", err)
+		}
+		return
+	}
+
+	owner, err := s.User(vote.UserID.Format())
+	if err != nil {
+		log.Info("Error fetching the owner when rendering a past vote", err)
+		return
+	}
+
+	concluded := vote.VoteOutcome != model.VoteOutcomeNotDone
+	messages := []string{}
+	if vote.IsPoll() {
+		messages = append(messages, fmt.Sprintf(MsgShowPoll, vote.VoteID, owner.Username)+vote.Message)
+		messages = append(messages, MsgSpacer)
+		switch {
+		case vote.VoteOutcome == model.VoteOutcomeCancelled:
+			messages = append(messages, MsgStatusPollCancelled, ballotsString(vote))
+		case concluded:
+			messages = append(messages, CompletedPollLines(vote, vote.TallyPoll())...)
+			messages = append(messages, ballotsString(vote))
+		default:
+			messages = append(messages, PollStatusLines(e.modelHelper.UTCClock, vote)...)
+		}
+	} else {
+		messages = append(messages, fmt.Sprintf(MsgShowVote, vote.VoteID, owner.Username)+vote.Message)
+		messages = append(messages, MsgSpacer)
+		if concluded {
+			messages = append(messages, CompletedStatusLine(vote))
+		} else {
+			messages = append(messages, StatusLine(e.modelHelper.UTCClock, vote))
+		}
+		if vote.Action != nil {
+			messages = append(messages, ActionLine(vote))
+		}
+	}
+
+	if _, err := s.ChannelMessageSend(channel.Format(), strings.Join(messages, "\n")); err != nil {
+		log.Info("Failed to send vote message", err)
+	}
+}
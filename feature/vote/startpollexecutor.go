@@ -0,0 +1,107 @@
+package vote
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/config"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// StartPollExecutor executes a poll begin command
+type StartPollExecutor struct {
+	modelHelper    *ModelHelper
+	commandChannel chan<- *model.Command
+	utcTimer       model.UTCTimer
+	config         *config.Config
+}
+
+// NewStartPollExecutor works as advertised
+func NewStartPollExecutor(modelHelper *ModelHelper, commandChannel chan<- *model.Command, utcTimer model.UTCTimer, config *config.Config) *StartPollExecutor {
+	return &StartPollExecutor{
+		modelHelper:    modelHelper,
+		commandChannel: commandChannel,
+		utcTimer:       utcTimer,
+		config:         config,
+	}
+}
+
+// GetType returns the type of this feature.
+func (e *StartPollExecutor) GetType() int {
+	return model.CommandTypePoll
+}
+
+// PublicOnly returns whether the executor should be intercepted in a private channel.
+func (e *StartPollExecutor) PublicOnly() bool {
+	return true
+}
+
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *StartPollExecutor) ModeratorOnly() bool {
+	return false
+}
+
+const (
+	// MsgBroadcastNewPoll prints that a new plurality poll is happening
+	MsgBroadcastNewPoll = "@everyone -- %s started a new poll: %s\n%s\n\nType `?pick <number>` to vote. %s."
+	// MsgBroadcastNewRankedPoll prints that a new ranked poll is happening
+	MsgBroadcastNewRankedPoll = "@everyone -- %s started a new ranked poll: %s\n%s\n\nType `?rank <numbers>` to rank the options, most preferred first. %s."
+	// MsgPollID prints how to cast ballots while other votes are active
+	MsgPollID = "Other votes are active, so add this poll's ID before your choices: `%s #%d`."
+)
+
+// Execute starts a new poll if the channel doesn't have too many active votes
+// and polls already. It also starts a timer to use to conclude the poll.
+func (e *StartPollExecutor) Execute(s api.DiscordSession, channelID model.Snowflake, command *model.Command) {
+	openVotes, ok := openVotesBelowLimit(s, e.modelHelper, e.config, channelID)
+	if !ok {
+		return
+	}
+
+	duration, rejection := resolveDuration(e.config, command.Poll.Duration)
+	if len(rejection) > 0 {
+		if _, err := s.ChannelMessageSend(channelID.Format(), rejection); err != nil {
+			log.Info("Unable to send poll duration limits to user", err)
+		}
+		return
+	}
+
+	userID, err := model.ParseSnowflake(command.Author.ID)
+	if err != nil {
+		log.Info("Error parsing command user ID", err)
+		return
+	}
+	pollData := *command.Poll
+	pollData.Duration = duration
+	poll, err := e.modelHelper.StartNewPoll(channelID, userID, &pollData)
+	if err != nil {
+		log.Fatal("error starting new poll", err)
+	}
+
+	broadcastFormat := MsgBroadcastNewPoll
+	if poll.Ranked {
+		broadcastFormat = MsgBroadcastNewRankedPoll
+	}
+	broadcastMessage := fmt.Sprintf(broadcastFormat, command.Author.Mention(), poll.Message,
+		strings.Join(PollOptionLines(poll), "\n"), TimeString(e.modelHelper.UTCClock, poll.TimestampEnd))
+	if poll.LockBallots {
+		broadcastMessage += "\n" + MsgBallotsFinal
+	}
+	if len(openVotes) > 0 {
+		command := model.CommandNamePollPick
+		if poll.Ranked {
+			command = model.CommandNamePollRank
+		}
+		broadcastMessage += "\n" + fmt.Sprintf(MsgPollID, command, poll.VoteID)
+	}
+	if _, err := s.ChannelMessageSend(channelID.Format(), broadcastMessage); err != nil {
+		log.Fatal("Unable to broadcast new poll across the channel", err)
+	}
+
+	// Polls conclude the same way as votes.
+	e.utcTimer.ExecuteAfter(poll.TimestampEnd.Sub(poll.TimestampStart), func() {
+		e.commandChannel <- NewConcludeCommand(poll)
+	})
+}
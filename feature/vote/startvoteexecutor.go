@@ -2,25 +2,37 @@ package vote
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/bwmarrin/discordgo"
 	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/config"
+	"github.com/jakevoytko/crbot/feature"
+	"github.com/jakevoytko/crbot/feature/permissions"
 	"github.com/jakevoytko/crbot/log"
 	"github.com/jakevoytko/crbot/model"
 )
 
 // StartVoteExecutor executes a vote begin command
 type StartVoteExecutor struct {
-	modelHelper    *ModelHelper
-	commandChannel chan<- *model.Command
-	utcTimer       model.UTCTimer
+	modelHelper     *ModelHelper
+	featureRegistry *feature.Registry
+	weightings      *Weightings
+	commandChannel  chan<- *model.Command
+	utcTimer        model.UTCTimer
+	config          *config.Config
 }
 
 // NewStartVoteExecutor works as advertised
-func NewStartVoteExecutor(modelHelper *ModelHelper, commandChannel chan<- *model.Command, utcTimer model.UTCTimer) *StartVoteExecutor {
+func NewStartVoteExecutor(modelHelper *ModelHelper, featureRegistry *feature.Registry, weightings *Weightings, commandChannel chan<- *model.Command, utcTimer model.UTCTimer, config *config.Config) *StartVoteExecutor {
 	return &StartVoteExecutor{
-		modelHelper:    modelHelper,
-		commandChannel: commandChannel,
-		utcTimer:       utcTimer,
+		modelHelper:     modelHelper,
+		featureRegistry: featureRegistry,
+		weightings:      weightings,
+		commandChannel:  commandChannel,
+		utcTimer:        utcTimer,
+		config:          config,
 	}
 }
 
@@ -34,50 +46,290 @@ func (e *StartVoteExecutor) PublicOnly() bool {
 	return true
 }
 
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *StartVoteExecutor) ModeratorOnly() bool {
+	return false
+}
+
 const (
-	// MsgActiveVote prints that a vote is already active
-	MsgActiveVote = "Cannot start a vote while another is in progress. Type `?votestatus` for more info"
+	// MsgTooManyVotes prints that the channel already has as many active votes as allowed
+	MsgTooManyVotes = "Cannot start another vote while %d are in progress or scheduled. Type `?votestatus` for more info"
+	// MsgVoteID prints how to cast ballots while other votes are active
+	MsgVoteID = "Other votes are active, so add this vote's ID to your ballot: `?yes %d` or `?no %d`."
 	// MsgBroadcastNewVote prints that a new vote is happening
-	MsgBroadcastNewVote = "@everyone -- %s started a new vote: %s\n\nType `?yes` or `?no` to vote. 30 minutes remaining."
+	MsgBroadcastNewVote = "@everyone -- %s started a new vote: %s\n\nType `?yes` or `?no`, or react with ✅ or ❌, to vote. %s."
+	// MsgBroadcastScheduledVote prints that a vote will open later
+	MsgBroadcastScheduledVote = "@everyone -- %s scheduled vote #%d: %s\n\nVoting opens at %s, and lasts %v minutes. Type `?vote cancel %d` to cancel it before then."
+	// MsgVoteScheduleLimits prints that the requested start is not allowed
+	MsgVoteScheduleLimits = "Votes can be scheduled to open up to %v hours from now"
+	// MsgBroadcastNewSecretVote prints that a new secret vote is happening
+	MsgBroadcastNewSecretVote = "@everyone -- %s started a new secret vote: %s\n\nDirect message me `?ballot %s yes` or `?ballot %s no` to vote. %s."
+	// MsgVoteRulesQuorum prints a non-default quorum of a new vote
+	MsgVoteRulesQuorum = "%d votes must be cast before the vote can pass."
+	// MsgVoteRulesThreshold prints the threshold of a new vote
+	MsgVoteRulesThreshold = "%d votes must be cast, and at least %v of them must be in favor, for the vote to pass."
+	// MsgVoteWeighted prints how the ballots of a new vote are weighted
+	MsgVoteWeighted = "Ballots are weighted by %s."
+	// MsgUnknownWeighting prints the weightings that a vote can choose from
+	MsgUnknownWeighting = "Ballots can only be weighted by: %s"
+	// MsgBallotsFinal prints that ballots can't be changed or retracted
+	MsgBallotsFinal = "Ballots are final once cast."
+	// MsgVoteAction prints that a passing vote is carried out automatically
+	MsgVoteAction = "If this vote passes, I will carry it out automatically."
+	// MsgInvalidAction prints that the requested action can't be voted on
+	MsgInvalidAction = "I can't call that vote: %v"
+	// MsgVoteDurationLimits prints that the requested duration is not allowed
+	MsgVoteDurationLimits = "Votes must last between %v and %v minutes"
+	// MsgVoteQuorumLimits prints that the requested quorum is not allowed
+	MsgVoteQuorumLimits = "The quorum must be between 1 and %d votes"
+	// MsgVoteActionQuorum prints that votes on actions need a larger quorum
+	MsgVoteActionQuorum = "Votes on actions need a quorum of at least %d votes"
+	// MsgSecretVotesDisabled prints that secret votes need a ballot secret
+	MsgSecretVotesDisabled = "Secret votes aren't set up. A ballot secret must be added to my config first."
 )
 
-// Execute starts a new vote if one is not already active. It also starts a
-// timer to use to conclude the vote.
+// Execute starts a new vote if the channel doesn't have too many active
+// already. It also starts a timer to use to conclude the vote, and one to open
+// it if it is scheduled.
 func (e *StartVoteExecutor) Execute(s api.DiscordSession, channelID model.Snowflake, command *model.Command) {
-	ok, err := e.modelHelper.IsVoteActive(channelID)
-	if err != nil {
-		log.Fatal("Error occurred while calling for active vote", err)
+	openVotes, ok := openVotesBelowLimit(s, e.modelHelper, e.config, channelID)
+	if !ok {
+		return
 	}
-	if ok {
-		_, err := s.ChannelMessageSend(channelID.Format(), MsgActiveVote)
-		if err != nil {
-			log.Fatal("Unable to send vote-already-active message to user", err)
+
+	options, rejection := e.resolveOptions(command.Vote.Options)
+	if len(rejection) > 0 {
+		if _, err := s.ChannelMessageSend(channelID.Format(), rejection); err != nil {
+			log.Info("Unable to send vote option limits to user", err)
 		}
 		return
 	}
 
+	// Votes on actions are about what the action will do. Only users who can
+	// run the action's command can call a vote on it.
+	message := command.Vote.Message
+	if options.Action != nil {
+		description, rejection := e.describeAction(options.Action)
+		if len(rejection) == 0 {
+			rejection = e.checkActionPolicy(s, command, options.Action)
+		}
+		if len(rejection) > 0 {
+			if _, err := s.ChannelMessageSend(channelID.Format(), rejection); err != nil {
+				log.Info("Unable to send invalid action message to user", err)
+			}
+			return
+		}
+		message = description
+	}
+
 	userID, err := model.ParseSnowflake(command.Author.ID)
 	if err != nil {
 		log.Info("Error parsing command user ID", err)
 		return
 	}
-	vote, err := e.modelHelper.StartNewVote(channelID, userID, command.Vote.Message)
+	vote, err := e.modelHelper.StartNewVote(channelID, userID, message, options)
 	if err != nil {
 		log.Fatal("error starting new vote", err)
 	}
 
-	broadcastMessage := fmt.Sprintf(MsgBroadcastNewVote, command.Author.Mention(), command.Vote.Message)
-	_, err = s.ChannelMessageSend(channelID.Format(), broadcastMessage)
+	// Scheduled votes open on a timer. After the vote has expired, send a
+	// conclude command so the status can be written to storage and printed to
+	// the users.
+	scheduled := e.modelHelper.IsScheduled(vote)
+	if scheduled {
+		scheduleOpen(e.modelHelper.UTCClock, e.utcTimer, e.commandChannel, vote)
+	}
+	e.utcTimer.ExecuteAfter(vote.TimestampEnd.Sub(e.modelHelper.UTCClock.Now()), func() {
+		e.commandChannel <- NewConcludeCommand(vote)
+	})
+	scheduleReminders(e.modelHelper.UTCClock, e.utcTimer, e.commandChannel, vote, e.config.VoteReminders())
+
+	// Scheduled votes are announced now, and again when they open.
+	if scheduled {
+		message := fmt.Sprintf(MsgBroadcastScheduledVote, command.Author.Mention(), vote.VoteID, message, StartString(vote.TimestampStart), vote.TimestampEnd.Sub(vote.TimestampStart).Minutes(), vote.VoteID)
+		if _, err := s.ChannelMessageSend(channelID.Format(), message); err != nil {
+			log.Fatal("Unable to announce the scheduled vote across the channel", err)
+		}
+		return
+	}
+	announceVote(s, e.modelHelper, command.Author, vote, len(openVotes) > 0)
+}
+
+// announceVote broadcasts the opened vote and its rules to the channel. Other
+// open votes mean that ballots need the vote's ID.
+func announceVote(s api.DiscordSession, modelHelper *ModelHelper, author *discordgo.User, vote *model.Vote, otherVotesOpen bool) {
+	timeString := TimeString(modelHelper.UTCClock, vote.TimestampEnd)
+	broadcastMessage := fmt.Sprintf(MsgBroadcastNewVote, author.Mention(), vote.Message, timeString)
+	if vote.Secret {
+		reference := SecretBallotReference(vote)
+		broadcastMessage = fmt.Sprintf(MsgBroadcastNewSecretVote, author.Mention(), vote.Message, reference, reference, timeString)
+	}
+	if !vote.Threshold.IsMajority() {
+		broadcastMessage += "\n" + fmt.Sprintf(MsgVoteRulesThreshold, vote.RequiredBallots(), vote.Threshold)
+	} else if vote.RequiredBallots() != model.DefaultVoteQuorum {
+		broadcastMessage += "\n" + fmt.Sprintf(MsgVoteRulesQuorum, vote.RequiredBallots())
+	}
+	if vote.IsWeighted() {
+		broadcastMessage += "\n" + fmt.Sprintf(MsgVoteWeighted, vote.Weighting)
+	}
+	if vote.LockBallots {
+		broadcastMessage += "\n" + MsgBallotsFinal
+	}
+	if vote.Action != nil {
+		broadcastMessage += "\n" + MsgVoteAction
+	}
+	if otherVotesOpen && !vote.Secret {
+		broadcastMessage += "\n" + fmt.Sprintf(MsgVoteID, vote.VoteID, vote.VoteID)
+	}
+	broadcast, err := s.ChannelMessageSend(vote.ChannelID.Format(), broadcastMessage)
 	if err != nil {
 		log.Fatal("Unable to broadcast new message across the channel", err)
 	}
+	// Reactions are public, so secret ballots can't be cast with them.
+	if !vote.Secret {
+		addBallotReactions(s, modelHelper, vote, broadcast)
+	}
+}
 
-	// After the vote has expired, send a conclude command so the status can be
-	// written to storage and printed to the users.
-	e.utcTimer.ExecuteAfter(vote.TimestampEnd.Sub(vote.TimestampStart), func() {
-		e.commandChannel <- &model.Command{
-			Type:      model.CommandTypeVoteConclude,
-			ChannelID: channelID,
+// addBallotReactions lets users vote by reacting to the announcement. Voting
+// still works with ?yes and ?no if this fails.
+func addBallotReactions(s api.DiscordSession, modelHelper *ModelHelper, vote *model.Vote, broadcast *discordgo.Message) {
+	messageID, err := model.ParseSnowflake(broadcast.ID)
+	if err != nil {
+		log.Info("Error parsing the vote announcement ID", err)
+		return
+	}
+	if err := modelHelper.SetVoteMessageID(vote.ChannelID, vote.VoteID, messageID); err != nil {
+		log.Info("Error recording the vote announcement", err)
+		return
+	}
+	for _, emoji := range []string{ReactionInFavor, ReactionAgainst} {
+		if err := s.MessageReactionAdd(broadcast.ChannelID, broadcast.ID, emoji); err != nil {
+			log.Info("Error adding a ballot reaction", err)
 		}
-	})
+	}
+}
+
+// openVotesBelowLimit returns the channel's active votes. Scheduled votes count
+// toward the configured limit too, so that they can always open. If the limit
+// is reached, it tells the channel and returns false.
+func openVotesBelowLimit(s api.DiscordSession, modelHelper *ModelHelper, config *config.Config, channelID model.Snowflake) ([]*model.Vote, bool) {
+	votes, err := modelHelper.unconcludedVotes(channelID)
+	if err != nil {
+		log.Fatal("Error occurred while calling for active votes", err)
+	}
+	openVotes := []*model.Vote{}
+	scheduledVotes := 0
+	for _, vote := range votes {
+		if modelHelper.isActive(vote) {
+			openVotes = append(openVotes, vote)
+		} else if modelHelper.IsScheduled(vote) {
+			scheduledVotes++
+		}
+	}
+	if reserved := len(openVotes) + scheduledVotes; reserved >= config.VoteOpenLimit() {
+		if _, err := s.ChannelMessageSend(channelID.Format(), fmt.Sprintf(MsgTooManyVotes, reserved)); err != nil {
+			log.Fatal("Unable to send too-many-votes message to user", err)
+		}
+		return nil, false
+	}
+	return openVotes, true
+}
+
+// describeAction returns the description of the action to vote on. Returns a
+// user-visible message if the action can't be carried out.
+func (e *StartVoteExecutor) describeAction(voteAction *model.VoteAction) (string, string) {
+	action := e.featureRegistry.GetActionByName(voteAction.Name)
+	if action == nil {
+		return "", fmt.Sprintf(MsgInvalidAction, "unknown action "+voteAction.Name)
+	}
+	description, err := action.Describe(voteAction.Args)
+	if err != nil {
+		return "", fmt.Sprintf(MsgInvalidAction, err)
+	}
+	return description, ""
+}
+
+// checkActionPolicy returns a user-visible refusal if the author's command
+// policies don't let them run the action's command.
+func (e *StartVoteExecutor) checkActionPolicy(s api.DiscordSession, command *model.Command, voteAction *model.VoteAction) string {
+	name := "?" + voteAction.Name
+	policy := e.config.CommandPolicy(name)
+	if policy == nil {
+		return ""
+	}
+	if _, reason := permissions.Check(s, policy, command); len(reason) > 0 {
+		return fmt.Sprintf(permissions.MsgPermissionDenied, name, name)
+	}
+	return ""
+}
+
+// resolveOptions fills in the configured defaults for any options that weren't
+// given. Returns a user-visible message if the options are outside of the
+// configured limits.
+func (e *StartVoteExecutor) resolveOptions(options model.VoteOptions) (model.VoteOptions, string) {
+	duration, rejection := resolveDuration(e.config, options.Duration)
+	if len(rejection) > 0 {
+		return options, rejection
+	}
+	options.Duration = duration
+
+	if options.StartDelay > 0 {
+		options.Start = e.modelHelper.UTCClock.Now().Add(options.StartDelay)
+	}
+	if !options.Start.IsZero() {
+		untilStart := options.Start.Sub(e.modelHelper.UTCClock.Now())
+		if untilStart <= 0 || untilStart > e.config.VoteScheduleLimit() {
+			return options, fmt.Sprintf(MsgVoteScheduleLimits, e.config.VoteScheduleLimit().Hours())
+		}
+	}
+
+	// Votes on actions are carried out when they pass, so they can't be passed
+	// by a handful of voters.
+	minQuorum := 1
+	if options.Action != nil {
+		minQuorum = e.config.VoteActionQuorum()
+	}
+	if options.Quorum == 0 {
+		options.Quorum = e.config.VoteQuorum()
+		if options.Quorum < minQuorum {
+			options.Quorum = minQuorum
+		}
+	} else if options.Quorum > e.config.VoteQuorumLimit() {
+		return options, fmt.Sprintf(MsgVoteQuorumLimits, e.config.VoteQuorumLimit())
+	} else if options.Quorum < minQuorum {
+		return options, fmt.Sprintf(MsgVoteActionQuorum, minQuorum)
+	}
+
+	if options.Threshold.IsMajority() {
+		threshold, err := e.config.VoteThreshold()
+		if err != nil {
+			log.Info("Invalid default vote threshold, using a simple majority", err)
+		}
+		options.Threshold = threshold
+	}
+
+	if options.Secret && len(e.config.VoteBallotSecret) == 0 {
+		return options, MsgSecretVotesDisabled
+	}
+
+	if len(options.Weighting) > 0 && e.weightings.GetByName(options.Weighting) == nil {
+		return options, fmt.Sprintf(MsgUnknownWeighting, strings.Join(e.weightings.Names(), ", "))
+	}
+	return options, ""
+}
+
+// resolveDuration returns the configured default duration if none was given.
+// Returns a user-visible message if the duration is outside of the configured
+// limits.
+func resolveDuration(config *config.Config, duration time.Duration) (time.Duration, string) {
+	minDuration := config.VoteMinDuration()
+	maxDuration := config.VoteMaxDuration()
+	if duration == 0 {
+		return config.VoteDefaultDuration(), ""
+	}
+	if duration < minDuration || duration > maxDuration {
+		return duration, fmt.Sprintf(MsgVoteDurationLimits, minDuration.Minutes(), maxDuration.Minutes())
+	}
+	return duration, ""
 }
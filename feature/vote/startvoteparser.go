@@ -4,25 +4,59 @@ import (
 	"errors"
 	"log"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/jakevoytko/crbot/feature"
 	"github.com/jakevoytko/crbot/model"
 	"github.com/jakevoytko/crbot/util"
 )
 
 const (
 	// MsgHelpVote is the help text for ?vote
-	MsgHelpVote = "Type `?vote <message>` to call a yes/no vote on the given message. 5 votes must be cast before the vote can pass. The first character of the message must be alphanumeric.\n\nExample: `?vote are pirates better than ninjas?`"
+	MsgHelpVote = "Type `?vote [duration] [--quorum N] [--threshold 2/3] [--weight roles|karma] [--locked] [--secret] <message>` to call a yes/no vote on the given message. Durations look like `10m` or `1h`. Without options, the server's default duration and quorum are used, and a simple majority wins. Ballots can be changed by voting again, or retracted with `?unvote`, unless the vote is `--locked`. Ballots in a `--weight roles` or `--weight karma` vote count as much as the server gives the voter's roles or karma. Ballots in a `--secret` vote are cast by direct message with `?ballot`, and only the totals are shown. The first character of the message must be alphanumeric. Type `?vote at <time> [options] <message>` to schedule a vote, where the time is a delay like `2h` or a UTC time like `2026-10-20T15:00`. Type `?vote show <id>` to see a past vote from `?votehistory`, or `?vote export [id|all] [csv|json]` to export votes in this channel. Type `?vote cancel` to cancel a vote you started; moderators can cancel any vote, or conclude it early with `?vote end`. Type `?vote [options] action <action> <args>` to call a vote that I carry out if it passes, like `?vote action unlearn <call>`.\n\nExample: `?vote are pirates better than ninjas?`"
+
+	// OptionQuorum sets the number of ballots needed for a vote to pass
+	OptionQuorum = "--quorum"
+	// OptionLocked prevents ballots from being changed or retracted
+	OptionLocked = "--locked"
+	// OptionSecret makes ballots secret, and cast by direct message
+	OptionSecret = "--secret"
+	// OptionThreshold sets the fraction of ballots that must be in favor
+	OptionThreshold = "--threshold"
+	// OptionWeight chooses how ballots are weighted
+	OptionWeight = "--weight"
+
+	// SubcommandAt schedules a vote to open later
+	SubcommandAt = "at"
+	// SubcommandCancel cancels the active vote
+	SubcommandCancel = "cancel"
+	// SubcommandEnd concludes the active vote early
+	SubcommandEnd = "end"
+	// SubcommandExport exports votes
+	SubcommandExport = "export"
+	// ExportAll exports every vote in the channel
+	ExportAll = "all"
+	// SubcommandShow shows a past vote
+	SubcommandShow = "show"
+
+	// KeywordAction introduces an action to carry out if the vote passes
+	KeywordAction = "action"
 )
 
 // StartVoteParser parses ?vote commands.
 type StartVoteParser struct {
+	featureRegistry *feature.Registry
 }
 
 // NewStartVoteParser works as advertised.
-func NewStartVoteParser() *StartVoteParser {
-	return &StartVoteParser{}
+func NewStartVoteParser(featureRegistry *feature.Registry) *StartVoteParser {
+	return &StartVoteParser{
+		featureRegistry: featureRegistry,
+	}
 }
 
 // GetName returns the named type of this feature.
@@ -43,23 +77,204 @@ func (p *StartVoteParser) Parse(splitContent []string, m *discordgo.MessageCreat
 	// The command is everything at/after the first word.
 	splitContent = util.CollapseWhitespace(splitContent, 1)
 
+	// ?vote cancel and ?vote end take an optional vote ID.
+	if len(splitContent) > 1 {
+		if voteID, ok := voteTarget(splitContent[1:]); ok {
+			target := &model.VoteTargetData{VoteID: voteID}
+			switch splitContent[1] {
+			case SubcommandCancel:
+				return &model.Command{Type: model.CommandTypeVoteCancel, VoteTarget: target}, nil
+			case SubcommandEnd:
+				return &model.Command{Type: model.CommandTypeVoteEnd, VoteTarget: target}, nil
+			}
+		}
+	}
+
+	// ?vote export [id|all] [csv|json] exports votes. Anything else is a vote
+	// about exporting something.
+	if len(splitContent) > 1 && splitContent[1] == SubcommandExport {
+		if export, ok := parseExport(splitContent[2:]); ok {
+			return &model.Command{Type: model.CommandTypeVoteExport, VoteExport: export}, nil
+		}
+	}
+
+	// ?vote show <id> looks up a past vote. Anything else is a vote about
+	// showing something.
+	if len(splitContent) > 1 && splitContent[1] == SubcommandShow {
+		// Collapsing reuses the backing array, so work on a copy.
+		showContent := util.CollapseWhitespace(append([]string{}, splitContent...), 2)
+		if len(showContent) == 3 {
+			if voteID, err := strconv.Atoi(showContent[2]); err == nil && voteID > 0 {
+				return &model.Command{
+					Type: model.CommandTypeVoteShow,
+					VoteShow: &model.VoteShowData{
+						VoteID: voteID,
+					},
+				}, nil
+			}
+		}
+	}
+
 	voteRegexp := regexp.MustCompile("^[[:alnum:]].*$")
 
-	// Show help when not enough data is present, or malicious data is present.
-	if len(splitContent) < 2 || !voteRegexp.MatchString(splitContent[1]) {
+	help := &model.Command{
+		Type: model.CommandTypeHelp,
+		Help: &model.HelpData{
+			Command: model.CommandNameVote,
+		},
+	}
+
+	// ?vote at <time> schedules the vote. Anything else is a vote about
+	// something happening at some time.
+	options := model.VoteOptions{}
+	index := 1
+	if len(splitContent) > 1 && splitContent[1] == SubcommandAt {
+		scheduleContent := util.CollapseWhitespace(append([]string{}, splitContent...), 2)
+		if len(scheduleContent) > 2 {
+			if start, delay, ok := parseVoteStart(scheduleContent[2]); ok {
+				options.Start = start
+				options.StartDelay = delay
+				index = 3
+				splitContent = util.CollapseWhitespace(scheduleContent, index)
+			}
+		}
+	}
+
+	// Options come before the message.
+	if index < len(splitContent) {
+		if duration, ok := parseDuration(splitContent[index]); ok {
+			if duration <= 0 {
+				return help, nil
+			}
+			options.Duration = duration
+			index++
+			splitContent = util.CollapseWhitespace(splitContent, index)
+		}
+	}
+	for index+1 < len(splitContent) && strings.HasPrefix(splitContent[index], "--") {
+		if splitContent[index] == OptionLocked || splitContent[index] == OptionSecret {
+			flag := &options.LockBallots
+			if splitContent[index] == OptionSecret {
+				flag = &options.Secret
+			}
+			if *flag {
+				return help, nil
+			}
+			*flag = true
+			index++
+			splitContent = util.CollapseWhitespace(splitContent, index)
+			continue
+		}
+
+		splitContent = util.CollapseWhitespace(splitContent, index+1)
+		if index+1 >= len(splitContent) {
+			return help, nil
+		}
+		value := splitContent[index+1]
+		switch splitContent[index] {
+		case OptionQuorum:
+			quorum, err := strconv.Atoi(value)
+			if err != nil || quorum <= 0 || options.Quorum != 0 {
+				return help, nil
+			}
+			options.Quorum = quorum
+		case OptionThreshold:
+			threshold, err := model.ParseVoteThreshold(value)
+			if err != nil || !options.Threshold.IsMajority() {
+				return help, nil
+			}
+			options.Threshold = threshold
+		case OptionWeight:
+			if len(options.Weighting) > 0 {
+				return help, nil
+			}
+			options.Weighting = value
+		default:
+			return help, nil
+		}
+		index += 2
+		splitContent = util.CollapseWhitespace(splitContent, index)
+	}
+
+	// `action <name> <args>` calls a vote on a registered action. The start
+	// executor describes the action, and uses that as the message. Anything
+	// else is a vote about an action.
+	if len(splitContent) > index+1 && splitContent[index] == KeywordAction && p.featureRegistry.GetActionByName(splitContent[index+1]) != nil {
+		splitContent = util.CollapseWhitespace(splitContent, index+2)
+		options.Action = &model.VoteAction{
+			Name: splitContent[index+1],
+			Args: strings.Join(splitContent[index+2:], " "),
+		}
 		return &model.Command{
-			Type: model.CommandTypeHelp,
-			Help: &model.HelpData{
-				Command: model.CommandNameVote,
+			Type: model.CommandTypeVote,
+			Vote: &model.VoteData{
+				Options: options,
 			},
 		}, nil
 	}
 
-	message := strings.Join(splitContent[1:], " ")
+	// Show help when not enough data is present, or malicious data is present.
+	if len(splitContent) <= index || !voteRegexp.MatchString(splitContent[index]) {
+		return help, nil
+	}
+
+	message := strings.Join(splitContent[index:], " ")
 	return &model.Command{
 		Type: model.CommandTypeVote,
 		Vote: &model.VoteData{
 			Message: message,
+			Options: options,
 		},
 	}, nil
 }
+
+// parseDuration parses a duration like `10m`. Durations need a unit, so that
+// messages that start with a bare number like `0` aren't mistaken for one.
+func parseDuration(token string) (time.Duration, bool) {
+	if len(token) == 0 || !unicode.IsLetter(rune(token[len(token)-1])) {
+		return 0, false
+	}
+	duration, err := time.ParseDuration(token)
+	return duration, err == nil
+}
+
+// parseVoteStart parses when a scheduled vote opens: either a delay like `2h`,
+// or a time like `2026-10-20T15:00Z`. Times without a zone are in UTC.
+func parseVoteStart(token string) (time.Time, time.Duration, bool) {
+	if delay, ok := parseDuration(token); ok {
+		return time.Time{}, delay, delay > 0
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04Z07:00", "2006-01-02T15:04"} {
+		if start, err := time.Parse(layout, token); err == nil {
+			return start, 0, true
+		}
+	}
+	return time.Time{}, 0, false
+}
+
+// parseExport parses the optional vote ID and format of an export. Returns
+// false if there is anything else.
+func parseExport(args []string) (*model.VoteExportData, bool) {
+	export := &model.VoteExportData{Format: ExportFormatCSV}
+	seenTarget, seenFormat := false, false
+	for _, arg := range args {
+		switch {
+		case len(arg) == 0:
+		case !seenFormat && (arg == ExportFormatCSV || arg == ExportFormatJSON):
+			export.Format = arg
+			seenFormat = true
+		case !seenTarget && arg == ExportAll:
+			seenTarget = true
+		case !seenTarget:
+			voteID, ok := parseVoteID(arg)
+			if !ok {
+				return nil, false
+			}
+			export.VoteID = voteID
+			seenTarget = true
+		default:
+			return nil, false
+		}
+	}
+	return export, true
+}
@@ -1,7 +1,6 @@
 package vote
 
 import (
-	"errors"
 	"fmt"
 	"strings"
 
@@ -32,9 +31,20 @@ func (e *StatusExecutor) PublicOnly() bool {
 	return true
 }
 
+// ModeratorOnly returns whether the executor can only be used by moderators.
+func (e *StatusExecutor) ModeratorOnly() bool {
+	return false
+}
+
 const (
 	// MsgNoActiveVote prints that there was no active vote
 	MsgNoActiveVote = "No active vote"
+	// MsgAmbiguousVote prints that the command must say which active vote it means
+	MsgAmbiguousVote = "Several votes are active. Add the ID of the vote you mean. Type `?votestatus` to list them"
+	// MsgActiveVotes is the header of the list of active votes
+	MsgActiveVotes = "Active votes:"
+	// MsgActiveVotesFooter explains how to address one of the active votes
+	MsgActiveVotesFooter = "Type `?votestatus <id>` for the status of a vote, and add its ID to `?yes`, `?no`, and `?unvote`"
 	// MsgOneVoteAgainst is the unpluralized message for vote against
 	MsgOneVoteAgainst = "1 vote against"
 	// MsgOneVoteFor is the unpluralized message for vote for
@@ -43,6 +53,8 @@ const (
 	MsgSpacer = "-----"
 	// MsgStatusInconclusive prints that a vote was inconclusive
 	MsgStatusInconclusive = "Not enough votes were cast."
+	// MsgStatusVoteCancelled prints that a vote was cancelled
+	MsgStatusVoteCancelled = "Vote Cancelled."
 	// MsgStatusVoteFailed prints that a vote has failed
 	MsgStatusVoteFailed = "Vote Failed."
 	// MsgStatusVoteFailing prints that a vote is currently failing
@@ -52,7 +64,7 @@ const (
 	// MsgStatusVotePassing prints whether the vote is passing
 	MsgStatusVotePassing = "Vote is passing"
 	// MsgStatusVotesNeeded prints how many votes are needed
-	MsgStatusVotesNeeded = "5 votes must be cast before vote can pass"
+	MsgStatusVotesNeeded = "%d votes must be cast before vote can pass"
 	// MsgVoteOwner prints who started the vote
 	MsgVoteOwner = "Vote started by %s: "
 	// MsgVotesAgainst prints the votes against
@@ -63,24 +75,19 @@ const (
 
 // Execute prints the status of the current vote.
 func (e *StatusExecutor) Execute(s api.DiscordSession, channel model.Snowflake, command *model.Command) {
-	ok, err := e.modelHelper.IsVoteActive(channel)
-	if err != nil {
-		log.Fatal("Error reading vote status", err)
-	}
-	if !ok {
+	vote, err := e.modelHelper.ActiveVote(channel, targetVoteID(command))
+	switch err {
+	case nil:
+	case ErrorNoVoteActive:
 		if _, err := s.ChannelMessageSend(channel.Format(), MsgNoActiveVote); err != nil {
 			log.Fatal("Unable to send no-active-vote message to user", err)
 		}
 		return
-	}
-
-	vote, err := e.modelHelper.MostRecentVote(channel)
-	if err != nil {
-		log.Fatal("Error pulling most recent vote", err)
-	}
-	if vote == nil {
-		log.Fatal("Nil vote found after vote already active", errors.New("vote should not be null"))
+	case ErrorAmbiguousVote:
+		e.listActiveVotes(s, channel)
 		return
+	default:
+		log.Fatal("Error reading vote status", err)
 	}
 
 	// The below creates a string like this:
@@ -97,16 +104,45 @@ func (e *StatusExecutor) Execute(s api.DiscordSession, channel model.Snowflake,
 	if err != nil {
 		log.Fatal("Error fetching the owner when rendering a vote response", err)
 	}
-	// Status line and message.
-	messages = append(messages, fmt.Sprintf(MsgVoteOwner, owner.Username)+vote.Message)
+	if vote.IsPoll() {
+		messages = append(messages, fmt.Sprintf(MsgPollOwner, owner.Username)+vote.Message)
+		messages = append(messages, MsgSpacer)
+		messages = append(messages, PollStatusLines(e.modelHelper.UTCClock, vote)...)
+	} else {
+		// Status line and message.
+		messages = append(messages, fmt.Sprintf(MsgVoteOwner, owner.Username)+vote.Message)
 
-	// Spacer
-	messages = append(messages, MsgSpacer)
+		// Spacer
+		messages = append(messages, MsgSpacer)
 
-	messages = append(messages, StatusLine(e.modelHelper.UTCClock, vote))
+		messages = append(messages, StatusLine(e.modelHelper.UTCClock, vote))
+	}
 
 	finalMessage := strings.Join(messages, "\n")
 	if _, err := s.ChannelMessageSend(channel.Format(), finalMessage); err != nil {
 		log.Info("Failed to send vote message", err)
 	}
 }
+
+// listActiveVotes prints a summary of each active vote in the channel.
+func (e *StatusExecutor) listActiveVotes(s api.DiscordSession, channel model.Snowflake) {
+	votes, err := e.modelHelper.OpenVotes(channel)
+	if err != nil {
+		log.Fatal("Error reading active votes", err)
+	}
+
+	messages := []string{MsgActiveVotes}
+	for _, vote := range votes {
+		owner, err := s.User(vote.UserID.Format())
+		if err != nil {
+			log.Info("Error fetching the owner when listing active votes", err)
+			return
+		}
+		messages = append(messages, fmt.Sprintf(MsgVoteHistoryEntry, vote.VoteID, vote.Message, owner.Username, SummaryLine(e.modelHelper.UTCClock, vote)))
+	}
+	messages = append(messages, MsgActiveVotesFooter)
+
+	if _, err := s.ChannelMessageSend(channel.Format(), strings.Join(messages, "\n")); err != nil {
+		log.Info("Failed to send active votes message", err)
+	}
+}
@@ -24,7 +24,7 @@ func (p *StatusParser) GetName() string {
 
 const (
 	// MsgHelpStatus is the help text for ?votestatus
-	MsgHelpStatus = "Prints the status of the current vote, or a message indicating that no vote is active"
+	MsgHelpStatus = "Prints the status of the current vote, or a message indicating that no vote is active. When several votes are active, lists them; type `?votestatus 12` for the status of one of them"
 )
 
 // HelpText returns the help text.
@@ -37,7 +37,12 @@ func (p *StatusParser) Parse(splitContent []string, m *discordgo.MessageCreate)
 	if splitContent[0] != p.GetName() {
 		log.Fatal("parseVoteStatus called with non-list command", errors.New("wat"))
 	}
+	// Anything other than a vote ID is ignored.
+	voteID, _ := voteTarget(splitContent)
 	return &model.Command{
 		Type: model.CommandTypeVoteStatus,
+		VoteTarget: &model.VoteTargetData{
+			VoteID: voteID,
+		},
 	}, nil
 }
@@ -9,24 +9,18 @@ import (
 	"github.com/jakevoytko/crbot/model"
 )
 
-// StatusLine returns the full status line of an in-progress vote.
+// StatusLine returns the full status line of an in-progress vote, or when a
+// scheduled vote opens.
 func StatusLine(clock model.UTCClock, vote *model.Vote) string {
-	// Add the vote totals.
-	statusStr := statusString(vote)
-	votesFor := len(vote.VotesFor)
-	votesAgainst := len(vote.VotesAgainst)
-	votesForStr := MsgOneVoteFor
-	if votesFor != 1 {
-		votesForStr = fmt.Sprintf(MsgVotesFor, votesFor)
-	}
-	votesAgainstStr := MsgOneVoteAgainst
-	if votesAgainst != 1 {
-		votesAgainstStr = fmt.Sprintf(MsgVotesAgainst, votesAgainst)
+	if clock.Now().Before(vote.TimestampStart) {
+		return fmt.Sprintf(MsgStatusScheduled, StartString(vote.TimestampStart))
 	}
 
+	// Add the vote totals.
+	statusStr := statusString(vote)
 	timeString := TimeString(clock, vote.TimestampEnd)
 
-	return statusStr + ". " + votesForStr + ", " + votesAgainstStr + ". " + timeString
+	return statusStr + ". " + tallyString(vote) + ". " + timeString
 }
 
 // CompletedStatusLine generates the full status line of a concluded vote.
@@ -41,11 +35,17 @@ func CompletedStatusLine(vote *model.Vote) string {
 		statusStr = MsgStatusVoteFailed
 	case model.VoteOutcomeNotEnough:
 		statusStr = MsgStatusInconclusive
+	case model.VoteOutcomeCancelled:
+		statusStr = MsgStatusVoteCancelled
 	}
 
-	votesFor := len(vote.VotesFor)
-	votesAgainst := len(vote.VotesAgainst)
+	return statusStr + " " + tallyString(vote)
+}
 
+// tallyString returns the number of ballots on each side. Weighted votes also
+// get the weight on each side.
+func tallyString(vote *model.Vote) string {
+	votesFor, votesAgainst := vote.Tally()
 	votesForStr := MsgOneVoteFor
 	if votesFor != 1 {
 		votesForStr = fmt.Sprintf(MsgVotesFor, votesFor)
@@ -54,7 +54,30 @@ func CompletedStatusLine(vote *model.Vote) string {
 	if votesAgainst != 1 {
 		votesAgainstStr = fmt.Sprintf(MsgVotesAgainst, votesAgainst)
 	}
-	return statusStr + " " + votesForStr + ", " + votesAgainstStr
+	tally := votesForStr + ", " + votesAgainstStr
+	if vote.IsWeighted() {
+		weightFor, weightAgainst := vote.WeightedTally()
+		tally += " " + fmt.Sprintf(MsgWeightedTally, weightFor, weightAgainst)
+	}
+	return tally
+}
+
+// ActionLine describes the vote's action, and whether it was carried out.
+func ActionLine(vote *model.Vote) string {
+	action := vote.Action.Name
+	if len(vote.Action.Args) > 0 {
+		action += " " + vote.Action.Args
+	}
+	switch {
+	case vote.Action.Executed() && len(vote.Action.Error) > 0:
+		return fmt.Sprintf(MsgActionStatusFailed, action, vote.Action.Error)
+	case vote.Action.Executed():
+		return fmt.Sprintf(MsgActionStatusExecuted, action, vote.Action.Result)
+	case vote.VoteOutcome == model.VoteOutcomeNotDone:
+		return fmt.Sprintf(MsgActionStatusPending, action)
+	default:
+		return fmt.Sprintf(MsgActionStatusNotExecuted, action)
+	}
 }
 
 func statusString(vote *model.Vote) string {
@@ -67,7 +90,7 @@ func statusString(vote *model.Vote) string {
 			return MsgStatusVoteFailing
 		}
 	}
-	return MsgStatusVotesNeeded
+	return fmt.Sprintf(MsgStatusVotesNeeded, vote.RequiredBallots())
 }
 
 const (
@@ -79,8 +102,28 @@ const (
 	MsgSecondsRemaining = "%v seconds remaining"
 	// MsgMillisecondsRemaining is a time output for a few milliseconds remaining
 	MsgMillisecondsRemaining = "%v milliseconds remaining"
+
+	// MsgStatusScheduled prints when a scheduled vote opens
+	MsgStatusScheduled = "Voting opens at %s"
+
+	// MsgWeightedTally prints the weight of the ballots on each side
+	MsgWeightedTally = "(weighted: %d for, %d against)"
+
+	// MsgActionStatusPending prints the action of an active vote
+	MsgActionStatusPending = "Action `%s` will be carried out if the vote passes."
+	// MsgActionStatusExecuted prints the result of a vote's action
+	MsgActionStatusExecuted = "Action `%s` was carried out: %s"
+	// MsgActionStatusFailed prints that a vote's action failed
+	MsgActionStatusFailed = "Action `%s` failed: %s"
+	// MsgActionStatusNotExecuted prints that a vote's action was not carried out
+	MsgActionStatusNotExecuted = "Action `%s` was not carried out."
 )
 
+// StartString generates a user-readable string for when a scheduled vote opens.
+func StartString(timestampStart time.Time) string {
+	return timestampStart.UTC().Format("2006-01-02 15:04 MST")
+}
+
 // TimeString generates a user-readable string for the duration calculated from input
 func TimeString(clock model.UTCClock, timestampEnd time.Time) string {
 	currentTime := clock.Now()
@@ -108,10 +151,11 @@ func TimeString(clock model.UTCClock, timestampEnd time.Time) string {
 	return timeString
 }
 
-// HandleVotesOnInitialLoad iterates through active vote pointers to see if any require timers to be
-// re-fired.
-func HandleVotesOnInitialLoad(s api.DiscordSession, modelHelper *ModelHelper, clock model.UTCClock, timer model.UTCTimer, commandChannel chan<- *model.Command) error {
-	votes, err := modelHelper.MostRecentVotes()
+// HandleVotesOnInitialLoad iterates through the votes without outcomes to re-fire their timers.
+// Yes/no votes also get their remaining reminders rescheduled, and scheduled
+// votes that haven't opened yet open when their start time arrives.
+func HandleVotesOnInitialLoad(s api.DiscordSession, modelHelper *ModelHelper, clock model.UTCClock, timer model.UTCTimer, commandChannel chan<- *model.Command, reminders []time.Duration) error {
+	votes, err := modelHelper.UnconcludedVotes()
 	if err != nil {
 		return err
 	}
@@ -122,12 +166,15 @@ func HandleVotesOnInitialLoad(s api.DiscordSession, modelHelper *ModelHelper, cl
 		// Start a timer so that this vote can conclude. This will conclude expired
 		// votes immediately (negative durations cause timers to fire).
 		if vote.VoteOutcome == model.VoteOutcomeNotDone {
+			if vote.PendingOpen {
+				scheduleOpen(clock, timer, commandChannel, vote)
+			}
 			timer.ExecuteAfter(vote.TimestampEnd.Sub(now), func() {
-				commandChannel <- &model.Command{
-					Type:      model.CommandTypeVoteConclude,
-					ChannelID: vote.ChannelID,
-				}
+				commandChannel <- NewConcludeCommand(vote)
 			})
+			if !vote.IsPoll() {
+				scheduleReminders(clock, timer, commandChannel, vote, reminders)
+			}
 		}
 	}
 
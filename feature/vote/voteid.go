@@ -0,0 +1,46 @@
+package vote
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/jakevoytko/crbot/model"
+)
+
+// parseVoteID parses a vote ID like `12` or `#12`. Returns false if the token
+// isn't a positive ID.
+func parseVoteID(token string) (int, bool) {
+	voteID, err := strconv.Atoi(strings.TrimPrefix(token, "#"))
+	if err != nil || voteID <= 0 {
+		return 0, false
+	}
+	return voteID, true
+}
+
+// voteTarget returns the ID of the vote named in splitContent[1], or 0 if no
+// vote is named. Returns false if there is anything other than a vote ID.
+func voteTarget(splitContent []string) (int, bool) {
+	args := []string{}
+	for _, token := range splitContent[1:] {
+		if len(token) > 0 {
+			args = append(args, token)
+		}
+	}
+	switch len(args) {
+	case 0:
+		return 0, true
+	case 1:
+		return parseVoteID(args[0])
+	default:
+		return 0, false
+	}
+}
+
+// targetVoteID returns the ID of the vote that the command applies to, or 0 for
+// the channel's only active vote.
+func targetVoteID(command *model.Command) int {
+	if command.VoteTarget == nil {
+		return 0
+	}
+	return command.VoteTarget.VoteID
+}
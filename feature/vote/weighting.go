@@ -0,0 +1,162 @@
+package vote
+
+import (
+	"sort"
+
+	"github.com/jakevoytko/crbot/api"
+	"github.com/jakevoytko/crbot/config"
+	"github.com/jakevoytko/crbot/feature/karma"
+	"github.com/jakevoytko/crbot/log"
+	"github.com/jakevoytko/crbot/model"
+)
+
+// BallotWeighting decides how much each ballot counts in a weighted vote.
+type BallotWeighting interface {
+	// GetName returns the name that votes choose the weighting by.
+	GetName() string
+	// Weight returns the weight of the user's ballot in the vote.
+	Weight(s api.DiscordSession, vote *model.Vote, userID model.Snowflake) (int, error)
+}
+
+// Weightings holds the ballot weightings that votes can choose from.
+type Weightings struct {
+	nameToWeighting map[string]BallotWeighting
+}
+
+// NewWeightings works as advertised.
+func NewWeightings(weightings ...BallotWeighting) *Weightings {
+	nameToWeighting := map[string]BallotWeighting{}
+	for _, weighting := range weightings {
+		nameToWeighting[weighting.GetName()] = weighting
+	}
+	return &Weightings{
+		nameToWeighting: nameToWeighting,
+	}
+}
+
+// GetByName returns the weighting with the given name, or nil.
+func (w *Weightings) GetByName(name string) BallotWeighting {
+	return w.nameToWeighting[name]
+}
+
+// Names returns the names of the weightings, sorted.
+func (w *Weightings) Names() []string {
+	names := []string{}
+	for name := range w.nameToWeighting {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BallotWeight returns the weight of the user's ballot in the vote. Ballots in
+// unweighted votes, and ballots whose weight can't be looked up, count once.
+func (w *Weightings) BallotWeight(s api.DiscordSession, vote *model.Vote, userID model.Snowflake) int {
+	if !vote.IsWeighted() {
+		return 1
+	}
+	weighting := w.GetByName(vote.Weighting)
+	if weighting == nil {
+		return 1
+	}
+	weight, err := weighting.Weight(s, vote, userID)
+	if err != nil {
+		log.Info("Error weighing ballot", err)
+		return 1
+	}
+	return weight
+}
+
+const (
+	// WeightingRoles weighs ballots by the voter's Discord roles
+	WeightingRoles = "roles"
+	// WeightingKarma weighs ballots by the voter's karma
+	WeightingKarma = "karma"
+)
+
+// RoleWeighting weighs ballots by the voter's Discord roles.
+type RoleWeighting struct {
+	config *config.Config
+}
+
+// NewRoleWeighting works as advertised.
+func NewRoleWeighting(config *config.Config) *RoleWeighting {
+	return &RoleWeighting{
+		config: config,
+	}
+}
+
+// GetName returns the name of the weighting.
+func (w *RoleWeighting) GetName() string {
+	return WeightingRoles
+}
+
+// Weight returns the weight of the voter's heaviest role in the vote's guild.
+func (w *RoleWeighting) Weight(s api.DiscordSession, vote *model.Vote, userID model.Snowflake) (int, error) {
+	discordChannel, err := s.Channel(vote.ChannelID.Format())
+	if err != nil {
+		return 0, err
+	}
+	member, err := s.GuildMember(discordChannel.GuildID, userID.Format())
+	if err != nil {
+		return 0, err
+	}
+	roleIDs := []model.Snowflake{}
+	for _, role := range member.Roles {
+		roleID, err := model.ParseSnowflake(role)
+		if err != nil {
+			return 0, err
+		}
+		roleIDs = append(roleIDs, roleID)
+	}
+	return w.config.RoleBallotWeight(roleIDs), nil
+}
+
+// KarmaWeighting weighs ballots by the voter's karma tier.
+type KarmaWeighting struct {
+	karmaHelper *karma.ModelHelper
+	config      *config.Config
+}
+
+// NewKarmaWeighting works as advertised.
+func NewKarmaWeighting(karmaHelper *karma.ModelHelper, config *config.Config) *KarmaWeighting {
+	return &KarmaWeighting{
+		karmaHelper: karmaHelper,
+		config:      config,
+	}
+}
+
+// GetName returns the name of the weighting.
+func (w *KarmaWeighting) GetName() string {
+	return WeightingKarma
+}
+
+// Weight returns the weight of the voter's karma tier. Karma is looked up by
+// username in the vote's guild, the same way that `?++ @user` records it.
+func (w *KarmaWeighting) Weight(s api.DiscordSession, vote *model.Vote, userID model.Snowflake) (int, error) {
+	discordChannel, err := s.Channel(vote.ChannelID.Format())
+	if err != nil {
+		return 0, err
+	}
+	user, err := s.User(userID.Format())
+	if err != nil {
+		return 0, err
+	}
+	karmaHelper := w.karmaHelper
+	if len(discordChannel.GuildID) > 0 {
+		guildID, err := model.ParseSnowflake(discordChannel.GuildID)
+		if err != nil {
+			return 0, err
+		}
+		karmaHelper = karmaHelper.ForGuild(guildID)
+	}
+	target, err := karmaHelper.Resolve(user.Username)
+	if err != nil {
+		return 0, err
+	}
+	userKarma, err := karmaHelper.Karma(target)
+	if err != nil {
+		return 0, err
+	}
+	return w.config.KarmaBallotWeight(userKarma), nil
+}
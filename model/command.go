@@ -1,6 +1,10 @@
 package model
 
-import "github.com/bwmarrin/discordgo"
+import (
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
 
 ///////////////////////////////////////////////////////////////////////////////
 // Constants
@@ -8,54 +12,168 @@ import "github.com/bwmarrin/discordgo"
 
 // Consts use throughout the application
 const (
-	CommandTypeCustom = iota
+	CommandTypeAudit = iota
+	CommandTypeCustom
 	CommandTypeFactSphere
+	CommandTypeFeatureList
+	CommandTypeFeatureToggle
 	CommandTypeHelp
 	CommandTypeKarma
+	CommandTypeKarmaAlias
+	CommandTypeKarmaInfo
 	CommandTypeKarmaList
+	CommandTypeKarmaMerge
+	CommandTypeKarmaUnmerge
 	CommandTypeLearn
 	CommandTypeList
+	CommandTypeMute
+	CommandTypeMuteExpire
+	CommandTypeMutes
 	CommandTypeNone
+	CommandTypePermissionDenied
+	CommandTypePermissions
+	CommandTypePoll
+	CommandTypePollBallot
+	CommandTypeReaction
 	CommandTypeRickList
+	CommandTypeRickListAdd
+	CommandTypeRickListExpire
 	CommandTypeRickListInfo
+	CommandTypeRickListRemove
+	CommandTypeRickListResponseAdd
+	CommandTypeRickListResponseRemove
+	CommandTypeRickListResponses
+	CommandTypeSecretBallot
+	CommandTypeShadowCustom
+	CommandTypeShadowLearn
+	CommandTypeSpamRickList
+	CommandTypeSpamWarning
 	CommandTypeUnlearn
 	CommandTypeUnrecognized
 	CommandTypeVote
 	CommandTypeVoteBallot
+	CommandTypeVoteCancel
 	CommandTypeVoteConclude
+	CommandTypeVoteEnd
+	CommandTypeVoteExport
+	CommandTypeVoteHistory
+	CommandTypeVoteOpen
+	CommandTypeVoteReminder
+	CommandTypeVoteRetract
+	CommandTypeVoteShow
 	CommandTypeVoteStatus
 
+	CommandNameAudit          = "?audit"
 	CommandNameFactSphere     = "?factsphere"
+	CommandNameFeature        = "?feature"
 	CommandNameHelp           = "?help"
 	CommandNameKarmaIncrement = "?++"
 	CommandNameKarmaDecrement = "?--"
+	CommandNameKarmaAlias     = "?karmaalias"
+	CommandNameKarmaInfo      = "?karma"
 	CommandNameKarmaList      = "?karmalist"
+	CommandNameKarmaMerge     = "?karmamerge"
+	CommandNameKarmaUnmerge   = "?karmaunmerge"
 	CommandNameLearn          = "?learn"
 	CommandNameList           = "?list"
+	CommandNameMute           = "?mute"
+	CommandNameMutes          = "?mutes"
+	CommandNamePermissions    = "?permissions"
+	CommandNamePoll           = "?poll"
+	CommandNamePollPick       = "?pick"
+	CommandNamePollRank       = "?rank"
 	CommandNameRickListInfo   = "?ricklist"
+	CommandNameSecretBallot   = "?ballot"
 	CommandNameUnlearn        = "?unlearn"
 	CommandNameVote           = "?vote"
 	CommandNameVoteAgainstF2  = "?f2"
 	CommandNameVoteAgainstNo  = "?no"
+	CommandNameVoteHistory    = "?votehistory"
 	CommandNameVoteInFavorF1  = "?f1"
 	CommandNameVoteInFavorYes = "?yes"
+	CommandNameVoteRetract    = "?unvote"
 	CommandNameVoteStatus     = "?votestatus"
 )
 
+// commandAliases maps the names that only alias another command to the name of
+// that command.
+var commandAliases = map[string]string{
+	CommandNameVoteInFavorF1: CommandNameVoteInFavorYes,
+	CommandNameVoteAgainstF2: CommandNameVoteAgainstNo,
+}
+
+// CanonicalCommandName returns the name of the command that the given name,
+// including the leading ?, aliases. Other names are returned as-is.
+func CanonicalCommandName(name string) string {
+	if canonical, ok := commandAliases[name]; ok {
+		return canonical
+	}
+	return name
+}
+
+// Names of the features, which channels enable and disable them by
+const (
+	FeatureNameFactSphere  = "factsphere"
+	FeatureNameHelp        = "help"
+	FeatureNameKarma       = "karma"
+	FeatureNameKarmaList   = "karmalist"
+	FeatureNameLearn       = "learn"
+	FeatureNameList        = "list"
+	FeatureNameModeration  = "moderation"
+	FeatureNamePermissions = "permissions"
+	FeatureNameToggle      = "toggle"
+	FeatureNameVote        = "vote"
+)
+
 ///////////////////////////////////////////////////////////////////////////////
 // User message parsing
 ///////////////////////////////////////////////////////////////////////////////
 
+// FeatureToggleData holds the feature to enable or disable, and where. A 0
+// channel means the channel that the command was sent in.
+type FeatureToggleData struct {
+	Name       string
+	Enabled    bool
+	ChannelID  Snowflake
+	Everywhere bool
+}
+
 // HelpData holds data for Help commands.
 type HelpData struct {
 	Command string
 }
 
 // KarmaData holds the target and whether karma is to be incremented or
-// decremented
+// decremented, along with the optional reason for the change
 type KarmaData struct {
 	Increment bool
 	Target    string
+	Reason    string
+}
+
+// KarmaInfoData holds the target whose karma is being looked up
+type KarmaInfoData struct {
+	Target string
+}
+
+// KarmaListData describes a karma leaderboard. A zero Window covers all time,
+// and a zero Limit lists every target.
+type KarmaListData struct {
+	Window time.Duration
+	Bottom bool
+	Limit  int
+}
+
+// KarmaMergeData holds the target whose karma is moved, and the target it is
+// moved into
+type KarmaMergeData struct {
+	From string
+	Into string
+}
+
+// KarmaUnmergeData holds the ID of the merge to undo
+type KarmaUnmergeData struct {
+	MergeID int
 }
 
 // LearnData is the learn-specific data
@@ -80,28 +198,206 @@ type CustomData struct {
 // VoteData contains the information about the proposed vote
 type VoteData struct {
 	Message string
+	// Options that were given explicitly. Zero values use the configured
+	// defaults.
+	Options VoteOptions
+}
+
+// PollData contains the information about a proposed poll
+type PollData struct {
+	Question    string
+	Options     []string
+	Ranked      bool
+	LockBallots bool
+	// 0 uses the configured default.
+	Duration time.Duration
+}
+
+// PollBallotData contains the options that the user picked, most preferred
+// first. Options are indexed from 0. A VoteID of 0 means the channel's only
+// active vote.
+type PollBallotData struct {
+	VoteID  int
+	Choices []int
+}
+
+// VoteConcludeData contains the ID of the vote to conclude. The vote may have
+// been cancelled or ended early by the time its timer fires.
+type VoteConcludeData struct {
+	VoteID int
+}
+
+// VoteOpenData contains the ID of the scheduled vote to open. The vote may
+// have been cancelled by the time its timer fires.
+type VoteOpenData struct {
+	VoteID int
+}
+
+// VoteReminderData contains the ID of the vote to remind the channel about.
+// The vote may have concluded by the time the reminder fires.
+type VoteReminderData struct {
+	VoteID int
+}
+
+// VoteExportData contains the ID of the vote to export, or 0 for every vote in
+// the channel, and the format of the export.
+type VoteExportData struct {
+	VoteID int
+	Format string
 }
 
-// BallotData represents whether the user is for or against the vote
+// VoteHistoryData contains how many past votes to list
+type VoteHistoryData struct {
+	Count int
+}
+
+// VoteShowData contains the ID of the vote to show
+type VoteShowData struct {
+	VoteID int
+}
+
+// SecretBallotData identifies the secret vote by its channel and ID, and
+// whether the user is for or against it
+type SecretBallotData struct {
+	ChannelID Snowflake
+	VoteID    int
+	InFavor   bool
+}
+
+// PermissionsData names the command whose permissions are checked or were
+// denied. Names include the leading ?.
+type PermissionsData struct {
+	Command string
+}
+
+// MuteData identifies the user or channel to mute, and for how long. Exactly
+// one of UserID and ChannelID is set.
+type MuteData struct {
+	UserID    Snowflake
+	ChannelID Snowflake
+	Duration  time.Duration
+	Reason    string
+}
+
+// MuteExpireData identifies the mute whose time ran out. The mute may have
+// been replaced by the time its timer fires.
+type MuteExpireData struct {
+	UserID           Snowflake
+	ChannelID        Snowflake
+	TimestampExpires time.Time
+}
+
+// RickListUpdateData identifies the user to add to or remove from the
+// ricklist. A 0 duration keeps the user on the ricklist until they are removed.
+type RickListUpdateData struct {
+	UserID    Snowflake
+	Duration  time.Duration
+	Shadowban bool
+}
+
+// RickListData identifies the ricklisted user whose command was intercepted.
+type RickListData struct {
+	UserID Snowflake
+}
+
+// RickListResponseData describes a change to the responses that ricklisted
+// users get. A 0 user means the responses that every ricklisted user gets.
+// Responses to remove are numbered from 1.
+type RickListResponseData struct {
+	UserID   Snowflake
+	Response *RickListResponse
+	Number   int
+}
+
+// RickListExpireData identifies the timed ricklist entry whose time ran out.
+// The entry may have been removed or replaced by the time its timer fires.
+type RickListExpireData struct {
+	UserID           Snowflake
+	TimestampExpires time.Time
+}
+
+// SpamData identifies the user who repeated a command too often, and the
+// command they repeated.
+type SpamData struct {
+	UserID  Snowflake
+	Command string
+}
+
+// ReactionData describes an emoji reaction that a user added to or removed
+// from a message
+type ReactionData struct {
+	MessageID Snowflake
+	Emoji     string
+	Added     bool
+}
+
+// AuditData filters the audit log by the user who acted or was acted on, or by
+// command. Both are empty to show the latest entries.
+type AuditData struct {
+	UserID  Snowflake
+	Command string
+}
+
+// BallotData represents whether the user is for or against the vote. A VoteID
+// of 0 means the channel's only active vote.
 type BallotData struct {
+	VoteID  int
 	InFavor bool
 }
 
+// VoteTargetData contains the ID of the active vote that a command applies
+// to. 0 means the channel's only active vote. ChannelID is only set when a
+// secret vote is referenced from a direct message.
+type VoteTargetData struct {
+	ChannelID Snowflake
+	VoteID    int
+}
+
 // Command is the generic command interface
 // TODO(jake): Make this an interface that has only getType(), cast in features.
 type Command struct {
 	// Metadata
 	Author       *discordgo.User
 	ChannelID    Snowflake
+	GuildID      Snowflake
 	Type         int
 	OriginalName string
+	// Content is the text of the message that the command was parsed from.
+	Content string
 
 	// Message data
-	Ballot  *BallotData
-	Custom  *CustomData
-	Help    *HelpData
-	Karma   *KarmaData
-	Learn   *LearnData
-	Unlearn *UnlearnData
-	Vote    *VoteData
+	Audit            *AuditData
+	Ballot           *BallotData
+	Custom           *CustomData
+	FeatureToggle    *FeatureToggleData
+	Help             *HelpData
+	Karma            *KarmaData
+	KarmaInfo        *KarmaInfoData
+	KarmaList        *KarmaListData
+	KarmaMerge       *KarmaMergeData
+	KarmaUnmerge     *KarmaUnmergeData
+	Learn            *LearnData
+	Mute             *MuteData
+	MuteExpire       *MuteExpireData
+	PermissionDenied *PermissionsData
+	Permissions      *PermissionsData
+	Poll             *PollData
+	PollBallot       *PollBallotData
+	Reaction         *ReactionData
+	RickList         *RickListData
+	RickListAdd      *RickListUpdateData
+	RickListExpire   *RickListExpireData
+	RickListRemove   *RickListUpdateData
+	RickListResponse *RickListResponseData
+	SecretBallot     *SecretBallotData
+	Spam             *SpamData
+	Unlearn          *UnlearnData
+	Vote             *VoteData
+	VoteConclude     *VoteConcludeData
+	VoteExport       *VoteExportData
+	VoteHistory      *VoteHistoryData
+	VoteOpen         *VoteOpenData
+	VoteReminder     *VoteReminderData
+	VoteShow         *VoteShowData
+	VoteTarget       *VoteTargetData
 }
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"math"
+	"time"
+)
+
+// KarmaEvent is a single recorded change to a target's karma.
+type KarmaEvent struct {
+	Timestamp time.Time
+	Delta     int
+	Reason    string
+}
+
+// KarmaHistory is the JSON-serialized and -deserialized list of every karma
+// change recorded for a single target.
+type KarmaHistory struct {
+	Target string
+	Events []KarmaEvent
+}
+
+// NewKarmaHistory works as advertised.
+func NewKarmaHistory(target string) *KarmaHistory {
+	return &KarmaHistory{
+		Target: target,
+		Events: []KarmaEvent{},
+	}
+}
+
+// Decayed returns the weight of the change at the given time, where the change
+// loses half of its weight every halfLife after it happened. A non-positive
+// halfLife disables decay.
+func (e *KarmaEvent) Decayed(now time.Time, halfLife time.Duration) float64 {
+	age := now.Sub(e.Timestamp)
+	if halfLife <= 0 || age <= 0 {
+		return float64(e.Delta)
+	}
+	return float64(e.Delta) * math.Pow(0.5, float64(age)/float64(halfLife))
+}
+
+// Decayed returns the sum of every decayed change in the history.
+func (h *KarmaHistory) Decayed(now time.Time, halfLife time.Duration) float64 {
+	total := 0.0
+	for i := range h.Events {
+		total += h.Events[i].Decayed(now, halfLife)
+	}
+	return total
+}
+
+// KarmaMerge is the JSON-serialized and -deserialized record of a single
+// karma merge. It stores everything needed to undo the merge.
+type KarmaMerge struct {
+	MergeID   int
+	From      string
+	Into      string
+	UserID    Snowflake
+	Timestamp time.Time
+	// The karma total and history moved out of From.
+	FromKarma  int
+	FromEvents []KarmaEvent
+	// Whether From was made an alias of Into, and what From was an alias of
+	// beforehand, if anything.
+	Alias         bool
+	PreviousAlias string
+	Undone        bool
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// MuteEntry is a user or a channel that crbot ignores commands from until the
+// mute expires. Exactly one of UserID and ChannelID is set.
+type MuteEntry struct {
+	UserID    Snowflake
+	ChannelID Snowflake
+	// The moderator who added the mute.
+	AddedBy          Snowflake
+	Reason           string
+	TimestampAdded   time.Time
+	TimestampExpires time.Time
+	// The channel that the mute was added in, where its expiry is announced.
+	AnnounceChannelID Snowflake
+}
+
+// IsExpired returns whether the mute's time ran out.
+func (e *MuteEntry) IsExpired(now time.Time) bool {
+	return !now.Before(e.TimestampExpires)
+}
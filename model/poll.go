@@ -0,0 +1,155 @@
+package model
+
+// PollBallot is a single user's ballot in a poll. Choices are indexes into
+// the poll's options, most preferred first. Ballots in plurality polls have a
+// single choice.
+type PollBallot struct {
+	UserID  Snowflake
+	Choices []int
+}
+
+// PollResult is the outcome of tallying a poll.
+type PollResult struct {
+	// The winning options. More than one means a tie, and none means that no
+	// ballots were cast.
+	Winners []int
+	// The number of ballots counted for each option, in each round. Plurality
+	// polls always have a single round. Eliminated options have no entry.
+	Rounds []map[int]int
+}
+
+// IsPoll returns whether the vote is a poll.
+func (v *Vote) IsPoll() bool {
+	return len(v.PollOptions) > 0
+}
+
+// PollBallot returns the index of the user's ballot in the poll, or -1 if the
+// user hasn't cast one.
+func (v *Vote) PollBallot(userID Snowflake) int {
+	for i, ballot := range v.PollBallots {
+		if ballot.UserID == userID {
+			return i
+		}
+	}
+	return -1
+}
+
+// FirstChoices returns the number of ballots that picked each option first.
+func (v *Vote) FirstChoices() []int {
+	counts := make([]int, len(v.PollOptions))
+	for _, ballot := range v.PollBallots {
+		if len(ballot.Choices) > 0 {
+			counts[ballot.Choices[0]]++
+		}
+	}
+	return counts
+}
+
+// TallyPoll tallies the poll by plurality, or by instant runoff if the poll is
+// ranked.
+func (v *Vote) TallyPoll() *PollResult {
+	if v.Ranked {
+		return v.tallyInstantRunoff()
+	}
+
+	round := map[int]int{}
+	for option, count := range v.FirstChoices() {
+		round[option] = count
+	}
+	return &PollResult{
+		Winners: mostVotes(round),
+		Rounds:  []map[int]int{round},
+	}
+}
+
+// tallyInstantRunoff counts each ballot towards its most preferred option that
+// is still in the running. Each round eliminates every option tied for the
+// fewest ballots, until an option has a majority of the ballots that are still
+// counted. If every remaining option is tied, they all win.
+func (v *Vote) tallyInstantRunoff() *PollResult {
+	remaining := map[int]bool{}
+	for option := range v.PollOptions {
+		remaining[option] = true
+	}
+
+	result := &PollResult{Winners: []int{}}
+	for len(remaining) > 0 {
+		round := map[int]int{}
+		for option := range remaining {
+			round[option] = 0
+		}
+		total := 0
+		for _, ballot := range v.PollBallots {
+			for _, choice := range ballot.Choices {
+				if remaining[choice] {
+					round[choice]++
+					total++
+					break
+				}
+			}
+		}
+		result.Rounds = append(result.Rounds, round)
+
+		if total == 0 {
+			return result
+		}
+		for option, count := range round {
+			if 2*count > total {
+				result.Winners = []int{option}
+				return result
+			}
+		}
+
+		fewest := total
+		for _, count := range round {
+			if count < fewest {
+				fewest = count
+			}
+		}
+		eliminated := []int{}
+		for option, count := range round {
+			if count == fewest {
+				eliminated = append(eliminated, option)
+			}
+		}
+		if len(eliminated) == len(remaining) {
+			result.Winners = mostVotes(round)
+			return result
+		}
+		for _, option := range eliminated {
+			delete(remaining, option)
+		}
+	}
+	return result
+}
+
+// mostVotes returns the options with the most ballots, in option order. Returns
+// nothing if no ballots were counted.
+func mostVotes(round map[int]int) []int {
+	most := 0
+	for _, count := range round {
+		if count > most {
+			most = count
+		}
+	}
+	winners := []int{}
+	if most == 0 {
+		return winners
+	}
+	for option := 0; option <= maxOption(round); option++ {
+		if count, ok := round[option]; ok && count == most {
+			winners = append(winners, option)
+		}
+	}
+	return winners
+}
+
+func maxOption(round map[int]int) int {
+	max := -1
+	for option := range round {
+		if option > max {
+			max = option
+		}
+	}
+	return max
+}
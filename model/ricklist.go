@@ -0,0 +1,57 @@
+package model
+
+import "time"
+
+// RickListEntry is a user on the ricklist. Entries without an expiry last
+// until they are removed.
+type RickListEntry struct {
+	UserID Snowflake
+	// The moderator who added the user, or 0 for users seeded from the config.
+	AddedBy        Snowflake
+	TimestampAdded time.Time
+	// Zero for entries that don't expire. Timed entries announce their expiry
+	// in the channel they were added in.
+	TimestampExpires time.Time
+	ChannelID        Snowflake
+	// Shadowbanned users aren't rickrolled. Their commands seem to work, but
+	// nothing that they ?learn is visible to anybody else.
+	Shadowban bool
+}
+
+// Expires returns whether the entry is timed.
+func (e *RickListEntry) Expires() bool {
+	return !e.TimestampExpires.IsZero()
+}
+
+// IsExpired returns whether the entry's time ran out.
+func (e *RickListEntry) IsExpired(now time.Time) bool {
+	return e.Expires() && !now.Before(e.TimestampExpires)
+}
+
+// Kinds of responses that ricklisted users get instead of their command's
+const (
+	// RickListResponseText is a message
+	RickListResponseText = "text"
+	// RickListResponseCommand is the response of a learned command
+	RickListResponseCommand = "command"
+	// RickListResponseDrop silently drops the command
+	RickListResponseDrop = "drop"
+)
+
+// RickListResponse is a response that ricklisted users may get instead of
+// their command's. Text is the message, or the call of the learned command.
+type RickListResponse struct {
+	Kind string
+	Text string
+}
+
+// Format returns the response the way moderators type it.
+func (r *RickListResponse) Format() string {
+	switch r.Kind {
+	case RickListResponseCommand:
+		return "?" + r.Text
+	case RickListResponseDrop:
+		return "drop"
+	}
+	return r.Text
+}
@@ -1,14 +1,26 @@
 package model
 
-import "time"
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // Vote outcomes used for storage.
 const (
 	// These are serialized and stored, so they cannot change.
-	VoteOutcomeNotDone   = 1
-	VoteOutcomePassed    = 2
-	VoteOutcomeFailed    = 3
-	VoteOutcomeNotEnough = 4
+	VoteOutcomeNotDone    = 1
+	VoteOutcomePassed     = 2
+	VoteOutcomeFailed     = 3
+	VoteOutcomeNotEnough  = 4
+	VoteOutcomePollWinner = 5
+	VoteOutcomePollTie    = 6
+	VoteOutcomeCancelled  = 7
 )
 
 // Vote is the JSON-serialized and -deserialized implementation of a single vote.
@@ -24,6 +36,124 @@ type Vote struct {
 	VotesFor       []Snowflake
 	VotesAgainst   []Snowflake
 	VoteOutcome    int
+	// Votes recorded before these were configurable decode them as 0, and use
+	// the original rules: 5 ballots, and a simple majority.
+	Quorum    int
+	Threshold VoteThreshold
+	// Polls have options instead of yes/no ballots. Yes/no votes have none.
+	PollOptions []string
+	Ranked      bool
+	PollBallots []PollBallot
+	// Whether ballots are final once cast.
+	LockBallots bool
+	// The announcement message, whose reactions are counted as ballots. 0 if
+	// the announcement failed, or predates reaction voting.
+	MessageID Snowflake
+	// Secret votes keep their ballots in SecretBallots instead of VotesFor and
+	// VotesAgainst. The salt is mixed into the hashes of the voter IDs, so the
+	// same voter hashes differently in each vote.
+	Secret        bool
+	Salt          string
+	SecretBallots []SecretBallot
+	// The operation to carry out if the vote passes, or nil.
+	Action *VoteAction
+	// Weighted votes name the strategy that weighs their ballots, and record the
+	// weight of each open ballot. Unweighted votes leave these empty, and count
+	// every ballot once.
+	Weighting     string
+	BallotWeights map[Snowflake]int
+	// Scheduled votes open after they are called, and are pending until their
+	// opening is announced.
+	PendingOpen bool
+}
+
+// VoteAction is an operation that is carried out automatically when a vote
+// passes. Once the action runs, the result is kept as an audit record.
+type VoteAction struct {
+	Name string
+	Args string
+	// Zero until the action runs.
+	TimestampExecuted time.Time
+	Result            string
+	Error             string
+}
+
+// Executed returns whether the action has run.
+func (a *VoteAction) Executed() bool {
+	return !a.TimestampExecuted.IsZero()
+}
+
+// SecretBallot is a ballot in a secret vote. Only a salted hash of the voter's
+// ID is kept, which is enough to reject double votes.
+type SecretBallot struct {
+	VoterHash string
+	InFavor   bool
+	// 0 in unweighted votes, where the ballot counts once.
+	Weight int
+}
+
+// DefaultVoteQuorum is the number of ballots needed by votes that don't
+// specify a quorum.
+const DefaultVoteQuorum = 5
+
+// VoteThreshold is the fraction of ballots that must be in favor for a vote to
+// pass. The zero value means a simple majority.
+type VoteThreshold struct {
+	Numerator   int
+	Denominator int
+}
+
+// ErrorInvalidThreshold indicates that a threshold isn't a fraction above 1/2,
+// and at most 1.
+var ErrorInvalidThreshold = errors.New("threshold must be a fraction above 1/2, and at most 1")
+
+// ParseVoteThreshold parses a fraction like 2/3. The fraction must be above 1/2,
+// since a tie would pass a 1/2 threshold, and at most 1.
+func ParseVoteThreshold(threshold string) (VoteThreshold, error) {
+	parts := strings.Split(threshold, "/")
+	if len(parts) != 2 {
+		return VoteThreshold{}, ErrorInvalidThreshold
+	}
+	numerator, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return VoteThreshold{}, ErrorInvalidThreshold
+	}
+	denominator, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return VoteThreshold{}, ErrorInvalidThreshold
+	}
+	if numerator <= 0 || denominator <= 0 || numerator > denominator || 2*numerator <= denominator {
+		return VoteThreshold{}, ErrorInvalidThreshold
+	}
+	return VoteThreshold{Numerator: numerator, Denominator: denominator}, nil
+}
+
+// IsMajority returns whether the threshold is a simple majority.
+func (t VoteThreshold) IsMajority() bool {
+	return t.Denominator == 0
+}
+
+// String returns the threshold as a fraction, like 2/3.
+func (t VoteThreshold) String() string {
+	return fmt.Sprintf("%d/%d", t.Numerator, t.Denominator)
+}
+
+// VoteOptions are the rules that a single vote is held under. Zero values use
+// the defaults.
+type VoteOptions struct {
+	Duration    time.Duration
+	Quorum      int
+	Threshold   VoteThreshold
+	LockBallots bool
+	Secret      bool
+	// The operation to carry out if the vote passes, or nil.
+	Action *VoteAction
+	// The name of the strategy that weighs ballots, or "" for one per voter.
+	Weighting string
+	// Scheduled votes open at Start, or StartDelay after they are called. Zero
+	// values open the vote right away.
+	Start      time.Time
+	StartDelay time.Duration
 }
 
 // NewVote works as advertised.
@@ -41,15 +171,121 @@ func NewVote(voteID int, channelID, userID Snowflake, message string, timestampS
 	}
 }
 
+// RequiredBallots returns the number of ballots that must be cast before the
+// vote can pass.
+func (v *Vote) RequiredBallots() int {
+	if v.Quorum <= 0 {
+		return DefaultVoteQuorum
+	}
+	return v.Quorum
+}
+
 // HasEnoughVotes returns whether there are enough votes to claim confidence.
+// The quorum counts voters, even in weighted votes.
 func (v *Vote) HasEnoughVotes() bool {
-	return len(v.VotesFor)+len(v.VotesAgainst) >= 5
+	votesFor, votesAgainst := v.Tally()
+	return votesFor+votesAgainst >= v.RequiredBallots()
+}
+
+// Tally returns the number of ballots on each side of the yes/no vote.
+func (v *Vote) Tally() (votesFor int, votesAgainst int) {
+	votesFor, votesAgainst = len(v.VotesFor), len(v.VotesAgainst)
+	for _, ballot := range v.SecretBallots {
+		if ballot.InFavor {
+			votesFor++
+		} else {
+			votesAgainst++
+		}
+	}
+	return votesFor, votesAgainst
+}
+
+// IsWeighted returns whether ballots in the vote count by their weight.
+func (v *Vote) IsWeighted() bool {
+	return len(v.Weighting) > 0
+}
+
+// BallotWeight returns how much the user's open ballot counts. Ballots without
+// a recorded weight count once.
+func (v *Vote) BallotWeight(userID Snowflake) int {
+	return countedWeight(v.BallotWeights[userID])
+}
+
+// WeightedTally returns the total weight of the ballots on each side of the
+// yes/no vote. In unweighted votes, this is the same as Tally.
+func (v *Vote) WeightedTally() (weightFor int, weightAgainst int) {
+	for _, id := range v.VotesFor {
+		weightFor += v.BallotWeight(id)
+	}
+	for _, id := range v.VotesAgainst {
+		weightAgainst += v.BallotWeight(id)
+	}
+	for _, ballot := range v.SecretBallots {
+		if ballot.InFavor {
+			weightFor += countedWeight(ballot.Weight)
+		} else {
+			weightAgainst += countedWeight(ballot.Weight)
+		}
+	}
+	return weightFor, weightAgainst
+}
+
+// countedWeight returns how much a ballot with the recorded weight counts.
+func countedWeight(weight int) int {
+	if weight <= 0 {
+		return 1
+	}
+	return weight
+}
+
+// VoterHash returns the hash that identifies the user's ballot in a secret
+// vote. It is keyed with the ballot secret, which is never stored with the
+// votes, so the hash can't be reversed by trying every user ID.
+func (v *Vote) VoterHash(ballotSecret string, userID Snowflake) string {
+	mac := hmac.New(sha256.New, []byte(ballotSecret))
+	mac.Write([]byte(v.Salt + ":" + userID.Format()))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Ballot returns whether the user has cast a ballot in the yes/no vote, and
+// which side the ballot is on. Ballots in secret votes are found with the
+// ballot secret.
+func (v *Vote) Ballot(ballotSecret string, userID Snowflake) (voted bool, inFavor bool) {
+	if v.Secret {
+		voterHash := v.VoterHash(ballotSecret, userID)
+		for _, ballot := range v.SecretBallots {
+			if ballot.VoterHash == voterHash {
+				return true, ballot.InFavor
+			}
+		}
+		return false, false
+	}
+	for _, id := range v.VotesFor {
+		if id == userID {
+			return true, true
+		}
+	}
+	for _, id := range v.VotesAgainst {
+		if id == userID {
+			return true, false
+		}
+	}
+	return false, false
 }
 
 // CalculateActiveStatus compares the vote totals and returns what the outcome
-// would be. This ignores the recorded outcome, and the number of votes.
+// would be. This ignores the recorded outcome, and the number of votes. A vote
+// with a threshold passes when at least that fraction of ballots is in favor.
+// Weighted votes compare the weight of the ballots instead of their number.
 func (v *Vote) CalculateActiveStatus() int {
-	if len(v.VotesFor) > len(v.VotesAgainst) {
+	votesFor, votesAgainst := v.WeightedTally()
+	if v.Threshold.IsMajority() {
+		if votesFor > votesAgainst {
+			return VoteOutcomePassed
+		}
+		return VoteOutcomeFailed
+	}
+	if votesFor > 0 && votesFor*v.Threshold.Denominator >= v.Threshold.Numerator*(votesFor+votesAgainst) {
 		return VoteOutcomePassed
 	}
 	return VoteOutcomeFailed
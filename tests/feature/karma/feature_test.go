@@ -2,10 +2,16 @@ package karma
 
 import (
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/bwmarrin/discordgo"
 	"github.com/jakevoytko/crbot/app"
+	"github.com/jakevoytko/crbot/config"
 	"github.com/jakevoytko/crbot/feature/karma"
+	"github.com/jakevoytko/crbot/feature/karmalist"
+	"github.com/jakevoytko/crbot/model"
 	"github.com/jakevoytko/crbot/testutil"
 )
 
@@ -49,3 +55,228 @@ func TestKarmaDecrement(t *testing.T) {
 	runner.SendMessage(testutil.MainChannelID, "?-- @target#1337", fmt.Sprintf(karma.MsgDecrementKarma, "target", "target", -3))
 
 }
+
+func TestKarma_CorruptValue(t *testing.T) {
+	runner := testutil.NewRunner(t)
+
+	if err := karma.NewGuildMap(runner.KarmaMap, testutil.MainGuildID).Set("target", "lots"); err != nil {
+		t.Fatalf("Error storing karma: %v", err)
+	}
+	runner.SendMessageAsWithResponses(&discordgo.User{ID: "1", Username: "username"}, testutil.MainChannelID, "?++ target")
+	runner.SendMessage(testutil.MainChannelID, "?++ other", fmt.Sprintf(karma.MsgIncrementKarma, "other", "other", 1))
+}
+
+func TestKarmaReason(t *testing.T) {
+	runner := testutil.NewRunner(t)
+
+	runner.SendMessage(testutil.MainChannelID, "?++ alice for fixing CI", fmt.Sprintf(karma.MsgIncrementKarmaReason, "alice", "fixing CI", "alice", 1))
+	runner.SendMessage(testutil.MainChannelID, "?++ alice  for   fixing CI", fmt.Sprintf(karma.MsgIncrementKarmaReason, "alice", "fixing CI", "alice", 2))
+	runner.SendMessage(testutil.MainChannelID, "?-- alice for breaking CI", fmt.Sprintf(karma.MsgDecrementKarmaReason, "alice", "breaking CI", "alice", 1))
+
+	// Anything that isn't a "for" clause is ignored, as before.
+	runner.SendMessage(testutil.MainChannelID, "?++ alice is great", fmt.Sprintf(karma.MsgIncrementKarma, "alice", "alice", 2))
+	runner.SendMessage(testutil.MainChannelID, "?++ alice for", fmt.Sprintf(karma.MsgIncrementKarma, "alice", "alice", 3))
+}
+
+func TestKarmaInfo(t *testing.T) {
+	runner := testutil.NewRunner(t)
+
+	// Wrong call format
+	runner.SendMessage(testutil.MainChannelID, "?karma", karma.MsgHelpKarmaInfo)
+	runner.SendMessage(testutil.MainChannelID, "?karma ?call", karma.MsgHelpKarmaInfo)
+
+	// Targets that never received karma have none.
+	runner.SendMessage(testutil.MainChannelID, "?karma alice", fmt.Sprintf(karma.MsgKarmaInfo, "alice", 0))
+
+	// Targets without reasons only show the score.
+	runner.SendMessageIgnoringResponse(testutil.MainChannelID, "?++ alice")
+	runner.SendMessage(testutil.MainChannelID, "?karma alice", fmt.Sprintf(karma.MsgKarmaInfo, "alice", 1))
+
+	// The most common reasons are listed first, ties alphabetically.
+	runner.SendMessageIgnoringResponse(testutil.MainChannelID, "?++ alice for fixing CI")
+	runner.SendMessageIgnoringResponse(testutil.MainChannelID, "?++ alice for fixing CI")
+	runner.SendMessageIgnoringResponse(testutil.MainChannelID, "?++ alice for reviews")
+	runner.SendMessageIgnoringResponse(testutil.MainChannelID, "?-- alice for puns")
+	runner.SendMessageIgnoringResponse(testutil.MainChannelID, "?-- alice for breaking CI")
+	runner.SendMessage(testutil.MainChannelID, "?karma @alice", fmt.Sprintf(karma.MsgKarmaInfo, "alice", 2)+"\n"+
+		karma.MsgKarmaReasons+
+		fmt.Sprintf(karma.MsgKarmaReason, "fixing CI", 2)+", "+
+		fmt.Sprintf(karma.MsgKarmaReason, "breaking CI", 1)+", "+
+		fmt.Sprintf(karma.MsgKarmaReason, "puns", 1))
+}
+
+func TestKarmaInfo_Decay(t *testing.T) {
+	config := config.NewConfig()
+	config.KarmaDecayHalfLifeDays = 1
+	runner := testutil.NewRunnerWithConfig(t, &config)
+
+	runner.SendMessageIgnoringResponse(testutil.MainChannelID, "?++ alice")
+	runner.SendMessage(testutil.MainChannelID, "?karma alice", fmt.Sprintf(karma.MsgKarmaInfoDecayed, "alice", 1, 1.0))
+
+	// Each day halves the weight of the karma given before it.
+	runner.UTCClock.Advance(time.Duration(24) * time.Hour)
+	runner.SendMessageIgnoringResponse(testutil.MainChannelID, "?++ alice")
+	runner.SendMessage(testutil.MainChannelID, "?karma alice", fmt.Sprintf(karma.MsgKarmaInfoDecayed, "alice", 2, 1.5))
+	runner.UTCClock.Advance(time.Duration(24) * time.Hour)
+	runner.SendMessage(testutil.MainChannelID, "?karma alice", fmt.Sprintf(karma.MsgKarmaInfoDecayed, "alice", 2, 0.75))
+
+	// Grudges fade too.
+	runner.SendMessageIgnoringResponse(testutil.MainChannelID, "?-- bob")
+	runner.UTCClock.Advance(time.Duration(48) * time.Hour)
+	runner.SendMessage(testutil.MainChannelID, "?karma bob", fmt.Sprintf(karma.MsgKarmaInfoDecayed, "bob", -1, -0.25))
+	runner.SendKarmaListMessage(testutil.MainChannelID)
+	runner.SendMessage(testutil.MainChannelID, "?karmalist top", strings.Join([]string{
+		fmt.Sprintf(karmalist.MsgLeaderboardTop, 2),
+		fmt.Sprintf(karmalist.MsgLeaderboardEntry, 1, "alice", 2) + fmt.Sprintf(karmalist.MsgDecayedSuffix, 0.1875),
+		fmt.Sprintf(karmalist.MsgLeaderboardEntry, 2, "bob", -1) + fmt.Sprintf(karmalist.MsgDecayedSuffix, -0.25),
+	}, "\n"))
+}
+
+func TestKarmaMerge_ModeratorOnly(t *testing.T) {
+	runner := testutil.NewRunner(t)
+
+	runner.SendMessageIgnoringResponse(testutil.MainChannelID, "?++ jake")
+	runner.SendMessage(testutil.MainChannelID, "?karmamerge jake jakev", fmt.Sprintf(app.MsgModeratorOnly, "?karmamerge"))
+	runner.SendMessage(testutil.MainChannelID, "?karmaalias jake jakev", fmt.Sprintf(app.MsgModeratorOnly, "?karmaalias"))
+	runner.SendMessage(testutil.MainChannelID, "?karmaunmerge 1", fmt.Sprintf(app.MsgModeratorOnly, "?karmaunmerge"))
+	runner.SendMessage(testutil.MainChannelID, "?karma jake", fmt.Sprintf(karma.MsgKarmaInfo, "jake", 1))
+}
+
+func TestKarmaMerge(t *testing.T) {
+	config := config.NewConfig()
+	config.Moderators = []model.Snowflake{1}
+	runner := testutil.NewRunnerWithConfig(t, &config)
+
+	runner.SendMessageIgnoringResponse(testutil.MainChannelID, "?++ jake")
+	runner.SendMessageIgnoringResponse(testutil.MainChannelID, "?++ jake")
+	runner.SendMessageIgnoringResponse(testutil.MainChannelID, "?++ jakev")
+
+	// Wrong call format
+	runner.SendMessage(testutil.MainChannelID, "?karmamerge jake", karma.MsgHelpKarmaMerge)
+	runner.SendMessage(testutil.MainChannelID, "?karmaunmerge one", karma.MsgHelpKarmaUnmerge)
+
+	runner.SendMessage(testutil.MainChannelID, "?karmamerge jake jake", fmt.Sprintf(karma.MsgKarmaMergeSame, "jake", "jake"))
+	runner.SendMessage(testutil.MainChannelID, "?karmamerge nobody jake", fmt.Sprintf(karma.MsgKarmaNothingToMerge, "nobody"))
+	runner.SendMessage(testutil.MainChannelID, "?karmamerge @jake#1234 jakev", fmt.Sprintf(karma.MsgKarmaMerged, "jake", "jakev", "jakev", 3, 1))
+	runner.SendMessage(testutil.MainChannelID, "?karma jake", fmt.Sprintf(karma.MsgKarmaInfo, "jake", 0))
+	runner.SendMessage(testutil.MainChannelID, "?karma jakev", fmt.Sprintf(karma.MsgKarmaInfo, "jakev", 3))
+
+	// A plain merge doesn't redirect future karma.
+	runner.SendMessage(testutil.MainChannelID, "?++ jake", fmt.Sprintf(karma.MsgIncrementKarma, "jake", "jake", 1))
+
+	runner.SendMessage(testutil.MainChannelID, "?karmaunmerge 1", fmt.Sprintf(karma.MsgKarmaUnmerged, 1, "jake", 3, "jakev", 1))
+	runner.SendMessage(testutil.MainChannelID, "?karmaunmerge 1", fmt.Sprintf(karma.MsgKarmaMergeUndone, 1))
+	runner.SendMessage(testutil.MainChannelID, "?karmaunmerge 2", fmt.Sprintf(karma.MsgKarmaNoSuchMerge, 2))
+}
+
+func TestKarmaUnmerge_ReverseOrder(t *testing.T) {
+	config := config.NewConfig()
+	config.Moderators = []model.Snowflake{1}
+	runner := testutil.NewRunnerWithConfig(t, &config)
+
+	runner.SendMessageIgnoringResponse(testutil.MainChannelID, "?++ a")
+	runner.SendMessageIgnoringResponse(testutil.MainChannelID, "?++ b")
+	runner.SendMessage(testutil.MainChannelID, "?karmamerge a b", fmt.Sprintf(karma.MsgKarmaMerged, "a", "b", "b", 2, 1))
+	runner.SendMessage(testutil.MainChannelID, "?karmamerge b c", fmt.Sprintf(karma.MsgKarmaMerged, "b", "c", "c", 2, 2))
+
+	// b's karma is in c now, so a -> b can't be undone until b -> c is.
+	runner.SendMessage(testutil.MainChannelID, "?karmaunmerge 1", fmt.Sprintf(karma.MsgKarmaMergeTargetMerged, "b", "c", 2, 2))
+	runner.SendMessage(testutil.MainChannelID, "?karma b", fmt.Sprintf(karma.MsgKarmaInfo, "b", 0))
+	runner.SendMessage(testutil.MainChannelID, "?karmaunmerge 2", fmt.Sprintf(karma.MsgKarmaUnmerged, 2, "b", 2, "c", 0))
+	runner.SendMessage(testutil.MainChannelID, "?karmaunmerge 1", fmt.Sprintf(karma.MsgKarmaUnmerged, 1, "a", 1, "b", 1))
+}
+
+func TestKarmaAlias(t *testing.T) {
+	config := config.NewConfig()
+	config.Moderators = []model.Snowflake{1}
+	runner := testutil.NewRunnerWithConfig(t, &config)
+
+	runner.SendMessageIgnoringResponse(testutil.MainChannelID, "?++ jake")
+	runner.SendMessageIgnoringResponse(testutil.MainChannelID, "?++ jakev")
+	runner.SendMessage(testutil.MainChannelID, "?karmaalias jake jakev", fmt.Sprintf(karma.MsgKarmaAliased, "jake", "jakev", "jakev", 2, 1))
+
+	// Future karma for the alias goes to the canonical target.
+	runner.SendMessage(testutil.MainChannelID, "?++ jake", fmt.Sprintf(karma.MsgIncrementKarma, "jakev", "jakev", 3))
+	runner.SendMessage(testutil.MainChannelID, "?karma jake", fmt.Sprintf(karma.MsgKarmaInfo, "jakev", 3))
+
+	// Undoing the alias returns the moved karma, and stops the redirect.
+	runner.SendMessage(testutil.MainChannelID, "?karmaunmerge 1", fmt.Sprintf(karma.MsgKarmaUnmerged, 1, "jake", 1, "jakev", 2))
+	runner.SendMessage(testutil.MainChannelID, "?++ jake", fmt.Sprintf(karma.MsgIncrementKarma, "jake", "jake", 2))
+}
+
+func TestKarma_GuildScoped(t *testing.T) {
+	runner := testutil.NewRunner(t)
+
+	runner.SendMessage(testutil.MainChannelID, "?++ target", fmt.Sprintf(karma.MsgIncrementKarma, "target", "target", 1))
+	runner.SendMessage(testutil.SecondChannelID, "?++ target", fmt.Sprintf(karma.MsgIncrementKarma, "target", "target", 2))
+	runner.SendMessage(testutil.OtherGuildChannelID, "?-- target", fmt.Sprintf(karma.MsgDecrementKarma, "target", "target", -1))
+
+	runner.SendMessage(testutil.MainChannelID, "?karma target", fmt.Sprintf(karma.MsgKarmaInfo, "target", 2))
+	runner.SendMessage(testutil.OtherGuildChannelID, "?karma target", fmt.Sprintf(karma.MsgKarmaInfo, "target", -1))
+	runner.SendMessage(testutil.OtherGuildChannelID, "?karmalist top", strings.Join([]string{
+		fmt.Sprintf(karmalist.MsgLeaderboardTop, 1),
+		fmt.Sprintf(karmalist.MsgLeaderboardEntry, 1, "target", -1),
+	}, "\n"))
+	runner.SendKarmaListMessage(testutil.MainChannelID)
+
+	// Direct messages have no guild, and can't see any guild's karma.
+	runner.SendMessage(testutil.DirectMessageID, "?karma target", fmt.Sprintf(karma.MsgKarmaInfo, "target", 0))
+}
+
+func TestKarma_MigrateToGuild(t *testing.T) {
+	config := config.NewConfig()
+	config.DefaultGuildID = testutil.OtherGuildID
+	runner := testutil.NewRunnerWithConfig(t, &config)
+
+	// Karma stored before karma was scoped by guild.
+	legacyModelHelper := karma.NewModelHelper(runner.KarmaMap, runner.KarmaHistoryMap, runner.KarmaMergeMap, runner.UTCClock)
+	legacyModelHelper.Increment("target", "being early")
+	legacyModelHelper.Increment("target", "being early")
+	legacyModelHelper.Increment("10:30", "")
+	runner.SendMessage(testutil.OtherGuildChannelID, "?karma target", fmt.Sprintf(karma.MsgKarmaInfo, "target", 0))
+
+	for _, fn := range runner.FeatureRegistry.GetInitialLoadFns() {
+		if err := fn(runner.DiscordSession); err != nil {
+			t.Fatalf("Initial load failed: %v", err)
+		}
+	}
+
+	runner.SendMessage(testutil.OtherGuildChannelID, "?karma target", fmt.Sprintf(karma.MsgKarmaInfo, "target", 2)+"\n"+
+		karma.MsgKarmaReasons+fmt.Sprintf(karma.MsgKarmaReason, "being early", 2))
+	runner.SendMessage(testutil.MainChannelID, "?karma target", fmt.Sprintf(karma.MsgKarmaInfo, "target", 0))
+	// Legacy targets that look scoped are migrated too.
+	runner.SendMessage(testutil.OtherGuildChannelID, "?karma 10:30", fmt.Sprintf(karma.MsgKarmaInfo, "10:30", 1))
+
+	// Running the migration again changes nothing, even after other guilds have
+	// karma.
+	runner.SendMessage(testutil.MainChannelID, "?++ target", fmt.Sprintf(karma.MsgIncrementKarma, "target", "target", 1))
+	for _, fn := range runner.FeatureRegistry.GetInitialLoadFns() {
+		if err := fn(runner.DiscordSession); err != nil {
+			t.Fatalf("Initial load failed: %v", err)
+		}
+	}
+	runner.SendMessage(testutil.OtherGuildChannelID, "?++ target", fmt.Sprintf(karma.MsgIncrementKarma, "target", "target", 3))
+	runner.SendMessage(testutil.MainChannelID, "?++ target", fmt.Sprintf(karma.MsgIncrementKarma, "target", "target", 2))
+}
+
+func TestKarma_MigrateToGuildAfterUnconfiguredRun(t *testing.T) {
+	config := config.NewConfig()
+	runner := testutil.NewRunnerWithConfig(t, &config)
+
+	for _, fn := range runner.FeatureRegistry.GetInitialLoadFns() {
+		if err := fn(runner.DiscordSession); err != nil {
+			t.Fatalf("Initial load failed: %v", err)
+		}
+	}
+	runner.SendMessage(testutil.MainChannelID, "?++ target", fmt.Sprintf(karma.MsgIncrementKarma, "target", "target", 1))
+
+	// Configuring a default guild later doesn't move other guilds' karma into it.
+	config.DefaultGuildID = testutil.OtherGuildID
+	for _, fn := range runner.FeatureRegistry.GetInitialLoadFns() {
+		if err := fn(runner.DiscordSession); err != nil {
+			t.Fatalf("Initial load failed: %v", err)
+		}
+	}
+	runner.SendMessage(testutil.MainChannelID, "?++ target", fmt.Sprintf(karma.MsgIncrementKarma, "target", "target", 2))
+	runner.SendMessage(testutil.OtherGuildChannelID, "?karma target", fmt.Sprintf(karma.MsgKarmaInfo, "target", 0))
+}
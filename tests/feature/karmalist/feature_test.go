@@ -1,8 +1,12 @@
 package karmalist
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/jakevoytko/crbot/feature/karma"
+	"github.com/jakevoytko/crbot/feature/karmalist"
 	"github.com/jakevoytko/crbot/testutil"
 )
 
@@ -14,3 +18,86 @@ func TestKarmaList_NoResponse(t *testing.T) {
 	// Test Karmalist verifying builtin success by proxy
 	runner.SendKarmaListMessage(testutil.MainChannelID)
 }
+
+func TestKarmaList_Leaderboards(t *testing.T) {
+	runner := testutil.NewRunner(t)
+
+	runner.SendMessage(testutil.MainChannelID, "?karmalist week", fmt.Sprintf(karmalist.MsgNoKarmaInWindow, 7))
+	runner.SendMessage(testutil.MainChannelID, "?karmalist top", karmalist.MsgNoKarma)
+
+	// Old karma.
+	runner.SendMessageIgnoringResponse(testutil.MainChannelID, "?++ Carrots")
+	runner.SendMessageIgnoringResponse(testutil.MainChannelID, "?++ Carrots")
+	runner.SendMessageIgnoringResponse(testutil.MainChannelID, "?-- Errors")
+	runner.UTCClock.Advance(karmalist.Week)
+
+	// Karma from the past week.
+	runner.SendMessageIgnoringResponse(testutil.MainChannelID, "?++ Peas")
+	runner.SendMessageIgnoringResponse(testutil.MainChannelID, "?-- Carrots")
+	runner.SendMessageIgnoringResponse(testutil.MainChannelID, "?-- Errors")
+	runner.SendMessageIgnoringResponse(testutil.MainChannelID, "?-- Errors")
+
+	runner.SendMessage(testutil.MainChannelID, "?karmalist week", strings.Join([]string{
+		fmt.Sprintf(karmalist.MsgLeaderboardWindow, 7),
+		fmt.Sprintf(karmalist.MsgLeaderboardEntry, 1, "Peas", 1),
+		fmt.Sprintf(karmalist.MsgLeaderboardEntry, 2, "Carrots", -1),
+		fmt.Sprintf(karmalist.MsgLeaderboardEntry, 3, "Errors", -2),
+	}, "\n"))
+	runner.SendMessage(testutil.MainChannelID, "?karmalist month", strings.Join([]string{
+		fmt.Sprintf(karmalist.MsgLeaderboardWindow, 30),
+		fmt.Sprintf(karmalist.MsgLeaderboardEntry, 1, "Carrots", 1),
+		fmt.Sprintf(karmalist.MsgLeaderboardEntry, 2, "Peas", 1),
+		fmt.Sprintf(karmalist.MsgLeaderboardEntry, 3, "Errors", -3),
+	}, "\n"))
+	runner.SendMessage(testutil.MainChannelID, "?karmalist top", strings.Join([]string{
+		fmt.Sprintf(karmalist.MsgLeaderboardTop, 3),
+		fmt.Sprintf(karmalist.MsgLeaderboardEntry, 1, "Carrots", 1),
+		fmt.Sprintf(karmalist.MsgLeaderboardEntry, 2, "Peas", 1),
+		fmt.Sprintf(karmalist.MsgLeaderboardEntry, 3, "Errors", -3),
+	}, "\n"))
+	runner.SendMessage(testutil.MainChannelID, "?karmalist bottom  2", strings.Join([]string{
+		fmt.Sprintf(karmalist.MsgLeaderboardBottom, 2),
+		fmt.Sprintf(karmalist.MsgLeaderboardEntry, 1, "Errors", -3),
+		fmt.Sprintf(karmalist.MsgLeaderboardEntry, 2, "Carrots", 1),
+	}, "\n"))
+
+	// Karma ages out of the window.
+	runner.UTCClock.Advance(karmalist.Week)
+	runner.SendMessage(testutil.MainChannelID, "?karmalist week", fmt.Sprintf(karmalist.MsgNoKarmaInWindow, 7))
+
+	// Wrong call format
+	runner.SendMessage(testutil.MainChannelID, "?karmalist year", karmalist.MsgHelpKarmaList)
+	runner.SendMessage(testutil.MainChannelID, "?karmalist week 5", karmalist.MsgHelpKarmaList)
+	runner.SendMessage(testutil.MainChannelID, "?karmalist top 0", karmalist.MsgHelpKarmaList)
+	runner.SendMessage(testutil.MainChannelID, "?karmalist top ten", karmalist.MsgHelpKarmaList)
+}
+
+func TestKarmaList_CorruptValue(t *testing.T) {
+	runner := testutil.NewRunner(t)
+
+	if err := karma.NewGuildMap(runner.KarmaMap, testutil.MainGuildID).Set("Corrupt", "lots"); err != nil {
+		t.Fatalf("Error storing karma: %v", err)
+	}
+	runner.SendMessageIgnoringResponse(testutil.MainChannelID, "?++ Peas")
+
+	// Totals that aren't numbers are left off the leaderboard.
+	runner.SendMessage(testutil.MainChannelID, "?karmalist top", strings.Join([]string{
+		fmt.Sprintf(karmalist.MsgLeaderboardTop, 1),
+		fmt.Sprintf(karmalist.MsgLeaderboardEntry, 1, "Peas", 1),
+	}, "\n"))
+}
+
+func TestKarmaList_LongLeaderboardUsesGist(t *testing.T) {
+	runner := testutil.NewRunner(t)
+
+	karmaModelHelper := karma.NewModelHelper(runner.KarmaMap, runner.KarmaHistoryMap, runner.KarmaMergeMap, runner.UTCClock).ForGuild(testutil.MainGuildID)
+	for i := 0; i < 200; i++ {
+		karmaModelHelper.Increment(fmt.Sprintf("target%03d", i), "" /* reason */)
+	}
+
+	runner.GistsCount++
+	runner.SendMessage(testutil.MainChannelID, "?karmalist week", karmalist.MsgGistLeaderboardAddress+": "+testutil.GistSuccessURL)
+	if !strings.HasPrefix(runner.Gist.Messages[0], fmt.Sprintf(karmalist.MsgLeaderboardWindow, 7)) {
+		t.Errorf("Expected the leaderboard to be uploaded, got %v", runner.Gist.Messages[0])
+	}
+}
@@ -21,11 +21,11 @@ func TestKarmaList_PositiveSort(t *testing.T) {
 	buffer.WriteString("\n")
 	expected := buffer.String()
 
-	karmaModelHelper := karma.NewModelHelper(runner.KarmaMap)
-	karmaModelHelper.Increment("Carrots")
-	karmaModelHelper.Increment("Peas")
-	karmaModelHelper.Increment("Peas")
-	karmalistModelHelper := karmalist.NewModelHelper(runner.KarmaMap)
+	karmaModelHelper := karma.NewModelHelper(runner.KarmaMap, runner.KarmaHistoryMap, runner.KarmaMergeMap, runner.UTCClock)
+	karmaModelHelper.Increment("Carrots", "" /* reason */)
+	karmaModelHelper.Increment("Peas", "" /* reason */)
+	karmaModelHelper.Increment("Peas", "" /* reason */)
+	karmalistModelHelper := karmalist.NewModelHelper(runner.KarmaMap, runner.KarmaHistoryMap, runner.UTCClock, 0 /* decayHalfLife */)
 	generated := karmalistModelHelper.GenerateList()
 
 	if generated != expected {
@@ -46,14 +46,14 @@ func TestKarmaList_MagnitudeSort(t *testing.T) {
 	buffer.WriteString("\n")
 	expected := buffer.String()
 
-	karmaModelHelper := karma.NewModelHelper(runner.KarmaMap)
-	karmaModelHelper.Increment("Carrots")
-	karmaModelHelper.Increment("Peas")
-	karmaModelHelper.Increment("Peas")
-	karmaModelHelper.Decrement("Errors")
-	karmaModelHelper.Decrement("Errors")
-	karmaModelHelper.Decrement("Errors")
-	karmalistModelHelper := karmalist.NewModelHelper(runner.KarmaMap)
+	karmaModelHelper := karma.NewModelHelper(runner.KarmaMap, runner.KarmaHistoryMap, runner.KarmaMergeMap, runner.UTCClock)
+	karmaModelHelper.Increment("Carrots", "" /* reason */)
+	karmaModelHelper.Increment("Peas", "" /* reason */)
+	karmaModelHelper.Increment("Peas", "" /* reason */)
+	karmaModelHelper.Decrement("Errors", "" /* reason */)
+	karmaModelHelper.Decrement("Errors", "" /* reason */)
+	karmaModelHelper.Decrement("Errors", "" /* reason */)
+	karmalistModelHelper := karmalist.NewModelHelper(runner.KarmaMap, runner.KarmaHistoryMap, runner.UTCClock, 0 /* decayHalfLife */)
 	generated := karmalistModelHelper.GenerateList()
 
 	if generated != expected {
@@ -66,7 +66,7 @@ func TestKarmaList_NoData(t *testing.T) {
 	buffer.WriteString(karmalist.MsgNoKarma)
 	expected := buffer.String()
 
-	karmalistModelHelper := karmalist.NewModelHelper(runner.KarmaMap)
+	karmalistModelHelper := karmalist.NewModelHelper(runner.KarmaMap, runner.KarmaHistoryMap, runner.UTCClock, 0 /* decayHalfLife */)
 	generated := karmalistModelHelper.GenerateList()
 
 	if generated != expected {
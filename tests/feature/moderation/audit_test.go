@@ -0,0 +1,104 @@
+package moderation
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jakevoytko/crbot/app"
+	"github.com/jakevoytko/crbot/config"
+	"github.com/jakevoytko/crbot/feature/learn"
+	"github.com/jakevoytko/crbot/feature/moderation"
+	"github.com/jakevoytko/crbot/feature/permissions"
+	"github.com/jakevoytko/crbot/model"
+	"github.com/jakevoytko/crbot/testutil"
+)
+
+func TestAudit(t *testing.T) {
+	policies := []config.CommandPolicy{
+		{Command: "?factsphere", DenyUsers: []model.Snowflake{3}},
+	}
+	config := config.NewConfig()
+	config.Moderators = []model.Snowflake{1}
+	config.RickList = []model.Snowflake{2}
+	config.AuditChannelID = testutil.SecondChannelID
+	config.AuditLogFile = filepath.Join(t.TempDir(), "audit.jsonl")
+	config.CommandPolicies = policies
+	runner := testutil.NewRunnerWithConfig(t, &config)
+
+	rickListedUser := runner.DiscordSession.Users["2"]
+	moderator := &discordgo.User{ID: "1", Username: "username"}
+	denied := &discordgo.User{ID: "3", Username: "denied"}
+	runner.AddUser(denied)
+	modLog := func(message string) *testutil.Message {
+		return testutil.NewMessage(testutil.SecondChannelID.Format(), message)
+	}
+	prefix := "[2017-01-01 01:01 UTC] "
+
+	runner.SendMessage(testutil.MainChannelID, "?audit", moderation.MsgAuditEmpty)
+	runner.SendMessageAs(denied, testutil.MainChannelID, "?audit", fmt.Sprintf(app.MsgModeratorOnly, "?audit"))
+
+	// Moderation actions are posted in the mod-log channel.
+	rickListHit := prefix + "ricklist-hit by <@2> in <#" + testutil.DirectMessageID.Format() + ">: `?help`"
+	runner.SendMessageAsWithResponses(rickListedUser, testutil.DirectMessageID, "?help",
+		modLog(rickListHit),
+		testutil.NewMessage(testutil.DirectMessageID.Format(), moderation.MsgRickList))
+
+	aclDenial := prefix + "permission-denied by <@3> in <#" + testutil.MainChannelID.Format() + ">: `?factsphere` (" + permissions.MsgReasonDeniedUser + ")"
+	runner.SendMessageAsWithResponses(denied, testutil.MainChannelID, "?factsphere",
+		modLog(aclDenial),
+		testutil.NewMessage(testutil.MainChannelID.Format(), fmt.Sprintf(permissions.MsgPermissionDenied, "?factsphere", "?factsphere")))
+
+	rickListAdd := prefix + "ricklist-add by <@1> on <@3> in <#" + testutil.MainChannelID.Format() + "> (for 1h)"
+	runner.SendMessageAsWithResponses(moderator, testutil.MainChannelID, "?ricklist add <@3> 1h",
+		modLog(rickListAdd),
+		testutil.NewMessage(testutil.MainChannelID.Format(), fmt.Sprintf(moderation.MsgRickListAddedFor, "@denied", "1h")))
+
+	runner.SendLearnMessage(testutil.MainChannelID, "?learn rick roll", testutil.NewLearnData("rick", "roll"))
+	delete(runner.LearnDataMap, "rick")
+	unlearn := prefix + "unlearn by <@1> in <#" + testutil.MainChannelID.Format() + ">: `?rick`"
+	runner.SendMessageAsWithResponses(moderator, testutil.MainChannelID, "?unlearn rick",
+		modLog(unlearn),
+		testutil.NewMessage(testutil.MainChannelID.Format(), fmt.Sprintf(learn.MsgUnlearnSuccess, "rick")))
+
+	// ?audit searches the log file.
+	runner.SendMessage(testutil.MainChannelID, "?audit", strings.Join([]string{rickListHit, aclDenial, rickListAdd, unlearn}, "\n"))
+	runner.SendMessage(testutil.MainChannelID, "?audit <@3>", strings.Join([]string{aclDenial, rickListAdd}, "\n"))
+	runner.SendMessage(testutil.MainChannelID, "?audit 2", rickListHit)
+	runner.SendMessage(testutil.MainChannelID, "?audit unlearn", unlearn)
+	runner.SendMessage(testutil.MainChannelID, "?audit ?factsphere", aclDenial)
+	runner.SendMessage(testutil.MainChannelID, "?audit ?vote", moderation.MsgAuditEmpty)
+	runner.SendMessage(testutil.MainChannelID, "?audit a b", moderation.MsgHelpAudit)
+
+	// Lines that were cut off by a crash are skipped.
+	file, err := os.OpenFile(config.AuditLogFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Error opening the audit log: %v", err)
+	}
+	if _, err := file.WriteString(`{"type":"unlea` + "\n"); err != nil {
+		t.Fatalf("Error writing the audit log: %v", err)
+	}
+	file.Close()
+	runner.SendMessage(testutil.MainChannelID, "?audit unlearn", unlearn)
+}
+
+func TestAudit_QueryFails(t *testing.T) {
+	config := config.NewConfig()
+	config.Moderators = []model.Snowflake{1}
+	// A directory can be opened, but not read.
+	config.AuditLogFile = t.TempDir()
+	runner := testutil.NewRunnerWithConfig(t, &config)
+
+	runner.SendMessage(testutil.MainChannelID, "?audit", moderation.MsgAuditFailed)
+}
+
+func TestAudit_NotConfigured(t *testing.T) {
+	config := config.NewConfig()
+	config.Moderators = []model.Snowflake{1}
+	runner := testutil.NewRunnerWithConfig(t, &config)
+
+	runner.SendMessage(testutil.MainChannelID, "?audit", moderation.MsgAuditNotConfigured)
+}
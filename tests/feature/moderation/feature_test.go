@@ -1,11 +1,17 @@
 package moderation
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/jakevoytko/crbot/app"
+	"github.com/jakevoytko/crbot/config"
 	"github.com/jakevoytko/crbot/feature/help"
 	"github.com/jakevoytko/crbot/feature/moderation"
+	"github.com/jakevoytko/crbot/feature/vote"
+	"github.com/jakevoytko/crbot/model"
 	"github.com/jakevoytko/crbot/testutil"
 )
 
@@ -32,3 +38,118 @@ func TestRickList(t *testing.T) {
 	// A learn can still go through.
 	runner.SendLearnMessageAs(rickListedUser, testutil.DirectMessageID, "?learn rick list", testutil.NewLearnData("rick", "list"))
 }
+
+func TestRickList_AddAndRemove(t *testing.T) {
+	config := config.NewConfig()
+	config.Moderators = []model.Snowflake{1}
+	config.RickList = []model.Snowflake{2}
+	runner := testutil.NewRunnerWithConfig(t, &config)
+
+	victim := &discordgo.User{
+		ID:       "3",
+		Username: "victim",
+	}
+	runner.AddUser(victim)
+
+	runner.SendMessage(testutil.MainChannelID, "?ricklist", moderation.MsgRickListUsers+"[@crbot]")
+
+	// Only moderators can change the ricklist.
+	runner.SendMessageAs(victim, testutil.MainChannelID, "?ricklist remove <@2>", fmt.Sprintf(app.MsgModeratorOnly, "?ricklist"))
+	runner.SendMessage(testutil.MainChannelID, "?ricklist add", moderation.MsgHelpRickListInfo)
+	runner.SendMessage(testutil.MainChannelID, "?ricklist add <@3> soon", moderation.MsgHelpRickListInfo)
+	runner.SendMessage(testutil.MainChannelID, "?ricklist remove victim", moderation.MsgHelpRickListInfo)
+
+	// Timed entries show their remaining time, and expire on their own.
+	runner.SendMessage(testutil.MainChannelID, "?ricklist add <@!3> 90m", fmt.Sprintf(moderation.MsgRickListAddedFor, "@victim", "1h30m"))
+	runner.SendMessageAs(victim, testutil.DirectMessageID, "?help help-arg", moderation.MsgRickList)
+	runner.ElapseTime(testutil.MainChannelID, 30*time.Minute)
+	runner.SendMessage(testutil.MainChannelID, "?ricklist", moderation.MsgRickListUsers+"[@crbot, @victim"+fmt.Sprintf(moderation.MsgRickListRemaining, "1h")+"]")
+	runner.ElapseTime(testutil.MainChannelID, time.Hour, fmt.Sprintf(moderation.MsgRickListExpired, "@victim"))
+	runner.SendMessageAs(victim, testutil.DirectMessageID, "?help help-arg", help.MsgDefaultHelp)
+
+	// Replacing a timed entry cancels its expiry.
+	runner.SendMessage(testutil.MainChannelID, "?ricklist add 3 1h", fmt.Sprintf(moderation.MsgRickListAddedFor, "@victim", "1h"))
+	runner.SendMessage(testutil.MainChannelID, "?ricklist add 3", fmt.Sprintf(moderation.MsgRickListAdded, "@victim"))
+	runner.ElapseTime(testutil.MainChannelID, time.Hour)
+	runner.SendMessage(testutil.MainChannelID, "?ricklist", moderation.MsgRickListUsers+"[@crbot, @victim]")
+
+	runner.SendMessage(testutil.MainChannelID, "?ricklist remove <@2>", fmt.Sprintf(moderation.MsgRickListRemoved, "@crbot"))
+	runner.SendMessage(testutil.MainChannelID, "?ricklist remove <@2>", fmt.Sprintf(moderation.MsgRickListNotListed, "@crbot"))
+	runner.SendMessage(testutil.MainChannelID, "?ricklist remove 3", fmt.Sprintf(moderation.MsgRickListRemoved, "@victim"))
+
+	// Users from the config are only seeded once, so they stay removed.
+	for _, fn := range runner.FeatureRegistry.GetInitialLoadFns() {
+		if err := fn(runner.DiscordSession); err != nil {
+			t.Fatalf("Initial load failed: %v", err)
+		}
+	}
+	runner.SendMessage(testutil.MainChannelID, "?ricklist", moderation.MsgRickListEmpty)
+}
+
+func TestRickList_ExpiresAfterRestart(t *testing.T) {
+	config := config.NewConfig()
+	runner := testutil.NewRunnerWithConfig(t, &config)
+
+	modelHelper := moderation.NewModelHelper(runner.ModerationMap, runner.UTCClock)
+	if _, err := modelHelper.AddToRickList(2, 1, testutil.MainChannelID, time.Hour, false); err != nil {
+		t.Fatalf("Error adding to the ricklist: %v", err)
+	}
+	for _, fn := range runner.FeatureRegistry.GetInitialLoadFns() {
+		if err := fn(runner.DiscordSession); err != nil {
+			t.Fatalf("Initial load failed: %v", err)
+		}
+	}
+
+	runner.SendMessage(testutil.MainChannelID, "?ricklist", moderation.MsgRickListUsers+"[@crbot"+fmt.Sprintf(moderation.MsgRickListRemaining, "1h")+"]")
+	runner.ElapseTime(testutil.MainChannelID, time.Hour, fmt.Sprintf(moderation.MsgRickListExpired, "@crbot"))
+	runner.SendMessage(testutil.MainChannelID, "?ricklist", moderation.MsgRickListEmpty)
+}
+
+func TestRickList_Vote(t *testing.T) {
+	config := config.NewConfig()
+	config.Moderators = []model.Snowflake{1}
+	config.AuditChannelID = testutil.MainChannelID
+	runner := testutil.NewRunnerWithConfig(t, &config)
+
+	voters := []*discordgo.User{}
+	for id := 3; id < 8; id++ {
+		voter := testutil.NewUser(fmt.Sprintf("voter%d", id), model.Snowflake(id), false /* bot */)
+		runner.AddUser(voter)
+		voters = append(voters, voter)
+	}
+	author := voters[0]
+	target := testutil.NewUser("target", 8 /* id */, false /* bot */)
+	runner.AddUser(target)
+
+	// Moderators and malformed calls can't be voted on.
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote action ricklist <@1>",
+		fmt.Sprintf(vote.MsgInvalidAction, moderation.MsgRickListModerator))
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote action ricklist <@8> forever",
+		fmt.Sprintf(vote.MsgInvalidAction, moderation.MsgHelpRickListAction))
+
+	description := fmt.Sprintf(moderation.MsgRickListActionDescriptionFor, 8, "10m")
+	runner.ActiveVoteDataMap[testutil.MainChannelID] = &testutil.VoteData{
+		Channel:      testutil.MainChannelID,
+		Author:       author,
+		Message:      description,
+		VotesFor:     []model.Snowflake{},
+		VotesAgainst: []model.Snowflake{},
+		TimestampEnd: runner.UTCClock.Now().Add(vote.VoteDuration),
+	}
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote action ricklist <@8> 10m",
+		fmt.Sprintf(vote.MsgBroadcastNewVote, author.Mention(), description, fmt.Sprintf(vote.MsgMinutesRemaining, 30))+"\n"+vote.MsgVoteAction)
+	for _, voter := range voters {
+		runner.CastBallotAs(voter, testutil.MainChannelID, true /* inFavor */)
+	}
+	runner.ActiveVoteDataMap[testutil.MainChannelID] = nil
+
+	// A passing vote ricklists the user, and is posted in the mod-log channel.
+	passed := fmt.Sprintf(vote.MsgVoteConcluded, author.Mention()) + "\n" + vote.MsgStatusVotePassed + " " + fmt.Sprintf(vote.MsgVotesFor, 5) + ", " + fmt.Sprintf(vote.MsgVotesAgainst, 0)
+	runner.ElapseTime(testutil.MainChannelID, vote.VoteDuration,
+		passed,
+		"[2017-01-01 01:31 UTC] ricklist-add on <@8> in <#"+testutil.MainChannelID.Format()+"> (by vote for 10m)",
+		fmt.Sprintf(vote.MsgActionExecuted, fmt.Sprintf(moderation.MsgRickListAddedFor, "@target", "10m")))
+	runner.SendMessageAsWithResponses(target, testutil.DirectMessageID, "?help",
+		testutil.NewMessage(testutil.MainChannelID.Format(), "[2017-01-01 01:31 UTC] ricklist-hit by <@8> in <#"+testutil.DirectMessageID.Format()+">: `?help`"),
+		testutil.NewMessage(testutil.DirectMessageID.Format(), moderation.MsgRickList))
+}
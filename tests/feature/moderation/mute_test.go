@@ -0,0 +1,95 @@
+package moderation
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jakevoytko/crbot/app"
+	"github.com/jakevoytko/crbot/config"
+	"github.com/jakevoytko/crbot/feature/help"
+	"github.com/jakevoytko/crbot/feature/moderation"
+	"github.com/jakevoytko/crbot/model"
+	"github.com/jakevoytko/crbot/testutil"
+)
+
+func TestMute(t *testing.T) {
+	config := config.NewConfig()
+	config.Moderators = []model.Snowflake{1}
+	runner := testutil.NewRunnerWithConfig(t, &config)
+
+	victim := &discordgo.User{
+		ID:       "3",
+		Username: "victim",
+	}
+	bystander := &discordgo.User{
+		ID:       "4",
+		Username: "bystander",
+	}
+	runner.AddUser(victim)
+	runner.AddUser(bystander)
+
+	runner.SendMessage(testutil.MainChannelID, "?mutes", moderation.MsgMutesEmpty)
+
+	// Only moderators can mute, and they can't be muted.
+	runner.SendMessageAs(victim, testutil.MainChannelID, "?mute <@4> 1h", fmt.Sprintf(app.MsgModeratorOnly, "?mute"))
+	runner.SendMessage(testutil.MainChannelID, "?mute <@1> 1h", moderation.MsgMuteModerator)
+	runner.SendMessage(testutil.MainChannelID, "?mute <@3>", moderation.MsgHelpMute)
+	runner.SendMessage(testutil.MainChannelID, "?mute <@3> soon", moderation.MsgHelpMute)
+	runner.SendMessage(testutil.MainChannelID, fmt.Sprintf("?mute <#%v> 1h too loud", testutil.SecondChannelID), moderation.MsgHelpMute)
+	runner.SendMessage(testutil.MainChannelID, fmt.Sprintf("?mute <#%v> 1h", testutil.OtherGuildChannelID), moderation.MsgMuteUnknownChannel)
+
+	// Muted users are ignored everywhere.
+	runner.SendMessage(testutil.MainChannelID, "?mute <@3> 90m being  loud", fmt.Sprintf(moderation.MsgMutedUserReason, "@victim", "1h30m", "being  loud"))
+	runner.SendMessageAsWithResponses(victim, testutil.MainChannelID, "?help")
+	runner.SendMessageAsWithResponses(victim, testutil.DirectMessageID, "?help")
+
+	// Everybody but moderators is ignored in muted channels.
+	runner.SendMessage(testutil.MainChannelID, fmt.Sprintf("?mute <#%v> 30m", testutil.SecondChannelID), fmt.Sprintf(moderation.MsgMutedChannel, testutil.SecondChannelID, "30m"))
+	runner.SendMessageAsWithResponses(bystander, testutil.SecondChannelID, "?help")
+	runner.SendMessageAs(bystander, testutil.MainChannelID, "?help", help.MsgDefaultHelp)
+	runner.SendMessage(testutil.SecondChannelID, "?help", help.MsgDefaultHelp)
+
+	runner.ElapseTime(testutil.MainChannelID, 10*time.Minute)
+	runner.SendMessage(testutil.MainChannelID, "?mutes", moderation.MsgMutes+"\n"+
+		fmt.Sprintf(moderation.MsgMuteLine, fmt.Sprintf("<#%v>", testutil.SecondChannelID), "20m")+"\n"+
+		fmt.Sprintf(moderation.MsgMuteLineReason, "@victim", "1h20m", "being  loud"))
+
+	// Mutes are lifted on their own.
+	runner.ElapseTime(testutil.MainChannelID, 20*time.Minute, fmt.Sprintf(moderation.MsgMuteExpiredChannel, testutil.SecondChannelID))
+	runner.SendMessageAs(bystander, testutil.SecondChannelID, "?help", help.MsgDefaultHelp)
+	runner.ElapseTime(testutil.MainChannelID, time.Hour, fmt.Sprintf(moderation.MsgMuteExpiredUser, "@victim"))
+	runner.SendMessageAs(victim, testutil.MainChannelID, "?help", help.MsgDefaultHelp)
+	runner.SendMessage(testutil.MainChannelID, "?mutes", moderation.MsgMutesEmpty)
+
+	// Muting again replaces the mute and its timer.
+	runner.SendMessage(testutil.MainChannelID, "?mute 3 1h", fmt.Sprintf(moderation.MsgMutedUser, "@victim", "1h"))
+	runner.SendMessage(testutil.MainChannelID, "?mute 3 2h", fmt.Sprintf(moderation.MsgMutedUser, "@victim", "2h"))
+	runner.ElapseTime(testutil.MainChannelID, time.Hour)
+	runner.SendMessageAsWithResponses(victim, testutil.MainChannelID, "?help")
+	runner.ElapseTime(testutil.MainChannelID, time.Hour, fmt.Sprintf(moderation.MsgMuteExpiredUser, "@victim"))
+}
+
+func TestMute_ExpiresAfterRestart(t *testing.T) {
+	config := config.NewConfig()
+	runner := testutil.NewRunnerWithConfig(t, &config)
+
+	modelHelper := moderation.NewModelHelper(runner.ModerationMap, runner.UTCClock)
+	if _, err := modelHelper.Mute(0, testutil.SecondChannelID, 1, testutil.MainChannelID, time.Hour, ""); err != nil {
+		t.Fatalf("Error muting: %v", err)
+	}
+	for _, fn := range runner.FeatureRegistry.GetInitialLoadFns() {
+		if err := fn(runner.DiscordSession); err != nil {
+			t.Fatalf("Initial load failed: %v", err)
+		}
+	}
+
+	bystander := &discordgo.User{
+		ID:       "4",
+		Username: "bystander",
+	}
+	runner.SendMessageAsWithResponses(bystander, testutil.SecondChannelID, "?help")
+	runner.ElapseTime(testutil.MainChannelID, time.Hour, fmt.Sprintf(moderation.MsgMuteExpiredChannel, testutil.SecondChannelID))
+	runner.SendMessage(testutil.SecondChannelID, "?help", help.MsgDefaultHelp)
+}
@@ -0,0 +1,103 @@
+package moderation
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jakevoytko/crbot/app"
+	"github.com/jakevoytko/crbot/config"
+	"github.com/jakevoytko/crbot/feature/help"
+	"github.com/jakevoytko/crbot/feature/learn"
+	"github.com/jakevoytko/crbot/feature/moderation"
+	"github.com/jakevoytko/crbot/model"
+	"github.com/jakevoytko/crbot/testutil"
+)
+
+func TestRickList_Responses(t *testing.T) {
+	config := config.NewConfig()
+	config.Moderators = []model.Snowflake{1}
+	runner := testutil.NewRunnerWithConfig(t, &config)
+
+	victim := &discordgo.User{
+		ID:       "3",
+		Username: "victim",
+	}
+	runner.AddUser(victim)
+	runner.SendMessage(testutil.MainChannelID, "?ricklist add 3", fmt.Sprintf(moderation.MsgRickListAdded, "@victim"))
+
+	// Without responses, ricklisted users get rickrolled.
+	runner.SendMessage(testutil.MainChannelID, "?ricklist responses", fmt.Sprintf(moderation.MsgRickListResponsesEmpty, "everyone"))
+	runner.SendMessageAs(victim, testutil.DirectMessageID, "?help", moderation.MsgRickList)
+
+	// Responses for everyone.
+	runner.SendMessage(testutil.MainChannelID, "?ricklist response add nice  try", fmt.Sprintf(moderation.MsgRickListResponseAdded, "everyone"))
+	runner.SendMessageAs(victim, testutil.DirectMessageID, "?help", "nice  try")
+
+	// A user's own responses win, and can refer to learned commands.
+	runner.SendLearnMessage(testutil.MainChannelID, "?learn nope no way", testutil.NewLearnData("nope", "no way"))
+	runner.SendMessage(testutil.MainChannelID, "?ricklist response add <@3> ?missing", fmt.Sprintf(moderation.MsgRickListResponseUnknownCommand, "missing"))
+	runner.SendMessage(testutil.MainChannelID, "?ricklist response add <@3> ?nope", fmt.Sprintf(moderation.MsgRickListResponseAdded, "@victim"))
+	runner.SendMessage(testutil.MainChannelID, "?ricklist responses <@3>", fmt.Sprintf(moderation.MsgRickListResponses, "@victim")+"\n1. ?nope")
+	runner.SendMessageAs(victim, testutil.DirectMessageID, "?help", "no way")
+
+	// Malformed responses.
+	runner.SendMessage(testutil.MainChannelID, "?ricklist response add !bang", moderation.MsgHelpRickListInfo)
+	runner.SendMessage(testutil.MainChannelID, "?ricklist response add <@3>", moderation.MsgHelpRickListInfo)
+	runner.SendMessage(testutil.MainChannelID, "?ricklist response remove first", moderation.MsgHelpRickListInfo)
+
+	// Removing the user's last response falls back to everyone's.
+	runner.SendMessage(testutil.MainChannelID, "?ricklist response remove <@3> 2", fmt.Sprintf(moderation.MsgRickListResponseNotFound, 2, "@victim"))
+	runner.SendMessage(testutil.MainChannelID, "?ricklist response remove <@3> 1", fmt.Sprintf(moderation.MsgRickListResponseRemoved, 1, "@victim"))
+	runner.SendMessageAs(victim, testutil.DirectMessageID, "?help", "nice  try")
+
+	// Commands can be dropped silently.
+	runner.SendMessage(testutil.MainChannelID, "?ricklist response remove 1", fmt.Sprintf(moderation.MsgRickListResponseRemoved, 1, "everyone"))
+	runner.SendMessage(testutil.MainChannelID, "?ricklist response add drop", fmt.Sprintf(moderation.MsgRickListResponseAdded, "everyone"))
+	runner.SendMessage(testutil.MainChannelID, "?ricklist responses", fmt.Sprintf(moderation.MsgRickListResponses, "everyone")+"\n1. drop")
+	runner.SendMessageAsWithResponses(victim, testutil.DirectMessageID, "?help")
+
+	// Only moderators can see and change the responses.
+	runner.SendMessageAs(victim, testutil.MainChannelID, "?ricklist responses", fmt.Sprintf(app.MsgModeratorOnly, "?ricklist"))
+}
+
+func TestRickList_Shadowban(t *testing.T) {
+	config := config.NewConfig()
+	config.Moderators = []model.Snowflake{1}
+	runner := testutil.NewRunnerWithConfig(t, &config)
+
+	victim := &discordgo.User{
+		ID:       "3",
+		Username: "victim",
+	}
+	moderator := &discordgo.User{
+		ID:       "1",
+		Username: "username",
+	}
+	runner.AddUser(victim)
+	runner.SendMessage(testutil.MainChannelID, "?ricklist shadowban <@3>", fmt.Sprintf(moderation.MsgRickListShadowbanned, "@victim"))
+
+	// Shadowbanned users aren't listed or rickrolled.
+	runner.SendMessage(testutil.MainChannelID, "?ricklist", moderation.MsgRickListEmpty)
+	runner.SendMessageAs(victim, testutil.DirectMessageID, "?help", help.MsgDefaultHelp)
+
+	// What they learn is only visible to them, in private.
+	runner.SendMessageAs(victim, testutil.MainChannelID, "?learn secret hello $1", fmt.Sprintf(learn.MsgLearnSuccess, "secret"))
+	runner.SendMessageAs(victim, testutil.MainChannelID, "?learn secret again", fmt.Sprintf(learn.MsgLearnFail, "secret"))
+	runner.SendMessageAsWithResponses(victim, testutil.MainChannelID, "?secret world")
+	runner.SendMessageAs(victim, testutil.DirectMessageID, "?secret world", "hello world")
+	runner.SendMessageAsWithResponses(victim, testutil.MainChannelID, "?missing")
+	runner.SendMessageAsWithResponses(moderator, testutil.MainChannelID, "?secret world")
+
+	// Real commands win.
+	runner.SendLearnMessage(testutil.MainChannelID, "?learn secret public", testutil.NewLearnData("secret", "public"))
+	runner.SendMessageAs(victim, testutil.MainChannelID, "?secret", "public")
+
+	// Lifting the shadowban forgets what they learned.
+	runner.SendMessageAs(victim, testutil.MainChannelID, "?learn hidden boo", fmt.Sprintf(learn.MsgLearnSuccess, "hidden"))
+	runner.SendMessageAs(victim, testutil.DirectMessageID, "?hidden", "boo")
+	runner.SendMessage(testutil.MainChannelID, "?ricklist remove 3", fmt.Sprintf(moderation.MsgRickListRemoved, "@victim"))
+	runner.SendMessageAsWithResponses(victim, testutil.DirectMessageID, "?hidden")
+	runner.SendMessage(testutil.MainChannelID, "?ricklist shadowban 3 1h", fmt.Sprintf(moderation.MsgRickListShadowbannedFor, "@victim", "1h"))
+	runner.SendMessageAsWithResponses(victim, testutil.DirectMessageID, "?hidden")
+}
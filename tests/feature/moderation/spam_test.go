@@ -0,0 +1,98 @@
+package moderation
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jakevoytko/crbot/config"
+	"github.com/jakevoytko/crbot/feature/help"
+	"github.com/jakevoytko/crbot/feature/moderation"
+	"github.com/jakevoytko/crbot/model"
+	"github.com/jakevoytko/crbot/testutil"
+)
+
+func TestSpam_User(t *testing.T) {
+	config := config.NewConfig()
+	config.Moderators = []model.Snowflake{1}
+	config.SpamUserLimit = 2
+	config.SpamRickListStrikes = 4
+	runner := testutil.NewRunnerWithConfig(t, &config)
+
+	spammer := &discordgo.User{
+		ID:       "3",
+		Username: "spammer",
+	}
+	runner.AddUser(spammer)
+
+	runner.SendMessageAs(spammer, testutil.MainChannelID, "?help help-arg", help.MsgDefaultHelp)
+	runner.SendMessageAs(spammer, testutil.MainChannelID, "?help help-arg", help.MsgDefaultHelp)
+
+	// Near-identical commands past the limit are ignored, then warned about.
+	runner.SendMessageAsWithResponses(spammer, testutil.MainChannelID, "?help Help-Arg!")
+	runner.SendMessageAsWithResponses(spammer, testutil.MainChannelID, "?help help-arg",
+		testutil.NewMessage(testutil.MainChannelID.Format(), fmt.Sprintf(moderation.MsgSpamWarning, "@spammer", "?help")))
+	runner.SendMessageAsWithResponses(spammer, testutil.MainChannelID, "?help help-arg")
+
+	// Different commands, and other users, aren't affected.
+	runner.SendMessageAs(spammer, testutil.MainChannelID, "?help", help.MsgDefaultHelp)
+	runner.SendMessage(testutil.MainChannelID, "?help help-arg", help.MsgDefaultHelp)
+
+	// The window slides, but strikes are remembered.
+	runner.ElapseTime(testutil.MainChannelID, 10*time.Second)
+	runner.SendMessageAs(spammer, testutil.MainChannelID, "?help help-arg", help.MsgDefaultHelp)
+	runner.SendMessageAs(spammer, testutil.MainChannelID, "?help help-arg", help.MsgDefaultHelp)
+	runner.SendMessageAsWithResponses(spammer, testutil.MainChannelID, "?help help-arg",
+		testutil.NewMessage(testutil.MainChannelID.Format(), fmt.Sprintf(moderation.MsgSpamRickListed, "@spammer", "10m", "?help")))
+
+	// The spammer is muted for as long as they are ricklisted, so their public
+	// commands are dropped too.
+	runner.SendMessageAsWithResponses(spammer, testutil.MainChannelID, "?help")
+	runner.SendMessageAsWithResponses(spammer, testutil.SecondChannelID, "?help")
+	runner.SendMessageAsWithResponses(spammer, testutil.DirectMessageID, "?help")
+
+	// The ricklisting and the mute wear off.
+	runner.ElapseTime(testutil.MainChannelID, 10*time.Minute,
+		fmt.Sprintf(moderation.MsgRickListExpired, "@spammer"),
+		fmt.Sprintf(moderation.MsgMuteExpiredUser, "@spammer"))
+	runner.SendMessageAs(spammer, testutil.MainChannelID, "?help", help.MsgDefaultHelp)
+	runner.SendMessageAs(spammer, testutil.DirectMessageID, "?help help-arg", help.MsgDefaultHelp)
+}
+
+func TestSpam_Channel(t *testing.T) {
+	config := config.NewConfig()
+	config.Moderators = []model.Snowflake{1}
+	config.SpamChannelLimit = 2
+	runner := testutil.NewRunnerWithConfig(t, &config)
+
+	first := &discordgo.User{
+		ID:       "3",
+		Username: "first",
+	}
+	second := &discordgo.User{
+		ID:       "4",
+		Username: "second",
+	}
+	runner.AddUser(first)
+	runner.AddUser(second)
+
+	runner.SendMessageAs(first, testutil.MainChannelID, "?help help-arg", help.MsgDefaultHelp)
+	runner.SendMessageAs(second, testutil.MainChannelID, "?help help-arg", help.MsgDefaultHelp)
+	runner.SendMessageAsWithResponses(first, testutil.MainChannelID, "?help help-arg")
+	runner.SendMessageAsWithResponses(second, testutil.MainChannelID, "?help help-arg")
+
+	// Channel bursts are only throttled. Nobody is warned or ricklisted for them,
+	// since they didn't go past their own limit.
+	for i := 0; i < 5; i++ {
+		runner.SendMessageAsWithResponses(second, testutil.MainChannelID, "?help help-arg")
+	}
+
+	// Other channels and moderators aren't affected.
+	runner.SendMessageAs(first, testutil.SecondChannelID, "?help help-arg", help.MsgDefaultHelp)
+	runner.SendMessage(testutil.MainChannelID, "?help help-arg", help.MsgDefaultHelp)
+
+	runner.ElapseTime(testutil.MainChannelID, 10*time.Second)
+	runner.SendMessageAs(second, testutil.MainChannelID, "?help help-arg", help.MsgDefaultHelp)
+	runner.SendMessageAs(second, testutil.DirectMessageID, "?help", help.MsgDefaultHelp)
+}
@@ -0,0 +1,81 @@
+package permissions
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jakevoytko/crbot/config"
+	"github.com/jakevoytko/crbot/feature/help"
+	"github.com/jakevoytko/crbot/feature/karma"
+	"github.com/jakevoytko/crbot/feature/permissions"
+	"github.com/jakevoytko/crbot/model"
+	"github.com/jakevoytko/crbot/testutil"
+)
+
+func TestPermissions(t *testing.T) {
+	policies := []config.CommandPolicy{
+		{Command: "?help", AllowRoles: []model.Snowflake{50}, DenyUsers: []model.Snowflake{3}},
+		{Command: "karma", DenyChannels: []model.Snowflake{testutil.SecondChannelID}},
+		{Command: "?rick", AllowUsers: []model.Snowflake{4}},
+		{Command: "?yes", DenyUsers: []model.Snowflake{3}},
+		{Command: "f2", DenyUsers: []model.Snowflake{3}},
+	}
+	config := config.NewConfig()
+	config.CommandPolicies = policies
+	runner := testutil.NewRunnerWithConfig(t, &config)
+
+	banned := &discordgo.User{ID: "3", Username: "banned"}
+	member := &discordgo.User{ID: "4", Username: "member"}
+	runner.AddUser(banned)
+	runner.AddUser(member)
+	for _, user := range []*discordgo.User{banned, member} {
+		runner.DiscordSession.SetMember(testutil.MainGuildID.Format(), &discordgo.Member{
+			GuildID: testutil.MainGuildID.Format(),
+			User:    user,
+			Roles:   []string{"50"},
+		})
+	}
+	denied := func(name string) string {
+		return fmt.Sprintf(permissions.MsgPermissionDenied, name, name)
+	}
+
+	// Allowed roles.
+	runner.SendMessage(testutil.MainChannelID, "?help", denied("?help"))
+	runner.SendMessageAs(member, testutil.MainChannelID, "?help", help.MsgDefaultHelp)
+	runner.SendMessageAs(member, testutil.DirectMessageID, "?help", denied("?help"))
+
+	// Denials win over allowances.
+	runner.SendMessageAs(banned, testutil.MainChannelID, "?help", denied("?help"))
+
+	// Denied channels.
+	runner.SendMessage(testutil.MainChannelID, "?karma jake", fmt.Sprintf(karma.MsgKarmaInfo, "jake", 0))
+	runner.SendMessage(testutil.SecondChannelID, "?karma jake", denied("?karma"))
+
+	// Custom commands have policies too.
+	runner.SendLearnMessage(testutil.MainChannelID, "?learn rick roll", testutil.NewLearnData("rick", "roll"))
+	runner.SendMessage(testutil.MainChannelID, "?rick", denied("?rick"))
+	runner.SendMessageAs(member, testutil.MainChannelID, "?rick", "roll")
+
+	// Aliases share the policy of the command they alias.
+	runner.SendMessageAs(banned, testutil.MainChannelID, "?yes", denied("?yes"))
+	runner.SendMessageAs(banned, testutil.MainChannelID, "?f1", denied("?f1"))
+	runner.SendMessageAs(banned, testutil.MainChannelID, "?no", denied("?no"))
+	runner.SendMessageAs(banned, testutil.MainChannelID, "?f2", denied("?f2"))
+	runner.SendMessageAs(banned, testutil.MainChannelID, "?permissions ?f1",
+		fmt.Sprintf(permissions.MsgPermissionsDenied, "?f1", permissions.MsgReasonDeniedUser)+"\n"+fmt.Sprintf(permissions.MsgPolicyDeniedUsers, "<@3>"))
+
+	// ?permissions explains the policy to the caller.
+	helpPolicy := "\n" + fmt.Sprintf(permissions.MsgPolicyAllowedRoles, "<@&50>") + "\n" + fmt.Sprintf(permissions.MsgPolicyDeniedUsers, "<@3>")
+	runner.SendMessage(testutil.MainChannelID, "?permissions", permissions.MsgHelpPermissions)
+	runner.SendMessage(testutil.MainChannelID, "?permissions help",
+		fmt.Sprintf(permissions.MsgPermissionsDenied, "?help", permissions.MsgReasonNotAllowedRole)+helpPolicy)
+	runner.SendMessageAs(banned, testutil.MainChannelID, "?permissions ?help",
+		fmt.Sprintf(permissions.MsgPermissionsDenied, "?help", permissions.MsgReasonDeniedUser)+helpPolicy)
+	runner.SendMessageAs(member, testutil.MainChannelID, "?permissions ?help",
+		fmt.Sprintf(permissions.MsgPermissionsAllowed, "?help")+helpPolicy)
+	runner.SendMessage(testutil.SecondChannelID, "?permissions ?karma",
+		fmt.Sprintf(permissions.MsgPermissionsDenied, "?karma", permissions.MsgReasonDeniedChannel)+"\n"+
+			fmt.Sprintf(permissions.MsgPolicyDeniedChannels, "<#"+testutil.SecondChannelID.Format()+">"))
+	runner.SendMessage(testutil.MainChannelID, "?permissions ?list", fmt.Sprintf(permissions.MsgPermissionsUnrestricted, "?list"))
+}
@@ -0,0 +1,61 @@
+package toggle
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jakevoytko/crbot/app"
+	"github.com/jakevoytko/crbot/config"
+	"github.com/jakevoytko/crbot/feature/karma"
+	"github.com/jakevoytko/crbot/feature/toggle"
+	"github.com/jakevoytko/crbot/feature/vote"
+	"github.com/jakevoytko/crbot/model"
+	"github.com/jakevoytko/crbot/testutil"
+)
+
+func TestFeatureToggles(t *testing.T) {
+	config := config.NewConfig()
+	config.Moderators = []model.Snowflake{1}
+	runner := testutil.NewRunnerWithConfig(t, &config)
+
+	allFeatures := "factsphere, help, karma, karmalist, learn, list, moderation, permissions, vote"
+	runner.SendMessage(testutil.MainChannelID, "?feature", fmt.Sprintf(toggle.MsgFeaturesEnabled, allFeatures))
+	runner.SendMessage(testutil.MainChannelID, "?feature frob karma", toggle.MsgHelpFeature)
+	runner.SendMessage(testutil.MainChannelID, "?feature disable", toggle.MsgHelpFeature)
+	runner.SendMessage(testutil.MainChannelID, "?feature disable karma #second", toggle.MsgHelpFeature)
+	runner.SendMessage(testutil.MainChannelID, "?feature disable frob", fmt.Sprintf(toggle.MsgUnknownFeature, "frob", allFeatures))
+	runner.SendMessage(testutil.MainChannelID, "?feature disable toggle", fmt.Sprintf(toggle.MsgFeatureNotToggleable, "toggle"))
+	runner.SendMessage(testutil.MainChannelID, "?feature disable karma <#"+testutil.OtherGuildChannelID.Format()+">", toggle.MsgUnknownChannel)
+	runner.SendMessage(testutil.DirectMessageID, "?feature disable karma", fmt.Sprintf(app.MsgPublicOnly, "?feature"))
+	runner.SendMessageAs(runner.DiscordSession.Users["2"], testutil.MainChannelID, "?feature disable karma", fmt.Sprintf(app.MsgModeratorOnly, "?feature"))
+
+	// Features can be disabled in other channels.
+	runner.SendMessage(testutil.MainChannelID, "?feature disable karma <#"+testutil.SecondChannelID.Format()+">",
+		fmt.Sprintf(toggle.MsgFeatureDisabled, "karma", testutil.SecondChannelID))
+	runner.SendMessage(testutil.SecondChannelID, "?karma jake", fmt.Sprintf(app.MsgFeatureDisabled, "?karma"))
+	runner.SendMessage(testutil.SecondChannelID, "?++ jake", fmt.Sprintf(app.MsgFeatureDisabled, "?++"))
+	runner.SendMessage(testutil.MainChannelID, "?karma jake", fmt.Sprintf(karma.MsgKarmaInfo, "jake", 0))
+
+	// Disabling learn turns off learned commands too.
+	runner.SendLearnMessage(testutil.MainChannelID, "?learn rick roll", testutil.NewLearnData("rick", "roll"))
+	runner.SendMessage(testutil.MainChannelID, "?feature disable learn", fmt.Sprintf(toggle.MsgFeatureDisabled, "learn", testutil.MainChannelID))
+	runner.SendMessage(testutil.MainChannelID, "?rick", fmt.Sprintf(app.MsgFeatureDisabled, "?rick"))
+	runner.SendMessage(testutil.SecondChannelID, "?rick", "roll")
+	runner.SendMessage(testutil.MainChannelID, "?feature enable learn", fmt.Sprintf(toggle.MsgFeatureEnabled, "learn", testutil.MainChannelID))
+	runner.SendMessage(testutil.MainChannelID, "?rick", "roll")
+
+	// Votes only in the second channel. Channel settings win over the server's.
+	runner.SendMessage(testutil.MainChannelID, "?feature disable vote everywhere", fmt.Sprintf(toggle.MsgFeatureDisabledEverywhere, "vote"))
+	runner.SendMessage(testutil.MainChannelID, "?feature enable vote <#"+testutil.SecondChannelID.Format()+">",
+		fmt.Sprintf(toggle.MsgFeatureEnabled, "vote", testutil.SecondChannelID))
+	runner.SendMessage(testutil.MainChannelID, "?votestatus", fmt.Sprintf(app.MsgFeatureDisabled, "?votestatus"))
+	runner.SendMessage(testutil.MainChannelID, "?vote", fmt.Sprintf(app.MsgFeatureDisabled, "?vote"))
+	runner.SendMessage(testutil.SecondChannelID, "?votestatus", vote.MsgNoActiveVote)
+
+	runner.SendMessage(testutil.SecondChannelID, "?feature",
+		fmt.Sprintf(toggle.MsgFeaturesEnabled, "factsphere, help, karmalist, learn, list, moderation, permissions, vote")+"\n"+
+			fmt.Sprintf(toggle.MsgFeaturesDisabled, "karma"))
+	runner.SendMessage(testutil.MainChannelID, "?feature",
+		fmt.Sprintf(toggle.MsgFeaturesEnabled, "factsphere, help, karma, karmalist, learn, list, moderation, permissions")+"\n"+
+			fmt.Sprintf(toggle.MsgFeaturesDisabled, "vote"))
+}
@@ -1,17 +1,28 @@
 package vote
 
 import (
+	"encoding/json"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/jakevoytko/crbot/app"
+	"github.com/jakevoytko/crbot/config"
+	"github.com/jakevoytko/crbot/feature/karma"
+	"github.com/jakevoytko/crbot/feature/learn"
+	"github.com/jakevoytko/crbot/feature/permissions"
 	"github.com/jakevoytko/crbot/feature/vote"
+	"github.com/jakevoytko/crbot/model"
 	"github.com/jakevoytko/crbot/testutil"
 )
 
 func TestVote(t *testing.T) {
-	runner := testutil.NewRunner(t)
+	config := config.NewConfig()
+	config.VoteMaxOpen = 1
+	runner := testutil.NewRunnerWithConfig(t, &config)
 	runner.SendVoteStatusMessage(testutil.MainChannelID)
 
 	// Calls vote with no args, and then actually starts a vote.
@@ -21,8 +32,8 @@ func TestVote(t *testing.T) {
 	runner.SendVoteMessageAs(author, testutil.MainChannelID)
 	runner.SendVoteStatusMessage(testutil.MainChannelID)
 
-	// Assert that a second vote can't be started.
-	runner.SendMessageAs(author, testutil.MainChannelID, "?vote another vote", vote.MsgActiveVote)
+	// Assert that a second vote can't be started past the limit.
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote another vote", fmt.Sprintf(vote.MsgTooManyVotes, 1))
 
 	// Time the vote out.
 	runner.ExpireVote(testutil.MainChannelID)
@@ -220,7 +231,38 @@ func TestVote_CannotVoteTwice(t *testing.T) {
 	runner.SendVoteMessageAs(author, testutil.MainChannelID)
 	runner.CastBallotAs(author, testutil.MainChannelID, true /* inFavor */)
 	runner.CastDuplicateBallotAs(author, testutil.MainChannelID, true /* inFavor */)
+}
+
+func TestVote_ChangeAndRetractBallot(t *testing.T) {
+	runner := testutil.NewRunner(t)
+
+	author := testutil.NewUser("author", 0 /* id */, false /* bot */)
+	runner.AddUser(author)
+	runner.SendMessageAs(author, testutil.MainChannelID, "?unvote", vote.MsgNoActiveVote)
+	runner.SendVoteMessageAs(author, testutil.MainChannelID)
+	runner.SendMessageAs(author, testutil.MainChannelID, "?unvote", fmt.Sprintf(vote.MsgNotVoted, author.Mention()))
+	runner.CastBallotAs(author, testutil.MainChannelID, true /* inFavor */)
+	runner.ChangeBallotAs(author, testutil.MainChannelID, false /* inFavor */)
 	runner.CastDuplicateBallotAs(author, testutil.MainChannelID, false /* inFavor */)
+	runner.ChangeBallotAs(author, testutil.MainChannelID, true /* inFavor */)
+	runner.RetractBallotAs(author, testutil.MainChannelID)
+	runner.SendMessageAs(author, testutil.MainChannelID, "?unvote", fmt.Sprintf(vote.MsgNotVoted, author.Mention()))
+	runner.CastBallotAs(author, testutil.MainChannelID, false /* inFavor */)
+}
+
+func TestVote_LockedBallots(t *testing.T) {
+	runner := testutil.NewRunner(t)
+
+	author := testutil.NewUser("author", 0 /* id */, false /* bot */)
+	runner.AddUser(author)
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote --locked formal motion",
+		fmt.Sprintf(vote.MsgBroadcastNewVote, author.Mention(), "formal motion", fmt.Sprintf(vote.MsgMinutesRemaining, 30))+"\n"+vote.MsgBallotsFinal)
+	runner.SendMessageAs(author, testutil.MainChannelID, "?yes",
+		fmt.Sprintf(vote.MsgVotedInFavor, author.Mention())+"\n"+
+			fmt.Sprintf(vote.MsgStatusVotesNeeded, model.DefaultVoteQuorum)+". "+vote.MsgOneVoteFor+", "+fmt.Sprintf(vote.MsgVotesAgainst, 0)+". "+fmt.Sprintf(vote.MsgMinutesRemaining, 30))
+	runner.SendMessageAs(author, testutil.MainChannelID, "?yes", fmt.Sprintf(vote.MsgAlreadyVoted, author.Mention()))
+	runner.SendMessageAs(author, testutil.MainChannelID, "?no", vote.MsgBallotsLocked)
+	runner.SendMessageAs(author, testutil.MainChannelID, "?unvote", vote.MsgBallotsLocked)
 }
 
 func TestVote_PrivateChannel(t *testing.T) {
@@ -234,3 +276,914 @@ func TestVote_PrivateChannel(t *testing.T) {
 	runner.SendMessageAs(author, testutil.DirectMessageID, "?yes", fmt.Sprintf(app.MsgPublicOnly, "?yes"))
 	runner.SendMessageAs(author, testutil.DirectMessageID, "?no", fmt.Sprintf(app.MsgPublicOnly, "?no"))
 }
+
+func TestVote_Options(t *testing.T) {
+	runner := testutil.NewRunner(t)
+	author := testutil.NewUser("author", 0 /* id */, false /* bot */)
+	runner.AddUser(author)
+
+	// Wrong call format
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote 10m", vote.MsgHelpVote)
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote --quorum", vote.MsgHelpVote)
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote --quorum zero pirates?", vote.MsgHelpVote)
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote --threshold 1/3 pirates?", vote.MsgHelpVote)
+	// A tie would pass a 1/2 threshold, so it isn't allowed.
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote --threshold 1/2 pirates?", vote.MsgHelpVote)
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote --threshold 2/4 pirates?", vote.MsgHelpVote)
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote --turnout 3 pirates?", vote.MsgHelpVote)
+
+	// Limits
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote 30s pirates?", fmt.Sprintf(vote.MsgVoteDurationLimits, 1, 1440))
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote 25h pirates?", fmt.Sprintf(vote.MsgVoteDurationLimits, 1, 1440))
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote --quorum 101 pirates?", fmt.Sprintf(vote.MsgVoteQuorumLimits, 100))
+
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote 10m  --quorum 3 --threshold 2/3 are pirates better than ninjas?",
+		fmt.Sprintf(vote.MsgBroadcastNewVote, author.Mention(), "are pirates better than ninjas?", fmt.Sprintf(vote.MsgMinutesRemaining, 10))+"\n"+
+			fmt.Sprintf(vote.MsgVoteRulesThreshold, 3, "2/3"))
+
+	users := []*discordgo.User{
+		testutil.NewUser("user1", 1 /* id */, false /* bot */),
+		testutil.NewUser("user2", 2 /* id */, false /* bot */),
+		testutil.NewUser("user3", 3 /* id */, false /* bot */),
+	}
+	runner.SendMessageAs(users[0], testutil.MainChannelID, "?yes", fmt.Sprintf(vote.MsgVotedInFavor, users[0].Mention())+"\n"+
+		fmt.Sprintf(vote.MsgStatusVotesNeeded, 3)+". "+vote.MsgOneVoteFor+", "+fmt.Sprintf(vote.MsgVotesAgainst, 0)+". "+fmt.Sprintf(vote.MsgMinutesRemaining, 10))
+	runner.SendMessageAs(users[1], testutil.MainChannelID, "?yes", fmt.Sprintf(vote.MsgVotedInFavor, users[1].Mention())+"\n"+
+		fmt.Sprintf(vote.MsgStatusVotesNeeded, 3)+". "+fmt.Sprintf(vote.MsgVotesFor, 2)+", "+fmt.Sprintf(vote.MsgVotesAgainst, 0)+". "+fmt.Sprintf(vote.MsgMinutesRemaining, 10))
+	// 2 of 3 ballots meets a 2/3 threshold.
+	runner.SendMessageAs(users[2], testutil.MainChannelID, "?no", fmt.Sprintf(vote.MsgVotedAgainst, users[2].Mention())+"\n"+
+		vote.MsgStatusVotePassing+". "+fmt.Sprintf(vote.MsgVotesFor, 2)+", "+vote.MsgOneVoteAgainst+". "+fmt.Sprintf(vote.MsgMinutesRemaining, 10))
+
+	runner.ElapseTime(testutil.MainChannelID, time.Duration(10)*time.Minute,
+		fmt.Sprintf(vote.MsgVoteConcluded, author.Mention())+"\n"+vote.MsgStatusVotePassed+" "+fmt.Sprintf(vote.MsgVotesFor, 2)+", "+vote.MsgOneVoteAgainst)
+
+	// Numbers without a unit are part of the message, not a duration.
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote 0 tolerance for spam",
+		fmt.Sprintf(vote.MsgBroadcastNewVote, author.Mention(), "0 tolerance for spam", fmt.Sprintf(vote.MsgMinutesRemaining, int(vote.VoteDuration.Minutes()))))
+}
+
+func TestVote_ConfiguredDefaults(t *testing.T) {
+	config := config.NewConfig()
+	config.VoteDefaultDurationMinutes = 5
+	config.VoteDefaultQuorum = 2
+	runner := testutil.NewRunnerWithConfig(t, &config)
+	author := testutil.NewUser("author", 0 /* id */, false /* bot */)
+	runner.AddUser(author)
+
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote pirates?",
+		fmt.Sprintf(vote.MsgBroadcastNewVote, author.Mention(), "pirates?", fmt.Sprintf(vote.MsgMinutesRemaining, 5))+"\n"+
+			fmt.Sprintf(vote.MsgVoteRulesQuorum, 2))
+	runner.SendMessageAs(author, testutil.MainChannelID, "?no", fmt.Sprintf(vote.MsgVotedAgainst, author.Mention())+"\n"+
+		fmt.Sprintf(vote.MsgStatusVotesNeeded, 2)+". "+fmt.Sprintf(vote.MsgVotesFor, 0)+", "+vote.MsgOneVoteAgainst+". "+fmt.Sprintf(vote.MsgMinutesRemaining, 5))
+
+	runner.ElapseTime(testutil.MainChannelID, time.Duration(5)*time.Minute,
+		fmt.Sprintf(vote.MsgVoteConcluded, author.Mention())+"\n"+vote.MsgStatusInconclusive+" "+fmt.Sprintf(vote.MsgVotesFor, 0)+", "+vote.MsgOneVoteAgainst)
+}
+
+func TestPoll_Plurality(t *testing.T) {
+	config := config.NewConfig()
+	config.VoteMaxOpen = 1
+	runner := testutil.NewRunnerWithConfig(t, &config)
+	users := []*discordgo.User{
+		testutil.NewUser("user0", 0 /* id */, false /* bot */),
+		testutil.NewUser("user1", 1 /* id */, false /* bot */),
+		testutil.NewUser("user2", 2 /* id */, false /* bot */),
+	}
+	for _, user := range users {
+		runner.AddUser(user)
+	}
+
+	// Wrong call format
+	runner.SendMessageAs(users[0], testutil.MainChannelID, "?poll", vote.MsgHelpPoll)
+	runner.SendMessageAs(users[0], testutil.MainChannelID, "?poll lunch?", vote.MsgHelpPoll)
+	runner.SendMessageAs(users[0], testutil.MainChannelID, "?poll lunch? | tacos", vote.MsgHelpPoll)
+	runner.SendMessageAs(users[0], testutil.MainChannelID, "?poll lunch? | tacos | Tacos", vote.MsgHelpPoll)
+	runner.SendMessageAs(users[0], testutil.MainChannelID, "?poll lunch? | tacos | | pizza", vote.MsgHelpPoll)
+	runner.SendMessageAs(users[0], testutil.MainChannelID, "?pick 1", vote.MsgNoActivePoll)
+
+	runner.SendMessageAs(users[0], testutil.MainChannelID, "?poll 10m lunch? | tacos | pizza|sushi",
+		fmt.Sprintf(vote.MsgBroadcastNewPoll, users[0].Mention(), "lunch?", "1. tacos\n2. pizza\n3. sushi", fmt.Sprintf(vote.MsgMinutesRemaining, 10)))
+	runner.SendMessageAs(users[0], testutil.MainChannelID, "?vote another vote", fmt.Sprintf(vote.MsgTooManyVotes, 1))
+	runner.SendMessageAs(users[0], testutil.MainChannelID, "?yes", vote.MsgPollActive)
+	runner.SendMessageAs(users[0], testutil.MainChannelID, "?pick", vote.MsgHelpPick)
+	runner.SendMessageAs(users[0], testutil.MainChannelID, "?pick 1 2", vote.MsgHelpPick)
+	runner.SendMessageAs(users[0], testutil.MainChannelID, "?pick 4", fmt.Sprintf(vote.MsgInvalidChoice, 3))
+	runner.SendMessageAs(users[0], testutil.MainChannelID, "?rank 1 2", vote.MsgPollNotRanked)
+
+	runner.SendMessageAs(users[0], testutil.MainChannelID, "?pick 2",
+		fmt.Sprintf(vote.MsgPicked, users[0].Mention(), "pizza")+"\n"+vote.MsgOneBallotCast+". "+fmt.Sprintf(vote.MsgMinutesRemaining, 10))
+	runner.SendMessageAs(users[0], testutil.MainChannelID, "?pick 2", fmt.Sprintf(vote.MsgAlreadyVoted, users[0].Mention()))
+	runner.SendMessageAs(users[0], testutil.MainChannelID, "?pick 1",
+		fmt.Sprintf(vote.MsgChangedPollBallot, users[0].Mention(), "tacos")+"\n"+vote.MsgOneBallotCast+". "+fmt.Sprintf(vote.MsgMinutesRemaining, 10))
+	runner.SendMessageAs(users[0], testutil.MainChannelID, "?unvote",
+		fmt.Sprintf(vote.MsgRetracted, users[0].Mention())+"\n"+fmt.Sprintf(vote.MsgBallotsCast, 0)+". "+fmt.Sprintf(vote.MsgMinutesRemaining, 10))
+	runner.SendMessageAs(users[0], testutil.MainChannelID, "?pick 2",
+		fmt.Sprintf(vote.MsgPicked, users[0].Mention(), "pizza")+"\n"+vote.MsgOneBallotCast+". "+fmt.Sprintf(vote.MsgMinutesRemaining, 10))
+	runner.SendMessageAs(users[1], testutil.MainChannelID, "?pick 2",
+		fmt.Sprintf(vote.MsgPicked, users[1].Mention(), "pizza")+"\n"+fmt.Sprintf(vote.MsgBallotsCast, 2)+". "+fmt.Sprintf(vote.MsgMinutesRemaining, 10))
+	runner.SendMessageAs(users[2], testutil.MainChannelID, "?pick 3",
+		fmt.Sprintf(vote.MsgPicked, users[2].Mention(), "sushi")+"\n"+fmt.Sprintf(vote.MsgBallotsCast, 3)+". "+fmt.Sprintf(vote.MsgMinutesRemaining, 10))
+
+	runner.SendMessageAs(users[0], testutil.MainChannelID, "?votestatus", strings.Join([]string{
+		fmt.Sprintf(vote.MsgPollOwner, "user0") + "lunch?",
+		vote.MsgSpacer,
+		fmt.Sprintf(vote.MsgPollOptionCount, 1, "tacos", 0),
+		fmt.Sprintf(vote.MsgPollOptionCount, 2, "pizza", 2),
+		fmt.Sprintf(vote.MsgPollOptionCount, 3, "sushi", 1),
+		fmt.Sprintf(vote.MsgBallotsCast, 3) + ". " + fmt.Sprintf(vote.MsgMinutesRemaining, 10),
+	}, "\n"))
+
+	runner.ElapseTime(testutil.MainChannelID, time.Duration(10)*time.Minute, strings.Join([]string{
+		fmt.Sprintf(vote.MsgPollConcluded, users[0].Mention(), "lunch?"),
+		fmt.Sprintf(vote.MsgPollWinner, "pizza"),
+		fmt.Sprintf(vote.MsgPollOptionCount, 1, "tacos", 0),
+		fmt.Sprintf(vote.MsgPollOptionCount, 2, "pizza", 2),
+		fmt.Sprintf(vote.MsgPollOptionCount, 3, "sushi", 1),
+	}, "\n"))
+	runner.SendMessageAs(users[0], testutil.MainChannelID, "?pick 1", vote.MsgNoActivePoll)
+
+	// Polls with no ballots have no winner.
+	runner.SendMessageAs(users[0], testutil.MainChannelID, "?poll lunch? | tacos | pizza",
+		fmt.Sprintf(vote.MsgBroadcastNewPoll, users[0].Mention(), "lunch?", "1. tacos\n2. pizza", fmt.Sprintf(vote.MsgMinutesRemaining, 30)))
+	runner.ElapseTime(testutil.MainChannelID, vote.VoteDuration,
+		fmt.Sprintf(vote.MsgPollConcluded, users[0].Mention(), "lunch?")+"\n"+vote.MsgPollNoBallots)
+}
+
+func TestPoll_Ranked(t *testing.T) {
+	runner := testutil.NewRunner(t)
+	users := []*discordgo.User{
+		testutil.NewUser("user0", 0 /* id */, false /* bot */),
+		testutil.NewUser("user1", 1 /* id */, false /* bot */),
+		testutil.NewUser("user2", 2 /* id */, false /* bot */),
+		testutil.NewUser("user3", 3 /* id */, false /* bot */),
+		testutil.NewUser("user4", 4 /* id */, false /* bot */),
+	}
+	for _, user := range users {
+		runner.AddUser(user)
+	}
+
+	runner.SendMessageAs(users[0], testutil.MainChannelID, "?poll --ranked topping? | pineapple | mushrooms | anchovies",
+		fmt.Sprintf(vote.MsgBroadcastNewRankedPoll, users[0].Mention(), "topping?", "1. pineapple\n2. mushrooms\n3. anchovies", fmt.Sprintf(vote.MsgMinutesRemaining, 30)))
+	runner.SendMessageAs(users[0], testutil.MainChannelID, "?rank 1 1", vote.MsgHelpRank)
+
+	// Pineapple leads on first choices, but anchovies fans prefer mushrooms.
+	ballots := []string{"?rank 1 2", "?rank 1", "?rank 2 1", "?rank 3 2", "?pick 3"}
+	expected := []string{"pineapple, mushrooms", "pineapple", "mushrooms, pineapple", "anchovies, mushrooms", "anchovies"}
+	for i, ballot := range ballots {
+		ballotsCast := vote.MsgOneBallotCast
+		if i > 0 {
+			ballotsCast = fmt.Sprintf(vote.MsgBallotsCast, i+1)
+		}
+		runner.SendMessageAs(users[i], testutil.MainChannelID, ballot,
+			fmt.Sprintf(vote.MsgRanked, users[i].Mention(), expected[i])+"\n"+ballotsCast+". "+fmt.Sprintf(vote.MsgMinutesRemaining, 30))
+	}
+
+	// Round 1: pineapple 2, mushrooms 1, anchovies 2. Mushrooms is eliminated.
+	// Round 2: pineapple 3, anchovies 2.
+	runner.ElapseTime(testutil.MainChannelID, vote.VoteDuration, strings.Join([]string{
+		fmt.Sprintf(vote.MsgPollConcluded, users[0].Mention(), "topping?"),
+		fmt.Sprintf(vote.MsgPollWinner, "pineapple"),
+		fmt.Sprintf(vote.MsgPollOptionCount, 1, "pineapple", 3),
+		fmt.Sprintf(vote.MsgPollOptionCount, 3, "anchovies", 2),
+		fmt.Sprintf(vote.MsgPollRounds, 2),
+	}, "\n"))
+}
+
+func TestVote_History(t *testing.T) {
+	config := config.NewConfig()
+	config.VoteMaxOpen = 1
+	runner := testutil.NewRunnerWithConfig(t, &config)
+
+	author := testutil.NewUser("author", 0 /* id */, false /* bot */)
+	runner.AddUser(author)
+
+	// Wrong call format
+	runner.SendMessageAs(author, testutil.MainChannelID, "?votehistory 0", vote.MsgHelpHistory)
+	runner.SendMessageAs(author, testutil.MainChannelID, "?votehistory ten", vote.MsgHelpHistory)
+	runner.SendMessageAs(author, testutil.MainChannelID, "?votehistory 1 2", vote.MsgHelpHistory)
+
+	runner.SendMessageAs(author, testutil.MainChannelID, "?votehistory", vote.MsgNoVoteHistory)
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote show 1", fmt.Sprintf(vote.MsgVoteNotFound, 1))
+
+	// A concluded poll, a concluded vote, and an active vote.
+	runner.SendMessageAs(author, testutil.MainChannelID, "?poll 10m lunch? | tacos | pizza",
+		fmt.Sprintf(vote.MsgBroadcastNewPoll, author.Mention(), "lunch?", "1. tacos\n2. pizza", fmt.Sprintf(vote.MsgMinutesRemaining, 10)))
+	runner.SendMessageAs(author, testutil.MainChannelID, "?pick 2",
+		fmt.Sprintf(vote.MsgPicked, author.Mention(), "pizza")+"\n"+vote.MsgOneBallotCast+". "+fmt.Sprintf(vote.MsgMinutesRemaining, 10))
+	runner.ElapseTime(testutil.MainChannelID, time.Duration(10)*time.Minute, strings.Join([]string{
+		fmt.Sprintf(vote.MsgPollConcluded, author.Mention(), "lunch?"),
+		fmt.Sprintf(vote.MsgPollWinner, "pizza"),
+		fmt.Sprintf(vote.MsgPollOptionCount, 1, "tacos", 0),
+		fmt.Sprintf(vote.MsgPollOptionCount, 2, "pizza", 1),
+	}, "\n"))
+	runner.SendVoteMessageAs(author, testutil.MainChannelID)
+	runner.CastBallotAs(author, testutil.MainChannelID, true /* inFavor */)
+	runner.ExpireVote(testutil.MainChannelID)
+	runner.SendVoteMessageAs(author, testutil.MainChannelID)
+
+	votesNeeded := fmt.Sprintf(vote.MsgStatusVotesNeeded, model.DefaultVoteQuorum)
+	noBallots := fmt.Sprintf(vote.MsgVotesFor, 0) + ", " + fmt.Sprintf(vote.MsgVotesAgainst, 0)
+	runner.SendMessageAs(author, testutil.MainChannelID, "?votehistory", strings.Join([]string{
+		vote.MsgVoteHistory,
+		fmt.Sprintf(vote.MsgVoteHistoryEntry, 3, "a vote has been called", "author", votesNeeded+". "+noBallots+". "+fmt.Sprintf(vote.MsgMinutesRemaining, 30)),
+		fmt.Sprintf(vote.MsgVoteHistoryEntry, 2, "a vote has been called", "author", vote.MsgStatusInconclusive+" "+vote.MsgOneVoteFor+", "+fmt.Sprintf(vote.MsgVotesAgainst, 0)),
+		fmt.Sprintf(vote.MsgVoteHistoryEntry, 1, "lunch?", "author", fmt.Sprintf(vote.MsgPollWinner, "pizza")+". "+vote.MsgOneBallotCast),
+	}, "\n"))
+	runner.SendMessageAs(author, testutil.MainChannelID, "?votehistory  1", strings.Join([]string{
+		vote.MsgVoteHistory,
+		fmt.Sprintf(vote.MsgVoteHistoryEntry, 3, "a vote has been called", "author", votesNeeded+". "+noBallots+". "+fmt.Sprintf(vote.MsgMinutesRemaining, 30)),
+	}, "\n"))
+	runner.SendMessageAs(author, testutil.SecondChannelID, "?votehistory", vote.MsgNoVoteHistory)
+
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote show 2", strings.Join([]string{
+		fmt.Sprintf(vote.MsgShowVote, 2, "author") + "a vote has been called",
+		vote.MsgSpacer,
+		vote.MsgStatusInconclusive + " " + vote.MsgOneVoteFor + ", " + fmt.Sprintf(vote.MsgVotesAgainst, 0),
+	}, "\n"))
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote  show  1", strings.Join([]string{
+		fmt.Sprintf(vote.MsgShowPoll, 1, "author") + "lunch?",
+		vote.MsgSpacer,
+		fmt.Sprintf(vote.MsgPollWinner, "pizza"),
+		fmt.Sprintf(vote.MsgPollOptionCount, 1, "tacos", 0),
+		fmt.Sprintf(vote.MsgPollOptionCount, 2, "pizza", 1),
+		vote.MsgOneBallotCast,
+	}, "\n"))
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote show 3", strings.Join([]string{
+		fmt.Sprintf(vote.MsgShowVote, 3, "author") + "a vote has been called",
+		vote.MsgSpacer,
+		votesNeeded + ". " + noBallots + ". " + fmt.Sprintf(vote.MsgMinutesRemaining, 30),
+	}, "\n"))
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote show 4", fmt.Sprintf(vote.MsgVoteNotFound, 4))
+
+	// Anything other than an ID is a vote about showing something.
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote show me the money", fmt.Sprintf(vote.MsgTooManyVotes, 1))
+}
+
+func TestVote_LongHistoryUsesGist(t *testing.T) {
+	runner := testutil.NewRunner(t)
+
+	author := testutil.NewUser("author", 0 /* id */, false /* bot */)
+	runner.AddUser(author)
+
+	modelHelper := vote.NewModelHelper(runner.VoteMap, runner.UTCClock, "" /* ballotSecret */)
+	for i := 0; i < 50; i++ {
+		if _, err := modelHelper.StartNewVote(testutil.MainChannelID, 0 /* userID */, strings.Repeat("long vote ", 10), model.VoteOptions{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := modelHelper.SetVoteOutcome(testutil.MainChannelID, model.VoteOutcomeNotEnough); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	runner.GistsCount++
+	runner.SendMessageAs(author, testutil.MainChannelID, "?votehistory 50", vote.MsgGistHistoryAddress+": "+testutil.GistSuccessURL)
+	if !strings.HasPrefix(runner.Gist.Messages[0], vote.MsgVoteHistory+"\n"+fmt.Sprintf(vote.MsgVoteHistoryEntry, 50, strings.Repeat("long vote ", 10), "author", "")) {
+		t.Errorf("Expected the history to be uploaded, got %v", runner.Gist.Messages[0])
+	}
+}
+
+func TestVote_CancelAndEnd(t *testing.T) {
+	config := config.NewConfig()
+	config.Moderators = []model.Snowflake{9}
+	runner := testutil.NewRunnerWithConfig(t, &config)
+
+	author := testutil.NewUser("author", 0 /* id */, false /* bot */)
+	other := testutil.NewUser("other", 1 /* id */, false /* bot */)
+	moderator := testutil.NewUser("moderator", 9 /* id */, false /* bot */)
+	for _, user := range []*discordgo.User{author, other, moderator} {
+		runner.AddUser(user)
+	}
+
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote cancel", vote.MsgNoActiveVote)
+	runner.SendMessageAs(moderator, testutil.MainChannelID, "?vote end", vote.MsgNoActiveVote)
+
+	// Only the initiator or a moderator can cancel.
+	runner.SendVoteMessageAs(author, testutil.MainChannelID)
+	runner.SendMessageAs(other, testutil.MainChannelID, "?vote cancel", vote.MsgCancelNotAllowed)
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote end", fmt.Sprintf(app.MsgModeratorOnly, "?vote"))
+	runner.ActiveVoteDataMap[testutil.MainChannelID] = nil
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote cancel",
+		fmt.Sprintf(vote.MsgVoteCancelled, author.Mention(), "a vote has been called"))
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote cancel", vote.MsgNoActiveVote)
+
+	// A new vote isn't concluded by the cancelled vote's timer.
+	runner.ElapseTime(testutil.MainChannelID, time.Duration(10)*time.Minute)
+	runner.SendVoteMessageAs(other, testutil.MainChannelID)
+	runner.ElapseTime(testutil.MainChannelID, time.Duration(20)*time.Minute)
+	runner.ActiveVoteDataMap[testutil.MainChannelID] = nil
+	runner.SendMessageAs(moderator, testutil.MainChannelID, "?vote cancel",
+		fmt.Sprintf(vote.MsgVoteCancelled, moderator.Mention(), "a vote has been called"))
+
+	// Moderators can conclude votes early, and the timer doesn't re-announce them.
+	runner.SendVoteMessageAs(author, testutil.MainChannelID)
+	runner.CastBallotAs(other, testutil.MainChannelID, true /* inFavor */)
+	runner.ActiveVoteDataMap[testutil.MainChannelID] = nil
+	runner.SendMessageAs(moderator, testutil.MainChannelID, "?vote end",
+		fmt.Sprintf(vote.MsgVoteConcluded, author.Mention())+"\n"+vote.MsgStatusInconclusive+" "+vote.MsgOneVoteFor+", "+fmt.Sprintf(vote.MsgVotesAgainst, 0))
+	runner.ElapseTime(testutil.MainChannelID, vote.VoteDuration)
+
+	// Polls can be cancelled too. The runner doesn't track polls.
+	delete(runner.ActiveVoteDataMap, testutil.MainChannelID)
+	runner.SendMessageAs(author, testutil.MainChannelID, "?poll lunch? | tacos | pizza",
+		fmt.Sprintf(vote.MsgBroadcastNewPoll, author.Mention(), "lunch?", "1. tacos\n2. pizza", fmt.Sprintf(vote.MsgMinutesRemaining, 30)))
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote cancel", fmt.Sprintf(vote.MsgPollCancelled, author.Mention(), "lunch?"))
+	runner.ElapseTime(testutil.MainChannelID, vote.VoteDuration)
+
+	noBallots := fmt.Sprintf(vote.MsgVotesFor, 0) + ", " + fmt.Sprintf(vote.MsgVotesAgainst, 0)
+	runner.SendMessageAs(author, testutil.MainChannelID, "?votehistory", strings.Join([]string{
+		vote.MsgVoteHistory,
+		fmt.Sprintf(vote.MsgVoteHistoryEntry, 4, "lunch?", "author", vote.MsgStatusPollCancelled+" "+fmt.Sprintf(vote.MsgBallotsCast, 0)),
+		fmt.Sprintf(vote.MsgVoteHistoryEntry, 3, "a vote has been called", "author", vote.MsgStatusInconclusive+" "+vote.MsgOneVoteFor+", "+fmt.Sprintf(vote.MsgVotesAgainst, 0)),
+		fmt.Sprintf(vote.MsgVoteHistoryEntry, 2, "a vote has been called", "other", vote.MsgStatusVoteCancelled+" "+noBallots),
+		fmt.Sprintf(vote.MsgVoteHistoryEntry, 1, "a vote has been called", "author", vote.MsgStatusVoteCancelled+" "+noBallots),
+	}, "\n"))
+}
+
+func TestVote_Reactions(t *testing.T) {
+	runner := testutil.NewRunner(t)
+
+	author := testutil.NewUser("author", 0 /* id */, false /* bot */)
+	voter := testutil.NewUser("voter", 1 /* id */, false /* bot */)
+	bot := testutil.NewUser("bot", 10 /* id */, true /* bot */)
+	for _, user := range []*discordgo.User{author, voter, bot} {
+		runner.AddUser(user)
+	}
+
+	runner.SendVoteMessageAs(author, testutil.MainChannelID)
+	messageIDs := runner.DiscordSession.MessageIDs
+	announcementID := messageIDs[len(messageIDs)-1]
+	if !reflect.DeepEqual(runner.DiscordSession.Reactions, []*testutil.Reaction{
+		{Channel: testutil.MainChannelID.Format(), MessageID: announcementID, Emoji: vote.ReactionInFavor},
+		{Channel: testutil.MainChannelID.Format(), MessageID: announcementID, Emoji: vote.ReactionAgainst},
+	}) {
+		t.Errorf("Expected ballot reactions on the announcement")
+	}
+
+	activeVote := runner.ActiveVoteDataMap[testutil.MainChannelID]
+	statusLine := func() string {
+		return vote.StatusLine(runner.UTCClock, activeVote.Reconstruct())
+	}
+
+	// Other reactions, other messages, and bots are ignored.
+	runner.ReactAs(voter, testutil.MainChannelID, announcementID, "🍕", true /* added */)
+	runner.ReactAs(voter, testutil.MainChannelID, "12345", vote.ReactionInFavor, true /* added */)
+	runner.ReactAs(bot, testutil.MainChannelID, announcementID, vote.ReactionInFavor, true /* added */)
+
+	activeVote.VotesFor = []model.Snowflake{1}
+	runner.ReactAs(voter, testutil.MainChannelID, announcementID, vote.ReactionInFavor, true, /* added */
+		fmt.Sprintf(vote.MsgVotedInFavor, voter.Mention())+"\n"+statusLine())
+	activeVote.VotesFor = []model.Snowflake{}
+	activeVote.VotesAgainst = []model.Snowflake{1}
+	runner.ReactAs(voter, testutil.MainChannelID, announcementID, vote.ReactionAgainst, true, /* added */
+		fmt.Sprintf(vote.MsgChangedVoteAgainst, voter.Mention())+"\n"+statusLine())
+
+	// Removing the stale reaction doesn't change the ballot, but removing the
+	// current one retracts it.
+	runner.ReactAs(voter, testutil.MainChannelID, announcementID, vote.ReactionInFavor, false /* added */)
+	activeVote.VotesAgainst = []model.Snowflake{}
+	runner.ReactAs(voter, testutil.MainChannelID, announcementID, vote.ReactionAgainst, false, /* added */
+		fmt.Sprintf(vote.MsgRetracted, voter.Mention())+"\n"+statusLine())
+
+	// Reactions that match a typed ballot are ignored.
+	runner.CastBallotAs(author, testutil.MainChannelID, true /* inFavor */)
+	runner.ReactAs(author, testutil.MainChannelID, announcementID, vote.ReactionInFavor, true /* added */)
+
+	// Reactions to concluded votes are ignored.
+	runner.ExpireVote(testutil.MainChannelID)
+	runner.ReactAs(voter, testutil.MainChannelID, announcementID, vote.ReactionInFavor, true /* added */)
+}
+
+func TestVote_ReactionsPolicy(t *testing.T) {
+	policies := []config.CommandPolicy{
+		{Command: "?yes", DenyUsers: []model.Snowflake{1}},
+	}
+	config := config.NewConfig()
+	config.CommandPolicies = policies
+	runner := testutil.NewRunnerWithConfig(t, &config)
+
+	author := testutil.NewUser("author", 0 /* id */, false /* bot */)
+	voter := testutil.NewUser("voter", 1 /* id */, false /* bot */)
+	for _, user := range []*discordgo.User{author, voter} {
+		runner.AddUser(user)
+	}
+
+	runner.SendVoteMessageAs(author, testutil.MainChannelID)
+	messageIDs := runner.DiscordSession.MessageIDs
+	announcementID := messageIDs[len(messageIDs)-1]
+	activeVote := runner.ActiveVoteDataMap[testutil.MainChannelID]
+
+	// Reactions follow the policy of the ballot that they stand for.
+	runner.ReactAs(voter, testutil.MainChannelID, announcementID, vote.ReactionInFavor, true, /* added */
+		fmt.Sprintf(permissions.MsgPermissionDenied, "?yes", "?yes"))
+	activeVote.VotesAgainst = []model.Snowflake{1}
+	runner.ReactAs(voter, testutil.MainChannelID, announcementID, vote.ReactionAgainst, true, /* added */
+		fmt.Sprintf(vote.MsgVotedAgainst, voter.Mention())+"\n"+vote.StatusLine(runner.UTCClock, activeVote.Reconstruct()))
+}
+
+func TestVote_ReactionsLocked(t *testing.T) {
+	runner := testutil.NewRunner(t)
+
+	author := testutil.NewUser("author", 0 /* id */, false /* bot */)
+	runner.AddUser(author)
+
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote --locked formal motion",
+		fmt.Sprintf(vote.MsgBroadcastNewVote, author.Mention(), "formal motion", fmt.Sprintf(vote.MsgMinutesRemaining, 30))+"\n"+vote.MsgBallotsFinal)
+	messageIDs := runner.DiscordSession.MessageIDs
+	announcementID := messageIDs[len(messageIDs)-1]
+
+	runner.ReactAs(author, testutil.MainChannelID, announcementID, vote.ReactionAgainst, true, /* added */
+		fmt.Sprintf(vote.MsgVotedAgainst, author.Mention())+"\n"+
+			fmt.Sprintf(vote.MsgStatusVotesNeeded, model.DefaultVoteQuorum)+". "+fmt.Sprintf(vote.MsgVotesFor, 0)+", "+vote.MsgOneVoteAgainst+". "+fmt.Sprintf(vote.MsgMinutesRemaining, 30))
+	runner.ReactAs(author, testutil.MainChannelID, announcementID, vote.ReactionInFavor, true /* added */, vote.MsgBallotsLocked)
+	runner.ReactAs(author, testutil.MainChannelID, announcementID, vote.ReactionAgainst, false /* added */, vote.MsgBallotsLocked)
+}
+
+func TestVote_SecretNeedsBallotSecret(t *testing.T) {
+	runner := testutil.NewRunner(t)
+
+	author := testutil.NewUser("author", 0 /* id */, false /* bot */)
+	runner.AddUser(author)
+
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote --secret raise dues?", vote.MsgSecretVotesDisabled)
+}
+
+func TestVote_Secret(t *testing.T) {
+	config := config.NewConfig()
+	config.RickList = []model.Snowflake{2}
+	config.VoteBallotSecret = "ballot secret"
+	runner := testutil.NewRunnerWithConfig(t, &config)
+
+	author := testutil.NewUser("author", 0 /* id */, false /* bot */)
+	voter := testutil.NewUser("voter", 1 /* id */, false /* bot */)
+	for _, user := range []*discordgo.User{author, voter} {
+		runner.AddUser(user)
+	}
+
+	// Wrong call format
+	runner.SendMessageAs(voter, testutil.DirectMessageID, "?ballot", vote.MsgHelpSecretBallot)
+	runner.SendMessageAs(voter, testutil.DirectMessageID, "?ballot 8675309 yes", vote.MsgHelpSecretBallot)
+	runner.SendMessageAs(voter, testutil.DirectMessageID, "?ballot 8675309-1 maybe", vote.MsgHelpSecretBallot)
+
+	runner.SendMessageAs(voter, testutil.DirectMessageID, "?ballot 8675309-1 yes", vote.MsgNoSecretVote)
+
+	reference := fmt.Sprintf("%v-%d", testutil.MainChannelID, 1)
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote --secret raise dues?",
+		fmt.Sprintf(vote.MsgBroadcastNewSecretVote, author.Mention(), "raise dues?", reference, reference, fmt.Sprintf(vote.MsgMinutesRemaining, 30)))
+	if len(runner.DiscordSession.Reactions) != 0 {
+		t.Errorf("Secret votes should not have ballot reactions")
+	}
+
+	// Ballots can't be cast in public.
+	secretVote := fmt.Sprintf(vote.MsgSecretVote, reference, reference)
+	runner.SendMessageAs(voter, testutil.MainChannelID, "?yes", secretVote)
+	runner.SendMessageAs(voter, testutil.MainChannelID, "?unvote", fmt.Sprintf(vote.MsgSecretRetract, reference))
+	runner.SendMessageAs(voter, testutil.MainChannelID, "?ballot "+reference+" yes", vote.MsgSecretBallotPrivateOnly)
+	runner.SendMessageAs(voter, testutil.DirectMessageID, "?ballot "+testutil.MainChannelID.Format()+"-2 yes", vote.MsgNoSecretVote)
+
+	status := func(votesFor, votesAgainst int) string {
+		votesForStr := fmt.Sprintf(vote.MsgVotesFor, votesFor)
+		if votesFor == 1 {
+			votesForStr = vote.MsgOneVoteFor
+		}
+		votesAgainstStr := fmt.Sprintf(vote.MsgVotesAgainst, votesAgainst)
+		if votesAgainst == 1 {
+			votesAgainstStr = vote.MsgOneVoteAgainst
+		}
+		return fmt.Sprintf(vote.MsgStatusVotesNeeded, model.DefaultVoteQuorum) + ". " + votesForStr + ", " + votesAgainstStr + ". " + fmt.Sprintf(vote.MsgMinutesRemaining, 30)
+	}
+
+	// The voter is told privately, and the channel only sees the totals.
+	sendSecretBallot := func(user *discordgo.User, ballot, reply, announcement string) {
+		t.Helper()
+		runner.SendMessageAsWithResponses(user, testutil.DirectMessageID, "?ballot "+reference+" "+ballot,
+			testutil.NewMessage(testutil.DirectMessageID.Format(), reply),
+			testutil.NewMessage(testutil.MainChannelID.Format(), announcement))
+	}
+	sendSecretBallot(voter, "no", vote.MsgSecretBallotCast, vote.MsgSecretBallotAnnounced+"\n"+status(0, 1))
+	runner.SendMessageAs(voter, testutil.DirectMessageID, "?ballot "+reference+" no", fmt.Sprintf(vote.MsgAlreadyVoted, voter.Mention()))
+	sendSecretBallot(voter, "YES", vote.MsgSecretBallotChanged, vote.MsgSecretBallotChangeAnnounced+"\n"+status(1, 0))
+	sendSecretBallot(author, "yes", vote.MsgSecretBallotCast, vote.MsgSecretBallotAnnounced+"\n"+status(2, 0))
+
+	// Ballots are retracted by direct message too.
+	runner.SendMessageAs(voter, testutil.DirectMessageID, "?unvote", vote.MsgRetractPrivate)
+	runner.SendMessageAsWithResponses(voter, testutil.DirectMessageID, "?unvote "+reference,
+		testutil.NewMessage(testutil.DirectMessageID.Format(), vote.MsgSecretBallotRetracted),
+		testutil.NewMessage(testutil.MainChannelID.Format(), vote.MsgSecretBallotRetractAnnounced+"\n"+status(1, 0)))
+	runner.SendMessageAs(voter, testutil.DirectMessageID, "?unvote "+reference, fmt.Sprintf(vote.MsgNotVoted, voter.Mention()))
+	runner.SendMessageAs(voter, testutil.DirectMessageID, "?unvote "+testutil.MainChannelID.Format()+"-2", vote.MsgNoSecretVote)
+	sendSecretBallot(voter, "yes", vote.MsgSecretBallotCast, vote.MsgSecretBallotAnnounced+"\n"+status(2, 0))
+
+	// Users outside of the vote's guild can't vote, or find out about the vote.
+	outsider := testutil.NewUser("outsider", 5 /* id */, false /* bot */)
+	runner.SendMessageAs(outsider, testutil.DirectMessageID, "?ballot "+reference+" yes", vote.MsgNoSecretVote)
+	runner.SendMessageAs(outsider, testutil.DirectMessageID, "?unvote "+reference, vote.MsgNoSecretVote)
+
+	runner.SendMessageAs(author, testutil.MainChannelID, "?votestatus", strings.Join([]string{
+		fmt.Sprintf(vote.MsgVoteOwner, "author") + "raise dues?",
+		vote.MsgSpacer,
+		status(2, 0),
+	}, "\n"))
+
+	// Only hashes of the voters are stored, and they are keyed with the ballot
+	// secret.
+	storedVote, err := vote.NewModelHelper(runner.VoteMap, runner.UTCClock, "" /* ballotSecret */).MostRecentVote(testutil.MainChannelID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(storedVote.VotesFor) != 0 || len(storedVote.VotesAgainst) != 0 || len(storedVote.SecretBallots) != 2 {
+		t.Errorf("Expected only secret ballots, got %v", storedVote)
+	}
+	for _, ballot := range storedVote.SecretBallots {
+		if ballot.VoterHash == "0" || ballot.VoterHash == "1" || len(ballot.VoterHash) != 64 {
+			t.Errorf("Expected a hashed voter, got %v", ballot.VoterHash)
+		}
+	}
+	if voted, _ := storedVote.Ballot("", 1); voted {
+		t.Errorf("Expected the ballots to be keyed with the ballot secret")
+	}
+	if voted, inFavor := storedVote.Ballot(config.VoteBallotSecret, 1); !voted || !inFavor {
+		t.Errorf("Expected the ballot secret to find the voter's ballot")
+	}
+
+	// Open votes don't take secret ballots.
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote cancel",
+		fmt.Sprintf(vote.MsgVoteCancelled, author.Mention(), "raise dues?"))
+	runner.SendVoteMessageAs(author, testutil.MainChannelID)
+	runner.SendMessageAs(voter, testutil.DirectMessageID, "?ballot "+testutil.MainChannelID.Format()+"-2 yes", vote.MsgNotSecret)
+	runner.SendMessageAs(voter, testutil.DirectMessageID, "?unvote "+testutil.MainChannelID.Format()+"-2", vote.MsgNotSecretRetract)
+}
+
+func TestVote_ActionLimits(t *testing.T) {
+	policies := []config.CommandPolicy{
+		{Command: "?unlearn", AllowUsers: []model.Snowflake{1}},
+	}
+	config := config.NewConfig()
+	config.VoteActionMinQuorum = 3
+	config.CommandPolicies = policies
+	runner := testutil.NewRunnerWithConfig(t, &config)
+
+	author := testutil.NewUser("author", 0 /* id */, false /* bot */)
+	runner.AddUser(author)
+	runner.SendLearnMessage(testutil.MainChannelID, "?learn annoying beep", testutil.NewLearnData("annoying", "beep"))
+
+	// Votes on actions can't be passed by a handful of voters.
+	runner.SendMessage(testutil.MainChannelID, "?vote --quorum 2 action unlearn ?annoying", fmt.Sprintf(vote.MsgVoteActionQuorum, 3))
+	runner.SendMessage(testutil.MainChannelID, "?vote --quorum 2 raise dues?",
+		fmt.Sprintf(vote.MsgBroadcastNewVote, "<@1>", "raise dues?", fmt.Sprintf(vote.MsgMinutesRemaining, 30))+"\n"+fmt.Sprintf(vote.MsgVoteRulesQuorum, 2))
+
+	// Only users who can run the action's command can call a vote on it.
+	runner.SendMessageAs(author, testutil.SecondChannelID, "?vote action unlearn ?annoying", fmt.Sprintf(permissions.MsgPermissionDenied, "?unlearn", "?unlearn"))
+}
+
+func TestVote_Action(t *testing.T) {
+	runner := testutil.NewRunner(t)
+
+	users := []*discordgo.User{
+		testutil.NewUser("user0", 0 /* id */, false /* bot */),
+		testutil.NewUser("user1", 1 /* id */, false /* bot */),
+		testutil.NewUser("user2", 2 /* id */, false /* bot */),
+		testutil.NewUser("user3", 3 /* id */, false /* bot */),
+		testutil.NewUser("user4", 4 /* id */, false /* bot */),
+	}
+	for _, user := range users {
+		runner.AddUser(user)
+	}
+	author := users[0]
+
+	runner.SendLearnMessage(testutil.MainChannelID, "?learn annoying beep", testutil.NewLearnData("annoying", "beep"))
+	runner.SendLearnMessage(testutil.MainChannelID, "?learn fleeting boop", testutil.NewLearnData("fleeting", "boop"))
+
+	// Only learned commands can be voted on.
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote action unlearn help",
+		fmt.Sprintf(vote.MsgInvalidAction, fmt.Sprintf(learn.MsgUnlearnFail, "help")))
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote action unlearn missing",
+		fmt.Sprintf(vote.MsgInvalidAction, fmt.Sprintf(learn.MsgUnlearnFail, "missing")))
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote action unlearn",
+		fmt.Sprintf(vote.MsgInvalidAction, learn.MsgHelpUnlearnAction))
+
+	// A passing vote carries out its action.
+	startAction := func(call string) {
+		description := fmt.Sprintf(learn.MsgUnlearnActionDescription, call)
+		runner.ActiveVoteDataMap[testutil.MainChannelID] = &testutil.VoteData{
+			Channel:      testutil.MainChannelID,
+			Author:       author,
+			Message:      description,
+			VotesFor:     []model.Snowflake{},
+			VotesAgainst: []model.Snowflake{},
+			TimestampEnd: runner.UTCClock.Now().Add(vote.VoteDuration),
+		}
+		runner.SendMessageAs(author, testutil.MainChannelID, "?vote action unlearn ?"+call,
+			fmt.Sprintf(vote.MsgBroadcastNewVote, author.Mention(), description, fmt.Sprintf(vote.MsgMinutesRemaining, 30))+"\n"+vote.MsgVoteAction)
+	}
+	passed := fmt.Sprintf(vote.MsgVoteConcluded, author.Mention()) + "\n" + vote.MsgStatusVotePassed + " " + fmt.Sprintf(vote.MsgVotesFor, 5) + ", " + fmt.Sprintf(vote.MsgVotesAgainst, 0)
+
+	startAction("annoying")
+	for _, user := range users {
+		runner.CastBallotAs(user, testutil.MainChannelID, true /* inFavor */)
+	}
+	runner.ActiveVoteDataMap[testutil.MainChannelID] = nil
+	delete(runner.LearnDataMap, "annoying")
+	runner.ElapseTime(testutil.MainChannelID, vote.VoteDuration,
+		passed, fmt.Sprintf(vote.MsgActionExecuted, fmt.Sprintf(learn.MsgUnlearnSuccess, "annoying")))
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote show 1", strings.Join([]string{
+		fmt.Sprintf(vote.MsgShowVote, 1, "user0") + fmt.Sprintf(learn.MsgUnlearnActionDescription, "annoying"),
+		vote.MsgSpacer,
+		vote.MsgStatusVotePassed + " " + fmt.Sprintf(vote.MsgVotesFor, 5) + ", " + fmt.Sprintf(vote.MsgVotesAgainst, 0),
+		fmt.Sprintf(vote.MsgActionStatusExecuted, "unlearn ?annoying", fmt.Sprintf(learn.MsgUnlearnSuccess, "annoying")),
+	}, "\n"))
+
+	// The action fails if it can't be carried out by the time the vote passes.
+	startAction("fleeting")
+	for _, user := range users {
+		runner.CastBallotAs(user, testutil.MainChannelID, true /* inFavor */)
+	}
+	runner.SendUnlearnMessage(testutil.MainChannelID, "?unlearn fleeting", "fleeting")
+	runner.ActiveVoteDataMap[testutil.MainChannelID] = nil
+	runner.ElapseTime(testutil.MainChannelID, vote.VoteDuration,
+		passed, fmt.Sprintf(vote.MsgActionFailed, fmt.Sprintf(learn.MsgUnlearnFail, "fleeting")))
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote show 2", strings.Join([]string{
+		fmt.Sprintf(vote.MsgShowVote, 2, "user0") + fmt.Sprintf(learn.MsgUnlearnActionDescription, "fleeting"),
+		vote.MsgSpacer,
+		vote.MsgStatusVotePassed + " " + fmt.Sprintf(vote.MsgVotesFor, 5) + ", " + fmt.Sprintf(vote.MsgVotesAgainst, 0),
+		fmt.Sprintf(vote.MsgActionStatusFailed, "unlearn ?fleeting", fmt.Sprintf(learn.MsgUnlearnFail, "fleeting")),
+	}, "\n"))
+
+	// Votes that don't pass leave the command alone.
+	runner.SendLearnMessage(testutil.MainChannelID, "?learn annoying beep", testutil.NewLearnData("annoying", "beep"))
+	startAction("annoying")
+	runner.ExpireVote(testutil.MainChannelID)
+	runner.SendMessage(testutil.MainChannelID, "?annoying", "beep")
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote show 3", strings.Join([]string{
+		fmt.Sprintf(vote.MsgShowVote, 3, "user0") + fmt.Sprintf(learn.MsgUnlearnActionDescription, "annoying"),
+		vote.MsgSpacer,
+		vote.MsgStatusInconclusive + " " + fmt.Sprintf(vote.MsgVotesFor, 0) + ", " + fmt.Sprintf(vote.MsgVotesAgainst, 0),
+		fmt.Sprintf(vote.MsgActionStatusNotExecuted, "unlearn ?annoying"),
+	}, "\n"))
+
+	// Unregistered actions are ordinary votes.
+	runner.ActiveVoteDataMap[testutil.MainChannelID] = &testutil.VoteData{}
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote action movies are the best",
+		fmt.Sprintf(vote.MsgBroadcastNewVote, author.Mention(), "action movies are the best", fmt.Sprintf(vote.MsgMinutesRemaining, 30)))
+}
+
+func TestVote_Reminders(t *testing.T) {
+	config := config.NewConfig()
+	config.VoteReminderMinutes = []int{10, 1, 0}
+	runner := testutil.NewRunnerWithConfig(t, &config)
+
+	users := []*discordgo.User{
+		testutil.NewUser("user0", 0 /* id */, false /* bot */),
+		testutil.NewUser("user1", 1 /* id */, false /* bot */),
+		testutil.NewUser("user2", 2 /* id */, false /* bot */),
+		testutil.NewUser("user3", 3 /* id */, false /* bot */),
+		testutil.NewUser("user4", 4 /* id */, false /* bot */),
+	}
+	for _, user := range users {
+		runner.AddUser(user)
+	}
+	author := users[0]
+	reminder := func(status string, remaining string) string {
+		return fmt.Sprintf(vote.MsgVoteReminder, author.Mention(), "a vote has been called", status+". "+remaining)
+	}
+
+	runner.SendVoteMessageAs(author, testutil.MainChannelID)
+	runner.CastBallotAs(users[1], testutil.MainChannelID, true /* inFavor */)
+	runner.ElapseTime(testutil.MainChannelID, time.Duration(20)*time.Minute,
+		reminder(fmt.Sprintf(vote.MsgStatusVotesNeeded, 5)+". "+vote.MsgOneVoteFor+", "+fmt.Sprintf(vote.MsgVotesAgainst, 0), fmt.Sprintf(vote.MsgMinutesRemaining, 10))+"\n"+fmt.Sprintf(vote.MsgBallotsNeeded, 4))
+	for _, user := range users[2:] {
+		runner.CastBallotAs(user, testutil.MainChannelID, true /* inFavor */)
+	}
+	runner.ElapseTime(testutil.MainChannelID, time.Duration(8)*time.Minute)
+	runner.ElapseTime(testutil.MainChannelID, time.Duration(1)*time.Minute,
+		reminder(fmt.Sprintf(vote.MsgStatusVotesNeeded, 5)+". "+fmt.Sprintf(vote.MsgVotesFor, 4)+", "+fmt.Sprintf(vote.MsgVotesAgainst, 0), fmt.Sprintf(vote.MsgSecondsRemaining, 60))+"\n"+vote.MsgOneBallotNeeded)
+	runner.ExpireVote(testutil.MainChannelID)
+
+	// Reminders that would come before the vote starts are skipped.
+	runner.ActiveVoteDataMap[testutil.MainChannelID] = &testutil.VoteData{}
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote 5m short vote",
+		fmt.Sprintf(vote.MsgBroadcastNewVote, author.Mention(), "short vote", fmt.Sprintf(vote.MsgMinutesRemaining, 5)))
+	runner.ElapseTime(testutil.MainChannelID, time.Duration(4)*time.Minute,
+		fmt.Sprintf(vote.MsgVoteReminder, author.Mention(), "short vote", fmt.Sprintf(vote.MsgStatusVotesNeeded, 5)+". "+fmt.Sprintf(vote.MsgVotesFor, 0)+", "+fmt.Sprintf(vote.MsgVotesAgainst, 0)+". "+fmt.Sprintf(vote.MsgSecondsRemaining, 60))+"\n"+fmt.Sprintf(vote.MsgBallotsNeeded, 5))
+	runner.ActiveVoteDataMap[testutil.MainChannelID] = nil
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote cancel", fmt.Sprintf(vote.MsgVoteCancelled, author.Mention(), "short vote"))
+
+	// Votes that concluded early aren't reminded about.
+	runner.SendVoteMessageAs(author, testutil.MainChannelID)
+	runner.ActiveVoteDataMap[testutil.MainChannelID] = nil
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote cancel", fmt.Sprintf(vote.MsgVoteCancelled, author.Mention(), "a vote has been called"))
+	runner.ElapseTime(testutil.MainChannelID, vote.VoteDuration)
+}
+
+func TestVote_Concurrent(t *testing.T) {
+	config := config.NewConfig()
+	config.VoteMaxOpen = 3
+	runner := testutil.NewRunnerWithConfig(t, &config)
+
+	author := testutil.NewUser("author", 0 /* id */, false /* bot */)
+	voter := testutil.NewUser("voter", 1 /* id */, false /* bot */)
+	for _, user := range []*discordgo.User{author, voter} {
+		runner.AddUser(user)
+	}
+	remaining := fmt.Sprintf(vote.MsgMinutesRemaining, 30)
+	status := func(votesFor, votesAgainst string) string {
+		return fmt.Sprintf(vote.MsgStatusVotesNeeded, 5) + ". " + votesFor + ", " + votesAgainst + ". " + remaining
+	}
+	noneFor, noneAgainst := fmt.Sprintf(vote.MsgVotesFor, 0), fmt.Sprintf(vote.MsgVotesAgainst, 0)
+
+	// The first vote works like it always did. The runner only tracks whether
+	// any vote is active.
+	runner.ActiveVoteDataMap[testutil.MainChannelID] = &testutil.VoteData{}
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote pizza",
+		fmt.Sprintf(vote.MsgBroadcastNewVote, author.Mention(), "pizza", remaining))
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote tacos",
+		fmt.Sprintf(vote.MsgBroadcastNewVote, author.Mention(), "tacos", remaining)+"\n"+fmt.Sprintf(vote.MsgVoteID, 2, 2))
+	messageIDs := runner.DiscordSession.MessageIDs
+	tacosID := messageIDs[len(messageIDs)-1]
+
+	// Ballots name the vote once several are active.
+	runner.SendMessageAs(voter, testutil.MainChannelID, "?yes", vote.MsgAmbiguousVote)
+	runner.SendMessageAs(voter, testutil.MainChannelID, "?yes 1",
+		fmt.Sprintf(vote.MsgVotedInFavor, voter.Mention())+"\n"+status(vote.MsgOneVoteFor, noneAgainst))
+	runner.SendMessageAs(voter, testutil.MainChannelID, "?no #2",
+		fmt.Sprintf(vote.MsgVotedAgainst, voter.Mention())+"\n"+status(noneFor, vote.MsgOneVoteAgainst))
+	runner.SendMessageAs(voter, testutil.MainChannelID, "?yes 5", vote.MsgNoActiveVote)
+	runner.SendMessageAs(voter, testutil.MainChannelID, "?unvote", vote.MsgAmbiguousVote)
+	runner.SendMessageAs(voter, testutil.MainChannelID, "?unvote 2",
+		fmt.Sprintf(vote.MsgRetracted, voter.Mention())+"\n"+status(noneFor, noneAgainst))
+	runner.ReactAs(voter, testutil.MainChannelID, tacosID, vote.ReactionInFavor, true, /* added */
+		fmt.Sprintf(vote.MsgVotedInFavor, voter.Mention())+"\n"+status(vote.MsgOneVoteFor, noneAgainst))
+
+	// Polls share the vote IDs.
+	runner.SendMessageAs(author, testutil.MainChannelID, "?poll lunch? | soup | salad",
+		fmt.Sprintf(vote.MsgBroadcastNewPoll, author.Mention(), "lunch?", "1. soup\n2. salad", remaining)+"\n"+fmt.Sprintf(vote.MsgPollID, "?pick", 3))
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote too many", fmt.Sprintf(vote.MsgTooManyVotes, 3))
+	runner.SendMessageAs(voter, testutil.MainChannelID, "?pick 1", vote.MsgAmbiguousVote)
+	runner.SendMessageAs(voter, testutil.MainChannelID, "?pick #2 1", vote.MsgNoActivePoll)
+	runner.SendMessageAs(voter, testutil.MainChannelID, "?pick #3 1",
+		fmt.Sprintf(vote.MsgPicked, voter.Mention(), "soup")+"\n"+vote.MsgOneBallotCast+". "+remaining)
+
+	// ?votestatus lists the active votes, or shows the one that is named.
+	runner.SendMessageAs(voter, testutil.MainChannelID, "?votestatus", strings.Join([]string{
+		vote.MsgActiveVotes,
+		fmt.Sprintf(vote.MsgVoteHistoryEntry, 1, "pizza", "author", status(vote.MsgOneVoteFor, noneAgainst)),
+		fmt.Sprintf(vote.MsgVoteHistoryEntry, 2, "tacos", "author", status(vote.MsgOneVoteFor, noneAgainst)),
+		fmt.Sprintf(vote.MsgVoteHistoryEntry, 3, "lunch?", "author", vote.MsgOneBallotCast+". "+remaining),
+		vote.MsgActiveVotesFooter,
+	}, "\n"))
+	runner.SendMessageAs(voter, testutil.MainChannelID, "?votestatus 2", strings.Join([]string{
+		fmt.Sprintf(vote.MsgVoteOwner, "author") + "tacos",
+		vote.MsgSpacer,
+		status(vote.MsgOneVoteFor, noneAgainst),
+	}, "\n"))
+
+	// Cancelling and ending name the vote too.
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote cancel", vote.MsgAmbiguousVote)
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote cancel 3", fmt.Sprintf(vote.MsgPollCancelled, author.Mention(), "lunch?"))
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote cancel #3", vote.MsgNoActiveVote)
+
+	// Each vote concludes on its own timer.
+	runner.ActiveVoteDataMap[testutil.MainChannelID] = nil
+	inconclusive := vote.MsgStatusInconclusive + " " + vote.MsgOneVoteFor + ", " + noneAgainst
+	runner.ElapseTime(testutil.MainChannelID, vote.VoteDuration,
+		fmt.Sprintf(vote.MsgVoteConcluded, author.Mention())+"\n"+inconclusive,
+		fmt.Sprintf(vote.MsgVoteConcluded, author.Mention())+"\n"+inconclusive)
+}
+
+func TestVote_Weighted(t *testing.T) {
+	roleWeights := []config.VoteRoleWeight{{RoleID: 42, Weight: 3}}
+	karmaTiers := []config.VoteKarmaTier{{MinKarma: 2, Weight: 2}}
+	config := config.NewConfig()
+	config.VoteRoleWeights = roleWeights
+	config.VoteKarmaTiers = karmaTiers
+	runner := testutil.NewRunnerWithConfig(t, &config)
+
+	author := testutil.NewUser("author", 0 /* id */, false /* bot */)
+	maintainer := testutil.NewUser("maintainer", 1 /* id */, false /* bot */)
+	voter := testutil.NewUser("voter", 3 /* id */, false /* bot */)
+	for _, user := range []*discordgo.User{author, maintainer, voter} {
+		runner.AddUser(user)
+	}
+	runner.DiscordSession.SetMember(testutil.MainGuildID.Format(), &discordgo.Member{User: maintainer, Roles: []string{"42"}})
+	remaining := fmt.Sprintf(vote.MsgMinutesRemaining, 30)
+
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote --weight stars merge it", fmt.Sprintf(vote.MsgUnknownWeighting, "karma, roles"))
+
+	// Maintainers count triple.
+	runner.ActiveVoteDataMap[testutil.MainChannelID] = &testutil.VoteData{}
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote --quorum 2 --weight roles merge it", strings.Join([]string{
+		fmt.Sprintf(vote.MsgBroadcastNewVote, author.Mention(), "merge it", remaining),
+		fmt.Sprintf(vote.MsgVoteRulesQuorum, 2),
+		fmt.Sprintf(vote.MsgVoteWeighted, vote.WeightingRoles),
+	}, "\n"))
+	runner.SendMessageAs(author, testutil.MainChannelID, "?no",
+		fmt.Sprintf(vote.MsgVotedAgainst, author.Mention())+"\n"+
+			fmt.Sprintf(vote.MsgStatusVotesNeeded, 2)+". "+fmt.Sprintf(vote.MsgVotesFor, 0)+", "+vote.MsgOneVoteAgainst+" "+fmt.Sprintf(vote.MsgWeightedTally, 0, 1)+". "+remaining)
+	runner.SendMessageAs(maintainer, testutil.MainChannelID, "?yes",
+		fmt.Sprintf(vote.MsgVotedInFavor, maintainer.Mention())+"\n"+
+			vote.MsgStatusVotePassing+". "+vote.MsgOneVoteFor+", "+vote.MsgOneVoteAgainst+" "+fmt.Sprintf(vote.MsgWeightedTally, 3, 1)+". "+remaining)
+	runner.ActiveVoteDataMap[testutil.MainChannelID] = nil
+	runner.ElapseTime(testutil.MainChannelID, vote.VoteDuration,
+		fmt.Sprintf(vote.MsgVoteConcluded, author.Mention())+"\n"+
+			vote.MsgStatusVotePassed+" "+vote.MsgOneVoteFor+", "+vote.MsgOneVoteAgainst+" "+fmt.Sprintf(vote.MsgWeightedTally, 3, 1))
+
+	// Voters with enough karma count double.
+	for i := 1; i <= 2; i++ {
+		runner.SendMessageAs(author, testutil.MainChannelID, "?++ voter", fmt.Sprintf(karma.MsgIncrementKarma, "voter", "voter", i))
+	}
+	runner.ActiveVoteDataMap[testutil.MainChannelID] = &testutil.VoteData{}
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote --weight karma ship it",
+		fmt.Sprintf(vote.MsgBroadcastNewVote, author.Mention(), "ship it", remaining)+"\n"+fmt.Sprintf(vote.MsgVoteWeighted, vote.WeightingKarma))
+	votesNeeded := fmt.Sprintf(vote.MsgStatusVotesNeeded, 5)
+	runner.SendMessageAs(voter, testutil.MainChannelID, "?yes",
+		fmt.Sprintf(vote.MsgVotedInFavor, voter.Mention())+"\n"+
+			votesNeeded+". "+vote.MsgOneVoteFor+", "+fmt.Sprintf(vote.MsgVotesAgainst, 0)+" "+fmt.Sprintf(vote.MsgWeightedTally, 2, 0)+". "+remaining)
+	runner.SendMessageAs(author, testutil.MainChannelID, "?no",
+		fmt.Sprintf(vote.MsgVotedAgainst, author.Mention())+"\n"+
+			votesNeeded+". "+vote.MsgOneVoteFor+", "+vote.MsgOneVoteAgainst+" "+fmt.Sprintf(vote.MsgWeightedTally, 2, 1)+". "+remaining)
+	runner.SendMessageAs(voter, testutil.MainChannelID, "?unvote",
+		fmt.Sprintf(vote.MsgRetracted, voter.Mention())+"\n"+
+			votesNeeded+". "+fmt.Sprintf(vote.MsgVotesFor, 0)+", "+vote.MsgOneVoteAgainst+" "+fmt.Sprintf(vote.MsgWeightedTally, 0, 1)+". "+remaining)
+}
+
+func TestVote_Scheduled(t *testing.T) {
+	config := config.NewConfig()
+	config.VoteMaxOpen = 2
+	runner := testutil.NewRunnerWithConfig(t, &config)
+
+	author := testutil.NewUser("author", 0 /* id */, false /* bot */)
+	runner.AddUser(author)
+	start := runner.UTCClock.Now().Add(time.Hour)
+
+	// Votes can't be scheduled in the past, or too far ahead.
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote at 200h lunch", fmt.Sprintf(vote.MsgVoteScheduleLimits, 168))
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote at 2016-01-01T12:00 lunch", fmt.Sprintf(vote.MsgVoteScheduleLimits, 168))
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote at 1h", vote.MsgHelpVote)
+
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote at 1h --quorum 1 lunch",
+		fmt.Sprintf(vote.MsgBroadcastScheduledVote, author.Mention(), 1, "lunch", vote.StartString(start), 30, 1))
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote at 2017-01-01T02:01Z dinner",
+		fmt.Sprintf(vote.MsgBroadcastScheduledVote, author.Mention(), 2, "dinner", vote.StartString(start), 30, 2))
+
+	// Scheduled votes count toward the limit of open votes.
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote breakfast", fmt.Sprintf(vote.MsgTooManyVotes, 2))
+
+	// Neither vote takes ballots until it opens.
+	runner.SendMessageAs(author, testutil.MainChannelID, "?yes", vote.MsgNoActiveVote)
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote cancel 2", fmt.Sprintf(vote.MsgVoteCancelled, author.Mention(), "dinner"))
+
+	runner.ActiveVoteDataMap[testutil.MainChannelID] = &testutil.VoteData{}
+	remaining := fmt.Sprintf(vote.MsgMinutesRemaining, 30)
+	runner.ElapseTime(testutil.MainChannelID, time.Hour,
+		fmt.Sprintf(vote.MsgBroadcastNewVote, author.Mention(), "lunch", remaining)+"\n"+fmt.Sprintf(vote.MsgVoteRulesQuorum, 1))
+	runner.SendMessageAs(author, testutil.MainChannelID, "?yes",
+		fmt.Sprintf(vote.MsgVotedInFavor, author.Mention())+"\n"+vote.MsgStatusVotePassing+". "+vote.MsgOneVoteFor+", "+fmt.Sprintf(vote.MsgVotesAgainst, 0)+". "+remaining)
+
+	runner.ActiveVoteDataMap[testutil.MainChannelID] = nil
+	runner.ElapseTime(testutil.MainChannelID, vote.VoteDuration,
+		fmt.Sprintf(vote.MsgVoteConcluded, author.Mention())+"\n"+vote.MsgStatusVotePassed+" "+vote.MsgOneVoteFor+", "+fmt.Sprintf(vote.MsgVotesAgainst, 0))
+}
+
+func TestVote_Export(t *testing.T) {
+	runner := testutil.NewRunner(t)
+
+	author := testutil.NewUser("author", 0 /* id */, false /* bot */)
+	runner.AddUser(author)
+
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote export", vote.MsgNoVoteHistory)
+
+	runner.ActiveVoteDataMap[testutil.MainChannelID] = &testutil.VoteData{}
+	remaining := fmt.Sprintf(vote.MsgMinutesRemaining, 30)
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote --quorum 1 lunch, anyone?",
+		fmt.Sprintf(vote.MsgBroadcastNewVote, author.Mention(), "lunch, anyone?", remaining)+"\n"+fmt.Sprintf(vote.MsgVoteRulesQuorum, 1))
+	runner.SendMessageAs(author, testutil.MainChannelID, "?yes",
+		fmt.Sprintf(vote.MsgVotedInFavor, author.Mention())+"\n"+vote.MsgStatusVotePassing+". "+vote.MsgOneVoteFor+", "+fmt.Sprintf(vote.MsgVotesAgainst, 0)+". "+remaining)
+	runner.ActiveVoteDataMap[testutil.MainChannelID] = nil
+	runner.ElapseTime(testutil.MainChannelID, vote.VoteDuration,
+		fmt.Sprintf(vote.MsgVoteConcluded, author.Mention())+"\n"+vote.MsgStatusVotePassed+" "+vote.MsgOneVoteFor+", "+fmt.Sprintf(vote.MsgVotesAgainst, 0))
+
+	exportAddress := vote.MsgGistExportAddress + ": " + testutil.GistSuccessURL
+	runner.GistsCount++
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote export all", exportAddress)
+	expectedCSV := strings.Join([]string{
+		"vote_id,channel_id,message,initiator_id,initiator,timestamp_start,timestamp_end,outcome,votes_for,votes_against,voters_for,voters_against,poll_options,poll_ballots",
+		`1,8675309,"lunch, anyone?",0,author,2017-01-01T01:01:00Z,2017-01-01T01:31:00Z,passed,1,0,0,,,`,
+		"",
+	}, "\n")
+	if actual := runner.Gist.Messages[len(runner.Gist.Messages)-1]; actual != expectedCSV {
+		t.Errorf("Expected export\n%v\ngot\n%v", expectedCSV, actual)
+	}
+
+	runner.GistsCount++
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote export json #1", exportAddress)
+	var exported []vote.ExportedVote
+	if err := json.Unmarshal([]byte(runner.Gist.Messages[len(runner.Gist.Messages)-1]), &exported); err != nil {
+		t.Fatalf("Error parsing JSON export: %v", err)
+	}
+	if len(exported) != 1 || exported[0].Message != "lunch, anyone?" || exported[0].Outcome != "passed" || !reflect.DeepEqual(exported[0].VotersFor, []string{"0"}) {
+		t.Errorf("Unexpected JSON export %+v", exported)
+	}
+
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote export 2", fmt.Sprintf(vote.MsgVoteNotFound, 2))
+	runner.Gist.FailNext = true
+	runner.SendMessageAs(author, testutil.MainChannelID, "?vote export", "gist upload failed")
+}
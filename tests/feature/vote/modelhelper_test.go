@@ -1,6 +1,8 @@
 package vote
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
 	"testing"
 	"time"
@@ -202,11 +204,62 @@ func TestStartNewVote_AddingFromNoMemory(t *testing.T) {
 	assertMostRecentVoteID(t, modelHelper, Channel1, vote.VoteID)
 }
 
-func TestStartNewVote_DoesNotOverrideExistingVote(t *testing.T) {
+func TestStartNewVote_ConcurrentVotes(t *testing.T) {
 	modelHelper, _ := initializeTests()
 
-	assertStartNewVote(t, modelHelper, Channel1, UserID1)
-	assertStartNewVoteFails(t, modelHelper, Channel1, 11)
+	first := assertStartNewVote(t, modelHelper, Channel1, UserID1)
+	second := assertStartNewVote(t, modelHelper, Channel1, 11)
+	if first.VoteID == second.VoteID {
+		t.Fatalf("Concurrent votes should have different IDs")
+	}
+	assertMostRecentVoteID(t, modelHelper, Channel1, second.VoteID)
+	assertOpenVoteIDs(t, modelHelper, Channel1, first.VoteID, second.VoteID)
+
+	// Votes must be addressed by ID while several are active.
+	if _, err := modelHelper.ActiveVote(Channel1, 0); err != vote.ErrorAmbiguousVote {
+		t.Errorf("Expected an ambiguous vote, got %v", err)
+	}
+	if _, err := modelHelper.CastBallot(Channel1, UserID2, true /* inFavor */); err != vote.ErrorAmbiguousVote {
+		t.Errorf("Expected an ambiguous vote, got %v", err)
+	}
+	if _, err := modelHelper.CastBallotByID(Channel1, 3, UserID2, true /* inFavor */, 1 /* weight */); err != vote.ErrorNoVoteActive {
+		t.Errorf("Expected no such vote, got %v", err)
+	}
+	updated, err := modelHelper.CastBallotByID(Channel1, first.VoteID, UserID2, true /* inFavor */, 1 /* weight */)
+	if err != nil || !reflect.DeepEqual(updated.VotesFor, []model.Snowflake{UserID2}) {
+		t.Errorf("Expected the ballot in the first vote, got %v", err)
+	}
+
+	// Once one concludes, the other is the only active vote.
+	if err := modelHelper.SetVoteOutcomeByID(Channel1, second.VoteID, model.VoteOutcomeCancelled); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	assertOpenVoteIDs(t, modelHelper, Channel1, first.VoteID)
+	assertMostRecentVoteID(t, modelHelper, Channel1, second.VoteID)
+	if active, err := modelHelper.ActiveVote(Channel1, 0); err != nil || active.VoteID != first.VoteID {
+		t.Errorf("Expected the first vote to be active, got %v", err)
+	}
+	if _, err := modelHelper.RetractBallot(Channel1, UserID2); err != nil {
+		t.Errorf("Expected to retract from the only active vote, got %v", err)
+	}
+}
+
+func TestOpenVotes_LegacyStorage(t *testing.T) {
+	modelHelper, _ := initializeTests()
+
+	// Votes stored before the index existed only have the most recent pointer.
+	legacyVote := assertStartNewVote(t, modelHelper, Channel1, UserID1)
+	if err := modelHelper.StringMap.Delete(fmt.Sprintf(vote.KeyOpenVoteIDs, Channel1)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	assertOpenVoteIDs(t, modelHelper, Channel1, legacyVote.VoteID)
+
+	newVote := assertStartNewVote(t, modelHelper, Channel1, UserID2)
+	assertOpenVoteIDs(t, modelHelper, Channel1, legacyVote.VoteID, newVote.VoteID)
+	unconcluded, err := modelHelper.UnconcludedVotes()
+	if err != nil || len(unconcluded) != 2 {
+		t.Errorf("Expected both votes to be unconcluded, got %v (%v)", len(unconcluded), err)
+	}
 }
 
 func TestCastBallot_NoVoteActive(t *testing.T) {
@@ -236,7 +289,6 @@ func TestCastBallot_CanNotVoteTwiceAfterInFavor(t *testing.T) {
 	assertStartNewVote(t, modelHelper, Channel1, UserID1)
 	assertCastBallot(t, modelHelper, Channel1, UserID1, true)
 	assertCannotVoteAgain(t, modelHelper, Channel1, UserID1, true)
-	assertCannotVoteAgain(t, modelHelper, Channel1, UserID1, false)
 }
 
 func TestCastBallot_CanNotVoteTwiceAfterOpposed(t *testing.T) {
@@ -244,10 +296,63 @@ func TestCastBallot_CanNotVoteTwiceAfterOpposed(t *testing.T) {
 
 	assertStartNewVote(t, modelHelper, Channel1, UserID1)
 	assertCastBallot(t, modelHelper, Channel1, UserID1, false)
-	assertCannotVoteAgain(t, modelHelper, Channel1, UserID1, true)
 	assertCannotVoteAgain(t, modelHelper, Channel1, UserID1, false)
 }
 
+func TestCastBallot_SwitchesSides(t *testing.T) {
+	modelHelper, _ := initializeTests()
+
+	assertStartNewVote(t, modelHelper, Channel1, UserID1)
+	assertCastBallot(t, modelHelper, Channel1, UserID1, true)
+	assertCastBallot(t, modelHelper, Channel1, UserID1, false)
+	assertCastBallot(t, modelHelper, Channel1, UserID1, true)
+
+	vote, err := modelHelper.MostRecentVote(Channel1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(vote.VotesFor) != 1 || len(vote.VotesAgainst) != 0 {
+		t.Errorf("Expected a single ballot in favor, got %v for and %v against", vote.VotesFor, vote.VotesAgainst)
+	}
+}
+
+func TestCastBallot_LockedBallots(t *testing.T) {
+	modelHelper, _ := initializeTests()
+
+	if _, err := modelHelper.StartNewVote(Channel1, UserID1, "hug Jake", model.VoteOptions{LockBallots: true}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	assertCastBallot(t, modelHelper, Channel1, UserID1, true)
+	assertCannotVoteAgain(t, modelHelper, Channel1, UserID1, true)
+	if _, err := modelHelper.CastBallot(Channel1, UserID1, false); err != vote.ErrorBallotsLocked {
+		t.Errorf("Expected locked ballot, got %v", err)
+	}
+	if _, err := modelHelper.RetractBallot(Channel1, UserID1); err != vote.ErrorBallotsLocked {
+		t.Errorf("Expected locked ballot, got %v", err)
+	}
+}
+
+func TestRetractBallot(t *testing.T) {
+	modelHelper, _ := initializeTests()
+
+	if _, err := modelHelper.RetractBallot(Channel1, UserID1); err != vote.ErrorNoVoteActive {
+		t.Errorf("Expected no active vote, got %v", err)
+	}
+	assertStartNewVote(t, modelHelper, Channel1, UserID1)
+	if _, err := modelHelper.RetractBallot(Channel1, UserID1); err != vote.ErrorNotVoted {
+		t.Errorf("Expected not voted, got %v", err)
+	}
+	assertCastBallot(t, modelHelper, Channel1, UserID1, false)
+	retracted, err := modelHelper.RetractBallot(Channel1, UserID1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(retracted.VotesFor) != 0 || len(retracted.VotesAgainst) != 0 {
+		t.Errorf("Expected no ballots, got %v for and %v against", retracted.VotesFor, retracted.VotesAgainst)
+	}
+	assertCastBallot(t, modelHelper, Channel1, UserID1, true)
+}
+
 func TestCastBallot_LotsOfVotes(t *testing.T) {
 	modelHelper, _ := initializeTests()
 
@@ -355,7 +460,7 @@ func TestSetVoteOutcome_SucceedsWhenExpired(t *testing.T) {
 func initializeTests() (*vote.ModelHelper, *testutil.FakeUTCClock) {
 	stringMap := stringmap.NewInMemoryStringMap()
 	clock := testutil.NewFakeUTCClock()
-	return vote.NewModelHelper(stringMap, clock), clock
+	return vote.NewModelHelper(stringMap, clock, "secret" /* ballotSecret */), clock
 }
 
 func assertIsVoteActive(t *testing.T, modelHelper *vote.ModelHelper, channel model.Snowflake, active bool) {
@@ -394,19 +499,26 @@ func assertMostRecentVoteID(t *testing.T, modelHelper *vote.ModelHelper, channel
 	}
 }
 
-func assertStartNewVoteFails(t *testing.T, modelHelper *vote.ModelHelper, channelID, userID model.Snowflake) {
+func assertOpenVoteIDs(t *testing.T, modelHelper *vote.ModelHelper, channelID model.Snowflake, voteIDs ...int) {
 	t.Helper()
 
-	_, err := modelHelper.StartNewVote(channelID, userID, "hug Jake")
-	if err != vote.ErrorOnlyOneVote {
-		t.Errorf("Should have failed to add vote with ID %v", userID)
+	votes, err := modelHelper.OpenVotes(channelID)
+	if err != nil {
+		t.Fatalf("Should not have errored reading open votes: %v", err)
+	}
+	openVoteIDs := []int{}
+	for _, vote := range votes {
+		openVoteIDs = append(openVoteIDs, vote.VoteID)
+	}
+	if !reflect.DeepEqual(openVoteIDs, voteIDs) {
+		t.Errorf("Expected open votes %v, got %v", voteIDs, openVoteIDs)
 	}
 }
 
 func assertStartNewVote(t *testing.T, modelHelper *vote.ModelHelper, channelID, userID model.Snowflake) *model.Vote {
 	t.Helper()
 
-	vote, err := modelHelper.StartNewVote(channelID, userID, "hug Jake")
+	vote, err := modelHelper.StartNewVote(channelID, userID, "hug Jake", model.VoteOptions{})
 	if err != nil {
 		t.Errorf("Should have started a vote with ID %v", userID)
 	}
@@ -503,3 +615,81 @@ func assertSetVoteOutcomeFails(t *testing.T, modelHelper *vote.ModelHelper, chan
 		t.Errorf("Expected failure due to existing outcome: %v", err)
 	}
 }
+
+func TestMostRecentVote_DecodesVotesWithoutOptions(t *testing.T) {
+	modelHelper, _ := initializeTests()
+	stringMap := modelHelper.StringMap
+
+	// A vote written before votes had a quorum or threshold.
+	stringMap.Set("vote-1-channel-1", `{"VoteID":1,"ChannelID":1,"UserID":8675309,"Message":"hug Jake","VotesFor":[1,2,3],"VotesAgainst":[4,5],"VoteOutcome":1}`)
+	stringMap.Set("most-recent-vote-id-channel-1", "vote-1-channel-1")
+
+	vote, err := modelHelper.MostRecentVote(Channel1)
+	if err != nil {
+		t.Fatalf("Should have decoded the vote: %v", err)
+	}
+	if vote.RequiredBallots() != model.DefaultVoteQuorum || !vote.HasEnoughVotes() {
+		t.Errorf("Old votes should need %v ballots", model.DefaultVoteQuorum)
+	}
+	if !vote.Threshold.IsMajority() || vote.CalculateActiveStatus() != model.VoteOutcomePassed {
+		t.Errorf("Old votes should pass with a simple majority")
+	}
+}
+
+func TestSetVoteOutcomeByID_OlderVoteKeepsMostRecent(t *testing.T) {
+	modelHelper, _ := initializeTests()
+
+	first := assertStartNewVote(t, modelHelper, Channel1, UserID1)
+	if err := modelHelper.SetVoteOutcome(Channel1, model.VoteOutcomeCancelled); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	second := assertStartNewVote(t, modelHelper, Channel1, UserID1)
+
+	if err := modelHelper.SetVoteOutcomeByID(Channel1, first.VoteID, model.VoteOutcomePassed); err != vote.ErrorVoteHasOutcome {
+		t.Errorf("Expected the cancelled vote to keep its outcome, got %v", err)
+	}
+	if err := modelHelper.SetVoteOutcomeByID(Channel1, 5, model.VoteOutcomePassed); err != vote.ErrorNoVoteActive {
+		t.Errorf("Expected missing vote, got %v", err)
+	}
+	mostRecentVoteID, err := modelHelper.MostRecentVoteID(Channel1)
+	if err != nil || mostRecentVoteID != second.VoteID {
+		t.Errorf("Expected most recent vote %v, got %v (%v)", second.VoteID, mostRecentVoteID, err)
+	}
+}
+
+func TestSetVoteActionResult(t *testing.T) {
+	modelHelper, _ := initializeTests()
+
+	plain := assertStartNewVote(t, modelHelper, Channel1, UserID1)
+	if _, err := modelHelper.SetVoteActionResult(Channel1, plain.VoteID, "done", nil); err != vote.ErrorNoAction {
+		t.Errorf("Expected a vote without an action, got %v", err)
+	}
+	if err := modelHelper.SetVoteOutcome(Channel1, model.VoteOutcomeCancelled); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	action, err := modelHelper.StartNewVote(Channel1, UserID1, "Unlearn `?annoying`", model.VoteOptions{
+		Action: &model.VoteAction{Name: "unlearn", Args: "annoying"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if action.Action.Executed() {
+		t.Errorf("New actions should not be executed")
+	}
+
+	audited, err := modelHelper.SetVoteActionResult(Channel1, action.VoteID, "", errors.New("failed"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !audited.Action.Executed() || audited.Action.Error != "failed" || !audited.Action.TimestampExecuted.Equal(modelHelper.UTCClock.Now()) {
+		t.Errorf("Expected the failure to be recorded, got %+v", audited.Action)
+	}
+	if _, err := modelHelper.SetVoteActionResult(Channel1, action.VoteID, "done", nil); err != vote.ErrorActionExecuted {
+		t.Errorf("Expected the first result to be kept, got %v", err)
+	}
+	stored, err := modelHelper.Vote(Channel1, action.VoteID)
+	if err != nil || !reflect.DeepEqual(stored.Action, audited.Action) {
+		t.Errorf("Expected the audit record to be stored, got %+v (%v)", stored.Action, err)
+	}
+}
@@ -48,10 +48,10 @@ func TestHandleVotesOnInitialLoad_EmptyMap(t *testing.T) {
 	stringMap := stringmap.NewInMemoryStringMap()
 	timer := testutil.NewFakeUTCTimer()
 	clock := testutil.NewFakeUTCClock()
-	modelHelper := vote.NewModelHelper(stringMap, clock)
+	modelHelper := vote.NewModelHelper(stringMap, clock, "secret" /* ballotSecret */)
 	commandChannel := make(chan *model.Command, 10)
 
-	vote.HandleVotesOnInitialLoad(session, modelHelper, clock, timer, commandChannel)
+	vote.HandleVotesOnInitialLoad(session, modelHelper, clock, timer, commandChannel, []time.Duration{})
 
 	timer.ElapseTime(vote.VoteDuration)
 	clock.Advance(vote.VoteDuration)
@@ -68,15 +68,15 @@ func TestHandleVotesOnInitialLoad_HalfDoneVote(t *testing.T) {
 	stringMap := stringmap.NewInMemoryStringMap()
 	timer := testutil.NewFakeUTCTimer()
 	clock := testutil.NewFakeUTCClock()
-	modelHelper := vote.NewModelHelper(stringMap, clock)
+	modelHelper := vote.NewModelHelper(stringMap, clock, "secret" /* ballotSecret */)
 	commandChannel := make(chan *model.Command, 10)
 
-	modelHelper.StartNewVote(model.Snowflake(1) /* channelID */, model.Snowflake(2) /* userID */, "oh noes")
+	modelHelper.StartNewVote(model.Snowflake(1) /* channelID */, model.Snowflake(2) /* userID */, "oh noes", model.VoteOptions{})
 
 	timer.ElapseTime(vote.VoteDuration / 2)
 	clock.Advance(vote.VoteDuration / 2)
 
-	vote.HandleVotesOnInitialLoad(session, modelHelper, clock, timer, commandChannel)
+	vote.HandleVotesOnInitialLoad(session, modelHelper, clock, timer, commandChannel, []time.Duration{})
 
 	// Assert the channel is now empty.
 	select {
@@ -115,15 +115,15 @@ func TestHandleVotesOnInitialLoad_VoteExpired(t *testing.T) {
 	stringMap := stringmap.NewInMemoryStringMap()
 	timer := testutil.NewFakeUTCTimer()
 	clock := testutil.NewFakeUTCClock()
-	modelHelper := vote.NewModelHelper(stringMap, clock)
+	modelHelper := vote.NewModelHelper(stringMap, clock, "secret" /* ballotSecret */)
 	commandChannel := make(chan *model.Command, 10)
 
-	modelHelper.StartNewVote(model.Snowflake(1) /* channelID */, model.Snowflake(2) /* userID */, "oh noes")
+	modelHelper.StartNewVote(model.Snowflake(1) /* channelID */, model.Snowflake(2) /* userID */, "oh noes", model.VoteOptions{})
 
 	timer.ElapseTime(vote.VoteDuration)
 	clock.Advance(vote.VoteDuration)
 
-	vote.HandleVotesOnInitialLoad(session, modelHelper, clock, timer, commandChannel)
+	vote.HandleVotesOnInitialLoad(session, modelHelper, clock, timer, commandChannel, []time.Duration{})
 
 	// Assert the command currently in the channel.
 	select {
@@ -151,17 +151,17 @@ func TestHandleVotesOnInitialLoad_PreviousExpriedVotesDoNotCauseNewConcludes(t *
 	stringMap := stringmap.NewInMemoryStringMap()
 	timer := testutil.NewFakeUTCTimer()
 	clock := testutil.NewFakeUTCClock()
-	modelHelper := vote.NewModelHelper(stringMap, clock)
+	modelHelper := vote.NewModelHelper(stringMap, clock, "secret" /* ballotSecret */)
 	commandChannel := make(chan *model.Command, 10)
 
-	modelHelper.StartNewVote(model.Snowflake(1) /* channelID */, model.Snowflake(2) /* userID */, "oh noes")
+	modelHelper.StartNewVote(model.Snowflake(1) /* channelID */, model.Snowflake(2) /* userID */, "oh noes", model.VoteOptions{})
 
 	timer.ElapseTime(vote.VoteDuration)
 	clock.Advance(vote.VoteDuration)
 
 	modelHelper.SetVoteOutcome(model.Snowflake(1) /* channelID */, model.VoteOutcomeNotEnough)
 
-	vote.HandleVotesOnInitialLoad(session, modelHelper, clock, timer, commandChannel)
+	vote.HandleVotesOnInitialLoad(session, modelHelper, clock, timer, commandChannel, []time.Duration{})
 
 	timer.ElapseTime(vote.VoteDuration)
 	clock.Advance(vote.VoteDuration)
@@ -173,3 +173,84 @@ func TestHandleVotesOnInitialLoad_PreviousExpriedVotesDoNotCauseNewConcludes(t *
 	default:
 	}
 }
+
+func TestHandleVotesOnInitialLoad_ReschedulesRemainingReminders(t *testing.T) {
+	session := testutil.NewInMemoryDiscordSession()
+	stringMap := stringmap.NewInMemoryStringMap()
+	timer := testutil.NewFakeUTCTimer()
+	clock := testutil.NewFakeUTCClock()
+	modelHelper := vote.NewModelHelper(stringMap, clock, "secret" /* ballotSecret */)
+	commandChannel := make(chan *model.Command, 10)
+
+	modelHelper.StartNewVote(model.Snowflake(1) /* channelID */, model.Snowflake(2) /* userID */, "oh noes", model.VoteOptions{})
+
+	// Restart after the first reminder would have been sent.
+	clock.Advance(vote.VoteDuration - time.Duration(5)*time.Minute)
+	reminders := []time.Duration{time.Duration(10) * time.Minute, time.Duration(1) * time.Minute}
+	vote.HandleVotesOnInitialLoad(session, modelHelper, clock, timer, commandChannel, reminders)
+
+	expectedTypes := []int{model.CommandTypeVoteReminder, model.CommandTypeVoteConclude}
+	for _, expectedType := range expectedTypes {
+		timer.ElapseTime(time.Duration(4) * time.Minute)
+		clock.Advance(time.Duration(4) * time.Minute)
+
+		select {
+		case command := <-commandChannel:
+			if command.Type != expectedType {
+				t.Errorf("Expected command type %v, got %v", expectedType, command.Type)
+			}
+		default:
+			t.Errorf("Channel should have not been empty")
+		}
+		select {
+		case <-commandChannel:
+			t.Errorf("Channel should have been empty")
+		default:
+		}
+	}
+}
+
+func TestHandleVotesOnInitialLoad_OpensScheduledVotes(t *testing.T) {
+	session := testutil.NewInMemoryDiscordSession()
+	stringMap := stringmap.NewInMemoryStringMap()
+	timer := testutil.NewFakeUTCTimer()
+	clock := testutil.NewFakeUTCClock()
+	modelHelper := vote.NewModelHelper(stringMap, clock, "secret" /* ballotSecret */)
+	commandChannel := make(chan *model.Command, 10)
+
+	start := clock.Now().Add(time.Hour)
+	modelHelper.StartNewVote(model.Snowflake(1) /* channelID */, model.Snowflake(2) /* userID */, "oh noes", model.VoteOptions{Start: start})
+	vote.HandleVotesOnInitialLoad(session, modelHelper, clock, timer, commandChannel, []time.Duration{} /* reminders */)
+
+	timer.ElapseTime(time.Hour)
+	clock.Advance(time.Hour)
+	select {
+	case command := <-commandChannel:
+		if command.Type != model.CommandTypeVoteOpen {
+			t.Errorf("Expected command type %v, got %v", model.CommandTypeVoteOpen, command.Type)
+		}
+	default:
+		t.Errorf("Channel should have not been empty")
+	}
+
+	// Once the vote opened, restarting doesn't open it again.
+	if err := modelHelper.SetVoteOpened(model.Snowflake(1), 1 /* voteID */); err != nil {
+		t.Errorf("Error recording that the vote opened: %v", err)
+	}
+	timer = testutil.NewFakeUTCTimer()
+	vote.HandleVotesOnInitialLoad(session, modelHelper, clock, timer, commandChannel, []time.Duration{} /* reminders */)
+	timer.ElapseTime(vote.VoteDuration)
+	select {
+	case command := <-commandChannel:
+		if command.Type != model.CommandTypeVoteConclude {
+			t.Errorf("Expected command type %v, got %v", model.CommandTypeVoteConclude, command.Type)
+		}
+	default:
+		t.Errorf("Channel should have not been empty")
+	}
+	select {
+	case <-commandChannel:
+		t.Errorf("Channel should have been empty")
+	default:
+	}
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jakevoytko/crbot/model"
+)
+
+func TestTallyPoll_PluralityTie(t *testing.T) {
+	poll := newPoll(false /* ranked */, []int{0}, []int{1}, []int{2}, []int{1}, []int{0})
+	assertWinners(t, poll, []int{0, 1}, 1 /* rounds */)
+}
+
+func TestTallyPoll_NoBallots(t *testing.T) {
+	assertWinners(t, newPoll(false /* ranked */), []int{}, 1 /* rounds */)
+	assertWinners(t, newPoll(true /* ranked */), []int{}, 1 /* rounds */)
+}
+
+func TestTallyPoll_InstantRunoff(t *testing.T) {
+	// Option 2 is eliminated, and its ballot moves to option 1.
+	poll := newPoll(true /* ranked */, []int{0}, []int{0}, []int{1}, []int{1, 0}, []int{2, 1})
+	assertWinners(t, poll, []int{1}, 2 /* rounds */)
+}
+
+func TestTallyPoll_InstantRunoffMajority(t *testing.T) {
+	poll := newPoll(true /* ranked */, []int{0, 1}, []int{0}, []int{2})
+	assertWinners(t, poll, []int{0}, 1 /* rounds */)
+}
+
+func TestTallyPoll_InstantRunoffTie(t *testing.T) {
+	// Exhausted ballots stop counting, and the remaining options tie.
+	poll := newPoll(true /* ranked */, []int{0}, []int{1}, []int{2})
+	assertWinners(t, poll, []int{0, 1, 2}, 1 /* rounds */)
+}
+
+func newPoll(ranked bool, ballots ...[]int) *model.Vote {
+	poll := &model.Vote{
+		PollOptions: []string{"a", "b", "c"},
+		Ranked:      ranked,
+	}
+	for i, choices := range ballots {
+		poll.PollBallots = append(poll.PollBallots, model.PollBallot{
+			UserID:  model.Snowflake(i),
+			Choices: choices,
+		})
+	}
+	return poll
+}
+
+func assertWinners(t *testing.T, poll *model.Vote, winners []int, rounds int) {
+	t.Helper()
+
+	result := poll.TallyPoll()
+	if !reflect.DeepEqual(result.Winners, winners) {
+		t.Errorf("Expected winners %v, got %v", winners, result.Winners)
+	}
+	if len(result.Rounds) != rounds {
+		t.Errorf("Expected %v rounds, got %v", rounds, len(result.Rounds))
+	}
+}
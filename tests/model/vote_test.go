@@ -0,0 +1,73 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/jakevoytko/crbot/model"
+)
+
+func TestVoterHash_DependsOnSalt(t *testing.T) {
+	vote := &model.Vote{Secret: true, Salt: "salt"}
+	otherVote := &model.Vote{Secret: true, Salt: "pepper"}
+
+	if vote.VoterHash("secret", 1) != vote.VoterHash("secret", 1) {
+		t.Errorf("Expected the same voter to hash the same way")
+	}
+	if vote.VoterHash("secret", 1) == vote.VoterHash("secret", 2) {
+		t.Errorf("Expected different voters to hash differently")
+	}
+	if vote.VoterHash("secret", 1) == otherVote.VoterHash("secret", 1) {
+		t.Errorf("Expected the salt to change the hash")
+	}
+	if vote.VoterHash("secret", 1) == vote.VoterHash("other secret", 1) {
+		t.Errorf("Expected the ballot secret to change the hash")
+	}
+}
+
+func TestTally_CountsSecretBallots(t *testing.T) {
+	vote := &model.Vote{
+		Secret: true,
+		Salt:   "salt",
+	}
+	vote.SecretBallots = []model.SecretBallot{
+		{VoterHash: vote.VoterHash("secret", 1), InFavor: true},
+		{VoterHash: vote.VoterHash("secret", 2), InFavor: false},
+		{VoterHash: vote.VoterHash("secret", 3), InFavor: true},
+	}
+
+	if votesFor, votesAgainst := vote.Tally(); votesFor != 2 || votesAgainst != 1 {
+		t.Errorf("Expected 2 for and 1 against, got %v and %v", votesFor, votesAgainst)
+	}
+	if voted, inFavor := vote.Ballot("secret", 2); !voted || inFavor {
+		t.Errorf("Expected user 2 to have voted against")
+	}
+	if voted, _ := vote.Ballot("secret", 4); voted {
+		t.Errorf("Expected user 4 not to have voted")
+	}
+}
+
+func TestWeightedTally(t *testing.T) {
+	vote := &model.Vote{
+		VotesFor:      []model.Snowflake{1},
+		VotesAgainst:  []model.Snowflake{2, 3},
+		Weighting:     "roles",
+		BallotWeights: map[model.Snowflake]int{1: 3, 2: 1},
+		SecretBallots: []model.SecretBallot{{VoterHash: "hash", InFavor: false, Weight: 2}},
+	}
+
+	if votesFor, votesAgainst := vote.Tally(); votesFor != 1 || votesAgainst != 3 {
+		t.Errorf("Expected 1 ballot for and 3 against, got %v and %v", votesFor, votesAgainst)
+	}
+	// Ballots without a recorded weight count once.
+	if weightFor, weightAgainst := vote.WeightedTally(); weightFor != 3 || weightAgainst != 4 {
+		t.Errorf("Expected weight 3 for and 4 against, got %v and %v", weightFor, weightAgainst)
+	}
+	if vote.CalculateActiveStatus() != model.VoteOutcomeFailed {
+		t.Errorf("Expected the heavier side to win")
+	}
+
+	vote.BallotWeights[1] = 5
+	if vote.CalculateActiveStatus() != model.VoteOutcomePassed {
+		t.Errorf("Expected the heavier side to win")
+	}
+}
@@ -2,6 +2,7 @@ package testutil
 
 import (
 	"errors"
+	"strconv"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
@@ -21,11 +22,23 @@ func NewMessage(channel, message string) *Message {
 	}
 }
 
+// Reaction is an emoji reaction that the bot added to a message.
+type Reaction struct {
+	Channel   string
+	MessageID string
+	Emoji     string
+}
+
 // InMemoryDiscordSession is a fake for the discord session.
 type InMemoryDiscordSession struct {
-	Messages  []*Message
-	Users     map[string]*discordgo.User
-	Channels  map[string]*discordgo.Channel
+	Messages []*Message
+	// MessageIDs holds the ID of each message in Messages.
+	MessageIDs []string
+	Reactions  []*Reaction
+	Users      map[string]*discordgo.User
+	Channels   map[string]*discordgo.Channel
+	// Members holds guild members by guild ID, then user ID.
+	Members   map[string]map[string]*discordgo.Member
 	currentID int
 	author    *discordgo.User
 }
@@ -64,20 +77,26 @@ func NewInMemoryDiscordSession() *InMemoryDiscordSession {
 	users["2"] = rickListedUser
 
 	return &InMemoryDiscordSession{
-		Messages:  []*Message{},
-		Channels:  channels,
-		Users:     users,
-		currentID: 0,
-		author:    author,
+		Messages:   []*Message{},
+		MessageIDs: []string{},
+		Reactions:  []*Reaction{},
+		Channels:   channels,
+		Users:      users,
+		Members:    make(map[string]map[string]*discordgo.Member),
+		currentID:  0,
+		author:     author,
 	}
 }
 
 // ChannelMessageSend records a new message delivery.
 func (s *InMemoryDiscordSession) ChannelMessageSend(channel string, message string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	s.currentID++
+	id := strconv.Itoa(s.currentID)
 	s.Messages = append(s.Messages, NewMessage(channel, message))
+	s.MessageIDs = append(s.MessageIDs, id)
 	editedTimestamp := time.Now()
 	return &discordgo.Message{
-		ID:              "id",
+		ID:              id,
 		ChannelID:       channel,
 		Content:         message,
 		Timestamp:       time.Now().Add(-time.Hour),
@@ -116,3 +135,33 @@ func (s *InMemoryDiscordSession) User(userID string, options ...discordgo.Reques
 	}
 	return nil, errors.New("Attempted to get missing user " + userID)
 }
+
+// MessageReactionAdd records a reaction added by the bot.
+func (s *InMemoryDiscordSession) MessageReactionAdd(channelID, messageID, emojiID string, options ...discordgo.RequestOption) error {
+	s.Reactions = append(s.Reactions, &Reaction{
+		Channel:   channelID,
+		MessageID: messageID,
+		Emoji:     emojiID,
+	})
+	return nil
+}
+
+// SetMember adds a member to the given guild. Can be used to give users roles.
+func (s *InMemoryDiscordSession) SetMember(guildID string, member *discordgo.Member) {
+	if s.Members[guildID] == nil {
+		s.Members[guildID] = make(map[string]*discordgo.Member)
+	}
+	s.Members[guildID][member.User.ID] = member
+}
+
+// GuildMember returns the member of the given guild. Known users that weren't
+// added with SetMember are members without roles.
+func (s *InMemoryDiscordSession) GuildMember(guildID, userID string, options ...discordgo.RequestOption) (*discordgo.Member, error) {
+	if member := s.Members[guildID][userID]; member != nil {
+		return member, nil
+	}
+	if user := s.Users[userID]; user != nil {
+		return &discordgo.Member{GuildID: guildID, User: user, Roles: []string{}}, nil
+	}
+	return nil, errors.New("Attempted to get missing member " + userID)
+}
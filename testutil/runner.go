@@ -21,6 +21,8 @@ import (
 	"github.com/jakevoytko/crbot/feature/learn"
 	"github.com/jakevoytko/crbot/feature/list"
 	"github.com/jakevoytko/crbot/feature/moderation"
+	"github.com/jakevoytko/crbot/feature/permissions"
+	"github.com/jakevoytko/crbot/feature/toggle"
 	"github.com/jakevoytko/crbot/feature/vote"
 	"github.com/jakevoytko/crbot/model"
 	stringmap "github.com/jakevoytko/go-stringmap"
@@ -28,9 +30,12 @@ import (
 
 // IDs used for testing
 const (
-	MainChannelID   = model.Snowflake(8675309)
-	SecondChannelID = model.Snowflake(9000000)
-	DirectMessageID = model.Snowflake(1)
+	MainChannelID       = model.Snowflake(8675309)
+	SecondChannelID     = model.Snowflake(9000000)
+	DirectMessageID     = model.Snowflake(1)
+	OtherGuildChannelID = model.Snowflake(4815162342)
+	MainGuildID         = model.Snowflake(1234)
+	OtherGuildID        = model.Snowflake(5678)
 )
 
 // Runner is a helper that executes messages incrementally, and asserts that
@@ -46,44 +51,66 @@ type Runner struct {
 	ActiveVoteDataMap    map[model.Snowflake]*VoteData // channel->vote. May be nil
 
 	// Fakes
-	CustomMap      *stringmap.InMemoryStringMap
-	KarmaMap       *stringmap.InMemoryStringMap
-	VoteMap        *stringmap.InMemoryStringMap
-	Gist           *InMemoryGist
-	DiscordSession *InMemoryDiscordSession
-	UTCClock       *FakeUTCClock
-	UTCTimer       *FakeUTCTimer
+	CustomMap       *stringmap.InMemoryStringMap
+	KarmaMap        *stringmap.InMemoryStringMap
+	KarmaHistoryMap *stringmap.InMemoryStringMap
+	KarmaMergeMap   *stringmap.InMemoryStringMap
+	VoteMap         *stringmap.InMemoryStringMap
+	ModerationMap   *stringmap.InMemoryStringMap
+	ToggleMap       *stringmap.InMemoryStringMap
+	Gist            *InMemoryGist
+	DiscordSession  *InMemoryDiscordSession
+	UTCClock        *FakeUTCClock
+	UTCTimer        *FakeUTCTimer
 
 	// Real objects
+	Config          *config.Config
 	FeatureRegistry *feature.Registry
 
 	// Controllers under test
-	Handler func(api.DiscordSession, *discordgo.MessageCreate)
+	Handler         func(api.DiscordSession, *discordgo.MessageCreate)
+	ReactionHandler func(api.DiscordSession, *discordgo.MessageReaction, *discordgo.Member, bool)
 }
 
 // NewRunner works as advertised
 func NewRunner(t *testing.T) *Runner {
+	config := config.NewConfig()
+	config.RickList = []model.Snowflake{model.Snowflake(2)}
+	return NewRunnerWithConfig(t, &config)
+}
+
+// NewRunnerWithConfig returns a runner for a bot with the given config.
+func NewRunnerWithConfig(t *testing.T, config *config.Config) *Runner {
 	// Initialize fakes.
 	customMap := stringmap.NewInMemoryStringMap()
 	karmaMap := stringmap.NewInMemoryStringMap()
+	karmaHistoryMap := stringmap.NewInMemoryStringMap()
+	karmaMergeMap := stringmap.NewInMemoryStringMap()
 	voteMap := stringmap.NewInMemoryStringMap()
+	moderationMap := stringmap.NewInMemoryStringMap()
+	toggleMap := stringmap.NewInMemoryStringMap()
 	gist := NewInMemoryGist()
 	discordSession := NewInMemoryDiscordSession()
 	discordSession.SetChannel(&discordgo.Channel{
-		ID:   MainChannelID.Format(),
-		Type: discordgo.ChannelTypeGuildText,
+		ID:      MainChannelID.Format(),
+		GuildID: MainGuildID.Format(),
+		Type:    discordgo.ChannelTypeGuildText,
+	})
+	discordSession.SetChannel(&discordgo.Channel{
+		ID:      SecondChannelID.Format(),
+		GuildID: MainGuildID.Format(),
+		Type:    discordgo.ChannelTypeGuildText,
 	})
 	discordSession.SetChannel(&discordgo.Channel{
-		ID:   SecondChannelID.Format(),
-		Type: discordgo.ChannelTypeGuildText,
+		ID:      OtherGuildChannelID.Format(),
+		GuildID: OtherGuildID.Format(),
+		Type:    discordgo.ChannelTypeGuildText,
 	})
 	discordSession.SetChannel(&discordgo.Channel{
 		ID:   DirectMessageID.Format(),
 		Type: discordgo.ChannelTypeDM,
 	})
 
-	rickList := []model.Snowflake{model.Snowflake(2)}
-
 	utcClock := NewFakeUTCClock()
 
 	// 0-length channel. Each time it consumes/processes a command, it issues a
@@ -92,9 +119,15 @@ func NewRunner(t *testing.T) *Runner {
 
 	utcTimer := NewFakeUTCTimer()
 
-	registry := app.InitializeRegistry(customMap, karmaMap, voteMap, gist, &config.Config{RickList: rickList}, utcClock, utcTimer, commandChannel)
+	registry := app.InitializeRegistry(customMap, karmaMap, karmaHistoryMap, karmaMergeMap, voteMap, moderationMap, toggleMap, gist, config, utcClock, utcTimer, commandChannel)
+
+	// The ricklist is seeded from the config when crbot starts. The other initial
+	// load functions are left to the tests that need them.
+	if _, err := moderation.NewModelHelper(moderationMap, utcClock).SeedRickList(config.RickList); err != nil {
+		t.Fatalf("Error seeding the ricklist: %v", err)
+	}
 
-	go app.HandleCommands(registry, discordSession, commandChannel)
+	go app.HandleCommands(registry, config, toggle.NewModelHelper(toggleMap), discordSession, commandChannel)
 
 	return &Runner{
 		T:                    t,
@@ -104,13 +137,19 @@ func NewRunner(t *testing.T) *Runner {
 		DiscordMessagesCount: 0,
 		CustomMap:            customMap,
 		KarmaMap:             karmaMap,
+		KarmaHistoryMap:      karmaHistoryMap,
+		KarmaMergeMap:        karmaMergeMap,
 		VoteMap:              voteMap,
+		ModerationMap:        moderationMap,
+		ToggleMap:            toggleMap,
 		Gist:                 gist,
 		DiscordSession:       discordSession,
 		UTCClock:             utcClock,
 		UTCTimer:             utcTimer,
+		Config:               config,
 		FeatureRegistry:      registry,
 		Handler:              app.GetHandleMessage(customMap, registry, commandChannel),
+		ReactionHandler:      app.GetHandleReaction(commandChannel),
 	}
 }
 
@@ -152,6 +191,17 @@ func (r *Runner) SendMessageAs(author *discordgo.User, channel model.Snowflake,
 	r.AssertState()
 }
 
+// SendMessageAsWithResponses sends a message as the given user, and asserts
+// that the bot sent the expected messages, which may be in other channels.
+func (r *Runner) SendMessageAsWithResponses(author *discordgo.User, channel model.Snowflake, message string, expectedResponses ...*Message) {
+	r.T.Helper()
+
+	sendMessageAs(author, r.DiscordSession, r.Handler, channel, message)
+	r.DiscordMessagesCount += len(expectedResponses)
+	assertNewMessages(r.T, r.DiscordSession, expectedResponses)
+	r.AssertState()
+}
+
 // SendMessageIgnoringResponse sends a message to the bot without checking the output
 func (r *Runner) SendMessageIgnoringResponse(channel model.Snowflake, message string) {
 	r.T.Helper()
@@ -182,7 +232,7 @@ func (r *Runner) SendVoteMessageAs(author *discordgo.User, channel model.Snowfla
 	r.DiscordMessagesCount++
 	r.ActiveVoteDataMap[channel] = newVoteData(channel, author, "a vote has been called", r.UTCClock.Now().Add(vote.VoteDuration))
 	assertNewMessages(r.T, r.DiscordSession,
-		[]*Message{NewMessage(channel.Format(), fmt.Sprintf(vote.MsgBroadcastNewVote, author.Mention(), "a vote has been called"))})
+		[]*Message{NewMessage(channel.Format(), fmt.Sprintf(vote.MsgBroadcastNewVote, author.Mention(), "a vote has been called", vote.TimeString(r.UTCClock, r.ActiveVoteDataMap[channel].TimestampEnd)))})
 	r.AssertState()
 }
 
@@ -236,6 +286,54 @@ func (r *Runner) CastDuplicateBallotAs(author *discordgo.User, channel model.Sno
 	r.AssertState()
 }
 
+// ChangeBallotAs switches the user's ballot to the given side.
+func (r *Runner) ChangeBallotAs(author *discordgo.User, channel model.Snowflake, inFavor bool) {
+	r.T.Helper()
+
+	voteString := "?no"
+	expectedMessage := fmt.Sprintf(vote.MsgChangedVoteAgainst, author.Mention())
+	if inFavor {
+		voteString = "?yes"
+		expectedMessage = fmt.Sprintf(vote.MsgChangedVoteInFavor, author.Mention())
+	}
+
+	sendMessageAs(author, r.DiscordSession, r.Handler, channel, voteString)
+
+	// Update internal state.
+	r.DiscordMessagesCount++
+	id, _ := model.ParseSnowflake(author.ID)
+	activeVote := r.ActiveVoteDataMap[channel]
+	activeVote.removeBallot(id)
+	if inFavor {
+		activeVote.VotesFor = append(activeVote.VotesFor, id)
+	} else {
+		activeVote.VotesAgainst = append(activeVote.VotesAgainst, id)
+	}
+
+	assertNewMessages(r.T, r.DiscordSession, []*Message{
+		NewMessage(channel.Format(), expectedMessage+"\n"+vote.StatusLine(r.UTCClock, activeVote.Reconstruct())),
+	})
+	r.AssertState()
+}
+
+// RetractBallotAs retracts the user's ballot.
+func (r *Runner) RetractBallotAs(author *discordgo.User, channel model.Snowflake) {
+	r.T.Helper()
+
+	sendMessageAs(author, r.DiscordSession, r.Handler, channel, "?unvote")
+
+	// Update internal state.
+	r.DiscordMessagesCount++
+	id, _ := model.ParseSnowflake(author.ID)
+	activeVote := r.ActiveVoteDataMap[channel]
+	activeVote.removeBallot(id)
+
+	assertNewMessages(r.T, r.DiscordSession, []*Message{
+		NewMessage(channel.Format(), fmt.Sprintf(vote.MsgRetracted, author.Mention())+"\n"+vote.StatusLine(r.UTCClock, activeVote.Reconstruct())),
+	})
+	r.AssertState()
+}
+
 // ExpireVote advances the clock enough that the vote expires, and fires the trigger.
 func (r *Runner) ExpireVote(channel model.Snowflake) {
 	r.T.Helper()
@@ -279,6 +377,58 @@ func (r *Runner) ExpireVote(channel model.Snowflake) {
 	r.AssertState()
 }
 
+// ElapseTime advances the clock and timer together, and asserts that the
+// timers that fired sent the expected messages to the channel.
+func (r *Runner) ElapseTime(channel model.Snowflake, duration time.Duration, expectedResponses ...string) {
+	r.T.Helper()
+
+	r.UTCClock.Advance(duration)
+	r.UTCTimer.ElapseTime(duration)
+	flushChannel(r.DiscordSession, r.Handler, channel)
+
+	messages := make([]*Message, 0, len(expectedResponses))
+	for _, response := range expectedResponses {
+		messages = append(messages, NewMessage(channel.Format(), response))
+	}
+	r.DiscordMessagesCount += len(messages)
+	assertNewMessages(r.T, r.DiscordSession, messages)
+	r.AssertState()
+}
+
+// ReactAs adds or removes the user's reaction to the given message, and asserts
+// that the bot sent the expected responses to the channel.
+func (r *Runner) ReactAs(author *discordgo.User, channel model.Snowflake, messageID, emoji string, added bool, expectedResponses ...string) {
+	r.T.Helper()
+
+	// Reactions in guild channels carry the guild ID, like they do in Discord.
+	guildID := ""
+	if discordChannel, err := r.DiscordSession.Channel(channel.Format()); err == nil {
+		guildID = discordChannel.GuildID
+	}
+	reaction := &discordgo.MessageReaction{
+		UserID:    author.ID,
+		MessageID: messageID,
+		Emoji:     discordgo.Emoji{Name: emoji},
+		ChannelID: channel.Format(),
+		GuildID:   guildID,
+	}
+	// Discord only sends the member for reactions that are added in a guild.
+	var member *discordgo.Member
+	if added && len(guildID) > 0 {
+		member = &discordgo.Member{User: author}
+	}
+	r.ReactionHandler(r.DiscordSession, reaction, member, added)
+	flushChannel(r.DiscordSession, r.Handler, channel)
+
+	messages := make([]*Message, 0, len(expectedResponses))
+	for _, response := range expectedResponses {
+		messages = append(messages, NewMessage(channel.Format(), response))
+	}
+	r.DiscordMessagesCount += len(messages)
+	assertNewMessages(r.T, r.DiscordSession, messages)
+	r.AssertState()
+}
+
 // SendLearnMessageAs sends a ?learn message as the given user
 func (r *Runner) SendLearnMessageAs(author *discordgo.User, channel model.Snowflake, message string, learnData *LearnData) {
 	r.T.Helper()
@@ -336,6 +486,12 @@ func (r *Runner) SendListMessage(channel model.Snowflake) {
 		buffer.WriteString(" - ?--: ")
 		buffer.WriteString(karma.MsgHelpKarmaDecrement)
 		buffer.WriteString("\n")
+		buffer.WriteString(" - ?audit: ")
+		buffer.WriteString(moderation.MsgHelpAudit)
+		buffer.WriteString("\n")
+		buffer.WriteString(" - ?ballot: ")
+		buffer.WriteString(vote.MsgHelpSecretBallot)
+		buffer.WriteString("\n")
 		buffer.WriteString(" - ?f1: ")
 		buffer.WriteString(vote.MsgHelpBallotInFavor)
 		buffer.WriteString("\n")
@@ -345,30 +501,69 @@ func (r *Runner) SendListMessage(channel model.Snowflake) {
 		buffer.WriteString(" - ?factsphere: ")
 		buffer.WriteString(factsphere.MsgHelpFactSphere)
 		buffer.WriteString("\n")
+		buffer.WriteString(" - ?feature: ")
+		buffer.WriteString(toggle.MsgHelpFeature)
+		buffer.WriteString("\n")
 		buffer.WriteString(" - ?help: ")
 		buffer.WriteString(help.MsgHelpHelp)
 		buffer.WriteString("\n")
+		buffer.WriteString(" - ?karma: ")
+		buffer.WriteString(karma.MsgHelpKarmaInfo)
+		buffer.WriteString("\n")
+		buffer.WriteString(" - ?karmaalias: ")
+		buffer.WriteString(karma.MsgHelpKarmaAlias)
+		buffer.WriteString("\n")
 		buffer.WriteString(" - ?karmalist: ")
 		buffer.WriteString(karmalist.MsgHelpKarmaList)
 		buffer.WriteString("\n")
+		buffer.WriteString(" - ?karmamerge: ")
+		buffer.WriteString(karma.MsgHelpKarmaMerge)
+		buffer.WriteString("\n")
+		buffer.WriteString(" - ?karmaunmerge: ")
+		buffer.WriteString(karma.MsgHelpKarmaUnmerge)
+		buffer.WriteString("\n")
 		buffer.WriteString(" - ?learn: ")
 		buffer.WriteString(learn.MsgHelpLearn)
 		buffer.WriteString("\n")
 		buffer.WriteString(" - ?list: ")
 		buffer.WriteString(list.MsgHelpList)
 		buffer.WriteString("\n")
+		buffer.WriteString(" - ?mute: ")
+		buffer.WriteString(moderation.MsgHelpMute)
+		buffer.WriteString("\n")
+		buffer.WriteString(" - ?mutes: ")
+		buffer.WriteString(moderation.MsgHelpMutes)
+		buffer.WriteString("\n")
 		buffer.WriteString(" - ?no: ")
 		buffer.WriteString(vote.MsgHelpBallotAgainst)
 		buffer.WriteString("\n")
+		buffer.WriteString(" - ?permissions: ")
+		buffer.WriteString(permissions.MsgHelpPermissions)
+		buffer.WriteString("\n")
+		buffer.WriteString(" - ?pick: ")
+		buffer.WriteString(vote.MsgHelpPick)
+		buffer.WriteString("\n")
+		buffer.WriteString(" - ?poll: ")
+		buffer.WriteString(vote.MsgHelpPoll)
+		buffer.WriteString("\n")
+		buffer.WriteString(" - ?rank: ")
+		buffer.WriteString(vote.MsgHelpRank)
+		buffer.WriteString("\n")
 		buffer.WriteString(" - ?ricklist: ")
 		buffer.WriteString(moderation.MsgHelpRickListInfo)
 		buffer.WriteString("\n")
 		buffer.WriteString(" - ?unlearn: ")
 		buffer.WriteString(learn.MsgHelpUnlearn)
 		buffer.WriteString("\n")
+		buffer.WriteString(" - ?unvote: ")
+		buffer.WriteString(vote.MsgHelpRetract)
+		buffer.WriteString("\n")
 		buffer.WriteString(" - ?vote: ")
 		buffer.WriteString(vote.MsgHelpVote)
 		buffer.WriteString("\n")
+		buffer.WriteString(" - ?votehistory: ")
+		buffer.WriteString(vote.MsgHelpHistory)
+		buffer.WriteString("\n")
 		buffer.WriteString(" - ?votestatus: ")
 		buffer.WriteString(vote.MsgHelpStatus)
 		buffer.WriteString("\n")
@@ -411,7 +606,13 @@ func (r *Runner) SendKarmaListMessage(channel model.Snowflake) {
 	r.GistsCount++
 	assertNewMessages(r.T, r.DiscordSession, []*Message{NewMessage(channel.Format(), "The list of karma is here: https://www.example.com/success")})
 	if r.GistsCount > 0 {
-		karmaRunner := karmalist.NewModelHelper(r.KarmaMap)
+		guildID := model.Snowflake(0)
+		if discordChannel, err := r.DiscordSession.Channel(channel.Format()); err == nil && len(discordChannel.GuildID) > 0 {
+			if guildID, err = model.ParseSnowflake(discordChannel.GuildID); err != nil {
+				r.T.Fatalf("Invalid guild ID: %v", err)
+			}
+		}
+		karmaRunner := karmalist.NewModelHelper(r.KarmaMap, r.KarmaHistoryMap, r.UTCClock, r.Config.KarmaDecayHalfLife()).ForGuild(guildID)
 		generated := karmaRunner.GenerateList()
 		actual := r.Gist.Messages[len(r.Gist.Messages)-1]
 		if generated != actual {
@@ -443,7 +644,7 @@ func (r *Runner) SendVoteStatusMessage(channel model.Snowflake) {
 		if len(activeVote.VotesAgainst) != 1 {
 			againstMessage = fmt.Sprintf(vote.MsgVotesAgainst, len(r.ActiveVoteDataMap[channel].VotesAgainst))
 		}
-		statusMessage := vote.MsgStatusVotesNeeded
+		statusMessage := fmt.Sprintf(vote.MsgStatusVotesNeeded, model.DefaultVoteQuorum)
 		if len(activeVote.VotesAgainst)+len(activeVote.VotesFor) >= 5 {
 			if len(activeVote.VotesFor) > len(activeVote.VotesAgainst) {
 				statusMessage = vote.MsgStatusVotePassing
@@ -515,6 +716,22 @@ func newVoteData(channel model.Snowflake, author *discordgo.User, message string
 	}
 }
 
+// removeBallot removes the user's ballot from either side.
+func (v *VoteData) removeBallot(userID model.Snowflake) {
+	v.VotesFor = removeSnowflake(v.VotesFor, userID)
+	v.VotesAgainst = removeSnowflake(v.VotesAgainst, userID)
+}
+
+func removeSnowflake(snowflakes []model.Snowflake, toRemove model.Snowflake) []model.Snowflake {
+	result := []model.Snowflake{}
+	for _, snowflake := range snowflakes {
+		if snowflake != toRemove {
+			result = append(result, snowflake)
+		}
+	}
+	return result
+}
+
 // Reconstruct creates a vote.Vote out of the local storage vote. This isn't
 // meant to be a complete reconstruction, but rather all the info necessary for
 // testing (mostly reproducing status lines).
@@ -559,7 +776,7 @@ func assertNumDiscordMessages(t *testing.T, discordSession *InMemoryDiscordSessi
 func assertVote(t *testing.T, utcClock model.UTCClock, voteMap *stringmap.InMemoryStringMap, activeVoteMap map[model.Snowflake]*VoteData) {
 	t.Helper()
 
-	modelHelper := vote.NewModelHelper(voteMap, utcClock)
+	modelHelper := vote.NewModelHelper(voteMap, utcClock, "" /* ballotSecret */)
 	for channel, vote := range activeVoteMap {
 		ok, _ := modelHelper.IsVoteActive(channel)
 		if vote != nil && !ok {
@@ -588,11 +805,18 @@ func sendMessage(discordSession api.DiscordSession, handler func(api.DiscordSess
 }
 
 func sendMessageAs(author *discordgo.User, discordSession api.DiscordSession, handler func(api.DiscordSession, *discordgo.MessageCreate), channel model.Snowflake, message string) {
+	// Messages in guild channels carry the guild ID, like they do in Discord.
+	guildID := ""
+	if discordChannel, err := discordSession.Channel(channel.Format()); err == nil {
+		guildID = discordChannel.GuildID
+	}
+
 	editedTimestamp := time.Now()
 	messageCreate := &discordgo.MessageCreate{
 		Message: &discordgo.Message{
 			ID:              "messageID",
 			ChannelID:       channel.Format(),
+			GuildID:         guildID,
 			Content:         message,
 			Timestamp:       time.Now().Add(-time.Hour),
 			EditedTimestamp: &editedTimestamp,